@@ -122,7 +122,7 @@ func seedBands(bandsStore *store.SQLiteBandsStore, users []*store.User) []*store
 		// Add additional members
 		for _, memberIdx := range data.members {
 			if memberIdx < len(users) && memberIdx != data.creatorIdx {
-				_, err := bandsStore.AddBandMember(band.ID, users[memberIdx].ID, "member")
+				_, err := bandsStore.AddBandMember(band.ID, users[memberIdx].ID, store.RoleEditor)
 				if err != nil {
 					fmt.Printf("Warning: Failed to add member to band %s: %v\n", data.name, err)
 				}
@@ -146,6 +146,7 @@ func seedSongs(songsStore *store.SQLiteSongsStore, bands []*store.Band, users []
 		artist     string
 		key        string
 		tempo      *int
+		duration   *int
 		notes      string
 		bandIdx    int
 		creatorIdx int
@@ -156,6 +157,7 @@ func seedSongs(songsStore *store.SQLiteSongsStore, bands []*store.Band, users []
 			artist:     "Scorpions",
 			key:        "D",
 			tempo:      intPtr(75),
+			duration:   intPtr(386),
 			notes:      "Power ballad with emotional guitar work",
 			bandIdx:    0,
 			creatorIdx: 0,
@@ -165,6 +167,7 @@ func seedSongs(songsStore *store.SQLiteSongsStore, bands []*store.Band, users []
 			artist:     "Crowded House",
 			key:        "C",
 			tempo:      intPtr(85),
+			duration:   intPtr(236),
 			notes:      "Melodic pop-rock with memorable chorus",
 			bandIdx:    0,
 			creatorIdx: 1,
@@ -174,6 +177,7 @@ func seedSongs(songsStore *store.SQLiteSongsStore, bands []*store.Band, users []
 			artist:     "Whitesnake",
 			key:        "A",
 			tempo:      intPtr(95),
+			duration:   intPtr(283),
 			notes:      "Classic rock ballad with powerful vocals",
 			bandIdx:    0,
 			creatorIdx: 2,
@@ -183,6 +187,7 @@ func seedSongs(songsStore *store.SQLiteSongsStore, bands []*store.Band, users []
 			artist:     "Tears for Fears",
 			key:        "D",
 			tempo:      intPtr(120),
+			duration:   intPtr(251),
 			notes:      "80s synth-pop with driving rhythm",
 			bandIdx:    0,
 			creatorIdx: 0,
@@ -192,6 +197,7 @@ func seedSongs(songsStore *store.SQLiteSongsStore, bands []*store.Band, users []
 			artist:     "Jon Secada",
 			key:        "G",
 			tempo:      intPtr(80),
+			duration:   intPtr(277),
 			notes:      "Latin pop ballad with emotional delivery",
 			bandIdx:    0,
 			creatorIdx: 1,
@@ -201,6 +207,7 @@ func seedSongs(songsStore *store.SQLiteSongsStore, bands []*store.Band, users []
 			artist:     "Dire Straits",
 			key:        "D",
 			tempo:      intPtr(135),
+			duration:   intPtr(506),
 			notes:      "Rock anthem with iconic guitar riff",
 			bandIdx:    0,
 			creatorIdx: 2,
@@ -211,6 +218,7 @@ func seedSongs(songsStore *store.SQLiteSongsStore, bands []*store.Band, users []
 			artist:     "Dave Brubeck",
 			key:        "Eb",
 			tempo:      intPtr(176),
+			duration:   intPtr(324),
 			notes:      "Jazz standard in 5/4 time signature",
 			bandIdx:    1,
 			creatorIdx: 1,
@@ -220,6 +228,7 @@ func seedSongs(songsStore *store.SQLiteSongsStore, bands []*store.Band, users []
 			artist:     "Miles Davis",
 			key:        "D",
 			tempo:      intPtr(160),
+			duration:   intPtr(562),
 			notes:      "Modal jazz composition from Kind of Blue",
 			bandIdx:    1,
 			creatorIdx: 3,
@@ -230,6 +239,7 @@ func seedSongs(songsStore *store.SQLiteSongsStore, bands []*store.Band, users []
 			artist:     "Oasis",
 			key:        "F#m",
 			tempo:      intPtr(87),
+			duration:   intPtr(258),
 			notes:      "Acoustic version with simplified arrangement",
 			bandIdx:    2,
 			creatorIdx: 2,
@@ -239,6 +249,7 @@ func seedSongs(songsStore *store.SQLiteSongsStore, bands []*store.Band, users []
 			artist:     "Jeff Buckley",
 			key:        "C",
 			tempo:      intPtr(72),
+			duration:   intPtr(413),
 			notes:      "Emotional ballad with fingerpicking",
 			bandIdx:    2,
 			creatorIdx: 4,
@@ -259,7 +270,9 @@ func seedSongs(songsStore *store.SQLiteSongsStore, bands []*store.Band, users []
 			data.notes,
 			"", // Empty content field - will be generated by AI if needed
 			users[data.creatorIdx].ID,
+			store.SongRevisionManual,
 			data.tempo,
+			data.duration,
 		)
 		if err != nil {
 			fmt.Printf("Warning: Failed to create song %s: %v\n", data.title, err)
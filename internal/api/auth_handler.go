@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"log"
 	"net/http"
+	"strings"
 
 	"github.com/nahue/setlist_manager/internal/services"
 	"github.com/nahue/setlist_manager/internal/store"
@@ -13,15 +14,17 @@ import (
 
 // Handler handles authentication-related requests
 type AuthHandler struct {
-	authDB  *store.SQLiteAuthStore
-	bandsDB *store.SQLiteBandsStore
+	authDB      *store.SQLiteAuthStore
+	bandsDB     *store.SQLiteBandsStore
+	mailService *services.MailService
 }
 
 // NewHandler creates a new auth handler
-func NewAuthHandler(authDB *store.SQLiteAuthStore, bandsDB *store.SQLiteBandsStore) *AuthHandler {
+func NewAuthHandler(authDB *store.SQLiteAuthStore, bandsDB *store.SQLiteBandsStore, mailService *services.MailService) *AuthHandler {
 	return &AuthHandler{
-		authDB:  authDB,
-		bandsDB: bandsDB,
+		authDB:      authDB,
+		bandsDB:     bandsDB,
+		mailService: mailService,
 	}
 }
 
@@ -62,11 +65,13 @@ func (h *AuthHandler) HandleMagicLinkRequest(w http.ResponseWriter, r *http.Requ
 		return
 	}
 
-	// In production, send email with magic link
-	// For now, we'll log it (NEVER do this in production)
-	baseURL := getBaseURL(r)
-	magicLink := fmt.Sprintf("%s/auth/verify?token=%s", baseURL, token)
-	log.Printf("Magic link for %s: %s", req.Email, magicLink)
+	// Email the magic link
+	magicLink := h.mailService.Link("/auth/verify?token=" + token)
+	if err := h.mailService.SendMagicLink(r.Context(), req.Email, magicLink); err != nil {
+		log.Printf("Failed to send magic link: %v", err)
+		http.Error(w, "Failed to send magic link", http.StatusInternalServerError)
+		return
+	}
 
 	// Return success response
 	w.Header().Set("Content-Type", "application/json")
@@ -116,29 +121,52 @@ func (h *AuthHandler) HandleMagicLinkVerification(w http.ResponseWriter, r *http
 
 	log.Printf("User authenticated successfully: %s", user.ID)
 
+	// Where to go after signing in, e.g. back to an invitation
+	next := safeRedirectPath(r.URL.Query().Get("next"))
+
 	// Check if user has any bands, create default band if not
 	bands, err := h.bandsDB.GetBandsByUser(user.ID)
 	if err != nil {
 		log.Printf("Error checking user bands: %v", err)
 		// Continue anyway, don't fail the login
 	} else if len(bands) == 0 {
-		// Create a default band for the user
-		defaultBandName := "My Band"
-		defaultBandDescription := "Your personal band for managing songs and setlists"
-
-		band, err := h.bandsDB.CreateBand(defaultBandName, defaultBandDescription, user.ID)
+		// Someone who was invited to a band joins that one instead
+		invitations, err := h.bandsDB.GetPendingInvitationsByEmail(user.Email)
 		if err != nil {
-			log.Printf("Error creating default band: %v", err)
-			// Continue anyway, don't fail the login
-		} else {
-			log.Printf("Created default band '%s' for user: %s", band.Name, user.Email)
+			log.Printf("Error checking user invitations: %v", err)
+		} else if len(invitations) == 0 {
+			// Create a default band for the user
+			defaultBandName := "My Band"
+			defaultBandDescription := "Your personal band for managing songs and setlists"
+
+			band, err := h.bandsDB.CreateBand(defaultBandName, defaultBandDescription, user.ID)
+			if err != nil {
+				log.Printf("Error creating default band: %v", err)
+				// Continue anyway, don't fail the login
+			} else {
+				log.Printf("Created default band '%s' for user: %s", band.Name, user.Email)
+			}
 		}
 	}
 
+	if next != "" {
+		http.Redirect(w, r, next, http.StatusSeeOther)
+		return
+	}
+
 	// Redirect to bands page
 	http.Redirect(w, r, "/bands", http.StatusSeeOther)
 }
 
+// safeRedirectPath returns next when it is a path in this app, so that
+// sign-in links can't send users to another site, and "" otherwise
+func safeRedirectPath(next string) string {
+	if !strings.HasPrefix(next, "/") || strings.HasPrefix(next, "//") || strings.HasPrefix(next, "/\\") {
+		return ""
+	}
+	return next
+}
+
 // HandleLogout handles POST /auth/logout
 func (h *AuthHandler) HandleLogout(w http.ResponseWriter, r *http.Request) {
 	// Clear the session cookie
@@ -189,7 +217,9 @@ func (h *AuthHandler) getCurrentUser(r *http.Request) *store.User {
 	return user
 }
 
-// getBaseURL gets the base URL for the application
+// getBaseURL gets the base URL the request was made to. It comes from the Host
+// header, which the client controls, so it is only for showing back to that
+// client; links in emails use MailService.Link.
 func getBaseURL(r *http.Request) string {
 	scheme := "http"
 	if r.TLS != nil {
@@ -1,10 +1,15 @@
 package api
 
 import (
+	"bytes"
 	"context"
 	"encoding/json"
+	"errors"
+	"fmt"
 	"log"
 	"net/http"
+	"strings"
+	"time"
 
 	"github.com/nahue/setlist_manager/internal/app/shared/types"
 	"github.com/nahue/setlist_manager/internal/services"
@@ -14,17 +19,27 @@ import (
 
 // Handler handles band-related requests
 type BandHandler struct {
-	bandsDB     *store.SQLiteBandsStore
-	songsDB     *store.SQLiteSongsStore
-	authService *services.AuthService
+	bandsDB           *store.SQLiteBandsStore
+	songsDB           *store.SQLiteSongsStore
+	setlistsDB        *store.SQLiteSetlistsStore
+	gigsDB            *store.SQLiteGigsStore
+	authService       *services.AuthService
+	archiveService    *services.BandArchiveService
+	invitationService *services.InvitationService
+	ownershipService  *services.OwnershipService
 }
 
 // NewHandler creates a new bands handler
-func NewBandHandler(bandsDB *store.SQLiteBandsStore, songsDB *store.SQLiteSongsStore, authService *services.AuthService) *BandHandler {
+func NewBandHandler(bandsDB *store.SQLiteBandsStore, songsDB *store.SQLiteSongsStore, setlistsDB *store.SQLiteSetlistsStore, gigsDB *store.SQLiteGigsStore, authService *services.AuthService, archiveService *services.BandArchiveService, invitationService *services.InvitationService, ownershipService *services.OwnershipService) *BandHandler {
 	return &BandHandler{
-		bandsDB:     bandsDB,
-		songsDB:     songsDB,
-		authService: authService,
+		bandsDB:           bandsDB,
+		songsDB:           songsDB,
+		setlistsDB:        setlistsDB,
+		gigsDB:            gigsDB,
+		authService:       authService,
+		archiveService:    archiveService,
+		invitationService: invitationService,
+		ownershipService:  ownershipService,
 	}
 }
 
@@ -36,7 +51,6 @@ type CreateBandRequest struct {
 
 type InviteMemberRequest struct {
 	Email string `json:"email"`
-	Name  string `json:"name"`
 	Role  string `json:"role"`
 }
 
@@ -44,6 +58,9 @@ type AcceptInvitationRequest struct {
 	InvitationID string `json:"invitation_id"`
 }
 
+// maxBandArchiveSize is the largest band archive accepted for restore
+const maxBandArchiveSize = 50 << 20
+
 // UserContextKey is the key used to store user in request context
 type UserContextKey struct{}
 
@@ -91,7 +108,7 @@ func (h *BandHandler) ServeBand(w http.ResponseWriter, r *http.Request) {
 		http.Error(w, "Failed to check band membership", http.StatusInternalServerError)
 		return
 	}
-	if member == nil {
+	if !services.Can(member, services.PermissionView) {
 		http.Error(w, "Access denied", http.StatusForbidden)
 		return
 	}
@@ -103,7 +120,7 @@ func (h *BandHandler) ServeBand(w http.ResponseWriter, r *http.Request) {
 		http.Error(w, "Failed to get band", http.StatusInternalServerError)
 		return
 	}
-	if band == nil {
+	if band == nil || !band.IsActive {
 		http.Error(w, "Band not found", http.StatusNotFound)
 		return
 	}
@@ -117,6 +134,14 @@ func (h *BandHandler) ServeBand(w http.ResponseWriter, r *http.Request) {
 	}
 	log.Printf("Band members: %v", members)
 
+	// Get invitations that were not answered yet
+	invitations, err := h.bandsDB.GetBandInvitations(bandID)
+	if err != nil {
+		log.Printf("Error getting band invitations: %v", err)
+		http.Error(w, "Failed to get band invitations", http.StatusInternalServerError)
+		return
+	}
+
 	// Get songs for the band
 	songs, err := h.songsDB.GetSongsByBand(bandID)
 	if err != nil {
@@ -125,17 +150,35 @@ func (h *BandHandler) ServeBand(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	// Determine user role
-	userRole := "member"
-	switch member.Role {
-	case "owner":
-		userRole = "owner"
-	case "admin":
-		userRole = "admin"
+	// Get setlists for the band
+	setlists, err := h.setlistsDB.GetSetlistsByBand(bandID)
+	if err != nil {
+		log.Printf("Error getting setlists: %v", err)
+		http.Error(w, "Failed to get setlists", http.StatusInternalServerError)
+		return
+	}
+
+	// Get gigs for the band
+	gigs, err := h.gigsDB.GetGigsByBand(bandID)
+	if err != nil {
+		log.Printf("Error getting gigs: %v", err)
+		http.Error(w, "Failed to get gigs", http.StatusInternalServerError)
+		return
+	}
+
+	// Get the ownership transfer waiting for this user's answer, if any
+	transfer, err := h.bandsDB.GetPendingOwnershipTransfer(bandID)
+	if err != nil {
+		log.Printf("Error getting ownership transfer: %v", err)
+		http.Error(w, "Failed to get ownership transfer", http.StatusInternalServerError)
+		return
+	}
+	if transfer != nil && transfer.ToUserID != user.ID {
+		transfer = nil
 	}
 
-	// Render band details page
-	component := templates.BandDetailsPage(band, members, songs, userRole, user)
+	// Render band details page with the controls the user's role allows
+	component := templates.BandDetailsPage(band, members, invitations, songs, setlists, gigs, member.Role, user, transfer)
 	component.Render(r.Context(), w)
 }
 
@@ -222,7 +265,7 @@ func (h *BandHandler) GetBand(w http.ResponseWriter, r *http.Request) {
 		http.Error(w, "Failed to check band membership", http.StatusInternalServerError)
 		return
 	}
-	if member == nil {
+	if !services.Can(member, services.PermissionView) {
 		http.Error(w, "Access denied", http.StatusForbidden)
 		return
 	}
@@ -234,7 +277,7 @@ func (h *BandHandler) GetBand(w http.ResponseWriter, r *http.Request) {
 		http.Error(w, "Failed to get band", http.StatusInternalServerError)
 		return
 	}
-	if band == nil {
+	if band == nil || !band.IsActive {
 		http.Error(w, "Band not found", http.StatusNotFound)
 		return
 	}
@@ -279,7 +322,7 @@ func (h *BandHandler) InviteMember(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	// Check if user is a member of the band
+	// Check if user can manage the band's members
 	member, err := h.bandsDB.GetBandMember(bandID, user.ID)
 	if err != nil {
 		log.Printf("Error checking band membership: %v", err)
@@ -292,7 +335,7 @@ func (h *BandHandler) InviteMember(w http.ResponseWriter, r *http.Request) {
 		}
 		return
 	}
-	if member == nil {
+	if !services.Can(member, services.PermissionManageMembers) {
 		// Return HTML error response
 		w.Header().Set("Content-Type", "text/html")
 		err = templates.MembersSectionError("Access denied", bandID).Render(r.Context(), w)
@@ -318,11 +361,8 @@ func (h *BandHandler) InviteMember(w http.ResponseWriter, r *http.Request) {
 
 	// Extract form values
 	email := r.FormValue("email")
-	name := r.FormValue("name")
 	role := r.FormValue("role")
 
-	log.Printf("Received form data: email=%s, name=%s, role=%s", email, name, role)
-
 	if email == "" {
 		// Return HTML error response
 		w.Header().Set("Content-Type", "text/html")
@@ -334,96 +374,154 @@ func (h *BandHandler) InviteMember(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	// Check if the email exists in the users table
-	invitedUser, err := h.bandsDB.GetUserByEmail(email)
-	if err != nil {
-		log.Printf("Error checking if user exists: %v", err)
-		// Return HTML error response
-		w.Header().Set("Content-Type", "text/html")
-		err = templates.MembersSectionError("Failed to check if user exists", bandID).Render(r.Context(), w)
-		if err != nil {
-			log.Printf("Error rendering error template: %v", err)
-			http.Error(w, "Failed to render error template", http.StatusInternalServerError)
-		}
+	// Set default role if not provided
+	if role == "" {
+		role = store.RoleEditor
+	}
+
+	// Members can only invite people with a lower role than their own
+	if !services.CanAssignRole(member.Role, role) {
+		h.renderMembersError(w, r, "You can't invite members with that role", bandID)
 		return
 	}
-	if invitedUser == nil {
-		// Return HTML error response
-		w.Header().Set("Content-Type", "text/html")
-		err = templates.MembersSectionError("User with this email does not exist. They must sign up first.", bandID).Render(r.Context(), w)
-		if err != nil {
-			log.Printf("Error rendering error template: %v", err)
-			http.Error(w, "Failed to render error template", http.StatusInternalServerError)
-		}
+
+	band, err := h.bandsDB.GetBandByID(bandID)
+	if err != nil || band == nil {
+		log.Printf("Error getting band: %v", err)
+		h.renderMembersError(w, r, "Failed to get band", bandID)
 		return
 	}
 
-	// Check if user is already a member of this band
-	existingMember, err := h.bandsDB.GetBandMember(bandID, invitedUser.ID)
-	if err != nil {
-		log.Printf("Error checking if user is already a member: %v", err)
-		// Return HTML error response
-		w.Header().Set("Content-Type", "text/html")
-		err = templates.MembersSectionError("Failed to check if user is already a member", bandID).Render(r.Context(), w)
-		if err != nil {
-			log.Printf("Error rendering error template: %v", err)
-			http.Error(w, "Failed to render error template", http.StatusInternalServerError)
-		}
+	// Invite the email; it doesn't need an account yet
+	invitation, err := h.invitationService.Invite(r.Context(), band, user, email, role)
+	switch {
+	case errors.Is(err, services.ErrInvalidInvitationEmail):
+		h.renderMembersError(w, r, "Invalid email address", bandID)
+		return
+	case errors.Is(err, services.ErrInvalidInvitationRole):
+		h.renderMembersError(w, r, "Invalid role", bandID)
+		return
+	case errors.Is(err, services.ErrAlreadyBandMember):
+		h.renderMembersError(w, r, "User is already a member of this band", bandID)
+		return
+	case errors.Is(err, services.ErrInvitationPending):
+		h.renderMembersError(w, r, "This email already has a pending invitation", bandID)
+		return
+	case err != nil && invitation != nil:
+		log.Printf("Error sending invitation: %v", err)
+		h.renderMembersError(w, r, "The invitation was saved but the email could not be sent. Resend it from the members list.", bandID)
+		return
+	case err != nil:
+		log.Printf("Error inviting member: %v", err)
+		h.renderMembersError(w, r, "Failed to invite member", bandID)
 		return
 	}
-	if existingMember != nil {
-		// Return HTML error response
-		w.Header().Set("Content-Type", "text/html")
-		err = templates.MembersSectionError("User is already a member of this band", bandID).Render(r.Context(), w)
-		if err != nil {
-			log.Printf("Error rendering error template: %v", err)
-			http.Error(w, "Failed to render error template", http.StatusInternalServerError)
-		}
+
+	// Return HTML response with the updated members section
+	h.renderMembersSection(w, r, bandID, member.Role)
+}
+
+// ResendInvitation handles POST /api/invitations/{invitationID}/resend
+func (h *BandHandler) ResendInvitation(w http.ResponseWriter, r *http.Request) {
+	invitation, member, ok := h.getBandInvitation(w, r)
+	if !ok {
 		return
 	}
 
-	// Set default role if not provided
-	if role == "" {
-		role = "member"
+	// Send the invitation again with a new link
+	err := h.invitationService.Resend(r.Context(), invitation)
+	if errors.Is(err, services.ErrInvitationNotPending) {
+		h.renderMembersError(w, r, "The invitation was already answered", invitation.BandID)
+		return
+	}
+	if err != nil {
+		log.Printf("Error resending invitation: %v", err)
+		h.renderMembersError(w, r, "Failed to resend the invitation", invitation.BandID)
+		return
 	}
 
-	// Validate role
-	if role != "member" && role != "admin" {
-		// Return HTML error response
-		w.Header().Set("Content-Type", "text/html")
-		err = templates.MembersSectionError("Invalid role", bandID).Render(r.Context(), w)
-		if err != nil {
-			log.Printf("Error rendering error template: %v", err)
-			http.Error(w, "Failed to render error template", http.StatusInternalServerError)
-		}
+	h.renderMembersSection(w, r, invitation.BandID, member.Role)
+}
+
+// RevokeInvitation handles DELETE /api/invitations/{invitationID}
+func (h *BandHandler) RevokeInvitation(w http.ResponseWriter, r *http.Request) {
+	invitation, member, ok := h.getBandInvitation(w, r)
+	if !ok {
 		return
 	}
 
-	// Add member directly to the band
-	_, err = h.bandsDB.AddBandMember(bandID, invitedUser.ID, role)
+	// Revoke the invitation so its link stops working
+	err := h.invitationService.Revoke(invitation)
+	if errors.Is(err, services.ErrInvitationNotPending) {
+		h.renderMembersError(w, r, "The invitation was already answered", invitation.BandID)
+		return
+	}
 	if err != nil {
-		log.Printf("Error adding member to band: %v", err)
-		// Return HTML error response
-		w.Header().Set("Content-Type", "text/html")
-		err = templates.MembersSectionError("Failed to add member to band", bandID).Render(r.Context(), w)
-		if err != nil {
-			log.Printf("Error rendering error template: %v", err)
-			http.Error(w, "Failed to render error template", http.StatusInternalServerError)
-		}
+		log.Printf("Error revoking invitation: %v", err)
+		h.renderMembersError(w, r, "Failed to revoke the invitation", invitation.BandID)
 		return
 	}
 
-	// Get updated band members
+	h.renderMembersSection(w, r, invitation.BandID, member.Role)
+}
+
+// getBandInvitation gets the invitation in the URL path for a member of its
+// band who manages members, writing the error response when there is none
+func (h *BandHandler) getBandInvitation(w http.ResponseWriter, r *http.Request) (*store.BandInvitation, *store.BandMember, bool) {
+	pathParts := strings.Split(r.URL.Path, "/")
+	if len(pathParts) < 4 || pathParts[3] == "" {
+		http.Error(w, "Invitation ID is required", http.StatusBadRequest)
+		return nil, nil, false
+	}
+	invitationID := pathParts[3]
+
+	// Get current user from context
+	user := GetUserFromContext(r.Context())
+	if user == nil {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return nil, nil, false
+	}
+
+	invitation, err := h.bandsDB.GetBandInvitationByID(invitationID)
+	if err != nil {
+		log.Printf("Error getting invitation: %v", err)
+		http.Error(w, "Failed to get invitation", http.StatusInternalServerError)
+		return nil, nil, false
+	}
+	if invitation == nil {
+		http.Error(w, "Invitation not found", http.StatusNotFound)
+		return nil, nil, false
+	}
+
+	// Check if user can manage the band's members
+	member, err := h.bandsDB.GetBandMember(invitation.BandID, user.ID)
+	if err != nil {
+		log.Printf("Error checking band membership: %v", err)
+		http.Error(w, "Failed to check band membership", http.StatusInternalServerError)
+		return nil, nil, false
+	}
+	// Like members, invitations are only managed by someone of a higher role
+	if !services.Can(member, services.PermissionManageMembers) || !services.CanManageRole(member.Role, invitation.Role) {
+		http.Error(w, "Access denied", http.StatusForbidden)
+		return nil, nil, false
+	}
+
+	return invitation, member, true
+}
+
+// renderMembersSection renders the members and pending invitations of a band,
+// with the controls a member with userRole may use
+func (h *BandHandler) renderMembersSection(w http.ResponseWriter, r *http.Request, bandID, userRole string) {
 	members, err := h.bandsDB.GetBandMembersShared(bandID)
 	if err != nil {
 		log.Printf("Error getting updated band members: %v", err)
-		// Return HTML error response
-		w.Header().Set("Content-Type", "text/html")
-		err = templates.MembersSectionError("Failed to get updated band members", bandID).Render(r.Context(), w)
-		if err != nil {
-			log.Printf("Error rendering error template: %v", err)
-			http.Error(w, "Failed to render error template", http.StatusInternalServerError)
-		}
+		h.renderMembersError(w, r, "Failed to get updated band members", bandID)
+		return
+	}
+	invitations, err := h.bandsDB.GetBandInvitations(bandID)
+	if err != nil {
+		log.Printf("Error getting band invitations: %v", err)
+		h.renderMembersError(w, r, "Failed to get band invitations", bandID)
 		return
 	}
 
@@ -431,11 +529,20 @@ func (h *BandHandler) InviteMember(w http.ResponseWriter, r *http.Request) {
 	w.Header().Set("Content-Type", "text/html")
 
 	// Render the members section directly to the response
-	err = templates.MembersSection(members, bandID).Render(r.Context(), w)
+	err = templates.MembersSection(members, invitations, bandID, userRole).Render(r.Context(), w)
 	if err != nil {
 		log.Printf("Error rendering members section: %v", err)
 		http.Error(w, "Failed to render members section", http.StatusInternalServerError)
-		return
+	}
+}
+
+// renderMembersError renders the members section with an error message
+func (h *BandHandler) renderMembersError(w http.ResponseWriter, r *http.Request, errorMsg, bandID string) {
+	w.Header().Set("Content-Type", "text/html")
+	err := templates.MembersSectionError(errorMsg, bandID).Render(r.Context(), w)
+	if err != nil {
+		log.Printf("Error rendering error template: %v", err)
+		http.Error(w, "Failed to render error template", http.StatusInternalServerError)
 	}
 }
 
@@ -478,7 +585,7 @@ func (h *BandHandler) RemoveMember(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	// Check if current user is a member of the band
+	// Check if current user can manage the band's members
 	currentMember, err := h.bandsDB.GetBandMember(bandID, currentUser.ID)
 	if err != nil {
 		log.Printf("Error checking band membership: %v", err)
@@ -491,7 +598,7 @@ func (h *BandHandler) RemoveMember(w http.ResponseWriter, r *http.Request) {
 		}
 		return
 	}
-	if currentMember == nil {
+	if !services.Can(currentMember, services.PermissionManageMembers) {
 		// Return HTML error response
 		w.Header().Set("Content-Type", "text/html")
 		err = templates.MembersSectionError("Access denied", bandID).Render(r.Context(), w)
@@ -539,7 +646,7 @@ func (h *BandHandler) RemoveMember(w http.ResponseWriter, r *http.Request) {
 	}
 
 	// Check if the user being removed is the owner
-	if targetMember.Role == "owner" {
+	if targetMember.Role == store.RoleOwner {
 		// Return HTML error response
 		w.Header().Set("Content-Type", "text/html")
 		err = templates.MembersSectionError("The owner cannot be removed from the band", bandID).Render(r.Context(), w)
@@ -550,6 +657,12 @@ func (h *BandHandler) RemoveMember(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	// Check if the current user's role is above the one of the user being removed
+	if !services.CanManageRole(currentMember.Role, targetMember.Role) {
+		h.renderMembersError(w, r, "You can only remove members with a lower role than yours", bandID)
+		return
+	}
+
 	// Remove the member from the band
 	err = h.bandsDB.RemoveBandMember(bandID, userID)
 	if err != nil {
@@ -564,30 +677,70 @@ func (h *BandHandler) RemoveMember(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	// Get updated band members
-	members, err := h.bandsDB.GetBandMembersShared(bandID)
-	if err != nil {
-		log.Printf("Error getting updated band members: %v", err)
-		// Return HTML error response
-		w.Header().Set("Content-Type", "text/html")
-		err = templates.MembersSectionError("Failed to get updated band members", bandID).Render(r.Context(), w)
-		if err != nil {
-			log.Printf("Error rendering error template: %v", err)
-			http.Error(w, "Failed to render error template", http.StatusInternalServerError)
-		}
+	// Return HTML response with the updated members section
+	h.renderMembersSection(w, r, bandID, currentMember.Role)
+}
+
+// UpdateMemberRole handles POST /api/bands/members/role
+func (h *BandHandler) UpdateMemberRole(w http.ResponseWriter, r *http.Request) {
+	bandID := r.URL.Query().Get("id")
+	if bandID == "" {
+		h.renderMembersError(w, r, "Band ID is required", "")
 		return
 	}
 
-	// Return HTML response with the updated members section
-	w.Header().Set("Content-Type", "text/html")
+	userID := r.URL.Query().Get("user_id")
+	if userID == "" {
+		h.renderMembersError(w, r, "User ID is required", bandID)
+		return
+	}
 
-	// Render the members section directly to the response
-	err = templates.MembersSection(members, bandID).Render(r.Context(), w)
+	// Get current user from context
+	currentUser := GetUserFromContext(r.Context())
+	if currentUser == nil {
+		h.renderMembersError(w, r, "Unauthorized", bandID)
+		return
+	}
+
+	// Check if current user can manage the band's members
+	currentMember, err := h.bandsDB.GetBandMember(bandID, currentUser.ID)
 	if err != nil {
-		log.Printf("Error rendering members section: %v", err)
-		http.Error(w, "Failed to render members section", http.StatusInternalServerError)
+		log.Printf("Error checking band membership: %v", err)
+		h.renderMembersError(w, r, "Failed to check band membership", bandID)
 		return
 	}
+	if !services.Can(currentMember, services.PermissionManageMembers) {
+		h.renderMembersError(w, r, "Access denied", bandID)
+		return
+	}
+
+	targetMember, err := h.bandsDB.GetBandMember(bandID, userID)
+	if err != nil {
+		log.Printf("Error checking target user membership: %v", err)
+		h.renderMembersError(w, r, "Failed to check target user membership", bandID)
+		return
+	}
+	if targetMember == nil {
+		h.renderMembersError(w, r, "User is not a member of this band", bandID)
+		return
+	}
+
+	// Members can only change the roles of members below them, to roles below
+	// their own; the owner's role only changes by transferring ownership
+	role := r.FormValue("role")
+	if !services.CanManageRole(currentMember.Role, targetMember.Role) || !services.CanAssignRole(currentMember.Role, role) {
+		h.renderMembersError(w, r, "You can't give this member that role", bandID)
+		return
+	}
+
+	if err := h.bandsDB.UpdateBandMemberRole(bandID, userID, role); err != nil {
+		log.Printf("Error updating band member role: %v", err)
+		h.renderMembersError(w, r, "Failed to update member role", bandID)
+		return
+	}
+
+	// Return HTML response with the updated members section
+	h.renderMembersSection(w, r, bandID, currentMember.Role)
 }
 
 // GetInvitations handles GET /api/invitations
@@ -624,19 +777,17 @@ func (h *BandHandler) AcceptInvitation(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	var req AcceptInvitationRequest
-	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		http.Error(w, "Invalid request body", http.StatusBadRequest)
+	invitation, ok := h.decodeInvitationRequest(w, r, user)
+	if !ok {
 		return
 	}
 
-	if req.InvitationID == "" {
-		http.Error(w, "Invitation ID is required", http.StatusBadRequest)
+	// Accept the invitation
+	err := h.invitationService.Accept(r.Context(), invitation, user)
+	if errors.Is(err, services.ErrInvitationNotPending) {
+		http.Error(w, "Invitation is no longer valid", http.StatusConflict)
 		return
 	}
-
-	// Accept the invitation
-	err := h.bandsDB.AcceptBandInvitation(req.InvitationID, user.ID)
 	if err != nil {
 		log.Printf("Error accepting invitation: %v", err)
 		http.Error(w, "Failed to accept invitation", http.StatusInternalServerError)
@@ -653,19 +804,24 @@ func (h *BandHandler) AcceptInvitation(w http.ResponseWriter, r *http.Request) {
 
 // DeclineInvitation handles POST /api/invitations/decline
 func (h *BandHandler) DeclineInvitation(w http.ResponseWriter, r *http.Request) {
-	var req AcceptInvitationRequest
-	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		http.Error(w, "Invalid request body", http.StatusBadRequest)
+	// Get current user from context
+	user := GetUserFromContext(r.Context())
+	if user == nil {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
 		return
 	}
 
-	if req.InvitationID == "" {
-		http.Error(w, "Invitation ID is required", http.StatusBadRequest)
+	invitation, ok := h.decodeInvitationRequest(w, r, user)
+	if !ok {
 		return
 	}
 
 	// Decline the invitation
-	err := h.bandsDB.DeclineBandInvitation(req.InvitationID)
+	err := h.invitationService.Decline(invitation)
+	if errors.Is(err, services.ErrInvitationNotPending) {
+		http.Error(w, "Invitation is no longer valid", http.StatusConflict)
+		return
+	}
 	if err != nil {
 		log.Printf("Error declining invitation: %v", err)
 		http.Error(w, "Failed to decline invitation", http.StatusInternalServerError)
@@ -679,3 +835,415 @@ func (h *BandHandler) DeclineInvitation(w http.ResponseWriter, r *http.Request)
 		"message": "Invitation declined successfully",
 	})
 }
+
+// decodeInvitationRequest gets the invitation in an AcceptInvitationRequest,
+// which must have been sent to the user's email
+func (h *BandHandler) decodeInvitationRequest(w http.ResponseWriter, r *http.Request, user *types.User) (*store.BandInvitation, bool) {
+	var req AcceptInvitationRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return nil, false
+	}
+
+	if req.InvitationID == "" {
+		http.Error(w, "Invitation ID is required", http.StatusBadRequest)
+		return nil, false
+	}
+
+	invitation, err := h.bandsDB.GetBandInvitationByID(req.InvitationID)
+	if err != nil {
+		log.Printf("Error getting invitation: %v", err)
+		http.Error(w, "Failed to get invitation", http.StatusInternalServerError)
+		return nil, false
+	}
+	if invitation == nil || !strings.EqualFold(invitation.InvitedEmail, user.Email) {
+		http.Error(w, "Invitation not found", http.StatusNotFound)
+		return nil, false
+	}
+
+	return invitation, true
+}
+
+// ExportBand handles GET /api/bands/export
+func (h *BandHandler) ExportBand(w http.ResponseWriter, r *http.Request) {
+	bandID := r.URL.Query().Get("id")
+	if bandID == "" {
+		http.Error(w, "Band ID is required", http.StatusBadRequest)
+		return
+	}
+
+	// Get current user from context
+	user := GetUserFromContext(r.Context())
+	if user == nil {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	// Check if user can manage the band
+	member, err := h.bandsDB.GetBandMember(bandID, user.ID)
+	if err != nil {
+		log.Printf("Error checking band membership: %v", err)
+		http.Error(w, "Failed to check band membership", http.StatusInternalServerError)
+		return
+	}
+	if !services.Can(member, services.PermissionManageBand) {
+		http.Error(w, "Access denied", http.StatusForbidden)
+		return
+	}
+
+	band, err := h.bandsDB.GetBandByID(bandID)
+	if err != nil {
+		log.Printf("Error getting band: %v", err)
+		http.Error(w, "Failed to get band", http.StatusInternalServerError)
+		return
+	}
+	if band == nil {
+		http.Error(w, "Band not found", http.StatusNotFound)
+		return
+	}
+
+	// Build the archive in memory so that a failure can still be reported as an error
+	var archive bytes.Buffer
+	if err := h.archiveService.Export(band, &archive); err != nil {
+		log.Printf("Error exporting band: %v", err)
+		http.Error(w, "Failed to export band", http.StatusInternalServerError)
+		return
+	}
+
+	filename := fmt.Sprintf("%s - %s.zip", band.Name, time.Now().Format("2006-01-02"))
+	w.Header().Set("Content-Type", "application/zip")
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=\"%s\"", filename))
+	w.Header().Set("Content-Length", fmt.Sprintf("%d", archive.Len()))
+
+	w.Write(archive.Bytes())
+}
+
+// ServeRestoreBand handles GET /bands/restore
+func (h *BandHandler) ServeRestoreBand(w http.ResponseWriter, r *http.Request) {
+	user := GetUserFromContext(r.Context())
+	component := templates.RestoreBandPage(user, "")
+	component.Render(r.Context(), w)
+}
+
+// RestoreBand handles POST /api/bands/restore
+func (h *BandHandler) RestoreBand(w http.ResponseWriter, r *http.Request) {
+	// Get current user from context
+	user := GetUserFromContext(r.Context())
+	if user == nil {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	renderError := func(message string) {
+		w.WriteHeader(http.StatusBadRequest)
+		err := templates.RestoreBandPage(user, message).Render(r.Context(), w)
+		if err != nil {
+			log.Printf("Error rendering restore page: %v", err)
+		}
+	}
+
+	// Read the uploaded archive
+	r.Body = http.MaxBytesReader(w, r.Body, maxBandArchiveSize)
+	if err := r.ParseMultipartForm(maxBandArchiveSize); err != nil {
+		renderError("Archivo inválido o demasiado grande")
+		return
+	}
+	file, header, err := r.FormFile("archive")
+	if err != nil {
+		renderError("Selecciona un archivo de respaldo")
+		return
+	}
+	defer file.Close()
+
+	band, err := h.archiveService.Restore(r.Context(), file, header.Size, user, r.FormValue("name"), r.FormValue("invite_members") == "on")
+	if err != nil {
+		log.Printf("Error restoring band: %v", err)
+		renderError("No se pudo restaurar la banda: " + err.Error())
+		return
+	}
+
+	// Redirect to the new band
+	http.Redirect(w, r, "/band?id="+band.ID, http.StatusSeeOther)
+}
+
+// ServeBandSettings handles GET /band/settings, where a band is edited,
+// transferred, left or deleted
+func (h *BandHandler) ServeBandSettings(w http.ResponseWriter, r *http.Request) {
+	bandID := r.URL.Query().Get("id")
+	if bandID == "" {
+		http.Error(w, "Band ID is required", http.StatusBadRequest)
+		return
+	}
+
+	user := GetUserFromContext(r.Context())
+	if user == nil {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	h.renderBandSettings(w, r, bandID, user, "")
+}
+
+// UpdateBand handles POST /api/bands/update
+func (h *BandHandler) UpdateBand(w http.ResponseWriter, r *http.Request) {
+	band, _, user, ok := h.getBandForSettings(w, r, services.PermissionManageBand)
+	if !ok {
+		return
+	}
+
+	name := strings.TrimSpace(r.FormValue("name"))
+	if name == "" {
+		h.renderBandSettingsError(w, r, band.ID, user, "El nombre de la banda es obligatorio")
+		return
+	}
+
+	if err := h.bandsDB.UpdateBand(band.ID, name, strings.TrimSpace(r.FormValue("description"))); err != nil {
+		log.Printf("Error updating band: %v", err)
+		http.Error(w, "Failed to update band", http.StatusInternalServerError)
+		return
+	}
+
+	http.Redirect(w, r, "/band?id="+band.ID, http.StatusSeeOther)
+}
+
+// DeleteBand handles POST /api/bands/delete. The band goes to the trash with
+// its songs, where the owner can restore it until it is purged.
+func (h *BandHandler) DeleteBand(w http.ResponseWriter, r *http.Request) {
+	band, _, user, ok := h.getBandForSettings(w, r, services.PermissionDeleteBand)
+	if !ok {
+		return
+	}
+
+	if err := h.bandsDB.DeleteBand(band.ID, user.ID); err != nil {
+		log.Printf("Error deleting band: %v", err)
+		http.Error(w, "Failed to delete band", http.StatusInternalServerError)
+		return
+	}
+
+	http.Redirect(w, r, "/bands/trash", http.StatusSeeOther)
+}
+
+// LeaveBand handles POST /api/bands/leave
+func (h *BandHandler) LeaveBand(w http.ResponseWriter, r *http.Request) {
+	band, member, user, ok := h.getBandForSettings(w, r, services.PermissionView)
+	if !ok {
+		return
+	}
+
+	err := h.ownershipService.Leave(member)
+	if errors.Is(err, services.ErrSoleOwnerCannotLeave) {
+		h.renderBandSettingsError(w, r, band.ID, user, "Eres el propietario: transfiere la banda a otro miembro o elimínala antes de salir")
+		return
+	}
+	if err != nil {
+		log.Printf("Error leaving band: %v", err)
+		http.Error(w, "Failed to leave band", http.StatusInternalServerError)
+		return
+	}
+
+	http.Redirect(w, r, "/bands", http.StatusSeeOther)
+}
+
+// TransferOwnership handles POST /api/bands/transfer, which asks another
+// member to take over the band
+func (h *BandHandler) TransferOwnership(w http.ResponseWriter, r *http.Request) {
+	band, member, user, ok := h.getBandForSettings(w, r, services.PermissionDeleteBand)
+	if !ok {
+		return
+	}
+
+	_, err := h.ownershipService.RequestTransfer(r.Context(), band, member, user, r.FormValue("user_id"))
+	switch {
+	case errors.Is(err, services.ErrNotBandOwner):
+		http.Error(w, "Access denied", http.StatusForbidden)
+		return
+	case errors.Is(err, services.ErrInvalidTransferTarget):
+		h.renderBandSettingsError(w, r, band.ID, user, "Elige a otro miembro de la banda")
+		return
+	case err != nil:
+		log.Printf("Error requesting ownership transfer: %v", err)
+		http.Error(w, "Failed to transfer band", http.StatusInternalServerError)
+		return
+	}
+
+	http.Redirect(w, r, "/band/settings?id="+band.ID, http.StatusSeeOther)
+}
+
+// AcceptOwnershipTransfer handles POST /api/bands/transfer/accept
+func (h *BandHandler) AcceptOwnershipTransfer(w http.ResponseWriter, r *http.Request) {
+	band, user, transfer, ok := h.getOwnershipTransfer(w, r)
+	if !ok {
+		return
+	}
+
+	err := h.ownershipService.Accept(r.Context(), transfer, band, user)
+	switch {
+	case errors.Is(err, services.ErrTransferWrongUser):
+		http.Error(w, "Access denied", http.StatusForbidden)
+		return
+	case errors.Is(err, services.ErrTransferNotPending), errors.Is(err, services.ErrOwnershipTransferFailed):
+		h.renderBandSettingsError(w, r, band.ID, user, "La transferencia ya no es válida")
+		return
+	case err != nil:
+		log.Printf("Error accepting ownership transfer: %v", err)
+		http.Error(w, "Failed to accept transfer", http.StatusInternalServerError)
+		return
+	}
+
+	http.Redirect(w, r, "/band?id="+band.ID, http.StatusSeeOther)
+}
+
+// DeclineOwnershipTransfer handles POST /api/bands/transfer/decline
+func (h *BandHandler) DeclineOwnershipTransfer(w http.ResponseWriter, r *http.Request) {
+	band, user, transfer, ok := h.getOwnershipTransfer(w, r)
+	if !ok {
+		return
+	}
+
+	err := h.ownershipService.Decline(transfer, user)
+	if errors.Is(err, services.ErrTransferWrongUser) {
+		http.Error(w, "Access denied", http.StatusForbidden)
+		return
+	}
+	if err != nil {
+		log.Printf("Error declining ownership transfer: %v", err)
+		http.Error(w, "Failed to decline transfer", http.StatusInternalServerError)
+		return
+	}
+
+	http.Redirect(w, r, "/band?id="+band.ID, http.StatusSeeOther)
+}
+
+// CancelOwnershipTransfer handles POST /api/bands/transfer/cancel
+func (h *BandHandler) CancelOwnershipTransfer(w http.ResponseWriter, r *http.Request) {
+	band, user, transfer, ok := h.getOwnershipTransfer(w, r)
+	if !ok {
+		return
+	}
+
+	err := h.ownershipService.Cancel(transfer, user)
+	if errors.Is(err, services.ErrNotBandOwner) {
+		http.Error(w, "Access denied", http.StatusForbidden)
+		return
+	}
+	if err != nil {
+		log.Printf("Error cancelling ownership transfer: %v", err)
+		http.Error(w, "Failed to cancel transfer", http.StatusInternalServerError)
+		return
+	}
+
+	http.Redirect(w, r, "/band/settings?id="+band.ID, http.StatusSeeOther)
+}
+
+// getBandForSettings gets the active band in the query for a member with the
+// permission, writing the appropriate error response when there is none
+func (h *BandHandler) getBandForSettings(w http.ResponseWriter, r *http.Request, permission services.Permission) (*store.Band, *store.BandMember, *types.User, bool) {
+	bandID := r.URL.Query().Get("id")
+	if bandID == "" {
+		http.Error(w, "Band ID is required", http.StatusBadRequest)
+		return nil, nil, nil, false
+	}
+
+	user := GetUserFromContext(r.Context())
+	if user == nil {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return nil, nil, nil, false
+	}
+
+	member, err := h.bandsDB.GetBandMember(bandID, user.ID)
+	if err != nil {
+		log.Printf("Error checking band membership: %v", err)
+		http.Error(w, "Failed to check band membership", http.StatusInternalServerError)
+		return nil, nil, nil, false
+	}
+	if !services.Can(member, permission) {
+		http.Error(w, "Access denied", http.StatusForbidden)
+		return nil, nil, nil, false
+	}
+
+	band, err := h.bandsDB.GetBandByID(bandID)
+	if err != nil {
+		log.Printf("Error getting band: %v", err)
+		http.Error(w, "Failed to get band", http.StatusInternalServerError)
+		return nil, nil, nil, false
+	}
+	if band == nil || !band.IsActive {
+		http.Error(w, "Band not found", http.StatusNotFound)
+		return nil, nil, nil, false
+	}
+
+	return band, member, user, true
+}
+
+// getOwnershipTransfer gets the band in the query and its pending ownership
+// transfer, writing the appropriate error response when there is none
+func (h *BandHandler) getOwnershipTransfer(w http.ResponseWriter, r *http.Request) (*store.Band, *types.User, *store.BandOwnershipTransfer, bool) {
+	band, _, user, ok := h.getBandForSettings(w, r, services.PermissionView)
+	if !ok {
+		return nil, nil, nil, false
+	}
+
+	transfer, err := h.bandsDB.GetPendingOwnershipTransfer(band.ID)
+	if err != nil {
+		log.Printf("Error getting ownership transfer: %v", err)
+		http.Error(w, "Failed to get ownership transfer", http.StatusInternalServerError)
+		return nil, nil, nil, false
+	}
+	if transfer == nil {
+		h.renderBandSettingsError(w, r, band.ID, user, "No hay ninguna transferencia pendiente")
+		return nil, nil, nil, false
+	}
+
+	return band, user, transfer, true
+}
+
+// renderBandSettingsError renders the settings page of a band with an error
+func (h *BandHandler) renderBandSettingsError(w http.ResponseWriter, r *http.Request, bandID string, user *types.User, errorMsg string) {
+	w.WriteHeader(http.StatusBadRequest)
+	h.renderBandSettings(w, r, bandID, user, errorMsg)
+}
+
+// renderBandSettings renders the settings page of a band for a member, with
+// the sections their role allows
+func (h *BandHandler) renderBandSettings(w http.ResponseWriter, r *http.Request, bandID string, user *types.User, errorMsg string) {
+	member, err := h.bandsDB.GetBandMember(bandID, user.ID)
+	if err != nil {
+		log.Printf("Error checking band membership: %v", err)
+		http.Error(w, "Failed to check band membership", http.StatusInternalServerError)
+		return
+	}
+	if !services.Can(member, services.PermissionView) {
+		http.Error(w, "Access denied", http.StatusForbidden)
+		return
+	}
+
+	band, err := h.bandsDB.GetBandByIDShared(bandID)
+	if err != nil {
+		log.Printf("Error getting band: %v", err)
+		http.Error(w, "Failed to get band", http.StatusInternalServerError)
+		return
+	}
+	if band == nil || !band.IsActive {
+		http.Error(w, "Band not found", http.StatusNotFound)
+		return
+	}
+
+	members, err := h.bandsDB.GetBandMembersShared(bandID)
+	if err != nil {
+		log.Printf("Error getting band members: %v", err)
+		http.Error(w, "Failed to get band members", http.StatusInternalServerError)
+		return
+	}
+
+	transfer, err := h.bandsDB.GetPendingOwnershipTransfer(bandID)
+	if err != nil {
+		log.Printf("Error getting ownership transfer: %v", err)
+		http.Error(w, "Failed to get ownership transfer", http.StatusInternalServerError)
+		return
+	}
+
+	err = templates.BandSettingsPage(band, members, transfer, member.Role, user, errorMsg).Render(r.Context(), w)
+	if err != nil {
+		log.Printf("Error rendering band settings: %v", err)
+	}
+}
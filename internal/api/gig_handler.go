@@ -0,0 +1,642 @@
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/nahue/setlist_manager/internal/app/shared/types"
+	"github.com/nahue/setlist_manager/internal/services"
+	"github.com/nahue/setlist_manager/internal/store"
+	"github.com/nahue/setlist_manager/templates"
+)
+
+// Handler handles gig-related requests
+type GigHandler struct {
+	gigsDB     *store.SQLiteGigsStore
+	setlistsDB *store.SQLiteSetlistsStore
+	bandsDB    *store.SQLiteBandsStore
+	gigService *services.GigService
+	pdfService *services.PDFService
+}
+
+// NewGigHandler creates a new gigs handler
+func NewGigHandler(gigsDB *store.SQLiteGigsStore, setlistsDB *store.SQLiteSetlistsStore, bandsDB *store.SQLiteBandsStore, gigService *services.GigService, pdfService *services.PDFService) *GigHandler {
+	return &GigHandler{
+		gigsDB:     gigsDB,
+		setlistsDB: setlistsDB,
+		bandsDB:    bandsDB,
+		gigService: gigService,
+		pdfService: pdfService,
+	}
+}
+
+// Request/Response structs
+type ReorderGigSetsRequest struct {
+	SetOrder []string `json:"set_order"`
+}
+
+// gigForm holds the gig fields submitted by the create and edit forms
+type gigForm struct {
+	Name       string
+	Venue      string
+	Date       string
+	LoadInTime string
+	StartTime  string
+	CurfewTime string
+	Notes      string
+}
+
+// ServeGig handles GET /gig
+func (h *GigHandler) ServeGig(w http.ResponseWriter, r *http.Request) {
+	gigID := r.URL.Query().Get("id")
+	if gigID == "" {
+		http.Error(w, "Gig ID is required", http.StatusBadRequest)
+		return
+	}
+
+	// Get current user from context
+	user := GetUserFromContext(r.Context())
+	if user == nil {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	gig, ok := h.getGigForMember(w, gigID, user, services.PermissionView)
+	if !ok {
+		return
+	}
+
+	// Get band details
+	band, err := h.bandsDB.GetBandByIDShared(gig.BandID)
+	if err != nil {
+		log.Printf("Error getting band: %v", err)
+		http.Error(w, "Failed to get band", http.StatusInternalServerError)
+		return
+	}
+	if band == nil {
+		http.Error(w, "Band not found", http.StatusNotFound)
+		return
+	}
+
+	schedule, setlists, err := h.getGigSchedule(gig)
+	if err != nil {
+		log.Printf("Error getting gig schedule: %v", err)
+		http.Error(w, "Failed to get gig schedule", http.StatusInternalServerError)
+		return
+	}
+
+	// Render gig page
+	component := templates.GigDetailsPage(gig, band, schedule, setlists, user)
+	component.Render(r.Context(), w)
+}
+
+// GetGigs handles GET /api/bands/gigs
+func (h *GigHandler) GetGigs(w http.ResponseWriter, r *http.Request) {
+	bandID := r.URL.Query().Get("id")
+	if bandID == "" {
+		http.Error(w, "Band ID is required", http.StatusBadRequest)
+		return
+	}
+
+	// Get current user from context
+	user := GetUserFromContext(r.Context())
+	if user == nil {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	// Check if user is a member of the band
+	member, err := h.bandsDB.GetBandMember(bandID, user.ID)
+	if err != nil {
+		log.Printf("Error checking band membership: %v", err)
+		http.Error(w, "Failed to check band membership", http.StatusInternalServerError)
+		return
+	}
+	if !services.Can(member, services.PermissionView) {
+		http.Error(w, "Access denied", http.StatusForbidden)
+		return
+	}
+
+	h.renderGigsSection(w, r, bandID)
+}
+
+// CreateGig handles POST /api/bands/gigs
+func (h *GigHandler) CreateGig(w http.ResponseWriter, r *http.Request) {
+	bandID := r.URL.Query().Get("id")
+	if bandID == "" {
+		http.Error(w, "Band ID is required", http.StatusBadRequest)
+		return
+	}
+
+	// Get current user from context
+	user := GetUserFromContext(r.Context())
+	if user == nil {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	// Check if user can edit the band
+	member, err := h.bandsDB.GetBandMember(bandID, user.ID)
+	if err != nil {
+		log.Printf("Error checking band membership: %v", err)
+		http.Error(w, "Failed to check band membership", http.StatusInternalServerError)
+		return
+	}
+	if !services.Can(member, services.PermissionEdit) {
+		http.Error(w, "Access denied", http.StatusForbidden)
+		return
+	}
+
+	// Parse form data
+	if err := r.ParseForm(); err != nil {
+		http.Error(w, "Invalid form data", http.StatusBadRequest)
+		return
+	}
+
+	form, errorMsg := parseGigForm(r)
+	if errorMsg != "" {
+		h.renderGigsError(w, r, errorMsg, bandID)
+		return
+	}
+
+	// Create gig
+	_, err = h.gigsDB.CreateGig(bandID, form.Name, form.Venue, form.Date, form.LoadInTime, form.StartTime, form.CurfewTime, form.Notes, user.ID)
+	if err != nil {
+		log.Printf("Error creating gig: %v", err)
+		h.renderGigsError(w, r, "Failed to create gig", bandID)
+		return
+	}
+
+	h.renderGigsSection(w, r, bandID)
+}
+
+// EditGig handles POST /api/gigs/{gigID}
+func (h *GigHandler) EditGig(w http.ResponseWriter, r *http.Request) {
+	// Extract gig ID from URL path
+	pathParts := strings.Split(r.URL.Path, "/")
+	if len(pathParts) < 4 {
+		http.Error(w, "Gig ID is required", http.StatusBadRequest)
+		return
+	}
+	gigID := pathParts[3]
+
+	// Get current user from context
+	user := GetUserFromContext(r.Context())
+	if user == nil {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	gig, ok := h.getGigForMember(w, gigID, user, services.PermissionEdit)
+	if !ok {
+		return
+	}
+
+	// Parse form data
+	if err := r.ParseForm(); err != nil {
+		http.Error(w, "Invalid form data", http.StatusBadRequest)
+		return
+	}
+
+	form, errorMsg := parseGigForm(r)
+	if errorMsg != "" {
+		http.Error(w, errorMsg, http.StatusBadRequest)
+		return
+	}
+
+	// Update gig
+	err := h.gigsDB.UpdateGig(gig.ID, form.Name, form.Venue, form.Date, form.LoadInTime, form.StartTime, form.CurfewTime, form.Notes)
+	if err != nil {
+		log.Printf("Error updating gig: %v", err)
+		http.Error(w, "Failed to update gig", http.StatusInternalServerError)
+		return
+	}
+
+	// Redirect to gig page
+	http.Redirect(w, r, "/gig?id="+gig.ID, http.StatusSeeOther)
+}
+
+// DeleteGig handles DELETE /api/gigs/{gigID}
+func (h *GigHandler) DeleteGig(w http.ResponseWriter, r *http.Request) {
+	// Extract gig ID from URL path
+	pathParts := strings.Split(r.URL.Path, "/")
+	if len(pathParts) < 4 {
+		http.Error(w, "Gig ID is required", http.StatusBadRequest)
+		return
+	}
+	gigID := pathParts[3]
+
+	// Get current user from context
+	user := GetUserFromContext(r.Context())
+	if user == nil {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	gig, ok := h.getGigForMember(w, gigID, user, services.PermissionEdit)
+	if !ok {
+		return
+	}
+
+	// Delete gig; its setlists stay with the band
+	err := h.gigsDB.DeleteGig(gig.ID)
+	if err != nil {
+		log.Printf("Error deleting gig: %v", err)
+		h.renderGigsError(w, r, "Failed to delete gig", gig.BandID)
+		return
+	}
+
+	h.renderGigsSection(w, r, gig.BandID)
+}
+
+// AddGigSet handles POST /api/gigs/{gigID}/sets
+func (h *GigHandler) AddGigSet(w http.ResponseWriter, r *http.Request) {
+	// Extract gig ID from URL path
+	pathParts := strings.Split(r.URL.Path, "/")
+	if len(pathParts) < 5 {
+		http.Error(w, "Gig ID is required", http.StatusBadRequest)
+		return
+	}
+	gigID := pathParts[3]
+
+	// Get current user from context
+	user := GetUserFromContext(r.Context())
+	if user == nil {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	gig, ok := h.getGigForMember(w, gigID, user, services.PermissionEdit)
+	if !ok {
+		return
+	}
+
+	// Parse form data
+	if err := r.ParseForm(); err != nil {
+		http.Error(w, "Invalid form data", http.StatusBadRequest)
+		return
+	}
+
+	setlistID := r.FormValue("setlist_id")
+	if setlistID == "" {
+		h.renderGigScheduleError(w, r, "Selecciona un setlist", gig.ID)
+		return
+	}
+
+	// The setlist must belong to the same band as the gig
+	setlist, err := h.setlistsDB.GetSetlistByID(setlistID)
+	if err != nil {
+		log.Printf("Error getting setlist: %v", err)
+		h.renderGigScheduleError(w, r, "Failed to get setlist", gig.ID)
+		return
+	}
+	if setlist == nil || setlist.BandID != gig.BandID {
+		h.renderGigScheduleError(w, r, "El setlist no pertenece a esta banda", gig.ID)
+		return
+	}
+
+	name, breakMinutes, isEncore, errorMsg := parseGigSetForm(r)
+	if errorMsg != "" {
+		h.renderGigScheduleError(w, r, errorMsg, gig.ID)
+		return
+	}
+	if name == "" {
+		name = setlist.Name
+	}
+
+	_, err = h.gigsDB.AddGigSet(gig.ID, setlist.ID, name, breakMinutes, isEncore)
+	if err != nil {
+		log.Printf("Error adding gig set: %v", err)
+		h.renderGigScheduleError(w, r, "Failed to add set", gig.ID)
+		return
+	}
+
+	h.renderGigScheduleSection(w, r, gig)
+}
+
+// UpdateGigSet handles POST /api/gigs/{gigID}/sets/{setID}
+func (h *GigHandler) UpdateGigSet(w http.ResponseWriter, r *http.Request) {
+	// Extract gig and set IDs from URL path
+	pathParts := strings.Split(r.URL.Path, "/")
+	if len(pathParts) < 6 {
+		http.Error(w, "Gig ID and set ID are required", http.StatusBadRequest)
+		return
+	}
+	gigID := pathParts[3]
+	setID := pathParts[5]
+
+	// Get current user from context
+	user := GetUserFromContext(r.Context())
+	if user == nil {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	gig, ok := h.getGigForMember(w, gigID, user, services.PermissionEdit)
+	if !ok {
+		return
+	}
+
+	// Parse form data
+	if err := r.ParseForm(); err != nil {
+		http.Error(w, "Invalid form data", http.StatusBadRequest)
+		return
+	}
+
+	name, breakMinutes, isEncore, errorMsg := parseGigSetForm(r)
+	if errorMsg == "" && name == "" {
+		errorMsg = "El nombre del set es obligatorio"
+	}
+	if errorMsg != "" {
+		h.renderGigScheduleError(w, r, errorMsg, gig.ID)
+		return
+	}
+
+	err := h.gigsDB.UpdateGigSet(gig.ID, setID, name, breakMinutes, isEncore)
+	if err != nil {
+		log.Printf("Error updating gig set: %v", err)
+		h.renderGigScheduleError(w, r, "Failed to update set", gig.ID)
+		return
+	}
+
+	h.renderGigScheduleSection(w, r, gig)
+}
+
+// RemoveGigSet handles DELETE /api/gigs/{gigID}/sets/{setID}
+func (h *GigHandler) RemoveGigSet(w http.ResponseWriter, r *http.Request) {
+	// Extract gig and set IDs from URL path
+	pathParts := strings.Split(r.URL.Path, "/")
+	if len(pathParts) < 6 {
+		http.Error(w, "Gig ID and set ID are required", http.StatusBadRequest)
+		return
+	}
+	gigID := pathParts[3]
+	setID := pathParts[5]
+
+	// Get current user from context
+	user := GetUserFromContext(r.Context())
+	if user == nil {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	gig, ok := h.getGigForMember(w, gigID, user, services.PermissionEdit)
+	if !ok {
+		return
+	}
+
+	err := h.gigsDB.RemoveGigSet(gig.ID, setID)
+	if err != nil {
+		log.Printf("Error removing gig set: %v", err)
+		h.renderGigScheduleError(w, r, "Failed to remove set", gig.ID)
+		return
+	}
+
+	h.renderGigScheduleSection(w, r, gig)
+}
+
+// ReorderGigSets handles POST /api/gigs/{gigID}/reorder
+func (h *GigHandler) ReorderGigSets(w http.ResponseWriter, r *http.Request) {
+	// Extract gig ID from URL path
+	pathParts := strings.Split(r.URL.Path, "/")
+	if len(pathParts) < 5 {
+		http.Error(w, "Gig ID is required", http.StatusBadRequest)
+		return
+	}
+	gigID := pathParts[3]
+
+	// Get current user from context
+	user := GetUserFromContext(r.Context())
+	if user == nil {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	gig, ok := h.getGigForMember(w, gigID, user, services.PermissionEdit)
+	if !ok {
+		return
+	}
+
+	var req ReorderGigSetsRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	err := h.gigsDB.ReorderGigSets(gig.ID, req.SetOrder)
+	if err != nil {
+		log.Printf("Error reordering gig sets: %v", err)
+		h.renderGigScheduleError(w, r, "Failed to reorder sets", gig.ID)
+		return
+	}
+
+	h.renderGigScheduleSection(w, r, gig)
+}
+
+// ExportGigPDF handles GET /api/gigs/{gigID}/export-pdf
+func (h *GigHandler) ExportGigPDF(w http.ResponseWriter, r *http.Request) {
+	// Extract gig ID from URL path
+	pathParts := strings.Split(r.URL.Path, "/")
+	if len(pathParts) < 5 {
+		http.Error(w, "Gig ID is required", http.StatusBadRequest)
+		return
+	}
+	gigID := pathParts[3]
+
+	// Get current user from context
+	user := GetUserFromContext(r.Context())
+	if user == nil {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	gig, ok := h.getGigForMember(w, gigID, user, services.PermissionView)
+	if !ok {
+		return
+	}
+
+	band, err := h.bandsDB.GetBandByID(gig.BandID)
+	if err != nil {
+		log.Printf("Error getting band: %v", err)
+		http.Error(w, "Failed to get band", http.StatusInternalServerError)
+		return
+	}
+	if band == nil {
+		http.Error(w, "Band not found", http.StatusNotFound)
+		return
+	}
+
+	schedule, err := h.gigService.GetSchedule(gig)
+	if err != nil {
+		log.Printf("Error getting gig schedule: %v", err)
+		http.Error(w, "Failed to get gig schedule", http.StatusInternalServerError)
+		return
+	}
+
+	book := services.GigBook(band.Name, gig, schedule)
+	book.SetLayout(songPDFLayout(r))
+
+	// Generate PDF
+	pdfBytes, err := h.pdfService.GenerateGigBookPDF(book)
+	if err != nil {
+		log.Printf("Error generating gig book PDF: %v", err)
+		http.Error(w, "Failed to generate PDF", http.StatusInternalServerError)
+		return
+	}
+
+	// Set response headers for PDF download
+	filename := fmt.Sprintf("%s - %s - %s.pdf", band.Name, gig.Name, gig.Date)
+	w.Header().Set("Content-Type", "application/pdf")
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=\"%s\"", filename))
+	w.Header().Set("Content-Length", fmt.Sprintf("%d", len(pdfBytes)))
+
+	w.Write(pdfBytes)
+}
+
+// getGigForMember loads a gig and verifies the user belongs to its band with the
+// permission, writing the appropriate error response when it does not
+func (h *GigHandler) getGigForMember(w http.ResponseWriter, gigID string, user *types.User, permission services.Permission) (*store.Gig, bool) {
+	gig, err := h.gigsDB.GetGigByID(gigID)
+	if err != nil {
+		log.Printf("Error getting gig: %v", err)
+		http.Error(w, "Failed to get gig", http.StatusInternalServerError)
+		return nil, false
+	}
+	if gig == nil {
+		http.Error(w, "Gig not found", http.StatusNotFound)
+		return nil, false
+	}
+
+	// Check if user is a member of the band
+	member, err := h.bandsDB.GetBandMember(gig.BandID, user.ID)
+	if err != nil {
+		log.Printf("Error checking band membership: %v", err)
+		http.Error(w, "Failed to check band membership", http.StatusInternalServerError)
+		return nil, false
+	}
+	if !services.Can(member, permission) {
+		http.Error(w, "Access denied", http.StatusForbidden)
+		return nil, false
+	}
+
+	return gig, true
+}
+
+// getGigSchedule computes the gig schedule and loads the band setlists that can be added as sets
+func (h *GigHandler) getGigSchedule(gig *store.Gig) (*services.GigSchedule, []*store.Setlist, error) {
+	schedule, err := h.gigService.GetSchedule(gig)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	setlists, err := h.setlistsDB.GetSetlistsByBand(gig.BandID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return schedule, setlists, nil
+}
+
+// parseGigForm reads and validates the gig fields, returning a user-facing error message if invalid
+func parseGigForm(r *http.Request) (gigForm, string) {
+	form := gigForm{
+		Name:       strings.TrimSpace(r.FormValue("name")),
+		Venue:      strings.TrimSpace(r.FormValue("venue")),
+		Date:       r.FormValue("date"),
+		LoadInTime: r.FormValue("load_in_time"),
+		StartTime:  r.FormValue("start_time"),
+		CurfewTime: r.FormValue("curfew_time"),
+		Notes:      r.FormValue("notes"),
+	}
+
+	if form.Name == "" {
+		return form, "El nombre del show es obligatorio"
+	}
+	if form.Date == "" || form.StartTime == "" {
+		return form, "La fecha y la hora de inicio son obligatorias"
+	}
+	if err := services.ValidateGigTimes(form.Date, form.LoadInTime, form.StartTime, form.CurfewTime); err != nil {
+		return form, "Fecha u horario inválido"
+	}
+
+	return form, ""
+}
+
+// parseGigSetForm reads the set name, break length and encore marker
+func parseGigSetForm(r *http.Request) (string, int, bool, string) {
+	name := strings.TrimSpace(r.FormValue("name"))
+	isEncore := r.FormValue("is_encore") == "on" || r.FormValue("is_encore") == "true"
+
+	breakMinutes := 0
+	if value := r.FormValue("break_minutes"); value != "" {
+		minutes, err := strconv.Atoi(value)
+		if err != nil || minutes < 0 {
+			return name, 0, isEncore, "La duración del descanso debe ser un número de minutos"
+		}
+		breakMinutes = minutes
+	}
+
+	return name, breakMinutes, isEncore, ""
+}
+
+// renderGigsSection renders the band's gigs section
+func (h *GigHandler) renderGigsSection(w http.ResponseWriter, r *http.Request, bandID string) {
+	gigs, err := h.gigsDB.GetGigsByBand(bandID)
+	if err != nil {
+		log.Printf("Error getting gigs: %v", err)
+		h.renderGigsError(w, r, "Failed to get gigs", bandID)
+		return
+	}
+
+	// Return HTML response with the gigs section
+	w.Header().Set("Content-Type", "text/html")
+	err = templates.GigsSection(gigs, bandID).Render(r.Context(), w)
+	if err != nil {
+		log.Printf("Error rendering gigs section: %v", err)
+		http.Error(w, "Failed to render gigs section", http.StatusInternalServerError)
+		return
+	}
+}
+
+// renderGigsError renders the gigs section with an error message
+func (h *GigHandler) renderGigsError(w http.ResponseWriter, r *http.Request, errorMsg, bandID string) {
+	w.Header().Set("Content-Type", "text/html")
+	err := templates.GigsSectionError(errorMsg, bandID).Render(r.Context(), w)
+	if err != nil {
+		log.Printf("Error rendering error template: %v", err)
+		http.Error(w, "Failed to render error template", http.StatusInternalServerError)
+	}
+}
+
+// renderGigScheduleSection renders the running order and timing of a gig
+func (h *GigHandler) renderGigScheduleSection(w http.ResponseWriter, r *http.Request, gig *store.Gig) {
+	schedule, setlists, err := h.getGigSchedule(gig)
+	if err != nil {
+		log.Printf("Error getting gig schedule: %v", err)
+		h.renderGigScheduleError(w, r, "Failed to get gig schedule", gig.ID)
+		return
+	}
+
+	// Return HTML response with the updated schedule section
+	w.Header().Set("Content-Type", "text/html")
+	err = templates.GigScheduleSection(gig, schedule, setlists).Render(r.Context(), w)
+	if err != nil {
+		log.Printf("Error rendering gig schedule section: %v", err)
+		http.Error(w, "Failed to render gig schedule section", http.StatusInternalServerError)
+		return
+	}
+}
+
+// renderGigScheduleError renders the gig schedule section with an error message
+func (h *GigHandler) renderGigScheduleError(w http.ResponseWriter, r *http.Request, errorMsg, gigID string) {
+	w.Header().Set("Content-Type", "text/html")
+	err := templates.GigScheduleSectionError(errorMsg, gigID).Render(r.Context(), w)
+	if err != nil {
+		log.Printf("Error rendering error template: %v", err)
+		http.Error(w, "Failed to render error template", http.StatusInternalServerError)
+	}
+}
@@ -0,0 +1,161 @@
+package api
+
+import (
+	"errors"
+	"log"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/nahue/setlist_manager/internal/services"
+	"github.com/nahue/setlist_manager/internal/store"
+	"github.com/nahue/setlist_manager/templates"
+)
+
+// Handler handles the page where invited people answer a band invitation
+type InvitationHandler struct {
+	authService       *services.AuthService
+	invitationService *services.InvitationService
+	mailService       *services.MailService
+}
+
+// NewInvitationHandler creates a new invitation handler
+func NewInvitationHandler(authService *services.AuthService, invitationService *services.InvitationService, mailService *services.MailService) *InvitationHandler {
+	return &InvitationHandler{
+		authService:       authService,
+		invitationService: invitationService,
+		mailService:       mailService,
+	}
+}
+
+// ServeInvitation handles GET /invitation. The page is public: the token in
+// the link identifies the invitation, and whoever opens it may not have an
+// account yet.
+func (h *InvitationHandler) ServeInvitation(w http.ResponseWriter, r *http.Request) {
+	token := r.URL.Query().Get("token")
+	invitation, ok := h.getInvitation(w, token)
+	if !ok {
+		return
+	}
+	if invitation == nil {
+		w.WriteHeader(http.StatusNotFound)
+	}
+
+	h.renderInvitation(w, r, invitation, token, "")
+}
+
+// SendSignInLink handles POST /invitation/sign-in, emailing the invited
+// address a magic link that comes back to the invitation. Signing in creates
+// the account when there is none.
+func (h *InvitationHandler) SendSignInLink(w http.ResponseWriter, r *http.Request) {
+	token := r.FormValue("token")
+	invitation, ok := h.getOpenInvitation(w, r, token)
+	if !ok {
+		return
+	}
+
+	magicToken, err := h.authService.GenerateMagicLink(invitation.InvitedEmail)
+	if err != nil {
+		log.Printf("Error generating magic link for invitation: %v", err)
+		http.Error(w, "Failed to send magic link", http.StatusInternalServerError)
+		return
+	}
+
+	next := "/invitation?token=" + url.QueryEscape(token)
+	magicLink := h.mailService.Link("/auth/verify?token=" + url.QueryEscape(magicToken) + "&next=" + url.QueryEscape(next))
+	if err := h.mailService.SendMagicLink(r.Context(), invitation.InvitedEmail, magicLink); err != nil {
+		log.Printf("Error sending magic link for invitation: %v", err)
+		http.Error(w, "Failed to send magic link", http.StatusInternalServerError)
+		return
+	}
+
+	h.renderInvitation(w, r, invitation, token, "Te enviamos un enlace a "+invitation.InvitedEmail+". Ábrelo para entrar y aceptar la invitación.")
+}
+
+// AcceptInvitation handles POST /invitation/accept
+func (h *InvitationHandler) AcceptInvitation(w http.ResponseWriter, r *http.Request) {
+	token := r.FormValue("token")
+	invitation, ok := h.getOpenInvitation(w, r, token)
+	if !ok {
+		return
+	}
+
+	// Accepting needs the account of the invited email
+	user := h.authService.GetCurrentUser(r)
+	if user == nil {
+		http.Redirect(w, r, "/invitation?token="+url.QueryEscape(token), http.StatusSeeOther)
+		return
+	}
+
+	err := h.invitationService.Accept(r.Context(), invitation, user)
+	if errors.Is(err, services.ErrInvitationWrongUser) {
+		w.WriteHeader(http.StatusForbidden)
+		h.renderInvitation(w, r, invitation, token, "Esta invitación es para "+invitation.InvitedEmail+".")
+		return
+	}
+	if errors.Is(err, services.ErrInvitationNotPending) {
+		http.Redirect(w, r, "/invitation?token="+url.QueryEscape(token), http.StatusSeeOther)
+		return
+	}
+	if err != nil {
+		log.Printf("Error accepting invitation: %v", err)
+		http.Error(w, "Failed to accept invitation", http.StatusInternalServerError)
+		return
+	}
+
+	// Redirect to the band
+	http.Redirect(w, r, "/band?id="+invitation.BandID, http.StatusSeeOther)
+}
+
+// DeclineInvitation handles POST /invitation/decline
+func (h *InvitationHandler) DeclineInvitation(w http.ResponseWriter, r *http.Request) {
+	token := r.FormValue("token")
+	invitation, ok := h.getOpenInvitation(w, r, token)
+	if !ok {
+		return
+	}
+
+	err := h.invitationService.Decline(invitation)
+	if err != nil && !errors.Is(err, services.ErrInvitationNotPending) {
+		log.Printf("Error declining invitation: %v", err)
+		http.Error(w, "Failed to decline invitation", http.StatusInternalServerError)
+		return
+	}
+
+	http.Redirect(w, r, "/invitation?token="+url.QueryEscape(token), http.StatusSeeOther)
+}
+
+// getInvitation gets the invitation sent with token, which is nil when there
+// is none, writing the error response when it can't be read
+func (h *InvitationHandler) getInvitation(w http.ResponseWriter, token string) (*store.BandInvitation, bool) {
+	invitation, err := h.invitationService.GetInvitationByToken(strings.TrimSpace(token))
+	if err != nil {
+		log.Printf("Error getting invitation: %v", err)
+		http.Error(w, "Failed to get invitation", http.StatusInternalServerError)
+		return nil, false
+	}
+	return invitation, true
+}
+
+// getOpenInvitation gets the invitation sent with token when it can still be
+// answered, and otherwise sends the browser back to the invitation page
+func (h *InvitationHandler) getOpenInvitation(w http.ResponseWriter, r *http.Request, token string) (*store.BandInvitation, bool) {
+	invitation, ok := h.getInvitation(w, token)
+	if !ok {
+		return nil, false
+	}
+	if invitation == nil || !invitation.Open() {
+		http.Redirect(w, r, "/invitation?token="+url.QueryEscape(token), http.StatusSeeOther)
+		return nil, false
+	}
+	return invitation, true
+}
+
+// renderInvitation renders the invitation page for the signed in user, if any
+func (h *InvitationHandler) renderInvitation(w http.ResponseWriter, r *http.Request, invitation *store.BandInvitation, token, message string) {
+	user := h.authService.GetCurrentUser(r)
+	err := templates.InvitationPage(invitation, token, user, message).Render(r.Context(), w)
+	if err != nil {
+		log.Printf("Error rendering invitation page: %v", err)
+	}
+}
@@ -0,0 +1,410 @@
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/nahue/setlist_manager/internal/app/shared/types"
+	"github.com/nahue/setlist_manager/internal/services"
+	"github.com/nahue/setlist_manager/internal/store"
+	"github.com/nahue/setlist_manager/templates"
+)
+
+// maxListedJobs bounds the jobs shown for a band
+const maxListedJobs = 100
+
+// Handler handles background job requests
+type JobHandler struct {
+	jobsDB     *store.SQLiteJobsStore
+	bandsDB    *store.SQLiteBandsStore
+	songsDB    *store.SQLiteSongsStore
+	setlistsDB *store.SQLiteSetlistsStore
+	gigsDB     *store.SQLiteGigsStore
+	jobQueue   *services.JobQueue
+}
+
+// NewJobHandler creates a new jobs handler
+func NewJobHandler(jobsDB *store.SQLiteJobsStore, bandsDB *store.SQLiteBandsStore, songsDB *store.SQLiteSongsStore, setlistsDB *store.SQLiteSetlistsStore, gigsDB *store.SQLiteGigsStore, jobQueue *services.JobQueue) *JobHandler {
+	return &JobHandler{
+		jobsDB:     jobsDB,
+		bandsDB:    bandsDB,
+		songsDB:    songsDB,
+		setlistsDB: setlistsDB,
+		gigsDB:     gigsDB,
+		jobQueue:   jobQueue,
+	}
+}
+
+// ServeJobs handles GET /band/jobs
+func (h *JobHandler) ServeJobs(w http.ResponseWriter, r *http.Request) {
+	bandID := r.URL.Query().Get("id")
+	if bandID == "" {
+		http.Error(w, "Band ID is required", http.StatusBadRequest)
+		return
+	}
+
+	user := GetUserFromContext(r.Context())
+	if !h.checkMember(w, bandID, user, services.PermissionView) {
+		return
+	}
+
+	band, err := h.bandsDB.GetBandByIDShared(bandID)
+	if err != nil {
+		log.Printf("Error getting band: %v", err)
+		http.Error(w, "Failed to get band", http.StatusInternalServerError)
+		return
+	}
+	if band == nil {
+		http.Error(w, "Band not found", http.StatusNotFound)
+		return
+	}
+
+	jobs, err := h.jobsDB.GetJobsByBand(bandID, maxListedJobs)
+	if err != nil {
+		log.Printf("Error getting jobs: %v", err)
+		http.Error(w, "Failed to get jobs", http.StatusInternalServerError)
+		return
+	}
+
+	templates.JobsPage(band, user, jobs).Render(r.Context(), w)
+}
+
+// GetJobs handles GET /api/bands/jobs
+func (h *JobHandler) GetJobs(w http.ResponseWriter, r *http.Request) {
+	bandID := r.URL.Query().Get("band_id")
+	if bandID == "" {
+		http.Error(w, "Band ID is required", http.StatusBadRequest)
+		return
+	}
+
+	user := GetUserFromContext(r.Context())
+	if !h.checkMember(w, bandID, user, services.PermissionView) {
+		return
+	}
+
+	jobs, err := h.jobsDB.GetJobsByBand(bandID, maxListedJobs)
+	if err != nil {
+		log.Printf("Error getting jobs: %v", err)
+		http.Error(w, "Failed to get jobs", http.StatusInternalServerError)
+		return
+	}
+	if jobs == nil {
+		jobs = []*store.Job{}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"success": true,
+		"jobs":    jobs,
+	})
+}
+
+// GetJob handles GET /api/jobs/{jobID}
+func (h *JobHandler) GetJob(w http.ResponseWriter, r *http.Request) {
+	job, ok := h.getJobForMember(w, r, services.PermissionView)
+	if !ok {
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"success": true,
+		"job":     job,
+	})
+}
+
+// CancelJob handles POST /api/jobs/{jobID}/cancel
+func (h *JobHandler) CancelJob(w http.ResponseWriter, r *http.Request) {
+	job, ok := h.getJobForMember(w, r, services.PermissionEdit)
+	if !ok {
+		return
+	}
+
+	cancelled, err := h.jobsDB.CancelJob(job.ID)
+	if err != nil {
+		log.Printf("Error cancelling job: %v", err)
+		http.Error(w, "Failed to cancel job", http.StatusInternalServerError)
+		return
+	}
+	if !cancelled {
+		http.Error(w, "Only queued jobs can be cancelled", http.StatusConflict)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"success": true,
+		"message": "Job cancelled",
+	})
+}
+
+// DownloadJobResult handles GET /api/jobs/{jobID}/download
+func (h *JobHandler) DownloadJobResult(w http.ResponseWriter, r *http.Request) {
+	job, ok := h.getJobForMember(w, r, services.PermissionView)
+	if !ok {
+		return
+	}
+
+	result, err := h.jobsDB.GetJobResult(job.ID)
+	if err != nil {
+		log.Printf("Error getting job result: %v", err)
+		http.Error(w, "Failed to get job result", http.StatusInternalServerError)
+		return
+	}
+	if result == nil {
+		http.Error(w, "The job has no result to download", http.StatusNotFound)
+		return
+	}
+
+	w.Header().Set("Content-Type", job.ResultType)
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=\"%s\"", job.ResultName))
+	w.Header().Set("Content-Length", fmt.Sprintf("%d", len(result)))
+
+	w.Write(result)
+}
+
+// QueueSongContent handles POST /api/songs/{songID}/jobs/generate-content
+func (h *JobHandler) QueueSongContent(w http.ResponseWriter, r *http.Request) {
+	user := GetUserFromContext(r.Context())
+	song, ok := h.getSongForMember(w, r, user, services.PermissionGenerate)
+	if !ok {
+		return
+	}
+
+	payload := services.SongContentJob{SongID: song.ID}
+	title := "Contenido con IA: " + song.Title
+	if role := r.FormValue("role"); role != "" && role != services.PromptRoleMain {
+		if !services.ValidPromptRole(role) {
+			http.Error(w, "Invalid role", http.StatusBadRequest)
+			return
+		}
+		payload.Role = role
+		title = fmt.Sprintf("%s con IA: %s", services.PromptRoleLabel(role), song.Title)
+	}
+	h.enqueue(w, r, song.BandID, services.JobSongContent, title, user, payload)
+}
+
+// QueueBandContent handles POST /api/bands/songs/generate-content
+func (h *JobHandler) QueueBandContent(w http.ResponseWriter, r *http.Request) {
+	bandID := r.URL.Query().Get("id")
+	if bandID == "" {
+		http.Error(w, "Band ID is required", http.StatusBadRequest)
+		return
+	}
+
+	user := GetUserFromContext(r.Context())
+	if !h.checkMember(w, bandID, user, services.PermissionGenerate) {
+		return
+	}
+
+	// Parse form data
+	if err := r.ParseForm(); err != nil {
+		http.Error(w, "Invalid form data", http.StatusBadRequest)
+		return
+	}
+
+	payload := services.BandContentJob{
+		BandID:    bandID,
+		SongIDs:   r.Form["song_ids"],
+		Overwrite: r.FormValue("overwrite") == "true",
+	}
+	label := "Contenido"
+	if role := r.FormValue("role"); role != "" && role != services.PromptRoleMain {
+		if !services.ValidPromptRole(role) {
+			http.Error(w, "Invalid role", http.StatusBadRequest)
+			return
+		}
+		payload.Role = role
+		label = services.PromptRoleLabel(role)
+	}
+	title := label + " con IA: canciones sin contenido"
+	if len(payload.SongIDs) > 0 {
+		title = fmt.Sprintf("%s con IA: %d canciones", label, len(payload.SongIDs))
+	}
+	h.enqueue(w, r, bandID, services.JobBandContent, title, user, payload)
+}
+
+// QueueSongPDF handles POST /api/songs/{songID}/jobs/export-pdf
+func (h *JobHandler) QueueSongPDF(w http.ResponseWriter, r *http.Request) {
+	user := GetUserFromContext(r.Context())
+	song, ok := h.getSongForMember(w, r, user, services.PermissionView)
+	if !ok {
+		return
+	}
+
+	payload := services.SongPDFJob{
+		SongID: song.ID,
+		Key:    r.FormValue("key"),
+		From:   r.FormValue("from"),
+	}
+	payload.Layout, payload.Columns = jobPDFLayout(r)
+	h.enqueue(w, r, song.BandID, services.JobSongPDF, "PDF: "+song.Title, user, payload)
+}
+
+// QueueSetlistPDF handles POST /api/setlists/{setlistID}/jobs/export-pdf
+func (h *JobHandler) QueueSetlistPDF(w http.ResponseWriter, r *http.Request) {
+	user := GetUserFromContext(r.Context())
+	// Extract setlist ID from URL path
+	pathParts := strings.Split(r.URL.Path, "/")
+	if len(pathParts) < 5 {
+		http.Error(w, "Setlist ID is required", http.StatusBadRequest)
+		return
+	}
+
+	setlist, err := h.setlistsDB.GetSetlistByID(pathParts[3])
+	if err != nil {
+		log.Printf("Error getting setlist: %v", err)
+		http.Error(w, "Failed to get setlist", http.StatusInternalServerError)
+		return
+	}
+	if setlist == nil {
+		http.Error(w, "Setlist not found", http.StatusNotFound)
+		return
+	}
+	if !h.checkMember(w, setlist.BandID, user, services.PermissionView) {
+		return
+	}
+
+	payload := services.SetlistPDFJob{SetlistID: setlist.ID}
+	payload.Layout, payload.Columns = jobPDFLayout(r)
+	h.enqueue(w, r, setlist.BandID, services.JobSetlistPDF, "Libro: "+setlist.Name, user, payload)
+}
+
+// QueueGigPDF handles POST /api/gigs/{gigID}/jobs/export-pdf
+func (h *JobHandler) QueueGigPDF(w http.ResponseWriter, r *http.Request) {
+	user := GetUserFromContext(r.Context())
+	// Extract gig ID from URL path
+	pathParts := strings.Split(r.URL.Path, "/")
+	if len(pathParts) < 5 {
+		http.Error(w, "Gig ID is required", http.StatusBadRequest)
+		return
+	}
+
+	gig, err := h.gigsDB.GetGigByID(pathParts[3])
+	if err != nil {
+		log.Printf("Error getting gig: %v", err)
+		http.Error(w, "Failed to get gig", http.StatusInternalServerError)
+		return
+	}
+	if gig == nil {
+		http.Error(w, "Gig not found", http.StatusNotFound)
+		return
+	}
+	if !h.checkMember(w, gig.BandID, user, services.PermissionView) {
+		return
+	}
+
+	payload := services.GigPDFJob{GigID: gig.ID}
+	payload.Layout, payload.Columns = jobPDFLayout(r)
+	h.enqueue(w, r, gig.BandID, services.JobGigPDF, "Libro: "+gig.Name, user, payload)
+}
+
+// enqueue queues a job and answers with it as JSON when asked for JSON, or sends
+// the browser to the band's jobs page
+func (h *JobHandler) enqueue(w http.ResponseWriter, r *http.Request, bandID, kind, title string, user *types.User, payload any) {
+	job, err := h.jobQueue.Enqueue(bandID, kind, title, user.ID, payload)
+	if err != nil {
+		log.Printf("Error queueing job: %v", err)
+		http.Error(w, "Failed to queue job", http.StatusInternalServerError)
+		return
+	}
+
+	if strings.Contains(r.Header.Get("Accept"), "application/json") {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusAccepted)
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"success": true,
+			"job":     job,
+		})
+		return
+	}
+
+	http.Redirect(w, r, "/band/jobs?id="+bandID, http.StatusSeeOther)
+}
+
+// jobPDFLayout reads the PDF layout from the form: layout=chart selects the
+// chord chart layout, with columns=2 for two columns
+func jobPDFLayout(r *http.Request) (string, int) {
+	if r.FormValue("layout") != services.SongLayoutChart {
+		return services.SongLayoutDocument, 1
+	}
+	columns, _ := strconv.Atoi(r.FormValue("columns"))
+	return services.SongLayoutChart, columns
+}
+
+// checkMember verifies the user belongs to the band with the permission,
+// writing the appropriate error response when they do not
+func (h *JobHandler) checkMember(w http.ResponseWriter, bandID string, user *types.User, permission services.Permission) bool {
+	if user == nil {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return false
+	}
+
+	member, err := h.bandsDB.GetBandMember(bandID, user.ID)
+	if err != nil {
+		log.Printf("Error checking band membership: %v", err)
+		http.Error(w, "Failed to check band membership", http.StatusInternalServerError)
+		return false
+	}
+	if !services.Can(member, permission) {
+		http.Error(w, "Access denied", http.StatusForbidden)
+		return false
+	}
+	return true
+}
+
+// getSongForMember loads the song from the URL and verifies the user belongs to its band
+// with the permission
+func (h *JobHandler) getSongForMember(w http.ResponseWriter, r *http.Request, user *types.User, permission services.Permission) (*store.Song, bool) {
+	// Extract song ID from URL path
+	pathParts := strings.Split(r.URL.Path, "/")
+	if len(pathParts) < 5 {
+		http.Error(w, "Song ID is required", http.StatusBadRequest)
+		return nil, false
+	}
+
+	song, err := h.songsDB.GetSongByID(pathParts[3])
+	if err != nil {
+		log.Printf("Error getting song: %v", err)
+		http.Error(w, "Failed to get song", http.StatusInternalServerError)
+		return nil, false
+	}
+	if song == nil {
+		http.Error(w, "Song not found", http.StatusNotFound)
+		return nil, false
+	}
+	if !h.checkMember(w, song.BandID, user, permission) {
+		return nil, false
+	}
+	return song, true
+}
+
+// getJobForMember loads the job from the URL and verifies the user belongs to its band
+// with the permission
+func (h *JobHandler) getJobForMember(w http.ResponseWriter, r *http.Request, permission services.Permission) (*store.Job, bool) {
+	// Extract job ID from URL path
+	pathParts := strings.Split(r.URL.Path, "/")
+	if len(pathParts) < 4 {
+		http.Error(w, "Job ID is required", http.StatusBadRequest)
+		return nil, false
+	}
+
+	job, err := h.jobsDB.GetJobByID(pathParts[3])
+	if err != nil {
+		log.Printf("Error getting job: %v", err)
+		http.Error(w, "Failed to get job", http.StatusInternalServerError)
+		return nil, false
+	}
+	if job == nil {
+		http.Error(w, "Job not found", http.StatusNotFound)
+		return nil, false
+	}
+	if !h.checkMember(w, job.BandID, GetUserFromContext(r.Context()), permission) {
+		return nil, false
+	}
+	return job, true
+}
@@ -0,0 +1,253 @@
+package api
+
+import (
+	"log"
+	"net/http"
+	"strings"
+
+	"github.com/nahue/setlist_manager/internal/app/shared/types"
+	"github.com/nahue/setlist_manager/internal/services"
+	"github.com/nahue/setlist_manager/internal/store"
+	"github.com/nahue/setlist_manager/templates"
+)
+
+// Handler handles prompt template requests
+type PromptHandler struct {
+	promptsDB *store.SQLitePromptsStore
+	bandsDB   *store.SQLiteBandsStore
+}
+
+// NewPromptHandler creates a new prompt templates handler
+func NewPromptHandler(promptsDB *store.SQLitePromptsStore, bandsDB *store.SQLiteBandsStore) *PromptHandler {
+	return &PromptHandler{
+		promptsDB: promptsDB,
+		bandsDB:   bandsDB,
+	}
+}
+
+// ServePrompts handles GET /band/prompts. With ?template= it also lists the
+// versions of that template.
+func (h *PromptHandler) ServePrompts(w http.ResponseWriter, r *http.Request) {
+	bandID := r.URL.Query().Get("id")
+	if bandID == "" {
+		http.Error(w, "Band ID is required", http.StatusBadRequest)
+		return
+	}
+
+	user := GetUserFromContext(r.Context())
+	if !h.checkMember(w, bandID, user) {
+		return
+	}
+
+	band, err := h.bandsDB.GetBandByIDShared(bandID)
+	if err != nil {
+		log.Printf("Error getting band: %v", err)
+		http.Error(w, "Failed to get band", http.StatusInternalServerError)
+		return
+	}
+	if band == nil {
+		http.Error(w, "Band not found", http.StatusNotFound)
+		return
+	}
+
+	promptTemplates, err := h.promptsDB.GetPromptTemplatesByBand(bandID)
+	if err != nil {
+		log.Printf("Error getting prompt templates: %v", err)
+		http.Error(w, "Failed to get prompt templates", http.StatusInternalServerError)
+		return
+	}
+
+	// Show the history of the selected template
+	var selected *store.PromptTemplate
+	var versions []*store.PromptTemplateVersion
+	if templateID := r.URL.Query().Get("template"); templateID != "" {
+		for _, t := range promptTemplates {
+			if t.ID == templateID {
+				selected = t
+			}
+		}
+		if selected != nil {
+			versions, err = h.promptsDB.GetPromptTemplateVersions(selected.ID)
+			if err != nil {
+				log.Printf("Error getting prompt template versions: %v", err)
+				http.Error(w, "Failed to get prompt template versions", http.StatusInternalServerError)
+				return
+			}
+		}
+	}
+
+	templates.PromptTemplatesPage(band, user, promptTemplates, selected, versions).Render(r.Context(), w)
+}
+
+// CreatePromptTemplate handles POST /api/bands/prompts
+func (h *PromptHandler) CreatePromptTemplate(w http.ResponseWriter, r *http.Request) {
+	bandID := r.URL.Query().Get("id")
+	if bandID == "" {
+		http.Error(w, "Band ID is required", http.StatusBadRequest)
+		return
+	}
+
+	user := GetUserFromContext(r.Context())
+	if !h.checkMember(w, bandID, user) {
+		return
+	}
+
+	// Parse form data
+	if err := r.ParseForm(); err != nil {
+		http.Error(w, "Invalid form data", http.StatusBadRequest)
+		return
+	}
+
+	name := strings.TrimSpace(r.FormValue("name"))
+	role := r.FormValue("role")
+	body := r.FormValue("body")
+	if name == "" {
+		http.Error(w, "Template name is required", http.StatusBadRequest)
+		return
+	}
+	if !services.ValidPromptRole(role) {
+		http.Error(w, "Invalid role", http.StatusBadRequest)
+		return
+	}
+	if err := services.ValidatePromptTemplate(body); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	promptTemplate, err := h.promptsDB.CreatePromptTemplate(bandID, name, role, body, user.ID)
+	if err != nil {
+		log.Printf("Error creating prompt template: %v", err)
+		http.Error(w, "Failed to create prompt template", http.StatusInternalServerError)
+		return
+	}
+
+	if r.FormValue("default") == "true" {
+		if err := h.promptsDB.SetDefaultPromptTemplate(promptTemplate.ID); err != nil {
+			log.Printf("Error setting default prompt template: %v", err)
+			http.Error(w, "Failed to set default prompt template", http.StatusInternalServerError)
+			return
+		}
+	}
+
+	http.Redirect(w, r, "/band/prompts?id="+bandID, http.StatusSeeOther)
+}
+
+// UpdatePromptTemplate handles POST /api/prompts/{templateID}. A changed body is
+// saved as a new version.
+func (h *PromptHandler) UpdatePromptTemplate(w http.ResponseWriter, r *http.Request) {
+	promptTemplate, ok := h.getTemplateForMember(w, r)
+	if !ok {
+		return
+	}
+	user := GetUserFromContext(r.Context())
+
+	// Parse form data
+	if err := r.ParseForm(); err != nil {
+		http.Error(w, "Invalid form data", http.StatusBadRequest)
+		return
+	}
+
+	name := strings.TrimSpace(r.FormValue("name"))
+	if name == "" {
+		name = promptTemplate.Name
+	}
+	body := r.FormValue("body")
+	if err := services.ValidatePromptTemplate(body); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	if err := h.promptsDB.UpdatePromptTemplate(promptTemplate.ID, name, body, user.ID); err != nil {
+		log.Printf("Error updating prompt template: %v", err)
+		http.Error(w, "Failed to update prompt template", http.StatusInternalServerError)
+		return
+	}
+
+	http.Redirect(w, r, "/band/prompts?id="+promptTemplate.BandID+"&template="+promptTemplate.ID, http.StatusSeeOther)
+}
+
+// SetDefaultPromptTemplate handles POST /api/prompts/{templateID}/default. With
+// default=false the band goes back to the built-in prompt for the role.
+func (h *PromptHandler) SetDefaultPromptTemplate(w http.ResponseWriter, r *http.Request) {
+	promptTemplate, ok := h.getTemplateForMember(w, r)
+	if !ok {
+		return
+	}
+
+	var err error
+	if r.FormValue("default") == "false" {
+		err = h.promptsDB.ClearDefaultPromptTemplate(promptTemplate.ID)
+	} else {
+		err = h.promptsDB.SetDefaultPromptTemplate(promptTemplate.ID)
+	}
+	if err != nil {
+		log.Printf("Error setting default prompt template: %v", err)
+		http.Error(w, "Failed to set default prompt template", http.StatusInternalServerError)
+		return
+	}
+
+	http.Redirect(w, r, "/band/prompts?id="+promptTemplate.BandID, http.StatusSeeOther)
+}
+
+// DeletePromptTemplate handles DELETE /api/prompts/{templateID}
+func (h *PromptHandler) DeletePromptTemplate(w http.ResponseWriter, r *http.Request) {
+	promptTemplate, ok := h.getTemplateForMember(w, r)
+	if !ok {
+		return
+	}
+
+	if err := h.promptsDB.DeletePromptTemplate(promptTemplate.ID); err != nil {
+		log.Printf("Error deleting prompt template: %v", err)
+		http.Error(w, "Failed to delete prompt template", http.StatusInternalServerError)
+		return
+	}
+
+	http.Redirect(w, r, "/band/prompts?id="+promptTemplate.BandID, http.StatusSeeOther)
+}
+
+// checkMember verifies the user belongs to the band with a role that manages
+// its prompt templates, writing the appropriate error response when they do not
+func (h *PromptHandler) checkMember(w http.ResponseWriter, bandID string, user *types.User) bool {
+	if user == nil {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return false
+	}
+
+	member, err := h.bandsDB.GetBandMember(bandID, user.ID)
+	if err != nil {
+		log.Printf("Error checking band membership: %v", err)
+		http.Error(w, "Failed to check band membership", http.StatusInternalServerError)
+		return false
+	}
+	if !services.Can(member, services.PermissionManageBand) {
+		http.Error(w, "Access denied", http.StatusForbidden)
+		return false
+	}
+	return true
+}
+
+// getTemplateForMember loads the template from the URL and verifies the user
+// belongs to its band
+func (h *PromptHandler) getTemplateForMember(w http.ResponseWriter, r *http.Request) (*store.PromptTemplate, bool) {
+	// Extract template ID from URL path
+	pathParts := strings.Split(r.URL.Path, "/")
+	if len(pathParts) < 4 {
+		http.Error(w, "Template ID is required", http.StatusBadRequest)
+		return nil, false
+	}
+
+	promptTemplate, err := h.promptsDB.GetPromptTemplateByID(pathParts[3])
+	if err != nil {
+		log.Printf("Error getting prompt template: %v", err)
+		http.Error(w, "Failed to get prompt template", http.StatusInternalServerError)
+		return nil, false
+	}
+	if promptTemplate == nil {
+		http.Error(w, "Template not found", http.StatusNotFound)
+		return nil, false
+	}
+	if !h.checkMember(w, promptTemplate.BandID, GetUserFromContext(r.Context())) {
+		return nil, false
+	}
+	return promptTemplate, true
+}
@@ -0,0 +1,707 @@
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"strings"
+
+	"github.com/nahue/setlist_manager/internal/app/shared/types"
+	"github.com/nahue/setlist_manager/internal/services"
+	"github.com/nahue/setlist_manager/internal/store"
+	"github.com/nahue/setlist_manager/templates"
+)
+
+// Handler handles setlist-related requests
+type SetlistHandler struct {
+	setlistsDB *store.SQLiteSetlistsStore
+	songsDB    *store.SQLiteSongsStore
+	bandsDB    *store.SQLiteBandsStore
+	pdfService *services.PDFService
+}
+
+// NewSetlistHandler creates a new setlists handler
+func NewSetlistHandler(setlistsDB *store.SQLiteSetlistsStore, songsDB *store.SQLiteSongsStore, bandsDB *store.SQLiteBandsStore, pdfService *services.PDFService) *SetlistHandler {
+	return &SetlistHandler{
+		setlistsDB: setlistsDB,
+		songsDB:    songsDB,
+		bandsDB:    bandsDB,
+		pdfService: pdfService,
+	}
+}
+
+// Request/Response structs
+type ReorderSetlistSongsRequest struct {
+	SongOrder []string `json:"song_order"`
+}
+
+// ServeSetlist handles GET /setlist
+func (h *SetlistHandler) ServeSetlist(w http.ResponseWriter, r *http.Request) {
+	setlistID := r.URL.Query().Get("id")
+	if setlistID == "" {
+		http.Error(w, "Setlist ID is required", http.StatusBadRequest)
+		return
+	}
+
+	// Get current user from context
+	user := GetUserFromContext(r.Context())
+	if user == nil {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	// Get setlist details
+	setlist, err := h.setlistsDB.GetSetlistByID(setlistID)
+	if err != nil {
+		log.Printf("Error getting setlist: %v", err)
+		http.Error(w, "Failed to get setlist", http.StatusInternalServerError)
+		return
+	}
+	if setlist == nil {
+		http.Error(w, "Setlist not found", http.StatusNotFound)
+		return
+	}
+
+	// Check if user is a member of the band
+	member, err := h.bandsDB.GetBandMember(setlist.BandID, user.ID)
+	if err != nil {
+		log.Printf("Error checking band membership: %v", err)
+		http.Error(w, "Failed to check band membership", http.StatusInternalServerError)
+		return
+	}
+	if !services.Can(member, services.PermissionView) {
+		http.Error(w, "Access denied", http.StatusForbidden)
+		return
+	}
+
+	// Get band details
+	band, err := h.bandsDB.GetBandByIDShared(setlist.BandID)
+	if err != nil {
+		log.Printf("Error getting band: %v", err)
+		http.Error(w, "Failed to get band", http.StatusInternalServerError)
+		return
+	}
+	if band == nil {
+		http.Error(w, "Band not found", http.StatusNotFound)
+		return
+	}
+
+	entries, available, err := h.getSetlistSongs(setlist)
+	if err != nil {
+		log.Printf("Error getting setlist songs: %v", err)
+		http.Error(w, "Failed to get setlist songs", http.StatusInternalServerError)
+		return
+	}
+
+	// Render setlist page
+	component := templates.SetlistDetailsPage(setlist, band, entries, available, user)
+	component.Render(r.Context(), w)
+}
+
+// GetSetlists handles GET /api/bands/setlists
+func (h *SetlistHandler) GetSetlists(w http.ResponseWriter, r *http.Request) {
+	bandID := r.URL.Query().Get("id")
+	if bandID == "" {
+		http.Error(w, "Band ID is required", http.StatusBadRequest)
+		return
+	}
+
+	// Get current user from context
+	user := GetUserFromContext(r.Context())
+	if user == nil {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	// Check if user is a member of the band
+	member, err := h.bandsDB.GetBandMember(bandID, user.ID)
+	if err != nil {
+		log.Printf("Error checking band membership: %v", err)
+		http.Error(w, "Failed to check band membership", http.StatusInternalServerError)
+		return
+	}
+	if !services.Can(member, services.PermissionView) {
+		http.Error(w, "Access denied", http.StatusForbidden)
+		return
+	}
+
+	h.renderSetlistsSection(w, r, bandID)
+}
+
+// CreateSetlist handles POST /api/bands/setlists
+func (h *SetlistHandler) CreateSetlist(w http.ResponseWriter, r *http.Request) {
+	bandID := r.URL.Query().Get("id")
+	if bandID == "" {
+		http.Error(w, "Band ID is required", http.StatusBadRequest)
+		return
+	}
+
+	// Get current user from context
+	user := GetUserFromContext(r.Context())
+	if user == nil {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	// Check if user can edit the band
+	member, err := h.bandsDB.GetBandMember(bandID, user.ID)
+	if err != nil {
+		log.Printf("Error checking band membership: %v", err)
+		http.Error(w, "Failed to check band membership", http.StatusInternalServerError)
+		return
+	}
+	if !services.Can(member, services.PermissionEdit) {
+		http.Error(w, "Access denied", http.StatusForbidden)
+		return
+	}
+
+	// Parse form data
+	if err := r.ParseForm(); err != nil {
+		http.Error(w, "Invalid form data", http.StatusBadRequest)
+		return
+	}
+
+	name := strings.TrimSpace(r.FormValue("name"))
+	description := r.FormValue("description")
+
+	if name == "" {
+		h.renderSetlistsError(w, r, "El nombre del setlist es obligatorio", bandID)
+		return
+	}
+
+	// Create setlist
+	_, err = h.setlistsDB.CreateSetlist(bandID, name, description, user.ID)
+	if err != nil {
+		log.Printf("Error creating setlist: %v", err)
+		h.renderSetlistsError(w, r, "Failed to create setlist", bandID)
+		return
+	}
+
+	h.renderSetlistsSection(w, r, bandID)
+}
+
+// EditSetlist handles POST /api/setlists/{setlistID}
+func (h *SetlistHandler) EditSetlist(w http.ResponseWriter, r *http.Request) {
+	// Extract setlist ID from URL path
+	pathParts := strings.Split(r.URL.Path, "/")
+	if len(pathParts) < 4 {
+		http.Error(w, "Setlist ID is required", http.StatusBadRequest)
+		return
+	}
+	setlistID := pathParts[3]
+
+	// Get current user from context
+	user := GetUserFromContext(r.Context())
+	if user == nil {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	setlist, ok := h.getSetlistForMember(w, setlistID, user, services.PermissionEdit)
+	if !ok {
+		return
+	}
+
+	// Parse form data
+	if err := r.ParseForm(); err != nil {
+		http.Error(w, "Invalid form data", http.StatusBadRequest)
+		return
+	}
+
+	name := strings.TrimSpace(r.FormValue("name"))
+	description := r.FormValue("description")
+
+	if name == "" {
+		http.Error(w, "Setlist name is required", http.StatusBadRequest)
+		return
+	}
+
+	// Update setlist
+	err := h.setlistsDB.UpdateSetlist(setlist.ID, name, description)
+	if err != nil {
+		log.Printf("Error updating setlist: %v", err)
+		http.Error(w, "Failed to update setlist", http.StatusInternalServerError)
+		return
+	}
+
+	// Redirect to setlist page
+	http.Redirect(w, r, "/setlist?id="+setlist.ID, http.StatusSeeOther)
+}
+
+// DeleteSetlist handles DELETE /api/setlists/{setlistID}
+func (h *SetlistHandler) DeleteSetlist(w http.ResponseWriter, r *http.Request) {
+	// Extract setlist ID from URL path
+	pathParts := strings.Split(r.URL.Path, "/")
+	if len(pathParts) < 4 {
+		http.Error(w, "Setlist ID is required", http.StatusBadRequest)
+		return
+	}
+	setlistID := pathParts[3]
+
+	// Get current user from context
+	user := GetUserFromContext(r.Context())
+	if user == nil {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	setlist, ok := h.getSetlistForMember(w, setlistID, user, services.PermissionEdit)
+	if !ok {
+		return
+	}
+
+	// Delete setlist; the songs stay in the band library
+	err := h.setlistsDB.DeleteSetlist(setlist.ID)
+	if err != nil {
+		log.Printf("Error deleting setlist: %v", err)
+		h.renderSetlistsError(w, r, "Failed to delete setlist", setlist.BandID)
+		return
+	}
+
+	h.renderSetlistsSection(w, r, setlist.BandID)
+}
+
+// AddSetlistSong handles POST /api/setlists/{setlistID}/songs
+func (h *SetlistHandler) AddSetlistSong(w http.ResponseWriter, r *http.Request) {
+	// Extract setlist ID from URL path
+	pathParts := strings.Split(r.URL.Path, "/")
+	if len(pathParts) < 5 {
+		http.Error(w, "Setlist ID is required", http.StatusBadRequest)
+		return
+	}
+	setlistID := pathParts[3]
+
+	// Get current user from context
+	user := GetUserFromContext(r.Context())
+	if user == nil {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	setlist, ok := h.getSetlistForMember(w, setlistID, user, services.PermissionEdit)
+	if !ok {
+		return
+	}
+
+	// Parse form data
+	if err := r.ParseForm(); err != nil {
+		http.Error(w, "Invalid form data", http.StatusBadRequest)
+		return
+	}
+
+	songID := r.FormValue("song_id")
+	if songID == "" {
+		h.renderSetlistSongsError(w, r, "Selecciona una canción", setlist.ID)
+		return
+	}
+
+	// The song must come from this band's library
+	song, err := h.songsDB.GetSongByID(songID)
+	if err != nil {
+		log.Printf("Error getting song: %v", err)
+		h.renderSetlistSongsError(w, r, "Failed to get song", setlist.ID)
+		return
+	}
+	if song == nil || song.BandID != setlist.BandID {
+		h.renderSetlistSongsError(w, r, "La canción no pertenece a esta banda", setlist.ID)
+		return
+	}
+
+	exists, err := h.setlistsDB.IsSongInSetlist(setlist.ID, song.ID)
+	if err != nil {
+		log.Printf("Error checking setlist song: %v", err)
+		h.renderSetlistSongsError(w, r, "Failed to check setlist songs", setlist.ID)
+		return
+	}
+	if exists {
+		h.renderSetlistSongsError(w, r, "La canción ya está en este setlist", setlist.ID)
+		return
+	}
+
+	_, err = h.setlistsDB.AddSongToSetlist(setlist.ID, song.ID)
+	if err != nil {
+		log.Printf("Error adding song to setlist: %v", err)
+		h.renderSetlistSongsError(w, r, "Failed to add song to setlist", setlist.ID)
+		return
+	}
+
+	h.renderSetlistSongsSection(w, r, setlist)
+}
+
+// RemoveSetlistSong handles DELETE /api/setlists/{setlistID}/songs/{songID}
+func (h *SetlistHandler) RemoveSetlistSong(w http.ResponseWriter, r *http.Request) {
+	// Extract setlist and song IDs from URL path
+	pathParts := strings.Split(r.URL.Path, "/")
+	if len(pathParts) < 6 {
+		http.Error(w, "Setlist ID and song ID are required", http.StatusBadRequest)
+		return
+	}
+	setlistID := pathParts[3]
+	songID := pathParts[5]
+
+	// Get current user from context
+	user := GetUserFromContext(r.Context())
+	if user == nil {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	setlist, ok := h.getSetlistForMember(w, setlistID, user, services.PermissionEdit)
+	if !ok {
+		return
+	}
+
+	err := h.setlistsDB.RemoveSongFromSetlist(setlist.ID, songID)
+	if err != nil {
+		log.Printf("Error removing song from setlist: %v", err)
+		h.renderSetlistSongsError(w, r, "Failed to remove song from setlist", setlist.ID)
+		return
+	}
+
+	h.renderSetlistSongsSection(w, r, setlist)
+}
+
+// ReorderSetlistSongs handles POST /api/setlists/{setlistID}/reorder
+func (h *SetlistHandler) ReorderSetlistSongs(w http.ResponseWriter, r *http.Request) {
+	// Extract setlist ID from URL path
+	pathParts := strings.Split(r.URL.Path, "/")
+	if len(pathParts) < 5 {
+		http.Error(w, "Setlist ID is required", http.StatusBadRequest)
+		return
+	}
+	setlistID := pathParts[3]
+
+	// Get current user from context
+	user := GetUserFromContext(r.Context())
+	if user == nil {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	setlist, ok := h.getSetlistForMember(w, setlistID, user, services.PermissionEdit)
+	if !ok {
+		return
+	}
+
+	var req ReorderSetlistSongsRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	// Reorder setlist songs; the library order in songs.position is untouched
+	err := h.setlistsDB.ReorderSetlistSongs(setlist.ID, req.SongOrder)
+	if err != nil {
+		log.Printf("Error reordering setlist songs: %v", err)
+		h.renderSetlistSongsError(w, r, "Failed to reorder setlist songs", setlist.ID)
+		return
+	}
+
+	h.renderSetlistSongsSection(w, r, setlist)
+}
+
+// ExportSetlistPDF handles GET /api/setlists/{setlistID}/export-pdf
+func (h *SetlistHandler) ExportSetlistPDF(w http.ResponseWriter, r *http.Request) {
+	// Extract setlist ID from URL path
+	pathParts := strings.Split(r.URL.Path, "/")
+	if len(pathParts) < 5 {
+		http.Error(w, "Setlist ID is required", http.StatusBadRequest)
+		return
+	}
+	setlistID := pathParts[3]
+
+	// Get current user from context
+	user := GetUserFromContext(r.Context())
+	if user == nil {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	setlist, ok := h.getSetlistForMember(w, setlistID, user, services.PermissionView)
+	if !ok {
+		return
+	}
+
+	band, err := h.bandsDB.GetBandByID(setlist.BandID)
+	if err != nil {
+		log.Printf("Error getting band: %v", err)
+		http.Error(w, "Failed to get band", http.StatusInternalServerError)
+		return
+	}
+	if band == nil {
+		http.Error(w, "Band not found", http.StatusNotFound)
+		return
+	}
+
+	entries, err := h.setlistsDB.GetSetlistSongs(setlist.ID)
+	if err != nil {
+		log.Printf("Error getting setlist songs: %v", err)
+		http.Error(w, "Failed to get setlist songs", http.StatusInternalServerError)
+		return
+	}
+
+	// A setlist prints as a book with a single set
+	book := services.SetlistBook(band.Name, setlist, entries)
+	book.SetLayout(songPDFLayout(r))
+
+	// Generate PDF
+	pdfBytes, err := h.pdfService.GenerateGigBookPDF(book)
+	if err != nil {
+		log.Printf("Error generating setlist PDF: %v", err)
+		http.Error(w, "Failed to generate PDF", http.StatusInternalServerError)
+		return
+	}
+
+	// Set response headers for PDF download
+	filename := fmt.Sprintf("%s - %s.pdf", band.Name, setlist.Name)
+	w.Header().Set("Content-Type", "application/pdf")
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=\"%s\"", filename))
+	w.Header().Set("Content-Length", fmt.Sprintf("%d", len(pdfBytes)))
+
+	w.Write(pdfBytes)
+}
+
+// getSetlistForMember loads a setlist and verifies the user belongs to its band
+// with the permission, writing the appropriate error response when it does not
+func (h *SetlistHandler) getSetlistForMember(w http.ResponseWriter, setlistID string, user *types.User, permission services.Permission) (*store.Setlist, bool) {
+	setlist, err := h.setlistsDB.GetSetlistByID(setlistID)
+	if err != nil {
+		log.Printf("Error getting setlist: %v", err)
+		http.Error(w, "Failed to get setlist", http.StatusInternalServerError)
+		return nil, false
+	}
+	if setlist == nil {
+		http.Error(w, "Setlist not found", http.StatusNotFound)
+		return nil, false
+	}
+
+	// Check if user is a member of the band
+	member, err := h.bandsDB.GetBandMember(setlist.BandID, user.ID)
+	if err != nil {
+		log.Printf("Error checking band membership: %v", err)
+		http.Error(w, "Failed to check band membership", http.StatusInternalServerError)
+		return nil, false
+	}
+	if !services.Can(member, permission) {
+		http.Error(w, "Access denied", http.StatusForbidden)
+		return nil, false
+	}
+
+	return setlist, true
+}
+
+// getSetlistSongs returns the setlist entries and the library songs not yet in the setlist
+func (h *SetlistHandler) getSetlistSongs(setlist *store.Setlist) ([]*store.SetlistSong, []*store.Song, error) {
+	entries, err := h.setlistsDB.GetSetlistSongs(setlist.ID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	library, err := h.songsDB.GetSongsByBand(setlist.BandID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	inSetlist := make(map[string]bool, len(entries))
+	for _, entry := range entries {
+		inSetlist[entry.SongID] = true
+	}
+
+	var available []*store.Song
+	for _, song := range library {
+		if !inSetlist[song.ID] {
+			available = append(available, song)
+		}
+	}
+
+	return entries, available, nil
+}
+
+// renderSetlistsSection renders the band's setlists section
+func (h *SetlistHandler) renderSetlistsSection(w http.ResponseWriter, r *http.Request, bandID string) {
+	setlists, err := h.setlistsDB.GetSetlistsByBand(bandID)
+	if err != nil {
+		log.Printf("Error getting setlists: %v", err)
+		h.renderSetlistsError(w, r, "Failed to get setlists", bandID)
+		return
+	}
+
+	// Return HTML response with the setlists section
+	w.Header().Set("Content-Type", "text/html")
+	err = templates.SetlistsSection(setlists, bandID).Render(r.Context(), w)
+	if err != nil {
+		log.Printf("Error rendering setlists section: %v", err)
+		http.Error(w, "Failed to render setlists section", http.StatusInternalServerError)
+		return
+	}
+}
+
+// renderSetlistsError renders the setlists section with an error message
+func (h *SetlistHandler) renderSetlistsError(w http.ResponseWriter, r *http.Request, errorMsg, bandID string) {
+	w.Header().Set("Content-Type", "text/html")
+	err := templates.SetlistsSectionError(errorMsg, bandID).Render(r.Context(), w)
+	if err != nil {
+		log.Printf("Error rendering error template: %v", err)
+		http.Error(w, "Failed to render error template", http.StatusInternalServerError)
+	}
+}
+
+// renderSetlistSongsSection renders the songs section of a setlist
+func (h *SetlistHandler) renderSetlistSongsSection(w http.ResponseWriter, r *http.Request, setlist *store.Setlist) {
+	entries, available, err := h.getSetlistSongs(setlist)
+	if err != nil {
+		log.Printf("Error getting setlist songs: %v", err)
+		h.renderSetlistSongsError(w, r, "Failed to get setlist songs", setlist.ID)
+		return
+	}
+
+	// Return HTML response with the updated setlist songs section
+	w.Header().Set("Content-Type", "text/html")
+	err = templates.SetlistSongsSection(setlist, entries, available).Render(r.Context(), w)
+	if err != nil {
+		log.Printf("Error rendering setlist songs section: %v", err)
+		http.Error(w, "Failed to render setlist songs section", http.StatusInternalServerError)
+		return
+	}
+}
+
+// renderSetlistSongsError renders the setlist songs section with an error message
+func (h *SetlistHandler) renderSetlistSongsError(w http.ResponseWriter, r *http.Request, errorMsg, setlistID string) {
+	w.Header().Set("Content-Type", "text/html")
+	err := templates.SetlistSongsSectionError(errorMsg, setlistID).Render(r.Context(), w)
+	if err != nil {
+		log.Printf("Error rendering error template: %v", err)
+		http.Error(w, "Failed to render error template", http.StatusInternalServerError)
+	}
+}
+
+// SetSetlistSongSegue handles POST /api/setlists/{setlistID}/songs/{songID}/segue
+func (h *SetlistHandler) SetSetlistSongSegue(w http.ResponseWriter, r *http.Request) {
+	// Extract setlist and song IDs from URL path
+	pathParts := strings.Split(r.URL.Path, "/")
+	if len(pathParts) < 7 {
+		http.Error(w, "Setlist ID and song ID are required", http.StatusBadRequest)
+		return
+	}
+	setlistID := pathParts[3]
+	songID := pathParts[5]
+
+	// Get current user from context
+	user := GetUserFromContext(r.Context())
+	if user == nil {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	setlist, ok := h.getSetlistForMember(w, setlistID, user, services.PermissionEdit)
+	if !ok {
+		return
+	}
+
+	// Parse form data
+	if err := r.ParseForm(); err != nil {
+		http.Error(w, "Invalid form data", http.StatusBadRequest)
+		return
+	}
+
+	err := h.setlistsDB.SetSetlistSongSegue(setlist.ID, songID, r.FormValue("segue") == "true")
+	if err != nil {
+		log.Printf("Error updating setlist song segue: %v", err)
+		h.renderSetlistSongsError(w, r, "Failed to update segue", setlist.ID)
+		return
+	}
+
+	h.renderSetlistSongsSection(w, r, setlist)
+}
+
+// ExportSetlistStageSheetPDF handles GET /api/setlists/{setlistID}/stage-sheet-pdf
+func (h *SetlistHandler) ExportSetlistStageSheetPDF(w http.ResponseWriter, r *http.Request) {
+	// Extract setlist ID from URL path
+	pathParts := strings.Split(r.URL.Path, "/")
+	if len(pathParts) < 5 {
+		http.Error(w, "Setlist ID is required", http.StatusBadRequest)
+		return
+	}
+
+	sheet, ok := h.getStageSheet(w, r, pathParts[3])
+	if !ok {
+		return
+	}
+
+	// Generate PDF
+	pdfBytes, err := h.pdfService.GenerateStageSheetPDF(sheet)
+	if err != nil {
+		log.Printf("Error generating stage sheet PDF: %v", err)
+		http.Error(w, "Failed to generate PDF", http.StatusInternalServerError)
+		return
+	}
+
+	// Set response headers for PDF download
+	filename := fmt.Sprintf("%s - stage.pdf", sheet.Title)
+	w.Header().Set("Content-Type", "application/pdf")
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=\"%s\"", filename))
+	w.Header().Set("Content-Length", fmt.Sprintf("%d", len(pdfBytes)))
+
+	w.Write(pdfBytes)
+}
+
+// ServeSetlistStageSheet handles GET /setlist/stage-sheet?id=
+func (h *SetlistHandler) ServeSetlistStageSheet(w http.ResponseWriter, r *http.Request) {
+	setlistID := r.URL.Query().Get("id")
+	if setlistID == "" {
+		http.Error(w, "Setlist ID is required", http.StatusBadRequest)
+		return
+	}
+
+	sheet, ok := h.getStageSheet(w, r, setlistID)
+	if !ok {
+		return
+	}
+
+	component := templates.StageSheetPage(sheet)
+	component.Render(r.Context(), w)
+}
+
+// getStageSheet loads a setlist the current user can access and builds its stage
+// sheet with the options from the query string, writing the error response if it can't
+func (h *SetlistHandler) getStageSheet(w http.ResponseWriter, r *http.Request, setlistID string) (*services.StageSheetRequest, bool) {
+	// Get current user from context
+	user := GetUserFromContext(r.Context())
+	if user == nil {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return nil, false
+	}
+
+	setlist, ok := h.getSetlistForMember(w, setlistID, user, services.PermissionView)
+	if !ok {
+		return nil, false
+	}
+
+	entries, err := h.setlistsDB.GetSetlistSongs(setlist.ID)
+	if err != nil {
+		log.Printf("Error getting setlist songs: %v", err)
+		http.Error(w, "Failed to get setlist songs", http.StatusInternalServerError)
+		return nil, false
+	}
+
+	return services.SetlistStageSheet(setlist, entries, parseStageSheetOptions(r)), true
+}
+
+// parseStageSheetOptions reads the stage sheet options form. Unchecked boxes are
+// not submitted, so the defaults only apply when the form wasn't used at all.
+func parseStageSheetOptions(r *http.Request) services.StageSheetOptions {
+	query := r.URL.Query()
+	if query.Get("options") == "" {
+		options := services.DefaultStageSheetOptions()
+		options.Landscape = query.Get("orientation") == "landscape"
+		return options
+	}
+	return services.StageSheetOptions{
+		Landscape:  query.Get("orientation") == "landscape",
+		ShowKey:    query.Get("key") != "",
+		ShowTempo:  query.Get("tempo") != "",
+		ShowSegues: query.Get("segues") != "",
+		ShowNotes:  query.Get("notes") != "",
+	}
+}
@@ -2,18 +2,30 @@ package api
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
 	"log"
 	"net/http"
+	"path/filepath"
 	"strconv"
 	"strings"
 
+	"github.com/a-h/templ"
 	"github.com/nahue/setlist_manager/internal/app/shared/types"
+	"github.com/nahue/setlist_manager/internal/chordpro"
 	"github.com/nahue/setlist_manager/internal/services"
 	"github.com/nahue/setlist_manager/internal/store"
+	"github.com/nahue/setlist_manager/internal/transpose"
 	"github.com/nahue/setlist_manager/templates"
 )
 
+// Largest files accepted for ChordPro and bulk song imports
+const (
+	maxChordProSize   = 1 << 20
+	maxSongImportSize = 5 << 20
+)
+
 // Handler handles song-related requests
 type SongHandler struct {
 	songsDB         *store.SQLiteSongsStore
@@ -40,17 +52,26 @@ func NewSongHandler(songsDB *store.SQLiteSongsStore, bandsDB *store.SQLiteBandsS
 
 // Request/Response structs
 type CreateSongRequest struct {
-	Title  string `json:"title"`
-	Artist string `json:"artist"`
-	Key    string `json:"key"`
-	Tempo  *int   `json:"tempo"`
-	Notes  string `json:"notes"`
+	Title    string `json:"title"`
+	Artist   string `json:"artist"`
+	Key      string `json:"key"`
+	Tempo    *int   `json:"tempo"`
+	Duration string `json:"duration"`
+	Notes    string `json:"notes"`
 }
 
 type ReorderSongsRequest struct {
 	SongOrder []string `json:"song_order"`
 }
 
+type TransposeSongResponse struct {
+	SongID    string `json:"song_id"`
+	FromKey   string `json:"from_key"`
+	Key       string `json:"key"`
+	Semitones int    `json:"semitones"`
+	Content   string `json:"content"`
+}
+
 // GetSongs handles GET /api/bands/songs
 func (h *SongHandler) GetSongs(w http.ResponseWriter, r *http.Request) {
 	bandID := r.URL.Query().Get("id")
@@ -59,8 +80,8 @@ func (h *SongHandler) GetSongs(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	// Get current user from session
-	user := h.authService.GetCurrentUser(r)
+	// Get current user from context
+	user := GetUserFromContext(r.Context())
 	if user == nil {
 		http.Error(w, "Unauthorized", http.StatusUnauthorized)
 		return
@@ -73,7 +94,7 @@ func (h *SongHandler) GetSongs(w http.ResponseWriter, r *http.Request) {
 		http.Error(w, "Failed to check band membership", http.StatusInternalServerError)
 		return
 	}
-	if member == nil {
+	if !services.Can(member, services.PermissionView) {
 		http.Error(w, "Access denied", http.StatusForbidden)
 		return
 	}
@@ -94,7 +115,7 @@ func (h *SongHandler) GetSongs(w http.ResponseWriter, r *http.Request) {
 
 	// Return HTML response with the songs section
 	w.Header().Set("Content-Type", "text/html")
-	err = templates.SongsSection(songs).Render(r.Context(), w)
+	err = templates.SongsSection(songs, member.Role).Render(r.Context(), w)
 	if err != nil {
 		log.Printf("Error rendering songs section: %v", err)
 		http.Error(w, "Failed to render songs section", http.StatusInternalServerError)
@@ -110,21 +131,21 @@ func (h *SongHandler) CreateSong(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	// Get current user from session
-	user := h.authService.GetCurrentUser(r)
+	// Get current user from context
+	user := GetUserFromContext(r.Context())
 	if user == nil {
 		http.Error(w, "Unauthorized", http.StatusUnauthorized)
 		return
 	}
 
-	// Check if user is a member of the band
+	// Check if user can edit the band
 	member, err := h.bandsDB.GetBandMember(bandID, user.ID)
 	if err != nil {
 		log.Printf("Error checking band membership: %v", err)
 		http.Error(w, "Failed to check band membership", http.StatusInternalServerError)
 		return
 	}
-	if member == nil {
+	if !services.Can(member, services.PermissionEdit) {
 		http.Error(w, "Access denied", http.StatusForbidden)
 		return
 	}
@@ -156,8 +177,20 @@ func (h *SongHandler) CreateSong(w http.ResponseWriter, r *http.Request) {
 		}
 	}
 
+	// Parse duration if provided, e.g. "3:45"
+	duration, err := services.ParseOptionalSongDuration(r.FormValue("duration"))
+	if err != nil {
+		w.Header().Set("Content-Type", "text/html")
+		err = templates.SongsSectionError("Duración inválida, usa el formato m:ss", bandID).Render(r.Context(), w)
+		if err != nil {
+			log.Printf("Error rendering error template: %v", err)
+			http.Error(w, "Failed to render error template", http.StatusInternalServerError)
+		}
+		return
+	}
+
 	// Create song
-	_, err = h.songsDB.CreateSong(bandID, title, artist, key, notes, content, user.ID, tempo)
+	_, err = h.songsDB.CreateSong(bandID, title, artist, key, notes, content, user.ID, store.SongRevisionManual, tempo, duration)
 	if err != nil {
 		log.Printf("Error creating song: %v", err)
 		// Return HTML error response
@@ -186,7 +219,7 @@ func (h *SongHandler) CreateSong(w http.ResponseWriter, r *http.Request) {
 
 	// Return HTML response with the updated songs section
 	w.Header().Set("Content-Type", "text/html")
-	err = templates.SongsSection(songs).Render(r.Context(), w)
+	err = templates.SongsSection(songs, member.Role).Render(r.Context(), w)
 	if err != nil {
 		log.Printf("Error rendering songs section: %v", err)
 		http.Error(w, "Failed to render songs section", http.StatusInternalServerError)
@@ -194,6 +227,316 @@ func (h *SongHandler) CreateSong(w http.ResponseWriter, r *http.Request) {
 	}
 }
 
+// ImportChordPro handles POST /api/bands/songs/import-chordpro
+func (h *SongHandler) ImportChordPro(w http.ResponseWriter, r *http.Request) {
+	bandID := r.URL.Query().Get("id")
+	if bandID == "" {
+		http.Error(w, "Band ID is required", http.StatusBadRequest)
+		return
+	}
+
+	// Get current user from context
+	user := GetUserFromContext(r.Context())
+	if user == nil {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	// Check if user can edit the band
+	member, err := h.bandsDB.GetBandMember(bandID, user.ID)
+	if err != nil {
+		log.Printf("Error checking band membership: %v", err)
+		http.Error(w, "Failed to check band membership", http.StatusInternalServerError)
+		return
+	}
+	if !services.Can(member, services.PermissionEdit) {
+		http.Error(w, "Access denied", http.StatusForbidden)
+		return
+	}
+
+	renderError := func(message string) {
+		w.Header().Set("Content-Type", "text/html")
+		err := templates.SongsSectionError(message, bandID).Render(r.Context(), w)
+		if err != nil {
+			log.Printf("Error rendering error template: %v", err)
+			http.Error(w, "Failed to render error template", http.StatusInternalServerError)
+		}
+	}
+
+	// Parse the uploaded file
+	if err := r.ParseMultipartForm(maxChordProSize); err != nil {
+		renderError("Archivo ChordPro inválido")
+		return
+	}
+	file, header, err := r.FormFile("file")
+	if err != nil {
+		renderError("Selecciona un archivo ChordPro")
+		return
+	}
+	defer file.Close()
+
+	song, err := chordpro.Parse(io.LimitReader(file, maxChordProSize))
+	if err != nil {
+		log.Printf("Error parsing ChordPro file: %v", err)
+		renderError("No se pudo leer el archivo ChordPro")
+		return
+	}
+
+	// Fall back to the file name when the file has no title directive
+	if song.Title == "" {
+		song.Title = strings.TrimSuffix(header.Filename, filepath.Ext(header.Filename))
+	}
+	if song.Title == "" {
+		renderError("El archivo ChordPro no tiene título")
+		return
+	}
+
+	// Create song
+	_, err = h.songsDB.CreateSong(bandID, song.Title, song.Artist, song.Key, "", song.Content, user.ID, store.SongRevisionImport, song.Tempo, song.Duration)
+	if err != nil {
+		log.Printf("Error creating song: %v", err)
+		renderError("Failed to create song")
+		return
+	}
+
+	// Get updated songs list to return
+	songs, err := h.songsDB.GetSongsByBand(bandID)
+	if err != nil {
+		log.Printf("Error getting updated songs: %v", err)
+		renderError("Failed to get updated songs")
+		return
+	}
+
+	// Return HTML response with the updated songs section
+	w.Header().Set("Content-Type", "text/html")
+	err = templates.SongsSection(songs, member.Role).Render(r.Context(), w)
+	if err != nil {
+		log.Printf("Error rendering songs section: %v", err)
+		http.Error(w, "Failed to render songs section", http.StatusInternalServerError)
+		return
+	}
+}
+
+// ServeImportSongs handles GET /band/import
+func (h *SongHandler) ServeImportSongs(w http.ResponseWriter, r *http.Request) {
+	bandID := r.URL.Query().Get("id")
+	if bandID == "" {
+		http.Error(w, "Band ID is required", http.StatusBadRequest)
+		return
+	}
+
+	// Get current user from context
+	user := GetUserFromContext(r.Context())
+	if user == nil {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	if !h.checkBandMember(w, bandID, user.ID, services.PermissionEdit) {
+		return
+	}
+
+	h.renderSongImportPage(w, r, bandID, user, nil)
+}
+
+// PreviewSongImport handles POST /api/bands/songs/import/preview
+func (h *SongHandler) PreviewSongImport(w http.ResponseWriter, r *http.Request) {
+	bandID := r.URL.Query().Get("id")
+	if bandID == "" {
+		http.Error(w, "Band ID is required", http.StatusBadRequest)
+		return
+	}
+
+	// Get current user from context
+	user := GetUserFromContext(r.Context())
+	if user == nil {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	if !h.checkBandMember(w, bandID, user.ID, services.PermissionEdit) {
+		return
+	}
+
+	// Read the uploaded file
+	if err := r.ParseMultipartForm(maxSongImportSize); err != nil {
+		h.renderSongImportError(w, r, "Archivo inválido")
+		return
+	}
+	file, header, err := r.FormFile("file")
+	if err != nil {
+		h.renderSongImportError(w, r, "Selecciona un archivo CSV o JSON")
+		return
+	}
+	defer file.Close()
+
+	data, err := io.ReadAll(io.LimitReader(file, maxSongImportSize+1))
+	if err != nil {
+		log.Printf("Error reading import file: %v", err)
+		h.renderSongImportError(w, r, "No se pudo leer el archivo")
+		return
+	}
+	if len(data) > maxSongImportSize {
+		h.renderSongImportError(w, r, "El archivo es demasiado grande")
+		return
+	}
+
+	result, ok := h.parseSongImport(w, r, bandID, header.Filename, data)
+	if !ok {
+		return
+	}
+
+	// Return the preview with the file contents so the import can be confirmed
+	w.Header().Set("Content-Type", "text/html")
+	err = templates.SongImportPreview(bandID, header.Filename, string(data), result, "").Render(r.Context(), w)
+	if err != nil {
+		log.Printf("Error rendering import preview: %v", err)
+		http.Error(w, "Failed to render import preview", http.StatusInternalServerError)
+		return
+	}
+}
+
+// ImportSongs handles POST /api/bands/songs/import
+func (h *SongHandler) ImportSongs(w http.ResponseWriter, r *http.Request) {
+	bandID := r.URL.Query().Get("id")
+	if bandID == "" {
+		http.Error(w, "Band ID is required", http.StatusBadRequest)
+		return
+	}
+
+	// Get current user from context
+	user := GetUserFromContext(r.Context())
+	if user == nil {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	if !h.checkBandMember(w, bandID, user.ID, services.PermissionEdit) {
+		return
+	}
+
+	// Parse form data. The file comes back URL-encoded, which can triple its size.
+	r.Body = http.MaxBytesReader(w, r.Body, 3*maxSongImportSize)
+	if err := r.ParseForm(); err != nil {
+		http.Error(w, "Invalid form data", http.StatusBadRequest)
+		return
+	}
+
+	// Validate the file again, since the band's songs may have changed since the preview
+	filename := r.FormValue("filename")
+	data := r.FormValue("data")
+	if len(data) > maxSongImportSize {
+		h.renderSongImportPage(w, r, bandID, user, templates.SongImportError("El archivo es demasiado grande"))
+		return
+	}
+	existing, err := h.songsDB.GetSongsByBand(bandID)
+	if err != nil {
+		log.Printf("Error getting songs: %v", err)
+		http.Error(w, "Failed to get songs", http.StatusInternalServerError)
+		return
+	}
+	result, err := services.ParseSongImport(filename, []byte(data), existing)
+	if err != nil {
+		log.Printf("Error parsing import file: %v", err)
+		h.renderSongImportPage(w, r, bandID, user, templates.SongImportError("No se pudo leer el archivo: "+err.Error()))
+		return
+	}
+
+	rows := result.Importable(r.FormValue("include_duplicates") == "on")
+	if len(rows) == 0 {
+		h.renderSongImportPage(w, r, bandID, user, templates.SongImportPreview(bandID, filename, data, result, "No hay canciones válidas para importar"))
+		return
+	}
+
+	songs := make([]*store.Song, 0, len(rows))
+	for _, row := range rows {
+		songs = append(songs, &store.Song{
+			Title:    row.Title,
+			Artist:   row.Artist,
+			Key:      row.Key,
+			Tempo:    row.Tempo,
+			Duration: row.Duration,
+			Notes:    row.Notes,
+			Content:  row.Content,
+		})
+	}
+
+	// Insert every song in one transaction
+	if _, err := h.songsDB.CreateSongs(bandID, user.ID, songs); err != nil {
+		log.Printf("Error importing songs: %v", err)
+		h.renderSongImportPage(w, r, bandID, user, templates.SongImportPreview(bandID, filename, data, result, "No se pudieron importar las canciones"))
+		return
+	}
+
+	// Redirect to band page
+	http.Redirect(w, r, "/band?id="+bandID, http.StatusSeeOther)
+}
+
+// checkBandMember verifies the user belongs to the band with the permission,
+// writing the appropriate error response when it does not
+func (h *SongHandler) checkBandMember(w http.ResponseWriter, bandID, userID string, permission services.Permission) bool {
+	member, err := h.bandsDB.GetBandMember(bandID, userID)
+	if err != nil {
+		log.Printf("Error checking band membership: %v", err)
+		http.Error(w, "Failed to check band membership", http.StatusInternalServerError)
+		return false
+	}
+	if !services.Can(member, permission) {
+		http.Error(w, "Access denied", http.StatusForbidden)
+		return false
+	}
+	return true
+}
+
+// parseSongImport validates an uploaded import file against the band's current songs,
+// rendering the import error when the file cannot be read
+func (h *SongHandler) parseSongImport(w http.ResponseWriter, r *http.Request, bandID, filename string, data []byte) (*services.SongImport, bool) {
+	existing, err := h.songsDB.GetSongsByBand(bandID)
+	if err != nil {
+		log.Printf("Error getting songs: %v", err)
+		h.renderSongImportError(w, r, "Failed to get songs")
+		return nil, false
+	}
+
+	result, err := services.ParseSongImport(filename, data, existing)
+	if err != nil {
+		log.Printf("Error parsing import file: %v", err)
+		h.renderSongImportError(w, r, "No se pudo leer el archivo: "+err.Error())
+		return nil, false
+	}
+
+	return result, true
+}
+
+// renderSongImportPage renders the import page, optionally showing a preview or error below the upload form
+func (h *SongHandler) renderSongImportPage(w http.ResponseWriter, r *http.Request, bandID string, user *types.User, preview templ.Component) {
+	// Get band details
+	band, err := h.bandsDB.GetBandByIDShared(bandID)
+	if err != nil {
+		log.Printf("Error getting band: %v", err)
+		http.Error(w, "Failed to get band", http.StatusInternalServerError)
+		return
+	}
+	if band == nil {
+		http.Error(w, "Band not found", http.StatusNotFound)
+		return
+	}
+
+	// Render import page
+	component := templates.SongImportPage(band, user, preview)
+	component.Render(r.Context(), w)
+}
+
+// renderSongImportError renders an error in place of the import preview
+func (h *SongHandler) renderSongImportError(w http.ResponseWriter, r *http.Request, message string) {
+	w.Header().Set("Content-Type", "text/html")
+	err := templates.SongImportError(message).Render(r.Context(), w)
+	if err != nil {
+		log.Printf("Error rendering error template: %v", err)
+		http.Error(w, "Failed to render error template", http.StatusInternalServerError)
+	}
+}
+
 // ReorderSongs handles POST /api/bands/songs/reorder
 func (h *SongHandler) ReorderSongs(w http.ResponseWriter, r *http.Request) {
 	bandID := r.URL.Query().Get("id")
@@ -202,21 +545,21 @@ func (h *SongHandler) ReorderSongs(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	// Get current user from session
-	user := h.authService.GetCurrentUser(r)
+	// Get current user from context
+	user := GetUserFromContext(r.Context())
 	if user == nil {
 		http.Error(w, "Unauthorized", http.StatusUnauthorized)
 		return
 	}
 
-	// Check if user is a member of the band
+	// Check if user can edit the band
 	member, err := h.bandsDB.GetBandMember(bandID, user.ID)
 	if err != nil {
 		log.Printf("Error checking band membership: %v", err)
 		http.Error(w, "Failed to check band membership", http.StatusInternalServerError)
 		return
 	}
-	if member == nil {
+	if !services.Can(member, services.PermissionEdit) {
 		http.Error(w, "Access denied", http.StatusForbidden)
 		return
 	}
@@ -257,7 +600,7 @@ func (h *SongHandler) ReorderSongs(w http.ResponseWriter, r *http.Request) {
 
 	// Return HTML response with the updated songs section
 	w.Header().Set("Content-Type", "text/html")
-	err = templates.SongsSection(songs).Render(r.Context(), w)
+	err = templates.SongsSection(songs, member.Role).Render(r.Context(), w)
 	if err != nil {
 		log.Printf("Error rendering songs section: %v", err)
 		http.Error(w, "Failed to render songs section", http.StatusInternalServerError)
@@ -273,8 +616,8 @@ func (h *SongHandler) ServeSongDetails(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	// Get current user from session
-	user := h.authService.GetCurrentUser(r)
+	// Get current user from context
+	user := GetUserFromContext(r.Context())
 	if user == nil {
 		http.Error(w, "Unauthorized", http.StatusUnauthorized)
 		return
@@ -311,7 +654,7 @@ func (h *SongHandler) ServeSongDetails(w http.ResponseWriter, r *http.Request) {
 		http.Error(w, "Failed to check band membership", http.StatusInternalServerError)
 		return
 	}
-	if member == nil {
+	if !services.Can(member, services.PermissionView) {
 		http.Error(w, "Access denied", http.StatusForbidden)
 		return
 	}
@@ -335,6 +678,21 @@ func (h *SongHandler) ServeSongDetails(w http.ResponseWriter, r *http.Request) {
 		IsActive:    band.IsActive,
 	}
 
+	// Transpose the content for viewing when a target key is requested
+	targetKey := r.URL.Query().Get("key")
+	fromKey := r.URL.Query().Get("from")
+	if targetKey != "" && song.Content != "" {
+		content, key, _, err := transposeSongContent(song, fromKey, targetKey)
+		if err != nil {
+			http.Error(w, "Invalid key", http.StatusBadRequest)
+			return
+		}
+		song.Content = content
+		targetKey = key
+	} else {
+		targetKey = ""
+	}
+
 	// Store original markdown content for editing
 	originalMarkdown := song.Content
 
@@ -344,9 +702,27 @@ func (h *SongHandler) ServeSongDetails(w http.ResponseWriter, r *http.Request) {
 		song.Content = string(htmlContent)
 	}
 
+	// Get the instrument-specific variants, converted to HTML for display
+	variants, err := h.songsDB.GetSongVariants(song.ID)
+	if err != nil {
+		log.Printf("Error getting song variants: %v", err)
+		http.Error(w, "Failed to get song variants", http.StatusInternalServerError)
+		return
+	}
+	for _, variant := range variants {
+		variant.Content = string(h.markdownService.ParseMarkdown(variant.Content))
+	}
+
+	revisions, err := h.songsDB.GetSongRevisions(song.ID)
+	if err != nil {
+		log.Printf("Error getting song revisions: %v", err)
+		http.Error(w, "Failed to get song revisions", http.StatusInternalServerError)
+		return
+	}
+
 	// Render the song details page
 	w.Header().Set("Content-Type", "text/html")
-	err = templates.SongDetailsPage(song, bandType, user, originalMarkdown).Render(r.Context(), w)
+	err = templates.SongDetailsPage(song, bandType, user, originalMarkdown, targetKey, fromKey, variants, revisions, member.Role).Render(r.Context(), w)
 	if err != nil {
 		log.Printf("Error rendering song details page: %v", err)
 		http.Error(w, "Failed to render song details page", http.StatusInternalServerError)
@@ -364,8 +740,8 @@ func (h *SongHandler) DeleteSong(w http.ResponseWriter, r *http.Request) {
 	}
 	songID := pathParts[len(pathParts)-1]
 
-	// Get current user from session
-	user := h.authService.GetCurrentUser(r)
+	// Get current user from context
+	user := GetUserFromContext(r.Context())
 	if user == nil {
 		http.Error(w, "Unauthorized", http.StatusUnauthorized)
 		return
@@ -383,20 +759,20 @@ func (h *SongHandler) DeleteSong(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	// Check if user is a member of the band
+	// Check if user can edit the band
 	member, err := h.bandsDB.GetBandMember(song.BandID, user.ID)
 	if err != nil {
 		log.Printf("Error checking band membership: %v", err)
 		http.Error(w, "Failed to check band membership", http.StatusInternalServerError)
 		return
 	}
-	if member == nil {
+	if !services.Can(member, services.PermissionEdit) {
 		http.Error(w, "Access denied", http.StatusForbidden)
 		return
 	}
 
 	// Delete song
-	err = h.songsDB.DeleteSong(songID)
+	err = h.songsDB.DeleteSong(songID, user.ID)
 	if err != nil {
 		log.Printf("Error deleting song: %v", err)
 		// Return HTML error response
@@ -425,7 +801,7 @@ func (h *SongHandler) DeleteSong(w http.ResponseWriter, r *http.Request) {
 
 	// Return HTML response with the updated songs section
 	w.Header().Set("Content-Type", "text/html")
-	err = templates.SongsSection(songs).Render(r.Context(), w)
+	err = templates.SongsSection(songs, member.Role).Render(r.Context(), w)
 	if err != nil {
 		log.Printf("Error rendering songs section: %v", err)
 		http.Error(w, "Failed to render songs section", http.StatusInternalServerError)
@@ -443,8 +819,8 @@ func (h *SongHandler) EditSong(w http.ResponseWriter, r *http.Request) {
 	}
 	songID := pathParts[len(pathParts)-1]
 
-	// Get current user from session
-	user := h.authService.GetCurrentUser(r)
+	// Get current user from context
+	user := GetUserFromContext(r.Context())
 	if user == nil {
 		http.Error(w, "Unauthorized", http.StatusUnauthorized)
 		return
@@ -462,14 +838,14 @@ func (h *SongHandler) EditSong(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	// Check if user is a member of the band
+	// Check if user can edit the band
 	member, err := h.bandsDB.GetBandMember(song.BandID, user.ID)
 	if err != nil {
 		log.Printf("Error checking band membership: %v", err)
 		http.Error(w, "Failed to check band membership", http.StatusInternalServerError)
 		return
 	}
-	if member == nil {
+	if !services.Can(member, services.PermissionEdit) {
 		http.Error(w, "Access denied", http.StatusForbidden)
 		return
 	}
@@ -501,8 +877,20 @@ func (h *SongHandler) EditSong(w http.ResponseWriter, r *http.Request) {
 		}
 	}
 
+	// Parse duration if provided, e.g. "3:45"
+	duration, err := services.ParseOptionalSongDuration(r.FormValue("duration"))
+	if err != nil {
+		w.Header().Set("Content-Type", "text/html")
+		err = templates.SongDetailsError("Duración inválida, usa el formato m:ss", songID).Render(r.Context(), w)
+		if err != nil {
+			log.Printf("Error rendering error template: %v", err)
+			http.Error(w, "Failed to render error template", http.StatusInternalServerError)
+		}
+		return
+	}
+
 	// Update song
-	err = h.songsDB.UpdateSong(songID, title, artist, key, notes, content, tempo)
+	err = h.songsDB.UpdateSong(songID, title, artist, key, notes, content, tempo, duration, user.ID, store.SongRevisionManual)
 	if err != nil {
 		log.Printf("Error updating song: %v", err)
 		// Return HTML error response
@@ -514,6 +902,9 @@ func (h *SongHandler) EditSong(w http.ResponseWriter, r *http.Request) {
 		}
 		return
 	}
+	if err := h.clearStaleSongSections(song, content); err != nil {
+		log.Printf("Error clearing song sections: %v", err)
+	}
 
 	// Redirect to song details page
 	http.Redirect(w, r, "/song?id="+songID, http.StatusSeeOther)
@@ -529,8 +920,8 @@ func (h *SongHandler) UpdateSongContent(w http.ResponseWriter, r *http.Request)
 	}
 	songID := pathParts[3]
 
-	// Get current user from session
-	user := h.authService.GetCurrentUser(r)
+	// Get current user from context
+	user := GetUserFromContext(r.Context())
 	if user == nil {
 		http.Error(w, "Unauthorized", http.StatusUnauthorized)
 		return
@@ -548,14 +939,14 @@ func (h *SongHandler) UpdateSongContent(w http.ResponseWriter, r *http.Request)
 		return
 	}
 
-	// Check if user is a member of the band
+	// Check if user can edit the band
 	member, err := h.bandsDB.GetBandMember(song.BandID, user.ID)
 	if err != nil {
 		log.Printf("Error checking band membership: %v", err)
 		http.Error(w, "Failed to check band membership", http.StatusInternalServerError)
 		return
 	}
-	if member == nil {
+	if !services.Can(member, services.PermissionEdit) {
 		http.Error(w, "Access denied", http.StatusForbidden)
 		return
 	}
@@ -570,12 +961,17 @@ func (h *SongHandler) UpdateSongContent(w http.ResponseWriter, r *http.Request)
 	content := r.FormValue("content")
 
 	// Update song content
-	err = h.songsDB.UpdateSong(songID, song.Title, song.Artist, song.Key, song.Notes, content, song.Tempo)
+	err = h.songsDB.UpdateSong(songID, song.Title, song.Artist, song.Key, song.Notes, content, song.Tempo, song.Duration, user.ID, store.SongRevisionManual)
 	if err != nil {
 		log.Printf("Error updating song content: %v", err)
 		http.Error(w, "Failed to update song content", http.StatusInternalServerError)
 		return
 	}
+	if err := h.clearStaleSongSections(song, content); err != nil {
+		log.Printf("Error clearing song sections: %v", err)
+		http.Error(w, "Failed to update song content", http.StatusInternalServerError)
+		return
+	}
 
 	// Get the updated song with processed content
 	updatedSong, err := h.songsDB.GetSongByID(songID)
@@ -594,7 +990,7 @@ func (h *SongHandler) UpdateSongContent(w http.ResponseWriter, r *http.Request)
 
 	// Return HTML response with the updated song content
 	w.Header().Set("Content-Type", "text/html")
-	err = templates.SongContent(updatedSong, originalMarkdown).Render(r.Context(), w)
+	err = templates.SongContent(updatedSong, originalMarkdown, member.Role).Render(r.Context(), w)
 	if err != nil {
 		log.Printf("Error rendering song content: %v", err)
 		http.Error(w, "Failed to render song content", http.StatusInternalServerError)
@@ -610,8 +1006,8 @@ func (h *SongHandler) ServeEditSong(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	// Get current user from session
-	user := h.authService.GetCurrentUser(r)
+	// Get current user from context
+	user := GetUserFromContext(r.Context())
 	if user == nil {
 		http.Error(w, "Unauthorized", http.StatusUnauthorized)
 		return
@@ -641,14 +1037,14 @@ func (h *SongHandler) ServeEditSong(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	// Check if user is a member of the band
+	// Check if user can edit the band
 	member, err := h.bandsDB.GetBandMember(song.BandID, user.ID)
 	if err != nil {
 		log.Printf("Error checking band membership: %v", err)
 		http.Error(w, "Failed to check band membership", http.StatusInternalServerError)
 		return
 	}
-	if member == nil {
+	if !services.Can(member, services.PermissionEdit) {
 		http.Error(w, "Access denied", http.StatusForbidden)
 		return
 	}
@@ -684,8 +1080,8 @@ func (h *SongHandler) ExportSongPDF(w http.ResponseWriter, r *http.Request) {
 	}
 	songID := pathParts[3]
 
-	// Get current user from session
-	user := h.authService.GetCurrentUser(r)
+	// Get current user from context
+	user := GetUserFromContext(r.Context())
 	if user == nil {
 		http.Error(w, "Unauthorized", http.StatusUnauthorized)
 		return
@@ -710,7 +1106,7 @@ func (h *SongHandler) ExportSongPDF(w http.ResponseWriter, r *http.Request) {
 		http.Error(w, "Failed to check band membership", http.StatusInternalServerError)
 		return
 	}
-	if member == nil {
+	if !services.Can(member, services.PermissionView) {
 		http.Error(w, "Access denied", http.StatusForbidden)
 		return
 	}
@@ -723,14 +1119,26 @@ func (h *SongHandler) ExportSongPDF(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	// Export the content transposed when a target key is requested
+	key := song.Key
+	content := song.Content
+	if targetKey := r.URL.Query().Get("key"); targetKey != "" {
+		content, key, _, err = transposeSongContent(song, r.URL.Query().Get("from"), targetKey)
+		if err != nil {
+			http.Error(w, "Invalid key", http.StatusBadRequest)
+			return
+		}
+	}
+
 	// Create PDF request with original markdown content
 	pdfReq := &services.SongContentPDFRequest{
 		SongTitle: song.Title,
 		Artist:    song.Artist,
-		Key:       song.Key,
+		Key:       key,
 		Tempo:     song.Tempo,
-		Content:   song.Content, // This is the original markdown content from the database
+		Content:   content, // This is the original markdown content from the database
 	}
+	pdfReq.Layout, pdfReq.Columns = songPDFLayout(r)
 
 	// Generate PDF
 	pdfBytes, err := h.pdfService.GenerateSongPDF(pdfReq)
@@ -754,6 +1162,41 @@ func (h *SongHandler) ExportSongPDF(w http.ResponseWriter, r *http.Request) {
 	w.Write(pdfBytes)
 }
 
+// ExportSongChordPro handles GET /api/songs/{songID}/export-chordpro
+func (h *SongHandler) ExportSongChordPro(w http.ResponseWriter, r *http.Request) {
+	song, ok := h.getAccessibleSong(w, r, services.PermissionView)
+	if !ok {
+		return
+	}
+
+	// Export the content transposed when a target key is requested
+	if targetKey := r.URL.Query().Get("key"); targetKey != "" {
+		content, key, _, err := transposeSongContent(song, r.URL.Query().Get("from"), targetKey)
+		if err != nil {
+			http.Error(w, "Invalid key", http.StatusBadRequest)
+			return
+		}
+		transposed := *song
+		transposed.Content = content
+		transposed.Key = key
+		song = &transposed
+	}
+
+	data := chordpro.Export(song)
+
+	// Set response headers for ChordPro download
+	filename := fmt.Sprintf("%s - %s.cho", song.Title, song.Artist)
+	if song.Artist == "" {
+		filename = fmt.Sprintf("%s.cho", song.Title)
+	}
+
+	w.Header().Set("Content-Type", "application/x-chordpro; charset=utf-8")
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=\"%s\"", filename))
+	w.Header().Set("Content-Length", fmt.Sprintf("%d", len(data)))
+
+	w.Write([]byte(data))
+}
+
 // GenerateSongContent handles POST /api/songs/{songID}/generate-content
 func (h *SongHandler) GenerateSongContent(w http.ResponseWriter, r *http.Request) {
 	// Extract song ID from URL path
@@ -764,8 +1207,8 @@ func (h *SongHandler) GenerateSongContent(w http.ResponseWriter, r *http.Request
 	}
 	songID := pathParts[3]
 
-	// Get current user from session
-	user := h.authService.GetCurrentUser(r)
+	// Get current user from context
+	user := GetUserFromContext(r.Context())
 	if user == nil {
 		http.Error(w, "Unauthorized", http.StatusUnauthorized)
 		return
@@ -783,27 +1226,31 @@ func (h *SongHandler) GenerateSongContent(w http.ResponseWriter, r *http.Request
 		return
 	}
 
-	// Check if user is a member of the band
+	// Check if user can generate content for the band
 	member, err := h.bandsDB.GetBandMember(song.BandID, user.ID)
 	if err != nil {
 		log.Printf("Error checking band membership: %v", err)
 		http.Error(w, "Failed to check band membership", http.StatusInternalServerError)
 		return
 	}
-	if member == nil {
+	if !services.Can(member, services.PermissionGenerate) {
 		http.Error(w, "Access denied", http.StatusForbidden)
 		return
 	}
 
-	// Generate content using AI service
-	aiReq := &services.SongContentRequest{
-		SongTitle: song.Title,
-		Artist:    song.Artist,
-		Key:       song.Key,
-		Tempo:     song.Tempo,
+	// Generate content using AI service, with the band's prompt template
+	aiReq, err := h.aiService.SongContentRequest(song, services.PromptRoleMain, user.ID)
+	if err != nil {
+		log.Printf("Error getting prompt template: %v", err)
+		http.Error(w, "Failed to generate song content", http.StatusInternalServerError)
+		return
 	}
 
-	aiResponse, err := h.aiService.GenerateSongContent(aiReq)
+	aiResponse, err := h.aiService.GenerateSongContent(r.Context(), aiReq)
+	if errors.Is(err, services.ErrAIQuotaExceeded) {
+		http.Error(w, err.Error(), http.StatusTooManyRequests)
+		return
+	}
 	if err != nil {
 		log.Printf("Error generating song content: %v", err)
 		http.Error(w, "Failed to generate song content", http.StatusInternalServerError)
@@ -811,8 +1258,7 @@ func (h *SongHandler) GenerateSongContent(w http.ResponseWriter, r *http.Request
 	}
 
 	// Update the song with the generated content
-	err = h.songsDB.UpdateSong(songID, song.Title, song.Artist, song.Key, song.Notes, aiResponse.Content, song.Tempo)
-	if err != nil {
+	if err := services.SaveGeneratedSongContent(h.songsDB, song, aiResponse, user.ID); err != nil {
 		log.Printf("Error updating song with generated content: %v", err)
 		http.Error(w, "Failed to update song with generated content", http.StatusInternalServerError)
 		return
@@ -835,10 +1281,313 @@ func (h *SongHandler) GenerateSongContent(w http.ResponseWriter, r *http.Request
 
 	// Return HTML response with the updated song content
 	w.Header().Set("Content-Type", "text/html")
-	err = templates.SongContent(updatedSong, originalMarkdown).Render(r.Context(), w)
+	err = templates.SongContent(updatedSong, originalMarkdown, member.Role).Render(r.Context(), w)
 	if err != nil {
 		log.Printf("Error rendering song content: %v", err)
 		http.Error(w, "Failed to render song content", http.StatusInternalServerError)
 		return
 	}
 }
+
+// StreamSongContent handles POST /api/songs/{songID}/generate-content/stream. It sends
+// server-sent events while the content is generated: "preview" events with the content
+// received so far, then "done" once the song is saved, or "error". Closing the
+// connection aborts the generation and leaves the song as it was.
+func (h *SongHandler) StreamSongContent(w http.ResponseWriter, r *http.Request) {
+	song, ok := h.getAccessibleSong(w, r, services.PermissionGenerate)
+	if !ok {
+		return
+	}
+
+	user := GetUserFromContext(r.Context())
+	aiReq, err := h.aiService.SongContentRequest(song, services.PromptRoleMain, user.ID)
+	if err != nil {
+		log.Printf("Error getting prompt template: %v", err)
+		http.Error(w, "Failed to generate song content", http.StatusInternalServerError)
+		return
+	}
+
+	stream := services.NewEventStream(w)
+	aiResponse, err := h.aiService.StreamSongContent(r.Context(), aiReq, func(content string) error {
+		return stream.Send("preview", map[string]string{"content": content})
+	})
+	if r.Context().Err() != nil {
+		log.Printf("Song content generation cancelled for song %s", song.ID)
+		return
+	}
+	if errors.Is(err, services.ErrAIQuotaExceeded) {
+		stream.Send("error", map[string]string{"error": "AI quota exceeded", "quota": err.Error()})
+		return
+	}
+	if err != nil {
+		log.Printf("Error generating song content: %v", err)
+		stream.Send("error", map[string]string{"error": "Failed to generate song content"})
+		return
+	}
+
+	if err := services.SaveGeneratedSongContent(h.songsDB, song, aiResponse, user.ID); err != nil {
+		log.Printf("Error updating song with generated content: %v", err)
+		stream.Send("error", map[string]string{"error": "Failed to update song with generated content"})
+		return
+	}
+
+	stream.Send("done", map[string]string{"redirect": "/song?id=" + song.ID})
+}
+
+// TransposeSong handles GET /api/songs/{songID}/transpose
+func (h *SongHandler) TransposeSong(w http.ResponseWriter, r *http.Request) {
+	song, ok := h.getAccessibleSong(w, r, services.PermissionView)
+	if !ok {
+		return
+	}
+
+	targetKey := r.URL.Query().Get("key")
+	if targetKey == "" {
+		http.Error(w, "Target key is required", http.StatusBadRequest)
+		return
+	}
+
+	content, key, semitones, err := transposeSongContent(song, r.URL.Query().Get("from"), targetKey)
+	if err != nil {
+		http.Error(w, "Invalid key", http.StatusBadRequest)
+		return
+	}
+
+	fromKey := r.URL.Query().Get("from")
+	if fromKey == "" {
+		fromKey = song.Key
+	}
+
+	// Return transposed content
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(TransposeSongResponse{
+		SongID:    song.ID,
+		FromKey:   fromKey,
+		Key:       key,
+		Semitones: semitones,
+		Content:   content,
+	})
+}
+
+// SaveTransposedSong handles POST /api/songs/{songID}/transpose
+func (h *SongHandler) SaveTransposedSong(w http.ResponseWriter, r *http.Request) {
+	song, ok := h.getAccessibleSong(w, r, services.PermissionEdit)
+	if !ok {
+		return
+	}
+
+	// Parse form data
+	if err := r.ParseForm(); err != nil {
+		http.Error(w, "Invalid form data", http.StatusBadRequest)
+		return
+	}
+
+	targetKey := r.FormValue("key")
+	if targetKey == "" {
+		http.Error(w, "Target key is required", http.StatusBadRequest)
+		return
+	}
+
+	content, key, semitones, err := transposeSongContent(song, r.FormValue("from"), targetKey)
+	if err != nil {
+		http.Error(w, "Invalid key", http.StatusBadRequest)
+		return
+	}
+
+	// Move the generated sections along with the content
+	sections, err := h.songsDB.GetSongSections(song.ID)
+	if err != nil {
+		log.Printf("Error getting song sections: %v", err)
+		http.Error(w, "Failed to save transposed song", http.StatusInternalServerError)
+		return
+	}
+	for _, section := range sections {
+		section.Body = transpose.Content(section.Body, semitones, transpose.UsesFlats(key))
+		if section.Key != "" {
+			if sectionKey, err := transpose.Key(section.Key, semitones); err == nil {
+				section.Key = sectionKey
+			}
+		}
+	}
+
+	// Save the transposed content, its sections and the new key together
+	user := GetUserFromContext(r.Context())
+	err = h.songsDB.UpdateSongWithSections(song.ID, song.Title, song.Artist, key, song.Notes, content, song.Tempo, song.Duration, sections, user.ID, store.SongRevisionManual)
+	if err != nil {
+		log.Printf("Error saving transposed song: %v", err)
+		http.Error(w, "Failed to save transposed song", http.StatusInternalServerError)
+		return
+	}
+
+	// Redirect to song details page
+	http.Redirect(w, r, "/song?id="+song.ID, http.StatusSeeOther)
+}
+
+// DiffSongRevisions handles GET /api/songs/{songID}/revisions/diff?from=&to=
+func (h *SongHandler) DiffSongRevisions(w http.ResponseWriter, r *http.Request) {
+	song, ok := h.getAccessibleSong(w, r, services.PermissionView)
+	if !ok {
+		return
+	}
+
+	fromRevision, err := strconv.Atoi(r.URL.Query().Get("from"))
+	if err != nil {
+		http.Error(w, "Invalid revision", http.StatusBadRequest)
+		return
+	}
+	toRevision, err := strconv.Atoi(r.URL.Query().Get("to"))
+	if err != nil {
+		http.Error(w, "Invalid revision", http.StatusBadRequest)
+		return
+	}
+
+	from, err := h.songsDB.GetSongRevision(song.ID, fromRevision)
+	if err != nil {
+		log.Printf("Error getting song revision: %v", err)
+		http.Error(w, "Failed to get song revision", http.StatusInternalServerError)
+		return
+	}
+	to, err := h.songsDB.GetSongRevision(song.ID, toRevision)
+	if err != nil {
+		log.Printf("Error getting song revision: %v", err)
+		http.Error(w, "Failed to get song revision", http.StatusInternalServerError)
+		return
+	}
+	if from == nil || to == nil {
+		http.Error(w, "Revision not found", http.StatusNotFound)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/html")
+	templates.SongRevisionDiff(services.DiffSongRevisions(from, to)).Render(r.Context(), w)
+}
+
+// RestoreSongRevision handles POST /api/songs/{songID}/revisions/{revision}/restore
+func (h *SongHandler) RestoreSongRevision(w http.ResponseWriter, r *http.Request) {
+	song, ok := h.getAccessibleSong(w, r, services.PermissionEdit)
+	if !ok {
+		return
+	}
+
+	// Extract revision number from URL path
+	pathParts := strings.Split(r.URL.Path, "/")
+	if len(pathParts) < 7 {
+		http.Error(w, "Revision is required", http.StatusBadRequest)
+		return
+	}
+	revisionNumber, err := strconv.Atoi(pathParts[5])
+	if err != nil {
+		http.Error(w, "Invalid revision", http.StatusBadRequest)
+		return
+	}
+
+	revision, err := h.songsDB.GetSongRevision(song.ID, revisionNumber)
+	if err != nil {
+		log.Printf("Error getting song revision: %v", err)
+		http.Error(w, "Failed to get song revision", http.StatusInternalServerError)
+		return
+	}
+	if revision == nil {
+		http.Error(w, "Revision not found", http.StatusNotFound)
+		return
+	}
+
+	// Save the old state as a new revision so the restore can be undone too
+	user := GetUserFromContext(r.Context())
+	err = h.songsDB.UpdateSong(song.ID, revision.Title, revision.Artist, revision.Key, revision.Notes, revision.Content, revision.Tempo, revision.Duration, user.ID, store.SongRevisionRestore)
+	if err != nil {
+		log.Printf("Error restoring song revision: %v", err)
+		http.Error(w, "Failed to restore song revision", http.StatusInternalServerError)
+		return
+	}
+
+	if err := h.clearStaleSongSections(song, revision.Content); err != nil {
+		log.Printf("Error clearing song sections: %v", err)
+	}
+
+	// Redirect to song details page
+	http.Redirect(w, r, "/song?id="+song.ID, http.StatusSeeOther)
+}
+
+// getAccessibleSong loads the song from the URL path and verifies the user belongs to its band
+// with the permission, writing the appropriate error response when it does not
+func (h *SongHandler) getAccessibleSong(w http.ResponseWriter, r *http.Request, permission services.Permission) (*store.Song, bool) {
+	// Extract song ID from URL path
+	pathParts := strings.Split(r.URL.Path, "/")
+	if len(pathParts) < 5 {
+		http.Error(w, "Song ID is required", http.StatusBadRequest)
+		return nil, false
+	}
+	songID := pathParts[3]
+
+	// Get current user from context
+	user := GetUserFromContext(r.Context())
+	if user == nil {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return nil, false
+	}
+
+	// Get song to check band membership
+	song, err := h.songsDB.GetSongByID(songID)
+	if err != nil {
+		log.Printf("Error getting song: %v", err)
+		http.Error(w, "Failed to get song", http.StatusInternalServerError)
+		return nil, false
+	}
+	if song == nil {
+		http.Error(w, "Song not found", http.StatusNotFound)
+		return nil, false
+	}
+
+	// Check if user is a member of the band
+	member, err := h.bandsDB.GetBandMember(song.BandID, user.ID)
+	if err != nil {
+		log.Printf("Error checking band membership: %v", err)
+		http.Error(w, "Failed to check band membership", http.StatusInternalServerError)
+		return nil, false
+	}
+	if !services.Can(member, permission) {
+		http.Error(w, "Access denied", http.StatusForbidden)
+		return nil, false
+	}
+
+	return song, true
+}
+
+// clearStaleSongSections drops the generated sections of a song once its content
+// is edited by hand, since they no longer match it
+func (h *SongHandler) clearStaleSongSections(song *store.Song, content string) error {
+	if content == song.Content {
+		return nil
+	}
+	return h.songsDB.ReplaceSongSections(song.ID, nil)
+}
+
+// transposeSongContent transposes a song's markdown content to the target key.
+// The source key defaults to the song's key and is required when the song has none.
+// It returns the resulting key with its conventional spelling, in the mode of the
+// source key, e.g. "Cm" for a song in "Am" moved to "C".
+func transposeSongContent(song *store.Song, fromKey, targetKey string) (string, string, int, error) {
+	if fromKey == "" {
+		fromKey = song.Key
+	}
+	semitones, err := transpose.Interval(fromKey, targetKey)
+	if err != nil {
+		return "", "", 0, err
+	}
+	key, err := transpose.Key(fromKey, semitones)
+	if err != nil {
+		return "", "", 0, err
+	}
+	return transpose.Content(song.Content, semitones, transpose.UsesFlats(key)), key, semitones, nil
+}
+
+// songPDFLayout reads the song PDF layout from the query string: ?layout=chart
+// selects the chord chart layout, with ?columns=2 for two columns
+func songPDFLayout(r *http.Request) (string, int) {
+	if r.URL.Query().Get("layout") != services.SongLayoutChart {
+		return services.SongLayoutDocument, 1
+	}
+	columns, _ := strconv.Atoi(r.URL.Query().Get("columns"))
+	return services.SongLayoutChart, columns
+}
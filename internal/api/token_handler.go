@@ -0,0 +1,129 @@
+package api
+
+import (
+	"errors"
+	"log"
+	"net/http"
+	"strconv"
+
+	"github.com/nahue/setlist_manager/internal/app/shared/types"
+	"github.com/nahue/setlist_manager/internal/services"
+	"github.com/nahue/setlist_manager/internal/store"
+	"github.com/nahue/setlist_manager/templates"
+)
+
+// TokenHandler handles the personal access tokens of the signed-in user. Its
+// routes live outside /api/ so that tokens can't be used to manage tokens.
+type TokenHandler struct {
+	apiTokenService *services.APITokenService
+}
+
+// NewTokenHandler creates a new API tokens handler
+func NewTokenHandler(apiTokenService *services.APITokenService) *TokenHandler {
+	return &TokenHandler{
+		apiTokenService: apiTokenService,
+	}
+}
+
+// ServeTokens handles GET /account/tokens
+func (h *TokenHandler) ServeTokens(w http.ResponseWriter, r *http.Request) {
+	user := GetUserFromContext(r.Context())
+	if user == nil {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	h.renderTokens(w, r, user, nil, "", "")
+}
+
+// CreateToken handles POST /account/tokens. The new token is shown on the
+// page this once; afterwards only its name and dates are.
+func (h *TokenHandler) CreateToken(w http.ResponseWriter, r *http.Request) {
+	user := GetUserFromContext(r.Context())
+	if user == nil {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	// Parse form data
+	if err := r.ParseForm(); err != nil {
+		http.Error(w, "Invalid form data", http.StatusBadRequest)
+		return
+	}
+
+	days, err := strconv.Atoi(r.FormValue("expires_in"))
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		h.renderTokens(w, r, user, nil, "", "Elige cuándo vence el token")
+		return
+	}
+
+	token, apiToken, err := h.apiTokenService.Create(user.ID, r.FormValue("name"), r.Form["scope"], days)
+	if err != nil {
+		var msg string
+		switch {
+		case errors.Is(err, services.ErrAPITokenNameRequired):
+			msg = "El nombre del token es obligatorio"
+		case errors.Is(err, services.ErrInvalidAPITokenScope):
+			msg = "Elige al menos un permiso para el token"
+		case errors.Is(err, services.ErrInvalidAPITokenTTL):
+			msg = "Elige cuándo vence el token"
+		default:
+			log.Printf("Error creating API token: %v", err)
+			http.Error(w, "Failed to create API token", http.StatusInternalServerError)
+			return
+		}
+		w.WriteHeader(http.StatusBadRequest)
+		h.renderTokens(w, r, user, nil, "", msg)
+		return
+	}
+
+	log.Printf("API token %s created for user: %s", apiToken.ID, user.ID)
+
+	// Keep the token out of browser caches
+	w.Header().Set("Cache-Control", "no-store")
+	h.renderTokens(w, r, user, apiToken, token, "")
+}
+
+// RevokeToken handles POST /account/tokens/revoke
+func (h *TokenHandler) RevokeToken(w http.ResponseWriter, r *http.Request) {
+	tokenID := r.URL.Query().Get("id")
+	if tokenID == "" {
+		http.Error(w, "Token ID is required", http.StatusBadRequest)
+		return
+	}
+
+	user := GetUserFromContext(r.Context())
+	if user == nil {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	if err := h.apiTokenService.Revoke(user.ID, tokenID); err != nil {
+		if errors.Is(err, services.ErrAPITokenNotFound) {
+			http.Error(w, "Token not found", http.StatusNotFound)
+			return
+		}
+		log.Printf("Error revoking API token: %v", err)
+		http.Error(w, "Failed to revoke API token", http.StatusInternalServerError)
+		return
+	}
+
+	http.Redirect(w, r, "/account/tokens", http.StatusSeeOther)
+}
+
+// renderTokens renders the user's tokens, with a token that was just created
+// or an error for the create form
+func (h *TokenHandler) renderTokens(w http.ResponseWriter, r *http.Request, user *types.User, created *store.APIToken, token, errorMsg string) {
+	tokens, err := h.apiTokenService.List(user.ID)
+	if err != nil {
+		log.Printf("Error getting API tokens: %v", err)
+		http.Error(w, "Failed to get API tokens", http.StatusInternalServerError)
+		return
+	}
+
+	err = templates.APITokensPage(user, tokens, created, token, getBaseURL(r), errorMsg).Render(r.Context(), w)
+	if err != nil {
+		log.Printf("Error rendering API tokens: %v", err)
+	}
+}
@@ -0,0 +1,186 @@
+package api
+
+import (
+	"log"
+	"net/http"
+	"strings"
+
+	"github.com/nahue/setlist_manager/internal/services"
+	"github.com/nahue/setlist_manager/internal/store"
+	"github.com/nahue/setlist_manager/templates"
+)
+
+// Handler handles trash requests
+type TrashHandler struct {
+	songsDB      *store.SQLiteSongsStore
+	bandsDB      *store.SQLiteBandsStore
+	trashService *services.TrashService
+}
+
+// NewTrashHandler creates a new trash handler
+func NewTrashHandler(songsDB *store.SQLiteSongsStore, bandsDB *store.SQLiteBandsStore, trashService *services.TrashService) *TrashHandler {
+	return &TrashHandler{
+		songsDB:      songsDB,
+		bandsDB:      bandsDB,
+		trashService: trashService,
+	}
+}
+
+// ServeBandTrash handles GET /band/trash, the songs deleted from a band
+func (h *TrashHandler) ServeBandTrash(w http.ResponseWriter, r *http.Request) {
+	bandID := r.URL.Query().Get("id")
+	if bandID == "" {
+		http.Error(w, "Band ID is required", http.StatusBadRequest)
+		return
+	}
+
+	user := GetUserFromContext(r.Context())
+	if user == nil {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	// Check if user can edit the band
+	member, err := h.bandsDB.GetBandMember(bandID, user.ID)
+	if err != nil {
+		log.Printf("Error checking band membership: %v", err)
+		http.Error(w, "Failed to check band membership", http.StatusInternalServerError)
+		return
+	}
+	if !services.Can(member, services.PermissionEdit) {
+		http.Error(w, "Access denied", http.StatusForbidden)
+		return
+	}
+
+	band, err := h.bandsDB.GetBandByIDShared(bandID)
+	if err != nil {
+		log.Printf("Error getting band: %v", err)
+		http.Error(w, "Failed to get band", http.StatusInternalServerError)
+		return
+	}
+	if band == nil {
+		http.Error(w, "Band not found", http.StatusNotFound)
+		return
+	}
+
+	songs, err := h.songsDB.GetDeletedSongs(bandID)
+	if err != nil {
+		log.Printf("Error getting deleted songs: %v", err)
+		http.Error(w, "Failed to get deleted songs", http.StatusInternalServerError)
+		return
+	}
+
+	templates.BandTrashPage(band, user, songs, h.trashService).Render(r.Context(), w)
+}
+
+// UndeleteSong handles POST /api/songs/{songID}/undelete
+func (h *TrashHandler) UndeleteSong(w http.ResponseWriter, r *http.Request) {
+	// Extract song ID from URL path
+	pathParts := strings.Split(r.URL.Path, "/")
+	if len(pathParts) < 5 {
+		http.Error(w, "Song ID is required", http.StatusBadRequest)
+		return
+	}
+	songID := pathParts[3]
+
+	user := GetUserFromContext(r.Context())
+	if user == nil {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	song, err := h.songsDB.GetDeletedSong(songID)
+	if err != nil {
+		log.Printf("Error getting deleted song: %v", err)
+		http.Error(w, "Failed to get deleted song", http.StatusInternalServerError)
+		return
+	}
+	if song == nil {
+		http.Error(w, "Song not found in the trash", http.StatusNotFound)
+		return
+	}
+
+	// Check if user can edit the band
+	member, err := h.bandsDB.GetBandMember(song.BandID, user.ID)
+	if err != nil {
+		log.Printf("Error checking band membership: %v", err)
+		http.Error(w, "Failed to check band membership", http.StatusInternalServerError)
+		return
+	}
+	if !services.Can(member, services.PermissionEdit) {
+		http.Error(w, "Access denied", http.StatusForbidden)
+		return
+	}
+
+	if err := h.songsDB.UndeleteSong(song.ID); err != nil {
+		log.Printf("Error undeleting song: %v", err)
+		http.Error(w, "Failed to restore song", http.StatusInternalServerError)
+		return
+	}
+
+	http.Redirect(w, r, "/band/trash?id="+song.BandID, http.StatusSeeOther)
+}
+
+// ServeBandsTrash handles GET /bands/trash, the deleted bands the user owns
+func (h *TrashHandler) ServeBandsTrash(w http.ResponseWriter, r *http.Request) {
+	user := GetUserFromContext(r.Context())
+	if user == nil {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	bands, err := h.bandsDB.GetDeletedBandsByUser(user.ID)
+	if err != nil {
+		log.Printf("Error getting deleted bands: %v", err)
+		http.Error(w, "Failed to get deleted bands", http.StatusInternalServerError)
+		return
+	}
+
+	templates.BandsTrashPage(user, bands, h.trashService).Render(r.Context(), w)
+}
+
+// UndeleteBand handles POST /api/bands/undelete
+func (h *TrashHandler) UndeleteBand(w http.ResponseWriter, r *http.Request) {
+	bandID := r.URL.Query().Get("id")
+	if bandID == "" {
+		http.Error(w, "Band ID is required", http.StatusBadRequest)
+		return
+	}
+
+	user := GetUserFromContext(r.Context())
+	if user == nil {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	// Only the band's owner can bring it back
+	member, err := h.bandsDB.GetDeletedBandMember(bandID, user.ID)
+	if err != nil {
+		log.Printf("Error checking band membership: %v", err)
+		http.Error(w, "Failed to check band membership", http.StatusInternalServerError)
+		return
+	}
+	if !services.Can(member, services.PermissionDeleteBand) {
+		http.Error(w, "Access denied", http.StatusForbidden)
+		return
+	}
+
+	band, err := h.bandsDB.GetBandByID(bandID)
+	if err != nil {
+		log.Printf("Error getting band: %v", err)
+		http.Error(w, "Failed to get band", http.StatusInternalServerError)
+		return
+	}
+	if band == nil || band.IsActive {
+		http.Error(w, "Band not found in the trash", http.StatusNotFound)
+		return
+	}
+
+	if err := h.bandsDB.UndeleteBand(bandID); err != nil {
+		log.Printf("Error undeleting band: %v", err)
+		http.Error(w, "Failed to restore band", http.StatusInternalServerError)
+		return
+	}
+
+	http.Redirect(w, r, "/band?id="+bandID, http.StatusSeeOther)
+}
@@ -0,0 +1,71 @@
+package api
+
+import (
+	"log"
+	"net/http"
+
+	"github.com/nahue/setlist_manager/internal/services"
+	"github.com/nahue/setlist_manager/internal/store"
+	"github.com/nahue/setlist_manager/templates"
+)
+
+// Handler handles AI usage requests
+type UsageHandler struct {
+	aiService *services.AIService
+	bandsDB   *store.SQLiteBandsStore
+}
+
+// NewUsageHandler creates a new AI usage handler
+func NewUsageHandler(aiService *services.AIService, bandsDB *store.SQLiteBandsStore) *UsageHandler {
+	return &UsageHandler{
+		aiService: aiService,
+		bandsDB:   bandsDB,
+	}
+}
+
+// ServeUsage handles GET /band/usage, the band's AI usage for the current month
+func (h *UsageHandler) ServeUsage(w http.ResponseWriter, r *http.Request) {
+	bandID := r.URL.Query().Get("id")
+	if bandID == "" {
+		http.Error(w, "Band ID is required", http.StatusBadRequest)
+		return
+	}
+
+	user := GetUserFromContext(r.Context())
+	if user == nil {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	// Check if user is a member of the band
+	member, err := h.bandsDB.GetBandMember(bandID, user.ID)
+	if err != nil {
+		log.Printf("Error checking band membership: %v", err)
+		http.Error(w, "Failed to check band membership", http.StatusInternalServerError)
+		return
+	}
+	if !services.Can(member, services.PermissionView) {
+		http.Error(w, "Access denied", http.StatusForbidden)
+		return
+	}
+
+	band, err := h.bandsDB.GetBandByIDShared(bandID)
+	if err != nil {
+		log.Printf("Error getting band: %v", err)
+		http.Error(w, "Failed to get band", http.StatusInternalServerError)
+		return
+	}
+	if band == nil {
+		http.Error(w, "Band not found", http.StatusNotFound)
+		return
+	}
+
+	report, err := h.aiService.AIUsageReport(bandID, user.ID)
+	if err != nil {
+		log.Printf("Error getting AI usage: %v", err)
+		http.Error(w, "Failed to get AI usage", http.StatusInternalServerError)
+		return
+	}
+
+	templates.UsagePage(band, user, report).Render(r.Context(), w)
+}
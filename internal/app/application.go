@@ -2,8 +2,10 @@ package app
 
 import (
 	"context"
+	"errors"
 	"log"
 	"net/http"
+	"strings"
 
 	"github.com/go-chi/chi/v5"
 	"github.com/go-chi/chi/v5/middleware"
@@ -16,12 +18,21 @@ import (
 
 // Application represents the main application
 type Application struct {
-	router        *chi.Mux
-	authService   *services.AuthService
-	authHandler   *api.AuthHandler
-	bandsHandler  *api.BandHandler
-	songsHandler  *api.SongHandler
-	healthHandler *api.HealthHandler
+	router            *chi.Mux
+	authService       *services.AuthService
+	apiTokenService   *services.APITokenService
+	authHandler       *api.AuthHandler
+	bandsHandler      *api.BandHandler
+	songsHandler      *api.SongHandler
+	setlistsHandler   *api.SetlistHandler
+	gigsHandler       *api.GigHandler
+	jobsHandler       *api.JobHandler
+	promptsHandler    *api.PromptHandler
+	usageHandler      *api.UsageHandler
+	trashHandler      *api.TrashHandler
+	invitationHandler *api.InvitationHandler
+	tokenHandler      *api.TokenHandler
+	healthHandler     *api.HealthHandler
 }
 
 // NewApplication creates a new application instance
@@ -30,29 +41,75 @@ func NewApplication(
 	authStore *store.SQLiteAuthStore,
 	bandsStore *store.SQLiteBandsStore,
 	songsStore *store.SQLiteSongsStore,
+	setlistsStore *store.SQLiteSetlistsStore,
+	gigsStore *store.SQLiteGigsStore,
+	jobsStore *store.SQLiteJobsStore,
+	promptsStore *store.SQLitePromptsStore,
+	aiUsageStore *store.SQLiteAIUsageStore,
 ) *Application {
 	// Initialize services
 	authService := services.NewAuthService(authStore)
+	apiTokenService := services.NewAPITokenService(authStore)
 	markdownService := services.NewMarkdownService()
-	aiService := services.NewAIService()
+	aiService := services.NewAIService(promptsStore, aiUsageStore)
 	pdfService := services.NewPDFService()
+	mailService := services.NewMailService()
+	gigService := services.NewGigService(gigsStore, setlistsStore)
+
+	// Initialize the job queue; jobs left by a previous run are picked up again
+	jobQueue := services.NewJobQueue(jobsStore, services.DefaultJobWorkers)
+	services.RegisterJobHandlers(jobQueue, bandsStore, songsStore, setlistsStore, gigsStore, gigService, aiService, pdfService)
+	if err := jobQueue.Start(); err != nil {
+		log.Printf("Error starting job queue: %v", err)
+	}
+
+	// Purge the trash on a schedule once items are past their retention
+	trashRetention, err := services.TrashRetentionFromEnv()
+	if err != nil {
+		log.Printf("Error reading trash retention, using the default: %v", err)
+	}
+	trashService := services.NewTrashService(songsStore, bandsStore, trashRetention)
+	trashService.Start()
+
+	// Mark band invitations as expired on a schedule
+	invitationService := services.NewInvitationService(bandsStore, mailService)
+	invitationService.Start()
+	archiveService := services.NewBandArchiveService(bandsStore, songsStore, setlistsStore, gigsStore, invitationService)
+	ownershipService := services.NewOwnershipService(bandsStore, mailService)
 
 	// Initialize handlers
-	authHandler := api.NewAuthHandler(authStore, bandsStore)
-	bandsHandler := api.NewBandHandler(bandsStore, songsStore, authService)
+	authHandler := api.NewAuthHandler(authStore, bandsStore, mailService)
+	bandsHandler := api.NewBandHandler(bandsStore, songsStore, setlistsStore, gigsStore, authService, archiveService, invitationService, ownershipService)
 	songsHandler := api.NewSongHandler(songsStore, bandsStore, authService, authStore, markdownService, aiService, pdfService)
+	setlistsHandler := api.NewSetlistHandler(setlistsStore, songsStore, bandsStore, pdfService)
+	gigsHandler := api.NewGigHandler(gigsStore, setlistsStore, bandsStore, gigService, pdfService)
+	jobsHandler := api.NewJobHandler(jobsStore, bandsStore, songsStore, setlistsStore, gigsStore, jobQueue)
+	promptsHandler := api.NewPromptHandler(promptsStore, bandsStore)
+	usageHandler := api.NewUsageHandler(aiService, bandsStore)
+	trashHandler := api.NewTrashHandler(songsStore, bandsStore, trashService)
+	invitationHandler := api.NewInvitationHandler(authService, invitationService, mailService)
+	tokenHandler := api.NewTokenHandler(apiTokenService)
 	healthHandler := api.NewHealthHandler(db)
 
 	// Initialize router
 	router := chi.NewRouter()
 
 	app := &Application{
-		router:        router,
-		authService:   authService,
-		authHandler:   authHandler,
-		bandsHandler:  bandsHandler,
-		songsHandler:  songsHandler,
-		healthHandler: healthHandler,
+		router:            router,
+		authService:       authService,
+		apiTokenService:   apiTokenService,
+		authHandler:       authHandler,
+		bandsHandler:      bandsHandler,
+		songsHandler:      songsHandler,
+		setlistsHandler:   setlistsHandler,
+		gigsHandler:       gigsHandler,
+		jobsHandler:       jobsHandler,
+		promptsHandler:    promptsHandler,
+		usageHandler:      usageHandler,
+		trashHandler:      trashHandler,
+		invitationHandler: invitationHandler,
+		tokenHandler:      tokenHandler,
+		healthHandler:     healthHandler,
 	}
 
 	app.setupMiddleware()
@@ -89,6 +146,12 @@ func (app *Application) setupRoutes() {
 	app.router.Post("/auth/logout", app.authHandler.HandleLogout)
 	app.router.Get("/auth/me", app.authHandler.HandleCurrentUser)
 
+	// Invitation page routes (public, the token in the link identifies the invitation)
+	app.router.Get("/invitation", app.invitationHandler.ServeInvitation)
+	app.router.Post("/invitation/sign-in", app.invitationHandler.SendSignInLink)
+	app.router.Post("/invitation/accept", app.invitationHandler.AcceptInvitation)
+	app.router.Post("/invitation/decline", app.invitationHandler.DeclineInvitation)
+
 	// Apply auth middleware to protected routes
 	app.router.Group(func(r chi.Router) {
 		r.Use(app.authMiddleware)
@@ -99,33 +162,118 @@ func (app *Application) setupRoutes() {
 		// Band routes
 		r.Get("/bands", app.bandsHandler.ServeBands)
 		r.Get("/bands/create", app.bandsHandler.ServeCreateBand)
+		r.Get("/bands/restore", app.bandsHandler.ServeRestoreBand)
 		r.Get("/band", app.bandsHandler.ServeBand)
 
 		// Song routes
 		r.Get("/song", app.songsHandler.ServeSongDetails)
 		r.Get("/song/edit", app.songsHandler.ServeEditSong)
+		r.Get("/band/import", app.songsHandler.ServeImportSongs)
 
 		// Band API routes
 		r.Get("/api/bands", app.bandsHandler.GetBands)
 		r.Post("/api/bands", app.bandsHandler.CreateBand)
 		r.Get("/api/bands/band", app.bandsHandler.GetBand)
+		r.Get("/api/bands/export", app.bandsHandler.ExportBand)
+		r.Post("/api/bands/restore", app.bandsHandler.RestoreBand)
 		r.Post("/api/bands/invite", app.bandsHandler.InviteMember)
 		r.Delete("/api/bands/members/remove", app.bandsHandler.RemoveMember)
+		r.Post("/api/bands/members/role", app.bandsHandler.UpdateMemberRole)
+
+		// Band settings routes
+		r.Get("/band/settings", app.bandsHandler.ServeBandSettings)
+		r.Post("/api/bands/update", app.bandsHandler.UpdateBand)
+		r.Post("/api/bands/delete", app.bandsHandler.DeleteBand)
+		r.Post("/api/bands/leave", app.bandsHandler.LeaveBand)
+		r.Post("/api/bands/transfer", app.bandsHandler.TransferOwnership)
+		r.Post("/api/bands/transfer/accept", app.bandsHandler.AcceptOwnershipTransfer)
+		r.Post("/api/bands/transfer/decline", app.bandsHandler.DeclineOwnershipTransfer)
+		r.Post("/api/bands/transfer/cancel", app.bandsHandler.CancelOwnershipTransfer)
 
 		// Song API routes
 		r.Get("/api/bands/songs", app.songsHandler.GetSongs)
 		r.Post("/api/bands/songs", app.songsHandler.CreateSong)
 		r.Post("/api/bands/songs/{songID}", app.songsHandler.EditSong)
 		r.Post("/api/bands/songs/reorder", app.songsHandler.ReorderSongs)
+		r.Post("/api/bands/songs/import-chordpro", app.songsHandler.ImportChordPro)
+		r.Post("/api/bands/songs/import/preview", app.songsHandler.PreviewSongImport)
+		r.Post("/api/bands/songs/import", app.songsHandler.ImportSongs)
 		r.Delete("/api/bands/songs/{songID}", app.songsHandler.DeleteSong)
 		r.Post("/api/songs/{songID}/generate-content", app.songsHandler.GenerateSongContent)
+		r.Post("/api/songs/{songID}/generate-content/stream", app.songsHandler.StreamSongContent)
 		r.Post("/api/songs/{songID}/update-content", app.songsHandler.UpdateSongContent)
 		r.Get("/api/songs/{songID}/export-pdf", app.songsHandler.ExportSongPDF)
+		r.Get("/api/songs/{songID}/export-chordpro", app.songsHandler.ExportSongChordPro)
+		r.Get("/api/songs/{songID}/transpose", app.songsHandler.TransposeSong)
+		r.Post("/api/songs/{songID}/transpose", app.songsHandler.SaveTransposedSong)
+		r.Get("/api/songs/{songID}/revisions/diff", app.songsHandler.DiffSongRevisions)
+		r.Post("/api/songs/{songID}/revisions/{revision}/restore", app.songsHandler.RestoreSongRevision)
+
+		// Setlist routes
+		r.Get("/setlist", app.setlistsHandler.ServeSetlist)
+		r.Get("/setlist/stage-sheet", app.setlistsHandler.ServeSetlistStageSheet)
+		r.Get("/api/bands/setlists", app.setlistsHandler.GetSetlists)
+		r.Post("/api/bands/setlists", app.setlistsHandler.CreateSetlist)
+		r.Post("/api/setlists/{setlistID}", app.setlistsHandler.EditSetlist)
+		r.Delete("/api/setlists/{setlistID}", app.setlistsHandler.DeleteSetlist)
+		r.Post("/api/setlists/{setlistID}/songs", app.setlistsHandler.AddSetlistSong)
+		r.Delete("/api/setlists/{setlistID}/songs/{songID}", app.setlistsHandler.RemoveSetlistSong)
+		r.Post("/api/setlists/{setlistID}/songs/{songID}/segue", app.setlistsHandler.SetSetlistSongSegue)
+		r.Post("/api/setlists/{setlistID}/reorder", app.setlistsHandler.ReorderSetlistSongs)
+		r.Get("/api/setlists/{setlistID}/export-pdf", app.setlistsHandler.ExportSetlistPDF)
+		r.Get("/api/setlists/{setlistID}/stage-sheet-pdf", app.setlistsHandler.ExportSetlistStageSheetPDF)
+
+		// Gig routes
+		r.Get("/gig", app.gigsHandler.ServeGig)
+		r.Get("/api/bands/gigs", app.gigsHandler.GetGigs)
+		r.Post("/api/bands/gigs", app.gigsHandler.CreateGig)
+		r.Post("/api/gigs/{gigID}", app.gigsHandler.EditGig)
+		r.Delete("/api/gigs/{gigID}", app.gigsHandler.DeleteGig)
+		r.Post("/api/gigs/{gigID}/sets", app.gigsHandler.AddGigSet)
+		r.Post("/api/gigs/{gigID}/sets/{setID}", app.gigsHandler.UpdateGigSet)
+		r.Delete("/api/gigs/{gigID}/sets/{setID}", app.gigsHandler.RemoveGigSet)
+		r.Post("/api/gigs/{gigID}/reorder", app.gigsHandler.ReorderGigSets)
+		r.Get("/api/gigs/{gigID}/export-pdf", app.gigsHandler.ExportGigPDF)
+
+		// Background job routes
+		r.Get("/band/jobs", app.jobsHandler.ServeJobs)
+		r.Get("/api/bands/jobs", app.jobsHandler.GetJobs)
+		r.Get("/api/jobs/{jobID}", app.jobsHandler.GetJob)
+		r.Post("/api/jobs/{jobID}/cancel", app.jobsHandler.CancelJob)
+		r.Get("/api/jobs/{jobID}/download", app.jobsHandler.DownloadJobResult)
+		r.Post("/api/bands/songs/generate-content", app.jobsHandler.QueueBandContent)
+		r.Post("/api/songs/{songID}/jobs/generate-content", app.jobsHandler.QueueSongContent)
+		r.Post("/api/songs/{songID}/jobs/export-pdf", app.jobsHandler.QueueSongPDF)
+		r.Post("/api/setlists/{setlistID}/jobs/export-pdf", app.jobsHandler.QueueSetlistPDF)
+		r.Post("/api/gigs/{gigID}/jobs/export-pdf", app.jobsHandler.QueueGigPDF)
+
+		// Prompt template routes
+		r.Get("/band/prompts", app.promptsHandler.ServePrompts)
+		r.Post("/api/bands/prompts", app.promptsHandler.CreatePromptTemplate)
+		r.Post("/api/prompts/{templateID}", app.promptsHandler.UpdatePromptTemplate)
+		r.Post("/api/prompts/{templateID}/default", app.promptsHandler.SetDefaultPromptTemplate)
+		r.Delete("/api/prompts/{templateID}", app.promptsHandler.DeletePromptTemplate)
+
+		// AI usage routes
+		r.Get("/band/usage", app.usageHandler.ServeUsage)
+
+		// Trash routes
+		r.Get("/band/trash", app.trashHandler.ServeBandTrash)
+		r.Get("/bands/trash", app.trashHandler.ServeBandsTrash)
+		r.Post("/api/songs/{songID}/undelete", app.trashHandler.UndeleteSong)
+		r.Post("/api/bands/undelete", app.trashHandler.UndeleteBand)
 
 		// Invitation routes
 		r.Get("/api/invitations", app.bandsHandler.GetInvitations)
 		r.Post("/api/invitations/accept", app.bandsHandler.AcceptInvitation)
 		r.Post("/api/invitations/decline", app.bandsHandler.DeclineInvitation)
+		r.Post("/api/invitations/{invitationID}/resend", app.bandsHandler.ResendInvitation)
+		r.Delete("/api/invitations/{invitationID}", app.bandsHandler.RevokeInvitation)
+
+		// API token routes
+		r.Get("/account/tokens", app.tokenHandler.ServeTokens)
+		r.Post("/account/tokens", app.tokenHandler.CreateToken)
+		r.Post("/account/tokens/revoke", app.tokenHandler.RevokeToken)
 	})
 }
 
@@ -135,9 +283,15 @@ func (app *Application) serveWelcome(w http.ResponseWriter, r *http.Request) {
 	http.Redirect(w, r, "/bands", http.StatusSeeOther)
 }
 
-// authMiddleware checks if the user is authenticated
+// authMiddleware checks if the user is authenticated, with the session cookie
+// or, for scripts calling the JSON API, a personal access token
 func (app *Application) authMiddleware(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if token, ok := bearerToken(r); ok {
+			app.apiTokenAuth(w, r, next, token)
+			return
+		}
+
 		// Get current user from session
 		user := app.authService.GetCurrentUser(r)
 		if user == nil {
@@ -151,6 +305,44 @@ func (app *Application) authMiddleware(next http.Handler) http.Handler {
 	})
 }
 
+// apiTokenAuth authenticates a request with a personal access token. Tokens
+// only reach the JSON API, within their scopes, and failures get a status
+// instead of the redirect to the login page.
+func (app *Application) apiTokenAuth(w http.ResponseWriter, r *http.Request, next http.Handler, token string) {
+	user, apiToken, err := app.apiTokenService.Authenticate(token)
+	if err != nil {
+		if !errors.Is(err, services.ErrInvalidAPIToken) {
+			log.Printf("Error checking API token: %v", err)
+		}
+		w.Header().Set("WWW-Authenticate", `Bearer error="invalid_token"`)
+		http.Error(w, "Invalid or expired token", http.StatusUnauthorized)
+		return
+	}
+	if !strings.HasPrefix(r.URL.Path, "/api/") {
+		http.Error(w, "API tokens can only be used with the JSON API", http.StatusForbidden)
+		return
+	}
+	if !services.APITokenAllows(apiToken, r.Method) {
+		w.Header().Set("WWW-Authenticate", `Bearer error="insufficient_scope"`)
+		http.Error(w, "Token scope does not allow this request", http.StatusForbidden)
+		return
+	}
+
+	// Store user in request context
+	ctx := context.WithValue(r.Context(), api.UserContextKey{}, user)
+	next.ServeHTTP(w, r.WithContext(ctx))
+}
+
+// bearerToken returns the token in the request's Authorization: Bearer header
+func bearerToken(r *http.Request) (string, bool) {
+	scheme, token, ok := strings.Cut(r.Header.Get("Authorization"), " ")
+	if !ok || !strings.EqualFold(scheme, "Bearer") {
+		return "", false
+	}
+	token = strings.TrimSpace(token)
+	return token, token != ""
+}
+
 // Start starts the HTTP server on the specified port
 func (app *Application) Start(port string) error {
 	log.Printf("Server starting on port %s", port)
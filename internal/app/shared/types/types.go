@@ -41,6 +41,7 @@ type Song struct {
 	Artist    string    `json:"artist"`
 	Key       string    `json:"key"`
 	Tempo     *int      `json:"tempo,omitempty"`
+	Duration  *int      `json:"duration_seconds,omitempty"`
 	Notes     string    `json:"notes"`
 	Position  int       `json:"position"`
 	CreatedBy string    `json:"created_by"`
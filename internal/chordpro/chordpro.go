@@ -0,0 +1,421 @@
+package chordpro
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/nahue/setlist_manager/internal/services"
+	"github.com/nahue/setlist_manager/internal/store"
+	"github.com/nahue/setlist_manager/internal/transpose"
+)
+
+// directivePattern matches a ChordPro directive such as "{title: Song}" or "{soc}"
+var directivePattern = regexp.MustCompile(`^\{\s*([A-Za-z_-]+)\s*(?::\s*(.*?))?\s*\}$`)
+
+// headingPattern matches a markdown heading and captures its level and text
+var headingPattern = regexp.MustCompile(`^(#{1,6})\s+(.*?)\s*#*$`)
+
+// listPattern matches a markdown list item prefix
+var listPattern = regexp.MustCompile(`^\s*(?:[-*+]|\d+\.)\s+`)
+
+// Sections that have start and end directives in ChordPro, keyed by the short and long
+// names of their start directive
+var sectionStarts = map[string]string{
+	"soc": "chorus", "start_of_chorus": "chorus",
+	"sov": "verse", "start_of_verse": "verse",
+	"sob": "bridge", "start_of_bridge": "bridge",
+}
+
+var sectionEnds = map[string]bool{
+	"eoc": true, "end_of_chorus": true,
+	"eov": true, "end_of_verse": true,
+	"eob": true, "end_of_bridge": true,
+}
+
+// Default heading used for each section when the directive has no label
+var sectionLabels = map[string]string{
+	"chorus": "Chorus",
+	"verse":  "Verse",
+	"bridge": "Bridge",
+}
+
+// Parse reads a ChordPro file and returns the song it describes. Metadata directives
+// fill the song's title, artist, key, tempo and duration; the body becomes markdown
+// content with sections as headings and inline "[C]" chords kept in place.
+func Parse(r io.Reader) (*store.Song, error) {
+	song := &store.Song{}
+	subtitle := ""
+
+	var content []string
+	inTab := false
+	first := true
+
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line := strings.TrimRight(scanner.Text(), " \t\r")
+		if first {
+			// Files saved on Windows may start with a byte order mark
+			line = strings.TrimPrefix(line, "\ufeff")
+			first = false
+		}
+
+		if inTab {
+			if name, _, ok := parseDirective(line); ok && (name == "eot" || name == "end_of_tab" || name == "eog" || name == "end_of_grid") {
+				content = append(content, "```", "")
+				inTab = false
+				continue
+			}
+			content = append(content, line)
+			continue
+		}
+
+		// Lines starting with # are ChordPro comments
+		if strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		name, value, ok := parseDirective(line)
+		if !ok {
+			if strings.TrimSpace(line) == "" {
+				content = appendBlank(content)
+				continue
+			}
+			// Two trailing spaces keep each lyric on its own line in markdown
+			content = append(content, line+"  ")
+			continue
+		}
+
+		switch name {
+		case "title", "t":
+			if song.Title == "" {
+				song.Title = value
+			}
+		case "subtitle", "st":
+			if subtitle == "" {
+				subtitle = value
+			}
+		case "artist":
+			if song.Artist == "" {
+				song.Artist = value
+			}
+		case "meta":
+			// {meta: artist Name} is an alternative form of the metadata directives
+			field, metaValue, _ := strings.Cut(value, " ")
+			switch strings.ToLower(field) {
+			case "title":
+				if song.Title == "" {
+					song.Title = strings.TrimSpace(metaValue)
+				}
+			case "artist":
+				if song.Artist == "" {
+					song.Artist = strings.TrimSpace(metaValue)
+				}
+			case "key":
+				song.Key = strings.TrimSpace(metaValue)
+			}
+		case "key":
+			song.Key = value
+		case "tempo":
+			if tempo, err := strconv.Atoi(strings.TrimSpace(value)); err == nil && tempo > 0 {
+				song.Tempo = &tempo
+			}
+		case "duration":
+			if duration, err := services.ParseSongDuration(value); err == nil {
+				song.Duration = &duration
+			}
+		case "capo":
+			content = appendBlank(content)
+			content = append(content, "*Capo: "+value+"*", "")
+		case "comment", "c", "comment_italic", "ci", "comment_box", "cb", "highlight":
+			content = appendBlank(content)
+			content = append(content, "*"+value+"*", "")
+		case "chorus":
+			// {chorus} recalls the chorus
+			label := value
+			if label == "" {
+				label = sectionLabels["chorus"]
+			}
+			content = appendBlank(content)
+			content = append(content, "*("+label+")*", "")
+		case "sot", "start_of_tab", "sog", "start_of_grid":
+			content = appendBlank(content)
+			content = append(content, "```")
+			inTab = true
+		default:
+			if section, ok := sectionStarts[name]; ok {
+				label := value
+				if label == "" {
+					label = sectionLabels[section]
+				}
+				content = appendBlank(content)
+				content = append(content, "## "+label, "")
+			} else if sectionEnds[name] {
+				content = appendBlank(content)
+			}
+			// Other directives only affect how a ChordPro viewer lays out the song
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read ChordPro file: %w", err)
+	}
+
+	if inTab {
+		content = append(content, "```")
+	}
+	if song.Artist == "" {
+		song.Artist = subtitle
+	}
+
+	song.Content = strings.TrimSpace(strings.Join(content, "\n"))
+	if song.Content != "" {
+		song.Content += "\n"
+	}
+
+	return song, nil
+}
+
+// Export renders a song as a ChordPro file. Markdown headings become sections or
+// comments, chord lines are merged into the lyric below them as inline chords and
+// fenced code blocks become tabs.
+func Export(song *store.Song) string {
+	var out []string
+
+	out = append(out, directive("title", song.Title))
+	if song.Artist != "" {
+		out = append(out, directive("artist", song.Artist))
+	}
+	if song.Key != "" {
+		out = append(out, directive("key", song.Key))
+	}
+	if song.Tempo != nil && *song.Tempo > 0 {
+		out = append(out, directive("tempo", strconv.Itoa(*song.Tempo)))
+	}
+	if song.Duration != nil && *song.Duration > 0 {
+		out = append(out, directive("duration", services.FormatSongDuration(*song.Duration)))
+	}
+	out = append(out, "")
+
+	section := ""
+	closeSection := func() {
+		if section == "" {
+			return
+		}
+		// End the section before the blank line that separates it from the next one
+		if len(out) > 0 && out[len(out)-1] == "" {
+			out = append(out[:len(out)-1], "{end_of_"+section+"}", "")
+		} else {
+			out = append(out, "{end_of_"+section+"}")
+		}
+		section = ""
+	}
+
+	lines := strings.Split(strings.ReplaceAll(song.Content, "\r\n", "\n"), "\n")
+	inFence := false
+	for i := 0; i < len(lines); i++ {
+		line := strings.TrimRight(lines[i], " \t\\")
+
+		if strings.HasPrefix(strings.TrimSpace(line), "```") {
+			if inFence {
+				out = append(out, "{end_of_tab}")
+			} else {
+				closeSection()
+				out = append(out, "{start_of_tab}")
+			}
+			inFence = !inFence
+			continue
+		}
+		if inFence {
+			out = append(out, line)
+			continue
+		}
+
+		trimmed := strings.TrimSpace(line)
+		switch {
+		case trimmed == "":
+			// Keep single blank lines between paragraphs, but not right after a section starts
+			if len(out) > 0 && out[len(out)-1] != "" && !strings.HasPrefix(out[len(out)-1], "{start_of_") {
+				out = append(out, "")
+			}
+		case trimmed == "---" || trimmed == "***" || trimmed == "___":
+			// Horizontal rules have no ChordPro equivalent
+		case strings.HasPrefix(trimmed, "{"):
+			// Already a ChordPro directive
+			out = append(out, trimmed)
+		case headingPattern.MatchString(trimmed):
+			match := headingPattern.FindStringSubmatch(trimmed)
+			text := stripEmphasis(match[2])
+			closeSection()
+			// The top-level heading usually repeats the title, which is already a directive
+			if len(match[1]) == 1 && strings.HasPrefix(strings.ToLower(text), strings.ToLower(song.Title)) {
+				continue
+			}
+			if kind := sectionKind(text); kind != "" {
+				section = kind
+				out = append(out, directive("start_of_"+kind, text))
+			} else {
+				out = append(out, directive("comment", text))
+			}
+		case transpose.IsChordLine(line):
+			if i+1 < len(lines) && isLyricLine(lines[i+1]) {
+				out = append(out, mergeChords(line, strings.TrimRight(lines[i+1], " \t\\")))
+				i++
+				continue
+			}
+			out = append(out, inlineChords(line))
+		case listPattern.MatchString(line) || strings.Contains(trimmed, "**") || isEmphasized(trimmed):
+			// Notes, labels and lists are shown as comments rather than lyrics
+			out = append(out, directive("comment", stripEmphasis(listPattern.ReplaceAllString(trimmed, "- "))))
+		default:
+			out = append(out, line)
+		}
+	}
+	if inFence {
+		out = append(out, "{end_of_tab}")
+	}
+	closeSection()
+
+	return strings.TrimRight(strings.Join(out, "\n"), "\n") + "\n"
+}
+
+// parseDirective splits a directive line into its lower-case name and value
+func parseDirective(line string) (string, string, bool) {
+	match := directivePattern.FindStringSubmatch(strings.TrimSpace(line))
+	if match == nil {
+		return "", "", false
+	}
+	return strings.ToLower(match[1]), strings.TrimSpace(match[2]), true
+}
+
+// directive formats a directive, omitting the value when it is empty
+func directive(name, value string) string {
+	if value == "" {
+		return "{" + name + "}"
+	}
+	return "{" + name + ": " + value + "}"
+}
+
+// appendBlank adds a blank line unless the content is empty or already ends with one
+func appendBlank(lines []string) []string {
+	if len(lines) == 0 || lines[len(lines)-1] == "" {
+		return lines
+	}
+	return append(lines, "")
+}
+
+// sectionKind maps a heading such as "Chorus", "Estribillo" or "Verse 2" to a ChordPro section
+func sectionKind(heading string) string {
+	lower := strings.ToLower(heading)
+	switch {
+	case strings.Contains(lower, "chorus") || strings.Contains(lower, "coro") || strings.Contains(lower, "estribillo"):
+		return "chorus"
+	case strings.Contains(lower, "verse") || strings.Contains(lower, "verso") || strings.Contains(lower, "estrofa"):
+		return "verse"
+	case strings.Contains(lower, "bridge") || strings.Contains(lower, "puente"):
+		return "bridge"
+	}
+	return ""
+}
+
+// isLyricLine reports whether a line can carry the chords from the line above it
+func isLyricLine(line string) bool {
+	trimmed := strings.TrimSpace(line)
+	if trimmed == "" || transpose.IsChordLine(line) {
+		return false
+	}
+	return !strings.HasPrefix(trimmed, "#") && !strings.HasPrefix(trimmed, "{") &&
+		!strings.HasPrefix(trimmed, "```") && !strings.Contains(trimmed, "**") &&
+		!listPattern.MatchString(line)
+}
+
+// isEmphasized reports whether a whole line is wrapped in markdown emphasis
+func isEmphasized(line string) bool {
+	return len(line) > 2 && (strings.HasPrefix(line, "*") && strings.HasSuffix(line, "*") ||
+		strings.HasPrefix(line, "_") && strings.HasSuffix(line, "_"))
+}
+
+// stripEmphasis removes markdown bold and italic markers
+func stripEmphasis(text string) string {
+	text = strings.ReplaceAll(text, "**", "")
+	text = strings.ReplaceAll(text, "__", "")
+	text = strings.ReplaceAll(text, "*", "")
+	if isEmphasized(text) {
+		text = text[1 : len(text)-1]
+	}
+	return strings.TrimSpace(text)
+}
+
+// chordTokens returns the chords of a chord line with the rune column each one starts at,
+// skipping separators such as "|" or "-"
+func chordTokens(line string) ([]string, []int) {
+	var chords []string
+	var columns []int
+
+	column := 0
+	token := []rune{}
+	start := 0
+	flush := func() {
+		if len(token) == 0 {
+			return
+		}
+		word := strings.Trim(string(token), "()*_,.;:")
+		if _, ok := transpose.ParseChord(word); ok {
+			chords = append(chords, word)
+			columns = append(columns, start)
+		}
+		token = token[:0]
+	}
+	for _, r := range line {
+		if r == ' ' || r == '\t' {
+			flush()
+		} else {
+			if len(token) == 0 {
+				start = column
+			}
+			token = append(token, r)
+		}
+		column++
+	}
+	flush()
+
+	return chords, columns
+}
+
+// inlineChords turns a chord line with no lyric below it into bracketed chords
+func inlineChords(line string) string {
+	chords, _ := chordTokens(line)
+	for i, chord := range chords {
+		chords[i] = "[" + chord + "]"
+	}
+	return strings.Join(chords, " ")
+}
+
+// mergeChords places the chords of a chord line into the lyric below it,
+// each at the column it sat above
+func mergeChords(chordLine, lyric string) string {
+	chords, columns := chordTokens(chordLine)
+	runes := []rune(lyric)
+
+	// Pad the lyric so that chords past its end still have somewhere to go
+	if len(columns) > 0 && columns[len(columns)-1] > len(runes) {
+		runes = append(runes, []rune(strings.Repeat(" ", columns[len(columns)-1]-len(runes)))...)
+	}
+
+	var out strings.Builder
+	next := 0
+	for i, r := range runes {
+		for next < len(chords) && columns[next] == i {
+			out.WriteString("[" + chords[next] + "]")
+			next++
+		}
+		out.WriteRune(r)
+	}
+	for ; next < len(chords); next++ {
+		out.WriteString("[" + chords[next] + "]")
+	}
+
+	return strings.TrimRight(out.String(), " ")
+}
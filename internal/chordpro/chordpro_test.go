@@ -0,0 +1,275 @@
+package chordpro
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/nahue/setlist_manager/internal/store"
+)
+
+// intPtr returns a pointer to the value, for optional song fields
+func intPtr(n int) *int {
+	return &n
+}
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		want  store.Song
+	}{
+		{
+			name: "metadata directives",
+			input: `{title: Wonderwall}
+{artist: Oasis}
+{key: F#m}
+{tempo: 87}
+{duration: 4:18}`,
+			want: store.Song{Title: "Wonderwall", Artist: "Oasis", Key: "F#m", Tempo: intPtr(87), Duration: intPtr(258)},
+		},
+		{
+			name: "short and meta forms",
+			input: `{t: Yesterday}
+{meta: artist The Beatles}
+{meta: key F}`,
+			want: store.Song{Title: "Yesterday", Artist: "The Beatles", Key: "F"},
+		},
+		{
+			name: "subtitle is the artist when there is none",
+			input: `{title: Hallelujah}
+{st: Leonard Cohen}`,
+			want: store.Song{Title: "Hallelujah", Artist: "Leonard Cohen"},
+		},
+		{
+			name: "the first title wins and invalid numbers are ignored",
+			input: `{title: First}
+{title: Second}
+{tempo: fast}
+{duration: long}`,
+			want: store.Song{Title: "First"},
+		},
+		{
+			name: "directive names are case insensitive",
+			input: `{TITLE: Loud}
+{Key: E}`,
+			want: store.Song{Title: "Loud", Key: "E"},
+		},
+		{
+			name: "inline chords stay in place",
+			input: `{title: Let It Be}
+When I [C]find myself in [G]times of trouble
+Mother [Am]Mary [F]comes to me`,
+			// Two trailing spaces keep each lyric on its own line in markdown
+			want: store.Song{Title: "Let It Be", Content: "When I [C]find myself in [G]times of trouble  \nMother [Am]Mary [F]comes to me\n"},
+		},
+		{
+			name: "chorus blocks become headings",
+			input: `{title: Song}
+{start_of_verse}
+[C]Verse line
+{end_of_verse}
+{soc: Final chorus}
+[G]Chorus line
+{eoc}
+{chorus}`,
+			want: store.Song{Title: "Song", Content: "## Verse\n\n[C]Verse line  \n\n## Final chorus\n\n[G]Chorus line  \n\n*(Chorus)*\n"},
+		},
+		{
+			name: "comments, capo and tabs",
+			input: `# a ChordPro comment
+{title: Song}
+{capo: 2}
+{c: Slowly}
+{sot}
+e|--0--|
+{eot}
+{new_page}`,
+			want: store.Song{Title: "Song", Content: "*Capo: 2*\n\n*Slowly*\n\n```\ne|--0--|\n```\n"},
+		},
+		{
+			name:  "byte order mark and Windows line endings",
+			input: "\ufeff{title: Song}\r\n[Am]Line\r\n",
+			want:  store.Song{Title: "Song", Content: "[Am]Line\n"},
+		},
+		{
+			name:  "unterminated tab",
+			input: "{sot}\ne|--0--|",
+			want:  store.Song{Content: "```\ne|--0--|\n```\n"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Parse(strings.NewReader(tt.input))
+			if err != nil {
+				t.Fatalf("Parse() error = %v", err)
+			}
+			if got.Title != tt.want.Title || got.Artist != tt.want.Artist || got.Key != tt.want.Key {
+				t.Errorf("metadata = %q/%q/%q, want %q/%q/%q", got.Title, got.Artist, got.Key, tt.want.Title, tt.want.Artist, tt.want.Key)
+			}
+			if !equalIntPtr(got.Tempo, tt.want.Tempo) || !equalIntPtr(got.Duration, tt.want.Duration) {
+				t.Errorf("tempo/duration = %v/%v, want %v/%v", deref(got.Tempo), deref(got.Duration), deref(tt.want.Tempo), deref(tt.want.Duration))
+			}
+			if got.Content != tt.want.Content {
+				t.Errorf("Content =\n%q\nwant\n%q", got.Content, tt.want.Content)
+			}
+		})
+	}
+}
+
+func TestExport(t *testing.T) {
+	tests := []struct {
+		name string
+		song store.Song
+		want string
+	}{
+		{
+			name: "metadata only",
+			song: store.Song{Title: "Wonderwall", Artist: "Oasis", Key: "F#m", Tempo: intPtr(87), Duration: intPtr(258)},
+			want: "{title: Wonderwall}\n{artist: Oasis}\n{key: F#m}\n{tempo: 87}\n{duration: 4:18}\n",
+		},
+		{
+			name: "chord lines merge into the lyric below",
+			song: store.Song{Title: "Let It Be", Content: "C          G\nWhen I find myself"},
+			want: "{title: Let It Be}\n\n[C]When I find[G] myself\n",
+		},
+		{
+			name: "chords past the end of the lyric",
+			song: store.Song{Title: "Song", Content: "C      G\nHey"},
+			want: "{title: Song}\n\n[C]Hey    [G]\n",
+		},
+		{
+			name: "chord line without a lyric",
+			song: store.Song{Title: "Song", Content: "| C | G | Am |"},
+			want: "{title: Song}\n\n[C] [G] [Am]\n",
+		},
+		{
+			name: "sections and comments",
+			song: store.Song{Title: "Song", Content: `# Song
+
+## Verse 1
+Line one
+
+## **Chorus**
+Line two
+
+## Solo
+**Tempo:** 120
+- Watch the drummer
+*Softly*`},
+			want: `{title: Song}
+
+{start_of_verse: Verse 1}
+Line one
+{end_of_verse}
+
+{start_of_chorus: Chorus}
+Line two
+{end_of_chorus}
+
+{comment: Solo}
+{comment: Tempo: 120}
+{comment: - Watch the drummer}
+{comment: Softly}
+`,
+		},
+		{
+			name: "tabs and rules",
+			song: store.Song{Title: "Song", Content: "---\n```\ne|--0--|\n```"},
+			want: "{title: Song}\n\n{start_of_tab}\ne|--0--|\n{end_of_tab}\n",
+		},
+		{
+			name: "directives pass through",
+			song: store.Song{Title: "Song", Content: "{capo: 2}\nLine"},
+			want: "{title: Song}\n\n{capo: 2}\nLine\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Export(&tt.song); got != tt.want {
+				t.Errorf("Export() =\n%s\nwant\n%s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRoundTrip(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+	}{
+		{
+			name: "metadata and inline chords",
+			input: `{title: Let It Be}
+{artist: The Beatles}
+{key: C}
+{tempo: 72}
+{duration: 4:03}
+
+When I [C]find myself in [G]times of trouble
+`,
+		},
+		{
+			name: "sections",
+			input: `{title: Song}
+
+{start_of_verse: Verse 1}
+[Am]First verse
+{end_of_verse}
+
+{start_of_chorus: Chorus}
+[F]Sing it [G]loud
+{end_of_chorus}
+`,
+		},
+		{
+			name: "tabs",
+			input: `{title: Song}
+
+{start_of_tab}
+e|--0--3--|
+{end_of_tab}
+`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			song, err := Parse(strings.NewReader(tt.input))
+			if err != nil {
+				t.Fatalf("Parse() error = %v", err)
+			}
+			exported := Export(song)
+			if exported != tt.input {
+				t.Errorf("Export(Parse()) =\n%s\nwant\n%s", exported, tt.input)
+			}
+
+			again, err := Parse(strings.NewReader(exported))
+			if err != nil {
+				t.Fatalf("Parse() of the export error = %v", err)
+			}
+			if again.Title != song.Title || again.Artist != song.Artist || again.Key != song.Key ||
+				!equalIntPtr(again.Tempo, song.Tempo) || !equalIntPtr(again.Duration, song.Duration) ||
+				again.Content != song.Content {
+				t.Errorf("song changed in the round trip: %+v, want %+v", again, song)
+			}
+		})
+	}
+}
+
+// equalIntPtr reports whether two optional numbers are both unset or equal
+func equalIntPtr(a, b *int) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return *a == *b
+}
+
+// deref returns an optional number, or 0 when it is unset
+func deref(n *int) int {
+	if n == nil {
+		return 0
+	}
+	return *n
+}
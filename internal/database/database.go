@@ -21,8 +21,9 @@ func NewDatabase() (*Database, error) {
 		return nil, fmt.Errorf("failed to create data directory: %w", err)
 	}
 
-	// Open SQLite database
-	db, err := sql.Open("sqlite3", "./data/setlist_manager.db")
+	// Open SQLite database, with foreign keys enforced on every pooled connection
+	// so deleting a band or song cascades to everything in it
+	db, err := sql.Open("sqlite3", "./data/setlist_manager.db?_foreign_keys=on")
 	if err != nil {
 		return nil, fmt.Errorf("failed to open database: %w", err)
 	}
@@ -32,11 +33,6 @@ func NewDatabase() (*Database, error) {
 		return nil, fmt.Errorf("failed to ping database: %w", err)
 	}
 
-	// Enable foreign keys
-	if _, err := db.Exec("PRAGMA foreign_keys = ON"); err != nil {
-		return nil, fmt.Errorf("failed to enable foreign keys: %w", err)
-	}
-
 	log.Println("Database connected successfully")
 	return &Database{db: db}, nil
 }
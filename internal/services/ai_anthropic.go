@@ -0,0 +1,234 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+)
+
+// anthropicVersion is the Messages API version sent with every request
+const anthropicVersion = "2023-06-01"
+
+// anthropicJSONPrefill starts the reply when JSON is requested. The Messages API
+// has no JSON mode, so the reply is begun as a JSON object for the model to finish.
+const anthropicJSONPrefill = "{"
+
+// AnthropicProvider talks to the Anthropic Messages API or a server that implements it
+type AnthropicProvider struct {
+	config       AIConfig
+	client       *http.Client
+	streamClient *http.Client
+}
+
+// NewAnthropicProvider creates a provider for an Anthropic-style Messages API
+func NewAnthropicProvider(cfg AIConfig) *AnthropicProvider {
+	cfg = cfg.withDefaults("https://api.anthropic.com", "claude-3-5-sonnet-latest")
+	return &AnthropicProvider{
+		config: cfg,
+		client: &http.Client{
+			Timeout: cfg.Timeout,
+		},
+		streamClient: newStreamingClient(cfg.Timeout),
+	}
+}
+
+// anthropicRequest is the body of a Messages API request
+type anthropicRequest struct {
+	Model       string          `json:"model"`
+	System      string          `json:"system,omitempty"`
+	Messages    []openAIMessage `json:"messages"`
+	Temperature float64         `json:"temperature"`
+	MaxTokens   int             `json:"max_tokens"`
+	Stream      bool            `json:"stream,omitempty"`
+}
+
+// anthropicContentBlock is one block of a Messages API reply
+type anthropicContentBlock struct {
+	Type string `json:"type"`
+	Text string `json:"text"`
+}
+
+// anthropicUsage is the token usage reported for a Messages API reply
+type anthropicUsage struct {
+	InputTokens  int `json:"input_tokens"`
+	OutputTokens int `json:"output_tokens"`
+}
+
+// anthropicResponse is the body of a Messages API response
+type anthropicResponse struct {
+	Model   string                  `json:"model"`
+	Content []anthropicContentBlock `json:"content"`
+	Usage   anthropicUsage          `json:"usage"`
+}
+
+// anthropicStreamEvent is the data of one event of a streamed Messages API response
+type anthropicStreamEvent struct {
+	Type    string                 `json:"type"`
+	Message *anthropicResponse     `json:"message,omitempty"`
+	Delta   *anthropicContentBlock `json:"delta,omitempty"`
+	Usage   *anthropicUsage        `json:"usage,omitempty"`
+	Error   *struct {
+		Message string `json:"message"`
+	} `json:"error,omitempty"`
+}
+
+// Name returns the provider and model
+func (p *AnthropicProvider) Name() string {
+	return "anthropic:" + p.config.Model
+}
+
+// newRequest builds the HTTP request for a Messages API call
+func (p *AnthropicProvider) newRequest(ctx context.Context, req *CompletionRequest, stream bool) (*http.Request, error) {
+	body := anthropicRequest{
+		Model:       p.config.Model,
+		System:      req.System,
+		Messages:    []openAIMessage{{Role: "user", Content: req.Prompt}},
+		Temperature: *p.config.Temperature,
+		MaxTokens:   p.config.MaxTokens,
+		Stream:      stream,
+	}
+	if req.MaxTokens > 0 {
+		body.MaxTokens = req.MaxTokens
+	}
+	if req.JSON {
+		body.Messages = append(body.Messages, openAIMessage{Role: "assistant", Content: anthropicJSONPrefill})
+	}
+
+	jsonData, err := json.Marshal(body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal Anthropic request: %w", err)
+	}
+
+	httpReq, err := http.NewRequestWithContext(ctx, "POST", p.config.BaseURL+"/v1/messages", bytes.NewBuffer(jsonData))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create HTTP request: %w", err)
+	}
+
+	httpReq.Header.Set("Content-Type", "application/json")
+	httpReq.Header.Set("x-api-key", p.config.APIKey)
+	httpReq.Header.Set("anthropic-version", anthropicVersion)
+	return httpReq, nil
+}
+
+// Complete sends the prompt as a single user message
+func (p *AnthropicProvider) Complete(ctx context.Context, req *CompletionRequest) (*CompletionResponse, error) {
+	httpReq, err := p.newRequest(ctx, req, false)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := p.client.Do(httpReq)
+	if err != nil {
+		return nil, fmt.Errorf("failed to make Anthropic request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	respBody, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read response body: %w", err)
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("Anthropic API error: %s - %s", resp.Status, string(respBody))
+	}
+
+	var anthropicResp anthropicResponse
+	if err := json.Unmarshal(respBody, &anthropicResp); err != nil {
+		return nil, fmt.Errorf("failed to parse Anthropic response: %w", err)
+	}
+
+	var content strings.Builder
+	for _, block := range anthropicResp.Content {
+		if block.Type == "text" {
+			content.WriteString(block.Text)
+		}
+	}
+	if content.Len() == 0 {
+		return nil, fmt.Errorf("no text in Anthropic response")
+	}
+
+	model := anthropicResp.Model
+	if model == "" {
+		model = p.config.Model
+	}
+	text := content.String()
+	if req.JSON {
+		text = anthropicJSONPrefill + text
+	}
+	return &CompletionResponse{
+		Content:      text,
+		Model:        model,
+		InputTokens:  anthropicResp.Usage.InputTokens,
+		OutputTokens: anthropicResp.Usage.OutputTokens,
+	}, nil
+}
+
+// Stream sends the prompt as a single user message and streams the reply
+func (p *AnthropicProvider) Stream(ctx context.Context, req *CompletionRequest, onDelta func(delta string) error) (*CompletionResponse, error) {
+	httpReq, err := p.newRequest(ctx, req, true)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := p.streamClient.Do(httpReq)
+	if err != nil {
+		return nil, fmt.Errorf("failed to make Anthropic request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		respBody, _ := io.ReadAll(resp.Body)
+		return nil, fmt.Errorf("Anthropic API error: %s - %s", resp.Status, string(respBody))
+	}
+
+	out := &CompletionResponse{Model: p.config.Model}
+	var content strings.Builder
+	if req.JSON {
+		content.WriteString(anthropicJSONPrefill)
+		if err := onDelta(anthropicJSONPrefill); err != nil {
+			return nil, err
+		}
+	}
+	err = readServerSentEvents(resp.Body, func(event, data string) error {
+		var streamEvent anthropicStreamEvent
+		if err := json.Unmarshal([]byte(data), &streamEvent); err != nil {
+			return fmt.Errorf("failed to parse Anthropic stream event: %w", err)
+		}
+		switch streamEvent.Type {
+		case "message_start":
+			if streamEvent.Message != nil {
+				if streamEvent.Message.Model != "" {
+					out.Model = streamEvent.Message.Model
+				}
+				out.InputTokens = streamEvent.Message.Usage.InputTokens
+			}
+		case "content_block_delta":
+			if streamEvent.Delta != nil && streamEvent.Delta.Text != "" {
+				content.WriteString(streamEvent.Delta.Text)
+				return onDelta(streamEvent.Delta.Text)
+			}
+		case "message_delta":
+			if streamEvent.Usage != nil {
+				out.OutputTokens = streamEvent.Usage.OutputTokens
+			}
+		case "error":
+			if streamEvent.Error != nil {
+				return fmt.Errorf("Anthropic API error: %s", streamEvent.Error.Message)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	if content.Len() == 0 || content.String() == anthropicJSONPrefill {
+		return nil, fmt.Errorf("no text in Anthropic stream")
+	}
+
+	out.Content = content.String()
+	return out, nil
+}
@@ -0,0 +1,284 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+)
+
+// newStandInAnthropicProvider creates an Anthropic client talking to a stand-in
+// server backed by the fake provider
+func newStandInAnthropicProvider(t *testing.T) (*AnthropicProvider, *FakeProvider) {
+	fake := NewFakeProvider()
+	server := newAIStandInServer(t, fake)
+	return NewAnthropicProvider(AIConfig{BaseURL: server.URL, APIKey: "test-key"}), fake
+}
+
+func TestAnthropicProviderComplete(t *testing.T) {
+	tests := []struct {
+		name          string
+		req           *CompletionRequest
+		wantContent   string
+		wantMaxTokens int
+	}{
+		{
+			name:          "plain",
+			req:           &CompletionRequest{System: "You are a musician", Prompt: "Wonderwall\nby Oasis"},
+			wantContent:   fakeReply("Wonderwall\nby Oasis"),
+			wantMaxTokens: 4096,
+		},
+		{
+			// The prefilled brace is part of the reply once, not twice
+			name:          "json mode",
+			req:           &CompletionRequest{System: "Reply in JSON", Prompt: "Yesterday", JSON: true},
+			wantContent:   fakeSectionsReply("Yesterday"),
+			wantMaxTokens: 4096,
+		},
+		{
+			name:          "max tokens override",
+			req:           &CompletionRequest{Prompt: "Let It Be", MaxTokens: 200},
+			wantContent:   fakeReply("Let It Be"),
+			wantMaxTokens: 200,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			provider, fake := newStandInAnthropicProvider(t)
+
+			resp, err := provider.Complete(context.Background(), tt.req)
+			if err != nil {
+				t.Fatalf("Complete() error = %v", err)
+			}
+			if resp.Content != tt.wantContent {
+				t.Errorf("Content = %q, want %q", resp.Content, tt.wantContent)
+			}
+			if resp.Model != "fake" {
+				t.Errorf("Model = %q, want %q", resp.Model, "fake")
+			}
+			if resp.InputTokens == 0 || resp.OutputTokens == 0 {
+				t.Errorf("tokens = %d/%d, want both reported", resp.InputTokens, resp.OutputTokens)
+			}
+
+			requests := fake.Requests()
+			if len(requests) != 1 {
+				t.Fatalf("server received %d requests, want 1", len(requests))
+			}
+			got := requests[0]
+			if got.System != tt.req.System || got.Prompt != tt.req.Prompt || got.JSON != tt.req.JSON {
+				t.Errorf("server received %+v, want %+v", got, tt.req)
+			}
+			if got.MaxTokens != tt.wantMaxTokens {
+				t.Errorf("server received max tokens %d, want %d", got.MaxTokens, tt.wantMaxTokens)
+			}
+			if tt.req.JSON {
+				if _, err := ParseAIGenerationResponse(resp.Content); err != nil {
+					t.Errorf("JSON reply doesn't parse: %v", err)
+				}
+			}
+		})
+	}
+}
+
+func TestAnthropicProviderStream(t *testing.T) {
+	tests := []struct {
+		name        string
+		req         *CompletionRequest
+		wantContent string
+		wantFirst   string
+	}{
+		{
+			name:        "plain",
+			req:         &CompletionRequest{Prompt: "Wonderwall"},
+			wantContent: fakeReply("Wonderwall"),
+			wantFirst:   "**Duration:** ",
+		},
+		{
+			// The prefilled brace is sent first, before the model's reply arrives
+			name:        "json mode",
+			req:         &CompletionRequest{Prompt: "Yesterday", JSON: true},
+			wantContent: fakeSectionsReply("Yesterday"),
+			wantFirst:   anthropicJSONPrefill,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			provider, fake := newStandInAnthropicProvider(t)
+
+			var deltas []string
+			resp, err := provider.Stream(context.Background(), tt.req, collectDeltas(&deltas))
+			if err != nil {
+				t.Fatalf("Stream() error = %v", err)
+			}
+			if len(deltas) < 2 {
+				t.Fatalf("got %d deltas, want the reply in pieces", len(deltas))
+			}
+			if !strings.HasPrefix(deltas[0], tt.wantFirst) {
+				t.Errorf("first delta = %q, want it to start with %q", deltas[0], tt.wantFirst)
+			}
+			if joined := strings.Join(deltas, ""); joined != tt.wantContent {
+				t.Errorf("deltas = %q, want %q", joined, tt.wantContent)
+			}
+			if resp.Content != tt.wantContent {
+				t.Errorf("Content = %q, want %q", resp.Content, tt.wantContent)
+			}
+			if resp.Model != "fake" || resp.OutputTokens == 0 {
+				t.Errorf("usage = %s %d, want the stand-in's", resp.Model, resp.OutputTokens)
+			}
+			if requests := fake.Requests(); len(requests) != 1 || requests[0].JSON != tt.req.JSON {
+				t.Errorf("server received %+v, want one request with JSON %v", requests, tt.req.JSON)
+			}
+		})
+	}
+}
+
+func TestAnthropicProviderStreamAbort(t *testing.T) {
+	provider, _ := newStandInAnthropicProvider(t)
+
+	errStop := errors.New("stop")
+	deltas := 0
+	_, err := provider.Stream(context.Background(), &CompletionRequest{Prompt: "Yesterday", JSON: true}, func(delta string) error {
+		deltas++
+		return errStop
+	})
+	if !errors.Is(err, errStop) {
+		t.Errorf("Stream() error = %v, want %v", err, errStop)
+	}
+	if deltas != 1 {
+		t.Errorf("got %d deltas, want the stream to stop after the first", deltas)
+	}
+}
+
+func TestAnthropicProviderRequestHeaders(t *testing.T) {
+	var got http.Header
+	server := newAIServer(t, func(w http.ResponseWriter, r *http.Request) {
+		got = r.Header.Clone()
+		fmt.Fprint(w, `{"model":"claude-test","content":[{"type":"text","text":"hello"}]}`)
+	})
+	provider := NewAnthropicProvider(AIConfig{BaseURL: server.URL, APIKey: "test-key"})
+
+	if _, err := provider.Complete(context.Background(), &CompletionRequest{Prompt: "Wonderwall"}); err != nil {
+		t.Fatalf("Complete() error = %v", err)
+	}
+	if got.Get("x-api-key") != "test-key" {
+		t.Errorf("x-api-key = %q, want %q", got.Get("x-api-key"), "test-key")
+	}
+	if got.Get("anthropic-version") != anthropicVersion {
+		t.Errorf("anthropic-version = %q, want %q", got.Get("anthropic-version"), anthropicVersion)
+	}
+}
+
+func TestAnthropicProviderErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		handler http.HandlerFunc
+		stream  bool
+		wantErr string
+	}{
+		{
+			name:    "provider error",
+			handler: aiStandInHandler(failingProvider{err: errors.New("model overloaded")}),
+			wantErr: "Anthropic API error: 500 Internal Server Error - model overloaded",
+		},
+		{
+			name:    "provider error while streaming",
+			handler: aiStandInHandler(failingProvider{err: errors.New("model overloaded")}),
+			stream:  true,
+			wantErr: "no text in Anthropic stream",
+		},
+		{
+			name: "overloaded",
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				http.Error(w, "overloaded", http.StatusServiceUnavailable)
+			},
+			wantErr: "Anthropic API error: 503 Service Unavailable - overloaded",
+		},
+		{
+			name: "overloaded while streaming",
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				http.Error(w, "overloaded", http.StatusServiceUnavailable)
+			},
+			stream:  true,
+			wantErr: "Anthropic API error: 503 Service Unavailable - overloaded",
+		},
+		{
+			name: "error event",
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				stream := NewEventStream(w)
+				stream.Send("content_block_delta", anthropicStreamEvent{Type: "content_block_delta", Delta: &anthropicContentBlock{Type: "text_delta", Text: "Verse"}})
+				stream.SendData("error", `{"type":"error","error":{"type":"overloaded_error","message":"Overloaded"}}`)
+			},
+			stream:  true,
+			wantErr: "Anthropic API error: Overloaded",
+		},
+		{
+			name: "no text",
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				fmt.Fprint(w, `{"model":"claude-test","content":[{"type":"tool_use"}]}`)
+			},
+			wantErr: "no text in Anthropic response",
+		},
+		{
+			name: "invalid body",
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				fmt.Fprint(w, `<html>`)
+			},
+			wantErr: "failed to parse Anthropic response",
+		},
+		{
+			name: "invalid stream event",
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				NewEventStream(w).SendData("message_start", "{")
+			},
+			stream:  true,
+			wantErr: "failed to parse Anthropic stream event",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := newAIServer(t, tt.handler)
+			provider := NewAnthropicProvider(AIConfig{BaseURL: server.URL, APIKey: "test-key"})
+
+			req := &CompletionRequest{Prompt: "Wonderwall"}
+			var err error
+			if tt.stream {
+				_, err = provider.Stream(context.Background(), req, func(string) error { return nil })
+			} else {
+				_, err = provider.Complete(context.Background(), req)
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("error = %v, want one containing %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestAnthropicProviderTimeout(t *testing.T) {
+	for _, stream := range []bool{false, true} {
+		t.Run(fmt.Sprintf("stream=%v", stream), func(t *testing.T) {
+			server := newAIServer(t, hangingHandler)
+			provider := NewAnthropicProvider(AIConfig{BaseURL: server.URL, APIKey: "test-key", Timeout: 50 * time.Millisecond})
+
+			start := time.Now()
+			req := &CompletionRequest{Prompt: "Wonderwall"}
+			var err error
+			if stream {
+				_, err = provider.Stream(context.Background(), req, func(string) error { return nil })
+			} else {
+				_, err = provider.Complete(context.Background(), req)
+			}
+			if err == nil || !strings.Contains(err.Error(), "failed to make Anthropic request") {
+				t.Errorf("error = %v, want the request to time out", err)
+			}
+			if elapsed := time.Since(start); elapsed > 2*time.Second {
+				t.Errorf("gave up after %v, want about the 50ms timeout", elapsed)
+			}
+		})
+	}
+}
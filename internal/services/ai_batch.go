@@ -0,0 +1,274 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/nahue/setlist_manager/internal/store"
+)
+
+// Defaults for batch generation
+const (
+	DefaultAIBatchConcurrency = 3
+	DefaultAIBandRateLimit    = 20 // requests per minute
+)
+
+// Outcomes of a song in a batch generation
+const (
+	BatchSongGenerated = "generated"
+	// BatchSongEdited is a song whose content was written or edited by hand, kept
+	// because the batch wasn't asked to overwrite it
+	BatchSongEdited = "edited"
+	// BatchSongChanged is a song whose content changed while its new content was
+	// being generated, kept so the change isn't lost
+	BatchSongChanged  = "changed"
+	BatchSongNotFound = "not_found"
+	BatchSongFailed   = "failed"
+)
+
+// BatchSongResult is the outcome of generating one song's content in a batch
+type BatchSongResult struct {
+	SongID string `json:"song_id"`
+	Title  string `json:"title"`
+	Status string `json:"status"`
+	Error  string `json:"error,omitempty"`
+}
+
+// BatchContentResult reports the outcome of a batch generation, song by song
+type BatchContentResult struct {
+	Generated int                `json:"generated"`
+	Skipped   int                `json:"skipped"`
+	Failed    int                `json:"failed"`
+	Songs     []*BatchSongResult `json:"songs"`
+}
+
+// setBatchLimits sets the concurrency and per-band rate limit of batch
+// generation, using the defaults for values below 1
+func (s *AIService) setBatchLimits(concurrency, perMinute int) {
+	if concurrency < 1 {
+		concurrency = DefaultAIBatchConcurrency
+	}
+	if perMinute < 1 {
+		perMinute = DefaultAIBandRateLimit
+	}
+	s.batchConcurrency = concurrency
+	s.bandLimiter = newBandRateLimiter(time.Minute / time.Duration(perMinute))
+}
+
+// GenerateBandContent generates the content of a band's songs for a role: the
+// given songs, or every song without content when none are given. Main content
+// that was written or edited by hand is only replaced when overwrite is set;
+// content generated earlier and left untouched is regenerated. Songs are
+// generated a few at a time and the band's requests to the provider are rate
+// limited.
+//
+// A song failing doesn't stop the others; the result reports each song. An error
+// is returned when the batch can't run, such as when the band or the user who
+// asked for it is out of AI quota, or when every song it tried failed.
+func (s *AIService) GenerateBandContent(ctx context.Context, songsDB *store.SQLiteSongsStore, bandID, userID, role string, songIDs []string, overwrite bool) (*BatchContentResult, error) {
+	if s.provider != nil {
+		if err := s.CheckAIQuota(bandID, userID); err != nil {
+			return nil, err
+		}
+	}
+
+	songs, err := songsDB.GetSongsByBand(bandID)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &BatchContentResult{}
+	var targets []*store.Song
+	if len(songIDs) == 0 {
+		for _, song := range songs {
+			missing, err := songMissingContent(songsDB, song, role)
+			if err != nil {
+				return nil, err
+			}
+			if missing {
+				targets = append(targets, song)
+			}
+		}
+	} else {
+		byID := make(map[string]*store.Song, len(songs))
+		for _, song := range songs {
+			byID[song.ID] = song
+		}
+		for _, songID := range songIDs {
+			if song, ok := byID[songID]; ok {
+				targets = append(targets, song)
+			} else {
+				result.Songs = append(result.Songs, &BatchSongResult{SongID: songID, Status: BatchSongNotFound})
+			}
+		}
+	}
+
+	outcomes := make([]*BatchSongResult, len(targets))
+	next := make(chan int)
+	var wg sync.WaitGroup
+	for i := 0; i < min(s.batchConcurrency, len(targets)); i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for index := range next {
+				outcomes[index] = s.generateBatchSong(ctx, songsDB, targets[index], userID, role, overwrite)
+			}
+		}()
+	}
+	for index := range targets {
+		next <- index
+	}
+	close(next)
+	wg.Wait()
+
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	result.Songs = append(result.Songs, outcomes...)
+	var firstErr string
+	for _, outcome := range result.Songs {
+		switch outcome.Status {
+		case BatchSongGenerated:
+			result.Generated++
+		case BatchSongFailed:
+			result.Failed++
+			if firstErr == "" {
+				firstErr = outcome.Error
+			}
+		default:
+			result.Skipped++
+		}
+	}
+
+	// Fail the job when nothing went through, e.g. the provider is down, so it is retried
+	if result.Failed > 0 && result.Generated == 0 {
+		return nil, fmt.Errorf("all %d songs failed: %s", result.Failed, firstErr)
+	}
+	return result, nil
+}
+
+// songMissingContent reports whether a song has no content for a role yet
+func songMissingContent(songsDB *store.SQLiteSongsStore, song *store.Song, role string) (bool, error) {
+	if role == PromptRoleMain {
+		return song.Content == "", nil
+	}
+	variant, err := songsDB.GetSongVariant(song.ID, role)
+	if err != nil {
+		return false, err
+	}
+	return variant == nil, nil
+}
+
+// generateBatchSong generates and saves the content of one song of a batch.
+// Variants are only ever generated, so they are replaced without checks.
+func (s *AIService) generateBatchSong(ctx context.Context, songsDB *store.SQLiteSongsStore, song *store.Song, userID, role string, overwrite bool) *BatchSongResult {
+	result := &BatchSongResult{SongID: song.ID, Title: song.Title}
+	failed := func(err error) *BatchSongResult {
+		result.Status = BatchSongFailed
+		result.Error = err.Error()
+		return result
+	}
+
+	// Only content that was last changed by the AI counts as untouched; a hand
+	// edit, an import or a restored revision is kept
+	if role == PromptRoleMain && song.Content != "" && !overwrite {
+		source, err := songsDB.GetSongContentSource(song.ID)
+		if err != nil {
+			return failed(err)
+		}
+		if source != store.SongRevisionAI {
+			result.Status = BatchSongEdited
+			return result
+		}
+	}
+
+	if s.provider != nil {
+		if err := s.bandLimiter.Wait(ctx, song.BandID); err != nil {
+			return failed(err)
+		}
+	}
+	req, err := s.SongContentRequest(song, role, userID)
+	if err != nil {
+		return failed(err)
+	}
+	resp, err := s.GenerateSongContent(ctx, req)
+	if err != nil {
+		return failed(err)
+	}
+
+	// Don't overwrite an edit made while the content was being generated
+	current, err := songsDB.GetSongByID(song.ID)
+	if err != nil {
+		return failed(err)
+	}
+	if current == nil {
+		result.Status = BatchSongNotFound
+		return result
+	}
+	if role != PromptRoleMain {
+		if err := SaveGeneratedSongVariant(songsDB, current, req, resp); err != nil {
+			return failed(err)
+		}
+		result.Status = BatchSongGenerated
+		return result
+	}
+	if current.Content != song.Content {
+		result.Status = BatchSongChanged
+		return result
+	}
+
+	if err := SaveGeneratedSongContent(songsDB, current, resp, userID); err != nil {
+		return failed(err)
+	}
+	result.Status = BatchSongGenerated
+	return result
+}
+
+// bandRateLimiter spaces out each band's requests to the AI provider
+type bandRateLimiter struct {
+	interval time.Duration
+
+	mu   sync.Mutex
+	next map[string]time.Time
+}
+
+// newBandRateLimiter creates a limiter allowing one request per interval per band
+func newBandRateLimiter(interval time.Duration) *bandRateLimiter {
+	return &bandRateLimiter{
+		interval: interval,
+		next:     make(map[string]time.Time),
+	}
+}
+
+// Wait blocks until the band may send its next request, or the context is done
+func (l *bandRateLimiter) Wait(ctx context.Context, bandID string) error {
+	l.mu.Lock()
+	now := time.Now()
+	for id, at := range l.next {
+		if at.Before(now) {
+			delete(l.next, id)
+		}
+	}
+	at := l.next[bandID]
+	if at.Before(now) {
+		at = now
+	}
+	l.next[bandID] = at.Add(l.interval)
+	l.mu.Unlock()
+
+	wait := time.Until(at)
+	if wait <= 0 {
+		return nil
+	}
+	timer := time.NewTimer(wait)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
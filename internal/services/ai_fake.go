@@ -0,0 +1,144 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"hash/fnv"
+	"strings"
+	"sync"
+	"unicode/utf8"
+)
+
+// FakeProvider is a deterministic AI provider for tests and offline development.
+// It answers with the given replies in turn, or with a reply derived from the
+// prompt when none are given, and records every request it receives.
+type FakeProvider struct {
+	mu       sync.Mutex
+	replies  []string
+	requests []*CompletionRequest
+}
+
+// NewFakeProvider creates a fake provider that answers with the replies in order, cycling
+func NewFakeProvider(replies ...string) *FakeProvider {
+	return &FakeProvider{replies: replies}
+}
+
+// Name identifies the fake provider
+func (p *FakeProvider) Name() string {
+	return "fake"
+}
+
+// Complete records the request and returns the next reply
+func (p *FakeProvider) Complete(ctx context.Context, req *CompletionRequest) (*CompletionResponse, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	var content string
+	switch {
+	case len(p.replies) > 0:
+		content = p.replies[len(p.requests)%len(p.replies)]
+	case req.JSON:
+		content = fakeSectionsReply(req.Prompt)
+	default:
+		content = fakeReply(req.Prompt)
+	}
+	p.requests = append(p.requests, req)
+
+	return &CompletionResponse{
+		Content:      content,
+		Model:        "fake",
+		InputTokens:  len(strings.Fields(req.System)) + len(strings.Fields(req.Prompt)),
+		OutputTokens: len(strings.Fields(content)),
+	}, nil
+}
+
+// fakeStreamChunk is the size of the pieces a fake reply is streamed in
+const fakeStreamChunk = 16
+
+// Stream records the request and sends the next reply in small pieces
+func (p *FakeProvider) Stream(ctx context.Context, req *CompletionRequest, onDelta func(delta string) error) (*CompletionResponse, error) {
+	resp, err := p.Complete(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+
+	for rest := resp.Content; rest != ""; {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		n := min(fakeStreamChunk, len(rest))
+		// Keep multi-byte characters whole
+		for n < len(rest) && !utf8.RuneStart(rest[n]) {
+			n++
+		}
+		if err := onDelta(rest[:n]); err != nil {
+			return nil, err
+		}
+		rest = rest[n:]
+	}
+	return resp, nil
+}
+
+// Requests returns the requests received so far
+func (p *FakeProvider) Requests() []*CompletionRequest {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return append([]*CompletionRequest(nil), p.requests...)
+}
+
+// promptHash hashes the prompt so fake replies depend only on it, and the same
+// prompt always gets the same reply
+func promptHash(prompt string) uint32 {
+	hash := fnv.New32a()
+	hash.Write([]byte(prompt))
+	return hash.Sum32()
+}
+
+// fakeReply builds a markdown cheatsheet that depends only on the prompt
+func fakeReply(prompt string) string {
+	sum := promptHash(prompt)
+
+	firstLine, _, _ := strings.Cut(strings.TrimSpace(prompt), "\n")
+	return fmt.Sprintf(`**Duration:** %d:%02d
+
+## Prompt
+%s
+
+## Verse
+C        G
+Generated offline
+Am       F
+by the fake provider
+
+## Notes
+- Reply %08x
+`, 2+sum%3, sum%60, firstLine, sum)
+}
+
+// fakeSectionsReply builds a JSON cheatsheet in the shape of AIGenerationResponse
+// that depends only on the prompt
+func fakeSectionsReply(prompt string) string {
+	sum := promptHash(prompt)
+
+	firstLine, _, _ := strings.Cut(strings.TrimSpace(prompt), "\n")
+	reply := AIGenerationResponse{
+		SongInfo: SongInfo{
+			OriginalKey:   "C",
+			Tempo:         fmt.Sprintf("%d BPM", 80+sum%80),
+			TimeSignature: "4/4",
+			Duration:      fmt.Sprintf("%d:%02d", 2+sum%3, sum%60),
+		},
+		Sections: []SongSection{
+			{Name: "verse", Key: "C", Body: "C        G\nGenerated offline\nAm       F\nby the fake provider"},
+			{Name: "notes", Body: fmt.Sprintf("- %s\n- Reply %08x", firstLine, sum)},
+		},
+	}
+
+	data, _ := json.MarshalIndent(reply, "", "  ")
+	return string(data)
+}
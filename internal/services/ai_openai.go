@@ -0,0 +1,220 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+)
+
+// OpenAIProvider talks to the OpenAI chat completions API or any server that
+// implements it, such as Ollama or llama.cpp
+type OpenAIProvider struct {
+	config       AIConfig
+	client       *http.Client
+	streamClient *http.Client
+}
+
+// NewOpenAIProvider creates a provider for an OpenAI-compatible API
+func NewOpenAIProvider(cfg AIConfig) *OpenAIProvider {
+	cfg = cfg.withDefaults("https://api.openai.com/v1", "gpt-4o")
+	return &OpenAIProvider{
+		config: cfg,
+		client: &http.Client{
+			Timeout: cfg.Timeout,
+		},
+		streamClient: newStreamingClient(cfg.Timeout),
+	}
+}
+
+// openAIMessage is a chat message in the OpenAI API
+type openAIMessage struct {
+	Role    string `json:"role"`
+	Content string `json:"content"`
+}
+
+// openAIResponseFormat selects the format of a chat completions reply
+type openAIResponseFormat struct {
+	Type string `json:"type"`
+}
+
+// openAIStreamOptions configures a streamed chat completions reply
+type openAIStreamOptions struct {
+	IncludeUsage bool `json:"include_usage"`
+}
+
+// openAIRequest is the body of a chat completions request
+type openAIRequest struct {
+	Model          string                `json:"model"`
+	Messages       []openAIMessage       `json:"messages"`
+	Temperature    float64               `json:"temperature"`
+	MaxTokens      int                   `json:"max_tokens"`
+	ResponseFormat *openAIResponseFormat `json:"response_format,omitempty"`
+	Stream         bool                  `json:"stream,omitempty"`
+	StreamOptions  *openAIStreamOptions  `json:"stream_options,omitempty"`
+}
+
+// openAIChoice is one of the replies in a chat completions response, or the
+// piece of it carried by a streamed chunk
+type openAIChoice struct {
+	Message openAIMessage `json:"message"`
+	Delta   openAIMessage `json:"delta"`
+}
+
+// openAIUsage is the token usage reported for a chat completion
+type openAIUsage struct {
+	PromptTokens     int `json:"prompt_tokens"`
+	CompletionTokens int `json:"completion_tokens"`
+}
+
+// openAIResponse is the body of a chat completions response, or one chunk of a
+// streamed response
+type openAIResponse struct {
+	Model   string         `json:"model"`
+	Choices []openAIChoice `json:"choices"`
+	Usage   *openAIUsage   `json:"usage,omitempty"`
+}
+
+// Name returns the provider and model
+func (p *OpenAIProvider) Name() string {
+	return "openai:" + p.config.Model
+}
+
+// newRequest builds the HTTP request for a chat completion
+func (p *OpenAIProvider) newRequest(ctx context.Context, req *CompletionRequest, stream bool) (*http.Request, error) {
+	body := openAIRequest{
+		Model:       p.config.Model,
+		Temperature: *p.config.Temperature,
+		MaxTokens:   p.config.MaxTokens,
+	}
+	if req.MaxTokens > 0 {
+		body.MaxTokens = req.MaxTokens
+	}
+	if req.JSON {
+		body.ResponseFormat = &openAIResponseFormat{Type: "json_object"}
+	}
+	if stream {
+		body.Stream = true
+		body.StreamOptions = &openAIStreamOptions{IncludeUsage: true}
+	}
+	if req.System != "" {
+		body.Messages = append(body.Messages, openAIMessage{Role: "system", Content: req.System})
+	}
+	body.Messages = append(body.Messages, openAIMessage{Role: "user", Content: req.Prompt})
+
+	jsonData, err := json.Marshal(body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal OpenAI request: %w", err)
+	}
+
+	httpReq, err := http.NewRequestWithContext(ctx, "POST", p.config.BaseURL+"/chat/completions", bytes.NewBuffer(jsonData))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create HTTP request: %w", err)
+	}
+
+	httpReq.Header.Set("Content-Type", "application/json")
+	// Local servers usually run without a key
+	if p.config.APIKey != "" {
+		httpReq.Header.Set("Authorization", "Bearer "+p.config.APIKey)
+	}
+	return httpReq, nil
+}
+
+// Complete sends the prompt as a chat completion
+func (p *OpenAIProvider) Complete(ctx context.Context, req *CompletionRequest) (*CompletionResponse, error) {
+	httpReq, err := p.newRequest(ctx, req, false)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := p.client.Do(httpReq)
+	if err != nil {
+		return nil, fmt.Errorf("failed to make OpenAI request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	respBody, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read response body: %w", err)
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("OpenAI API error: %s - %s", resp.Status, string(respBody))
+	}
+
+	var openAIResp openAIResponse
+	if err := json.Unmarshal(respBody, &openAIResp); err != nil {
+		return nil, fmt.Errorf("failed to parse OpenAI response: %w", err)
+	}
+	if len(openAIResp.Choices) == 0 {
+		return nil, fmt.Errorf("no choices in OpenAI response")
+	}
+
+	out := &CompletionResponse{
+		Content: openAIResp.Choices[0].Message.Content,
+		Model:   openAIResp.Model,
+	}
+	if out.Model == "" {
+		out.Model = p.config.Model
+	}
+	if openAIResp.Usage != nil {
+		out.InputTokens = openAIResp.Usage.PromptTokens
+		out.OutputTokens = openAIResp.Usage.CompletionTokens
+	}
+	return out, nil
+}
+
+// Stream sends the prompt as a streamed chat completion
+func (p *OpenAIProvider) Stream(ctx context.Context, req *CompletionRequest, onDelta func(delta string) error) (*CompletionResponse, error) {
+	httpReq, err := p.newRequest(ctx, req, true)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := p.streamClient.Do(httpReq)
+	if err != nil {
+		return nil, fmt.Errorf("failed to make OpenAI request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		respBody, _ := io.ReadAll(resp.Body)
+		return nil, fmt.Errorf("OpenAI API error: %s - %s", resp.Status, string(respBody))
+	}
+
+	out := &CompletionResponse{Model: p.config.Model}
+	var content strings.Builder
+	err = readServerSentEvents(resp.Body, func(event, data string) error {
+		if data == "[DONE]" {
+			return nil
+		}
+		var chunk openAIResponse
+		if err := json.Unmarshal([]byte(data), &chunk); err != nil {
+			return fmt.Errorf("failed to parse OpenAI stream chunk: %w", err)
+		}
+		if chunk.Model != "" {
+			out.Model = chunk.Model
+		}
+		if chunk.Usage != nil {
+			out.InputTokens = chunk.Usage.PromptTokens
+			out.OutputTokens = chunk.Usage.CompletionTokens
+		}
+		if len(chunk.Choices) == 0 || chunk.Choices[0].Delta.Content == "" {
+			return nil
+		}
+		content.WriteString(chunk.Choices[0].Delta.Content)
+		return onDelta(chunk.Choices[0].Delta.Content)
+	})
+	if err != nil {
+		return nil, err
+	}
+	if content.Len() == 0 {
+		return nil, fmt.Errorf("no content in OpenAI stream")
+	}
+
+	out.Content = content.String()
+	return out, nil
+}
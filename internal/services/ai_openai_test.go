@@ -0,0 +1,268 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+)
+
+// newStandInOpenAIProvider creates an OpenAI client talking to a stand-in
+// server backed by the fake provider
+func newStandInOpenAIProvider(t *testing.T) (*OpenAIProvider, *FakeProvider) {
+	fake := NewFakeProvider()
+	server := newAIStandInServer(t, fake)
+	return NewOpenAIProvider(AIConfig{BaseURL: server.URL + "/v1", Model: "gpt-test"}), fake
+}
+
+func TestOpenAIProviderComplete(t *testing.T) {
+	tests := []struct {
+		name          string
+		req           *CompletionRequest
+		wantContent   string
+		wantMaxTokens int
+	}{
+		{
+			name:          "plain",
+			req:           &CompletionRequest{System: "You are a musician", Prompt: "Wonderwall\nby Oasis"},
+			wantContent:   fakeReply("Wonderwall\nby Oasis"),
+			wantMaxTokens: 4096,
+		},
+		{
+			name:          "json mode",
+			req:           &CompletionRequest{System: "Reply in JSON", Prompt: "Yesterday", JSON: true},
+			wantContent:   fakeSectionsReply("Yesterday"),
+			wantMaxTokens: 4096,
+		},
+		{
+			name:          "max tokens override",
+			req:           &CompletionRequest{Prompt: "Let It Be", MaxTokens: 200},
+			wantContent:   fakeReply("Let It Be"),
+			wantMaxTokens: 200,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			provider, fake := newStandInOpenAIProvider(t)
+
+			resp, err := provider.Complete(context.Background(), tt.req)
+			if err != nil {
+				t.Fatalf("Complete() error = %v", err)
+			}
+			if resp.Content != tt.wantContent {
+				t.Errorf("Content = %q, want %q", resp.Content, tt.wantContent)
+			}
+			if resp.Model != "fake" {
+				t.Errorf("Model = %q, want %q", resp.Model, "fake")
+			}
+			if resp.InputTokens == 0 || resp.OutputTokens == 0 {
+				t.Errorf("tokens = %d/%d, want both reported", resp.InputTokens, resp.OutputTokens)
+			}
+
+			requests := fake.Requests()
+			if len(requests) != 1 {
+				t.Fatalf("server received %d requests, want 1", len(requests))
+			}
+			got := requests[0]
+			if got.System != tt.req.System || got.Prompt != tt.req.Prompt || got.JSON != tt.req.JSON {
+				t.Errorf("server received %+v, want %+v", got, tt.req)
+			}
+			if got.MaxTokens != tt.wantMaxTokens {
+				t.Errorf("server received max tokens %d, want %d", got.MaxTokens, tt.wantMaxTokens)
+			}
+			if tt.req.JSON {
+				if _, err := ParseAIGenerationResponse(resp.Content); err != nil {
+					t.Errorf("JSON reply doesn't parse: %v", err)
+				}
+			}
+		})
+	}
+}
+
+func TestOpenAIProviderStream(t *testing.T) {
+	tests := []struct {
+		name        string
+		req         *CompletionRequest
+		wantContent string
+	}{
+		{
+			name:        "plain",
+			req:         &CompletionRequest{Prompt: "Wonderwall"},
+			wantContent: fakeReply("Wonderwall"),
+		},
+		{
+			name:        "json mode",
+			req:         &CompletionRequest{Prompt: "Yesterday", JSON: true},
+			wantContent: fakeSectionsReply("Yesterday"),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			provider, fake := newStandInOpenAIProvider(t)
+
+			var deltas []string
+			resp, err := provider.Stream(context.Background(), tt.req, collectDeltas(&deltas))
+			if err != nil {
+				t.Fatalf("Stream() error = %v", err)
+			}
+			if len(deltas) < 2 {
+				t.Errorf("got %d deltas, want the reply in pieces", len(deltas))
+			}
+			if joined := strings.Join(deltas, ""); joined != tt.wantContent {
+				t.Errorf("deltas = %q, want %q", joined, tt.wantContent)
+			}
+			if resp.Content != tt.wantContent {
+				t.Errorf("Content = %q, want %q", resp.Content, tt.wantContent)
+			}
+			if resp.Model != "fake" || resp.InputTokens == 0 || resp.OutputTokens == 0 {
+				t.Errorf("usage = %s %d/%d, want the stand-in's", resp.Model, resp.InputTokens, resp.OutputTokens)
+			}
+			if requests := fake.Requests(); len(requests) != 1 || requests[0].JSON != tt.req.JSON {
+				t.Errorf("server received %+v, want one request with JSON %v", requests, tt.req.JSON)
+			}
+		})
+	}
+}
+
+func TestOpenAIProviderStreamAbort(t *testing.T) {
+	provider, _ := newStandInOpenAIProvider(t)
+
+	errStop := errors.New("stop")
+	deltas := 0
+	_, err := provider.Stream(context.Background(), &CompletionRequest{Prompt: "Wonderwall"}, func(delta string) error {
+		deltas++
+		return errStop
+	})
+	if !errors.Is(err, errStop) {
+		t.Errorf("Stream() error = %v, want %v", err, errStop)
+	}
+	if deltas != 1 {
+		t.Errorf("got %d deltas, want the stream to stop after the first", deltas)
+	}
+}
+
+func TestOpenAIProviderErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		handler http.HandlerFunc
+		stream  bool
+		wantErr string
+	}{
+		{
+			name:    "provider error",
+			handler: aiStandInHandler(failingProvider{err: errors.New("model overloaded")}),
+			wantErr: "500 Internal Server Error - model overloaded",
+		},
+		{
+			name:    "provider error while streaming",
+			handler: aiStandInHandler(failingProvider{err: errors.New("model overloaded")}),
+			stream:  true,
+			wantErr: "no content in OpenAI stream",
+		},
+		{
+			name: "rate limited",
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				http.Error(w, "slow down", http.StatusTooManyRequests)
+			},
+			wantErr: "OpenAI API error: 429 Too Many Requests - slow down",
+		},
+		{
+			name: "rate limited while streaming",
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				http.Error(w, "slow down", http.StatusTooManyRequests)
+			},
+			stream:  true,
+			wantErr: "OpenAI API error: 429 Too Many Requests - slow down",
+		},
+		{
+			name: "no choices",
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				fmt.Fprint(w, `{"model":"gpt-test","choices":[]}`)
+			},
+			wantErr: "no choices in OpenAI response",
+		},
+		{
+			name: "invalid body",
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				fmt.Fprint(w, `<html>`)
+			},
+			wantErr: "failed to parse OpenAI response",
+		},
+		{
+			name: "invalid stream chunk",
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				NewEventStream(w).SendData("", "{")
+			},
+			stream:  true,
+			wantErr: "failed to parse OpenAI stream chunk",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := newAIServer(t, tt.handler)
+			provider := NewOpenAIProvider(AIConfig{BaseURL: server.URL + "/v1"})
+
+			req := &CompletionRequest{Prompt: "Wonderwall"}
+			var err error
+			if tt.stream {
+				_, err = provider.Stream(context.Background(), req, func(string) error { return nil })
+			} else {
+				_, err = provider.Complete(context.Background(), req)
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("error = %v, want one containing %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestOpenAIProviderTimeout(t *testing.T) {
+	for _, stream := range []bool{false, true} {
+		t.Run(fmt.Sprintf("stream=%v", stream), func(t *testing.T) {
+			server := newAIServer(t, hangingHandler)
+			provider := NewOpenAIProvider(AIConfig{BaseURL: server.URL + "/v1", Timeout: 50 * time.Millisecond})
+
+			start := time.Now()
+			req := &CompletionRequest{Prompt: "Wonderwall"}
+			var err error
+			if stream {
+				_, err = provider.Stream(context.Background(), req, func(string) error { return nil })
+			} else {
+				_, err = provider.Complete(context.Background(), req)
+			}
+			if err == nil || !strings.Contains(err.Error(), "failed to make OpenAI request") {
+				t.Errorf("error = %v, want the request to time out", err)
+			}
+			if elapsed := time.Since(start); elapsed > 2*time.Second {
+				t.Errorf("gave up after %v, want about the 50ms timeout", elapsed)
+			}
+		})
+	}
+}
+
+func TestOpenAIProviderStreamOutlastsTimeout(t *testing.T) {
+	// The timeout bounds the wait for the reply to start, not the whole reply
+	server := newAIServer(t, func(w http.ResponseWriter, r *http.Request) {
+		stream := NewEventStream(w)
+		for _, word := range []string{"slow ", "but ", "steady"} {
+			time.Sleep(40 * time.Millisecond)
+			stream.Send("", openAIResponse{Choices: []openAIChoice{{Delta: openAIMessage{Content: word}}}})
+		}
+		stream.SendData("", "[DONE]")
+	})
+	provider := NewOpenAIProvider(AIConfig{BaseURL: server.URL + "/v1", Timeout: 50 * time.Millisecond})
+
+	resp, err := provider.Stream(context.Background(), &CompletionRequest{Prompt: "Wonderwall"}, func(string) error { return nil })
+	if err != nil {
+		t.Fatalf("Stream() error = %v", err)
+	}
+	if resp.Content != "slow but steady" {
+		t.Errorf("Content = %q, want %q", resp.Content, "slow but steady")
+	}
+}
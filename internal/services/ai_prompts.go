@@ -0,0 +1,231 @@
+package services
+
+import (
+	"fmt"
+	"strings"
+	"text/template"
+
+	"github.com/nahue/setlist_manager/internal/store"
+)
+
+// Roles song content is generated for. The main role is the song's own content;
+// the others are instrument-specific variants stored next to it.
+const (
+	PromptRoleMain   = "main"
+	PromptRoleDrums  = "drums"
+	PromptRoleBass   = "bass"
+	PromptRoleVocals = "vocals"
+)
+
+// PromptRoles lists the roles in display order
+var PromptRoles = []string{PromptRoleMain, PromptRoleDrums, PromptRoleBass, PromptRoleVocals}
+
+// VariantRoles lists the roles stored as song variants
+var VariantRoles = []string{PromptRoleDrums, PromptRoleBass, PromptRoleVocals}
+
+// promptRoleLabels name the roles in the UI
+var promptRoleLabels = map[string]string{
+	PromptRoleMain:   "Principal",
+	PromptRoleDrums:  "Batería",
+	PromptRoleBass:   "Bajo",
+	PromptRoleVocals: "Voz",
+}
+
+// PromptRoleLabel returns the name of a role shown in the UI
+func PromptRoleLabel(role string) string {
+	if label, ok := promptRoleLabels[role]; ok {
+		return label
+	}
+	return role
+}
+
+// ValidPromptRole reports whether role is one of PromptRoles
+func ValidPromptRole(role string) bool {
+	for _, r := range PromptRoles {
+		if r == role {
+			return true
+		}
+	}
+	return false
+}
+
+// maxPromptTemplateLength bounds the body of a prompt template
+const maxPromptTemplateLength = 8000
+
+// PromptData holds the song fields available to prompt templates. Tempo is 0 and
+// Duration is empty when the song doesn't have them.
+type PromptData struct {
+	Title    string
+	Artist   string
+	Key      string
+	Tempo    int
+	Duration string
+	Notes    string
+	Role     string
+}
+
+// builtInPromptTemplates are the prompts used for bands without a default
+// template of their own
+var builtInPromptTemplates = map[string]string{
+	PromptRoleMain: `Generate a band practice cheatsheet for "{{.Title}}"{{with .Artist}} by {{.}}{{end}} {{if .Key}}in the key of {{.Key}}{{else}}in its original key{{end}} {{if .Tempo}}at {{.Tempo}} BPM{{else}}at its original tempo{{end}}.
+
+Each section body should include:
+- The COMPLETE lyrics for the section (no placeholders like [...]), with chords written on their own line above the lyrics
+- Performance notes for the band: dynamics, rhythmic patterns, guitar techniques, bass lines and drum patterns, vocal delivery, and how the section connects to the next
+- The musical feel and energy of the section
+{{- with .Notes}}
+
+Notes from the band: {{.}}
+{{- end}}`,
+
+	PromptRoleDrums: `Write a drummer's chart for "{{.Title}}"{{with .Artist}} by {{.}}{{end}} {{if .Tempo}}at {{.Tempo}} BPM{{else}}at its original tempo{{end}}.
+
+Each section body should include:
+- The length of the section in bars and the time signature when it changes
+- The groove: kick, snare and hi-hat or ride pattern, and the feel
+- Fills and where they fall, cymbal accents and stops
+- Dynamics, and cues for the start and end of the section such as the first words sung
+
+Do not write out the full lyrics.
+{{- with .Notes}}
+
+Notes from the band: {{.}}
+{{- end}}`,
+
+	PromptRoleBass: `Write a bass player's chart for "{{.Title}}"{{with .Artist}} by {{.}}{{end}} {{if .Key}}in the key of {{.Key}}{{else}}in its original key{{end}} {{if .Tempo}}at {{.Tempo}} BPM{{else}}at its original tempo{{end}}.
+
+Each section body should include:
+- The chord progression with the number of bars of each chord
+- The root movement and a suggested bass line or pattern, with passing tones
+- How the part locks in with the kick drum, and the dynamics
+- Cues for the start and end of the section such as the first words sung
+
+Do not write out the full lyrics.
+{{- with .Notes}}
+
+Notes from the band: {{.}}
+{{- end}}`,
+
+	PromptRoleVocals: `Write a vocalist's lyric sheet for "{{.Title}}"{{with .Artist}} by {{.}}{{end}} {{if .Key}}in the key of {{.Key}}{{else}}in its original key{{end}}.
+
+Each section body should include:
+- The COMPLETE lyrics for the section (no placeholders like [...])
+- Harmony parts and who sings them, and backing vocals
+- Phrasing, breath points, dynamics and delivery, and ad-libs
+
+Leave out the chords.
+{{- with .Notes}}
+
+Notes from the band: {{.}}
+{{- end}}`,
+}
+
+// BuiltInPromptTemplate returns the built-in prompt template for a role
+func BuiltInPromptTemplate(role string) string {
+	if body, ok := builtInPromptTemplates[role]; ok {
+		return body
+	}
+	return builtInPromptTemplates[PromptRoleMain]
+}
+
+// RenderPromptTemplate renders a prompt template, a Go text/template, with the
+// song fields in data
+func RenderPromptTemplate(body string, data PromptData) (string, error) {
+	tmpl, err := template.New("prompt").Option("missingkey=error").Parse(body)
+	if err != nil {
+		return "", fmt.Errorf("invalid prompt template: %w", err)
+	}
+
+	var b strings.Builder
+	if err := tmpl.Execute(&b, data); err != nil {
+		return "", fmt.Errorf("invalid prompt template: %w", err)
+	}
+	return strings.TrimSpace(b.String()), nil
+}
+
+// ValidatePromptTemplate checks that a band's prompt template renders a prompt
+// for a sample song, so a broken template is caught when it is saved
+func ValidatePromptTemplate(body string) error {
+	if strings.TrimSpace(body) == "" {
+		return fmt.Errorf("the prompt template is empty")
+	}
+	if len(body) > maxPromptTemplateLength {
+		return fmt.Errorf("the prompt template is longer than %d characters", maxPromptTemplateLength)
+	}
+
+	prompt, err := RenderPromptTemplate(body, PromptData{
+		Title:    "Wonderwall",
+		Artist:   "Oasis",
+		Key:      "F#m",
+		Tempo:    87,
+		Duration: "4:18",
+		Notes:    "Capo on 2",
+		Role:     PromptRoleMain,
+	})
+	if err != nil {
+		return err
+	}
+	if prompt == "" {
+		return fmt.Errorf("the prompt template renders an empty prompt")
+	}
+	return nil
+}
+
+// SongContentRequest builds the request to generate a song's content for a role,
+// with the band's default prompt template for the role when it has one. Its usage
+// is recorded for the song's band and the given user.
+func (s *AIService) SongContentRequest(song *store.Song, role, userID string) (*SongContentRequest, error) {
+	req := &SongContentRequest{
+		SongTitle: song.Title,
+		Artist:    song.Artist,
+		Key:       song.Key,
+		Tempo:     song.Tempo,
+		Duration:  song.Duration,
+		Notes:     song.Notes,
+		Role:      role,
+		BandID:    song.BandID,
+		SongID:    song.ID,
+		UserID:    userID,
+	}
+	if s.promptsDB == nil {
+		return req, nil
+	}
+
+	tmpl, err := s.promptsDB.GetDefaultPromptTemplate(song.BandID, role)
+	if err != nil {
+		return nil, err
+	}
+	if tmpl != nil {
+		req.Template = tmpl.Body
+		req.TemplateID = tmpl.ID
+		req.TemplateVersion = tmpl.Version
+	}
+	return req, nil
+}
+
+// promptData returns the song fields of the request for its prompt template
+func (r *SongContentRequest) promptData() PromptData {
+	data := PromptData{
+		Title:  r.SongTitle,
+		Artist: r.Artist,
+		Key:    r.Key,
+		Notes:  r.Notes,
+		Role:   r.Role,
+	}
+	if data.Role == "" {
+		data.Role = PromptRoleMain
+	}
+	if r.Tempo != nil {
+		data.Tempo = *r.Tempo
+	}
+	if r.Duration != nil {
+		data.Duration = FormatSongDuration(*r.Duration)
+	}
+	return data
+}
+
+// SaveGeneratedSongVariant stores generated content as the song's variant for the
+// request's role, noting the band template it was generated with
+func SaveGeneratedSongVariant(songsDB *store.SQLiteSongsStore, song *store.Song, req *SongContentRequest, resp *SongContentResponse) error {
+	return songsDB.SaveSongVariant(song.ID, req.Role, resp.Content, req.TemplateID, req.TemplateVersion)
+}
@@ -0,0 +1,253 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// AI provider names accepted in AI_PROVIDER
+const (
+	AIProviderOpenAI    = "openai"
+	AIProviderAnthropic = "anthropic"
+	AIProviderFake      = "fake"
+)
+
+// AIProvider generates text completions from a language model
+type AIProvider interface {
+	// Name identifies the provider and model, for logs
+	Name() string
+	// Complete sends a prompt and returns the model's reply
+	Complete(ctx context.Context, req *CompletionRequest) (*CompletionResponse, error)
+}
+
+// StreamingAIProvider is an AIProvider that can send its reply as it is generated
+type StreamingAIProvider interface {
+	AIProvider
+	// Stream sends a prompt and calls onDelta with each piece of the reply as it
+	// arrives, returning the whole reply at the end. An error from onDelta aborts
+	// the request.
+	Stream(ctx context.Context, req *CompletionRequest, onDelta func(delta string) error) (*CompletionResponse, error)
+}
+
+// streamCompletion streams the reply when the provider supports it, and otherwise
+// passes the whole reply to onDelta at once
+func streamCompletion(ctx context.Context, provider AIProvider, req *CompletionRequest, onDelta func(delta string) error) (*CompletionResponse, error) {
+	if streaming, ok := provider.(StreamingAIProvider); ok {
+		return streaming.Stream(ctx, req, onDelta)
+	}
+	resp, err := provider.Complete(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+	if err := onDelta(resp.Content); err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+// CompletionRequest is a single prompt sent to an AI provider
+type CompletionRequest struct {
+	System string `json:"system"`
+	Prompt string `json:"prompt"`
+	// MaxTokens overrides the provider's configured limit when set
+	MaxTokens int `json:"max_tokens,omitempty"`
+	// JSON asks for a reply that is a single JSON object, for providers that support it
+	JSON bool `json:"json,omitempty"`
+}
+
+// CompletionResponse is the reply from an AI provider
+type CompletionResponse struct {
+	Content      string `json:"content"`
+	Model        string `json:"model"`
+	InputTokens  int    `json:"input_tokens"`
+	OutputTokens int    `json:"output_tokens"`
+}
+
+// AIConfig configures how an AI provider is reached
+type AIConfig struct {
+	Provider    string
+	BaseURL     string
+	APIKey      string
+	Model       string
+	Temperature *float64
+	MaxTokens   int
+	Timeout     time.Duration
+
+	// BatchConcurrency and BandRateLimit bound batch generation for a band: how
+	// many songs are generated at once, and how many requests per minute a band
+	// may send to the provider
+	BatchConcurrency int
+	BandRateLimit    int
+
+	// CacheTTL is how long replies are reused for the same prompt, 0 to not
+	// cache. BandMonthlyTokens and UserMonthlyTokens cap the tokens a band or a
+	// user may use each month, 0 for no cap. InputCostPerMillion and
+	// OutputCostPerMillion price the tokens, in USD per million, for the usage page.
+	CacheTTL             time.Duration
+	BandMonthlyTokens    int
+	UserMonthlyTokens    int
+	InputCostPerMillion  float64
+	OutputCostPerMillion float64
+}
+
+// AIConfigFromEnv reads the AI provider configuration from the environment:
+//
+//	AI_PROVIDER                 openai, anthropic or fake
+//	AI_BASE_URL                 API base URL, e.g. http://localhost:11434/v1 for a local Ollama server
+//	AI_API_KEY                  API key, falling back to OPENAI_API_KEY or ANTHROPIC_API_KEY
+//	AI_MODEL                    model name
+//	AI_TEMPERATURE              sampling temperature
+//	AI_MAX_TOKENS               reply length limit
+//	AI_TIMEOUT                  request timeout, e.g. 30s or 2m
+//	AI_BATCH_CONCURRENCY        songs generated at once by a batch
+//	AI_BAND_RATE_LIMIT          requests per minute a band's batches may send
+//	AI_CACHE_TTL                how long replies are reused for the same prompt, 0 to not cache
+//	AI_BAND_MONTHLY_TOKENS      tokens a band may use each month
+//	AI_USER_MONTHLY_TOKENS      tokens a user may use each month, across bands
+//	AI_INPUT_COST_PER_MILLION   USD per million prompt tokens, for the usage page
+//	AI_OUTPUT_COST_PER_MILLION  USD per million reply tokens, for the usage page
+//
+// When AI_PROVIDER is not set, OpenAI is used if an API key or base URL is configured.
+// It returns a config with an empty provider when AI is not configured at all.
+func AIConfigFromEnv() (*AIConfig, error) {
+	cfg := &AIConfig{
+		Provider: strings.ToLower(strings.TrimSpace(os.Getenv("AI_PROVIDER"))),
+		BaseURL:  strings.TrimSpace(os.Getenv("AI_BASE_URL")),
+		APIKey:   strings.TrimSpace(os.Getenv("AI_API_KEY")),
+		Model:    strings.TrimSpace(os.Getenv("AI_MODEL")),
+		CacheTTL: DefaultAICacheTTL,
+	}
+
+	if cfg.Provider == "" && (cfg.APIKey != "" || cfg.BaseURL != "" || os.Getenv("OPENAI_API_KEY") != "") {
+		cfg.Provider = AIProviderOpenAI
+	}
+	if cfg.APIKey == "" {
+		switch cfg.Provider {
+		case AIProviderOpenAI:
+			cfg.APIKey = os.Getenv("OPENAI_API_KEY")
+		case AIProviderAnthropic:
+			cfg.APIKey = os.Getenv("ANTHROPIC_API_KEY")
+		}
+	}
+
+	if value := os.Getenv("AI_TEMPERATURE"); value != "" {
+		temperature, err := strconv.ParseFloat(value, 64)
+		if err != nil || temperature < 0 || temperature > 2 {
+			return nil, fmt.Errorf("invalid AI_TEMPERATURE %q, must be between 0 and 2", value)
+		}
+		cfg.Temperature = &temperature
+	}
+	if value := os.Getenv("AI_MAX_TOKENS"); value != "" {
+		maxTokens, err := strconv.Atoi(value)
+		if err != nil || maxTokens < 1 {
+			return nil, fmt.Errorf("invalid AI_MAX_TOKENS %q", value)
+		}
+		cfg.MaxTokens = maxTokens
+	}
+	if value := os.Getenv("AI_TIMEOUT"); value != "" {
+		timeout, err := time.ParseDuration(value)
+		if err != nil || timeout <= 0 {
+			return nil, fmt.Errorf("invalid AI_TIMEOUT %q, use a duration like 30s or 2m", value)
+		}
+		cfg.Timeout = timeout
+	}
+	if value := os.Getenv("AI_BATCH_CONCURRENCY"); value != "" {
+		concurrency, err := strconv.Atoi(value)
+		if err != nil || concurrency < 1 {
+			return nil, fmt.Errorf("invalid AI_BATCH_CONCURRENCY %q", value)
+		}
+		cfg.BatchConcurrency = concurrency
+	}
+	if value := os.Getenv("AI_BAND_RATE_LIMIT"); value != "" {
+		limit, err := strconv.Atoi(value)
+		if err != nil || limit < 1 {
+			return nil, fmt.Errorf("invalid AI_BAND_RATE_LIMIT %q, in requests per minute", value)
+		}
+		cfg.BandRateLimit = limit
+	}
+	if value := os.Getenv("AI_CACHE_TTL"); value != "" {
+		ttl, err := time.ParseDuration(value)
+		if err != nil || ttl < 0 {
+			return nil, fmt.Errorf("invalid AI_CACHE_TTL %q, use a duration like 24h, or 0 to not cache", value)
+		}
+		cfg.CacheTTL = ttl
+	}
+	if value := os.Getenv("AI_BAND_MONTHLY_TOKENS"); value != "" {
+		tokens, err := strconv.Atoi(value)
+		if err != nil || tokens < 0 {
+			return nil, fmt.Errorf("invalid AI_BAND_MONTHLY_TOKENS %q", value)
+		}
+		cfg.BandMonthlyTokens = tokens
+	}
+	if value := os.Getenv("AI_USER_MONTHLY_TOKENS"); value != "" {
+		tokens, err := strconv.Atoi(value)
+		if err != nil || tokens < 0 {
+			return nil, fmt.Errorf("invalid AI_USER_MONTHLY_TOKENS %q", value)
+		}
+		cfg.UserMonthlyTokens = tokens
+	}
+	if value := os.Getenv("AI_INPUT_COST_PER_MILLION"); value != "" {
+		cost, err := strconv.ParseFloat(value, 64)
+		if err != nil || cost < 0 {
+			return nil, fmt.Errorf("invalid AI_INPUT_COST_PER_MILLION %q", value)
+		}
+		cfg.InputCostPerMillion = cost
+	}
+	if value := os.Getenv("AI_OUTPUT_COST_PER_MILLION"); value != "" {
+		cost, err := strconv.ParseFloat(value, 64)
+		if err != nil || cost < 0 {
+			return nil, fmt.Errorf("invalid AI_OUTPUT_COST_PER_MILLION %q", value)
+		}
+		cfg.OutputCostPerMillion = cost
+	}
+
+	return cfg, nil
+}
+
+// withDefaults fills the settings left unset with the defaults for the provider
+func (c AIConfig) withDefaults(baseURL, model string) AIConfig {
+	if c.BaseURL == "" {
+		c.BaseURL = baseURL
+	}
+	c.BaseURL = strings.TrimRight(c.BaseURL, "/")
+	if c.Model == "" {
+		c.Model = model
+	}
+	if c.Temperature == nil {
+		temperature := 0.7
+		c.Temperature = &temperature
+	}
+	if c.MaxTokens == 0 {
+		c.MaxTokens = 4096
+	}
+	if c.Timeout == 0 {
+		c.Timeout = 30 * time.Second
+	}
+	return c
+}
+
+// NewAIProvider creates the provider selected by the config. It returns nil
+// without an error when no provider is configured.
+func NewAIProvider(cfg *AIConfig) (AIProvider, error) {
+	switch cfg.Provider {
+	case "":
+		return nil, nil
+	case AIProviderOpenAI:
+		if cfg.APIKey == "" && cfg.BaseURL == "" {
+			return nil, fmt.Errorf("the openai provider needs an API key or a base URL")
+		}
+		return NewOpenAIProvider(*cfg), nil
+	case AIProviderAnthropic:
+		if cfg.APIKey == "" {
+			return nil, fmt.Errorf("the anthropic provider needs an API key")
+		}
+		return NewAnthropicProvider(*cfg), nil
+	case AIProviderFake:
+		return NewFakeProvider(), nil
+	}
+	return nil, fmt.Errorf("unknown AI provider %q", cfg.Provider)
+}
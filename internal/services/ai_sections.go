@@ -0,0 +1,167 @@
+package services
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/nahue/setlist_manager/internal/transpose"
+)
+
+// songSectionsSystemPrompt sets up the model as a band practice coach that answers in JSON
+const songSectionsSystemPrompt = "You are a music expert and band practice coach. You write practice charts split into song sections, focusing on practical performance aspects rather than technical music theory, and follow the request on what each section should contain. Reply with a single JSON object and nothing else."
+
+// maxSongSections bounds the number of sections accepted from a reply
+const maxSongSections = 40
+
+// songSectionsFormat is appended to every prompt, so band templates only need to
+// describe the content and the reply can always be parsed
+const songSectionsFormat = `Reply with JSON of exactly this shape:
+
+{
+  "song_info": {
+    "title": "Song title",
+    "artist": "Artist",
+    "original_key": "Key of the original recording, e.g. C, F#m, Bb",
+    "tempo": "Tempo of the original recording, e.g. 120 BPM",
+    "time_signature": "e.g. 4/4",
+    "duration": "Length of the original recording as m:ss"
+  },
+  "sections": [
+    {
+      "name": "Section name, e.g. Intro, Verse 1, Chorus, Bridge, Outro",
+      "key": "Key of the section, or empty",
+      "body": "Markdown for the section"
+    }
+  ]
+}
+
+List the sections in playing order. Do not put section headings inside the bodies.`
+
+// songSectionsPrompt builds the prompt asking for a song's content as JSON
+// sections, from the request's template or the built-in one for its role
+func songSectionsPrompt(req *SongContentRequest) (string, error) {
+	body := req.Template
+	if body == "" {
+		body = BuiltInPromptTemplate(req.Role)
+	}
+	prompt, err := RenderPromptTemplate(body, req.promptData())
+	if err != nil {
+		return "", err
+	}
+	return prompt + "\n\n" + songSectionsFormat, nil
+}
+
+// jsonObjectPattern finds the outermost JSON object in a reply that wraps it in prose or a code fence
+var jsonObjectPattern = regexp.MustCompile(`(?s)\{.*\}`)
+
+// ParseAIGenerationResponse reads and validates an AI reply holding the JSON for
+// an AIGenerationResponse, tolerating a code fence or text around the object
+func ParseAIGenerationResponse(content string) (*AIGenerationResponse, error) {
+	object := jsonObjectPattern.FindString(content)
+	if object == "" {
+		return nil, fmt.Errorf("the reply has no JSON object")
+	}
+
+	var resp AIGenerationResponse
+	if err := json.Unmarshal([]byte(object), &resp); err != nil {
+		return nil, fmt.Errorf("the reply is not valid JSON: %w", err)
+	}
+	if err := resp.Validate(); err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
+
+// Validate checks that the response has usable sections and normalizes its fields.
+// Keys that can't be parsed are dropped rather than rejected.
+func (r *AIGenerationResponse) Validate() error {
+	if len(r.Sections) == 0 {
+		return fmt.Errorf("the reply has no sections")
+	}
+	if len(r.Sections) > maxSongSections {
+		return fmt.Errorf("the reply has %d sections, the limit is %d", len(r.Sections), maxSongSections)
+	}
+
+	for i := range r.Sections {
+		section := &r.Sections[i]
+		section.Name = strings.TrimSpace(section.Name)
+		section.Key = validKey(section.Key)
+		section.Body = strings.TrimSpace(section.Body)
+		if section.Name == "" {
+			return fmt.Errorf("section %d has no name", i+1)
+		}
+		if section.Body == "" {
+			return fmt.Errorf("section %q has no body", section.Name)
+		}
+	}
+
+	r.SongInfo.OriginalKey = validKey(r.SongInfo.OriginalKey)
+	return nil
+}
+
+// validKey returns the key trimmed, or "" when it isn't a key transpose understands
+func validKey(key string) string {
+	key = strings.TrimSpace(key)
+	if key == "" {
+		return ""
+	}
+	if _, _, err := transpose.ParseKey(key); err != nil {
+		return ""
+	}
+	return key
+}
+
+// tempoPattern finds the BPM in a free text tempo such as "120 BPM, driving rock"
+var tempoPattern = regexp.MustCompile(`\d+`)
+
+// TempoBPM returns the tempo as beats per minute, or nil when it has no valid number
+func (i SongInfo) TempoBPM() *int {
+	match := tempoPattern.FindString(i.Tempo)
+	if match == "" {
+		return nil
+	}
+	tempo, err := strconv.Atoi(match)
+	if err != nil || tempo < 1 || tempo > 300 {
+		return nil
+	}
+	return &tempo
+}
+
+// DurationSeconds returns the duration in seconds, or nil when it can't be parsed
+func (i SongInfo) DurationSeconds() *int {
+	seconds, err := ParseSongDuration(strings.TrimSpace(i.Duration))
+	if err != nil {
+		return nil
+	}
+	return &seconds
+}
+
+// SectionTitle turns a section name such as "verse_1" into a heading such as "Verse 1"
+func SectionTitle(name string) string {
+	words := strings.Fields(strings.NewReplacer("_", " ", "-", " ").Replace(name))
+	for i, word := range words {
+		words[i] = strings.ToUpper(word[:1]) + word[1:]
+	}
+	return strings.Join(words, " ")
+}
+
+// Markdown renders the sections as song content, one heading per section. A
+// section's key is noted when it differs from the key the song is played in.
+func (r *AIGenerationResponse) Markdown(songKey string) string {
+	var b strings.Builder
+	for i, section := range r.Sections {
+		if i > 0 {
+			b.WriteString("\n\n")
+		}
+		b.WriteString("## " + SectionTitle(section.Name) + "\n\n")
+		if section.Key != "" && section.Key != songKey {
+			b.WriteString("*Key: " + section.Key + "*\n\n")
+		}
+		b.WriteString(section.Body)
+	}
+	b.WriteString("\n")
+	return b.String()
+}
@@ -1,29 +1,67 @@
 package services
 
 import (
-	"bytes"
-	"encoding/json"
+	"context"
 	"fmt"
-	"io"
-	"net/http"
-	"os"
+	"log"
 	"time"
+
+	"github.com/nahue/setlist_manager/internal/store"
+	"github.com/nahue/setlist_manager/internal/transpose"
 )
 
 // AIService handles AI-related operations
 type AIService struct {
-	openAIKey string
-	client    *http.Client
+	provider  AIProvider
+	promptsDB *store.SQLitePromptsStore
+	usageDB   *store.SQLiteAIUsageStore
+
+	// batchConcurrency and bandLimiter bound batch generation for a band
+	batchConcurrency int
+	bandLimiter      *bandRateLimiter
+
+	// cacheTTL is how long replies are reused, and the monthly quotas cap the
+	// tokens of a band and of a user; see AIConfig
+	cacheTTL             time.Duration
+	bandMonthlyTokens    int
+	userMonthlyTokens    int
+	inputCostPerMillion  float64
+	outputCostPerMillion float64
 }
 
-// NewAIService creates a new AI service instance
-func NewAIService() *AIService {
-	return &AIService{
-		openAIKey: os.Getenv("OPENAI_API_KEY"),
-		client: &http.Client{
-			Timeout: 30 * time.Second,
-		},
+// NewAIService creates a new AI service instance using the provider configured
+// in the environment, or sample content when none is configured. Prompts come
+// from the bands' templates in promptsDB; replies are cached and their usage
+// recorded in usageDB.
+func NewAIService(promptsDB *store.SQLitePromptsStore, usageDB *store.SQLiteAIUsageStore) *AIService {
+	cfg, err := AIConfigFromEnv()
+	if err != nil {
+		log.Printf("Warning: invalid AI configuration, using sample content: %v", err)
+		return NewAIServiceWithProvider(nil, promptsDB, usageDB)
+	}
+	provider, err := NewAIProvider(cfg)
+	if err != nil {
+		log.Printf("Warning: invalid AI configuration, using sample content: %v", err)
+		return NewAIServiceWithProvider(nil, promptsDB, usageDB)
+	}
+	if provider != nil {
+		log.Printf("AI provider: %s", provider.Name())
 	}
+	service := NewAIServiceWithProvider(provider, promptsDB, usageDB)
+	service.setBatchLimits(cfg.BatchConcurrency, cfg.BandRateLimit)
+	service.setUsageLimits(cfg)
+	return service
+}
+
+// NewAIServiceWithProvider creates an AI service that generates content with the
+// given provider, or sample content when it is nil. Without promptsDB, the
+// built-in prompts are always used; without usageDB, nothing is cached or
+// recorded. Replies are cached for DefaultAICacheTTL and there are no quotas.
+func NewAIServiceWithProvider(provider AIProvider, promptsDB *store.SQLitePromptsStore, usageDB *store.SQLiteAIUsageStore) *AIService {
+	service := &AIService{provider: provider, promptsDB: promptsDB, usageDB: usageDB}
+	service.setBatchLimits(0, 0)
+	service.setUsageLimits(&AIConfig{CacheTTL: DefaultAICacheTTL})
+	return service
 }
 
 // SongInfo represents song metadata for the cheatsheet
@@ -57,265 +95,147 @@ type AIGenerationResponse struct {
 	Sections []SongSection `json:"sections"`
 }
 
-// SongContentRequest represents the request for song content generation
+// SongContentRequest represents the request for song content generation. Role
+// selects the kind of content, the song's main content when empty. Template is
+// the band's prompt template, the built-in one for the role when empty. BandID,
+// SongID and UserID say who the request's usage is recorded for.
 type SongContentRequest struct {
 	SongTitle string `json:"song_title"`
 	Artist    string `json:"artist"`
 	Key       string `json:"key"`
 	Tempo     *int   `json:"tempo"`
+	Duration  *int   `json:"duration_seconds,omitempty"`
+	Notes     string `json:"notes,omitempty"`
+	Role      string `json:"role,omitempty"`
+
+	Template        string `json:"-"`
+	TemplateID      string `json:"-"`
+	TemplateVersion int    `json:"-"`
+
+	BandID string `json:"-"`
+	SongID string `json:"-"`
+	UserID string `json:"-"`
 }
 
-// SongContentResponse represents the response from song content generation
+// SongContentResponse represents the response from song content generation.
+// Key, Tempo and Duration are the original recording's as reported by the AI,
+// left empty when it didn't report a usable value.
 type SongContentResponse struct {
-	Content string `json:"content"`
+	Content  string               `json:"content"`
+	Sections []*store.SongSection `json:"sections"`
+	Key      string               `json:"key,omitempty"`
+	Tempo    *int                 `json:"tempo,omitempty"`
+	Duration *int                 `json:"duration_seconds,omitempty"`
 }
 
 // GenerateSongContent generates song content using AI for band practice
-func (s *AIService) GenerateSongContent(req *SongContentRequest) (*SongContentResponse, error) {
-	// Add a 1-second delay to simulate processing time
-	time.Sleep(1 * time.Second)
-
-	// If no OpenAI key is configured, return sample data
-	if s.openAIKey == "" {
-		return s.generateSampleContent(req.SongTitle, req.Artist, req.Key, req.Tempo), nil
+func (s *AIService) GenerateSongContent(ctx context.Context, req *SongContentRequest) (*SongContentResponse, error) {
+	// If no AI provider is configured, return sample data
+	if s.provider == nil {
+		return songContentResponse(s.generateSampleSections(req.SongTitle, req.Artist, req.Key), req.Key), nil
 	}
 
-	// Create the prompt for ChatGPT
-	tempoStr := "medium tempo"
-	if req.Tempo != nil {
-		tempoStr = fmt.Sprintf("%d BPM", *req.Tempo)
+	prompt, err := songSectionsPrompt(req)
+	if err != nil {
+		return nil, err
 	}
-
-	prompt := fmt.Sprintf(`Generate a comprehensive band practice cheatsheet for "%s" by %s in the key of %s at %s.
-
-The content should be formatted in Markdown and include:
-
-1. **Song Structure** - Clear section breakdown (Intro, Verse, Chorus, Bridge, etc.)
-2. **Complete Lyrics** - Full lyrics for each section (no placeholders like [...])
-3. **Chord Progressions** - Chords written above lyrics where appropriate
-4. **Performance Notes** - Specific hints for band members including:
-   - Dynamics (when to play soft/loud)
-   - Rhythmic patterns
-   - Guitar techniques (strumming, fingerpicking, etc.)
-   - Bass lines and drum patterns
-   - Vocal delivery tips
-   - How sections connect and flow
-5. **Musical Feel** - Overall mood and energy of each section
-
-Format the response as clean Markdown with clear headers, bullet points, and organized sections. Focus on practical information that helps band members perform the song effectively.
-
-IMPORTANT: Include the COMPLETE lyrics for each section. Do not use placeholders or partial lyrics.`, req.SongTitle, req.Artist, req.Key, tempoStr)
-
-	// Call OpenAI API
-	openAIReq := map[string]interface{}{
-		"model": "gpt-4o",
-		"messages": []map[string]string{
-			{
-				"role":    "system",
-				"content": "You are a music expert and band practice coach. Generate comprehensive band practice cheatsheets in Markdown format, focusing on practical performance aspects rather than technical music theory. Always include complete lyrics and specific performance hints for each band member.",
-			},
-			{
-				"role":    "user",
-				"content": prompt,
-			},
-		},
-		"temperature": 0.7,
-		"max_tokens":  4096,
+	completion := &CompletionRequest{
+		System: songSectionsSystemPrompt,
+		Prompt: prompt,
+		JSON:   true,
 	}
 
-	jsonData, err := json.Marshal(openAIReq)
-	if err != nil {
-		return nil, fmt.Errorf("failed to marshal OpenAI request: %w", err)
+	// Reuse the reply to the same prompt; cached replies don't count against quotas
+	cacheKey := s.songContentCacheKey(completion, req)
+	if cached := s.cachedCompletion(cacheKey); cached != nil {
+		if sections, err := ParseAIGenerationResponse(cached.Content); err == nil {
+			s.recordUsage(req, cached, true)
+			return songContentResponse(sections, req.Key), nil
+		}
 	}
 
-	// Make request to OpenAI
-	httpReq, err := http.NewRequest("POST", "https://api.openai.com/v1/chat/completions", bytes.NewBuffer(jsonData))
-	if err != nil {
-		return nil, fmt.Errorf("failed to create HTTP request: %w", err)
+	if err := s.CheckAIQuota(req.BandID, req.UserID); err != nil {
+		return nil, err
 	}
-
-	httpReq.Header.Set("Content-Type", "application/json")
-	httpReq.Header.Set("Authorization", "Bearer "+s.openAIKey)
-
-	resp, err := s.client.Do(httpReq)
+	resp, err := s.provider.Complete(ctx, completion)
 	if err != nil {
-		return nil, fmt.Errorf("failed to make OpenAI request: %w", err)
+		return nil, fmt.Errorf("failed to generate song content with %s: %w", s.provider.Name(), err)
 	}
-	defer resp.Body.Close()
+	s.recordUsage(req, resp, false)
 
-	body, err := io.ReadAll(resp.Body)
+	sections, err := ParseAIGenerationResponse(resp.Content)
 	if err != nil {
-		return nil, fmt.Errorf("failed to read response body: %w", err)
+		return nil, fmt.Errorf("invalid song content from %s: %w", s.provider.Name(), err)
 	}
+	s.cacheCompletion(cacheKey, resp)
 
-	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("OpenAI API error: %s - %s", resp.Status, string(body))
-	}
+	return songContentResponse(sections, req.Key), nil
+}
 
-	// Parse OpenAI response
-	var openAIResp map[string]interface{}
-	if err := json.Unmarshal(body, &openAIResp); err != nil {
-		return nil, fmt.Errorf("failed to parse OpenAI response: %w", err)
+// songContentResponse renders the sections as content in the song's key, or in
+// the original key reported by the AI when the song has none
+func songContentResponse(sections *AIGenerationResponse, songKey string) *SongContentResponse {
+	key := songKey
+	if key == "" {
+		key = sections.SongInfo.OriginalKey
 	}
-
-	// Extract the content from the response
-	choices, ok := openAIResp["choices"].([]interface{})
-	if !ok || len(choices) == 0 {
-		return nil, fmt.Errorf("no choices in OpenAI response")
+	storeSections := make([]*store.SongSection, 0, len(sections.Sections))
+	for _, section := range sections.Sections {
+		storeSections = append(storeSections, &store.SongSection{Name: section.Name, Key: section.Key, Body: section.Body})
 	}
-
-	choice, ok := choices[0].(map[string]interface{})
-	if !ok {
-		return nil, fmt.Errorf("invalid choice format")
+	return &SongContentResponse{
+		Content:  sections.Markdown(key),
+		Sections: storeSections,
+		Key:      sections.SongInfo.OriginalKey,
+		Tempo:    sections.SongInfo.TempoBPM(),
+		Duration: sections.SongInfo.DurationSeconds(),
 	}
+}
 
-	message, ok := choice["message"].(map[string]interface{})
-	if !ok {
-		return nil, fmt.Errorf("invalid message format")
+// SaveGeneratedSongContent stores generated content and sections on a song, as a
+// revision by the user who asked for it. The key, tempo and duration entered by
+// the user are kept; the ones left empty are filled from the song info reported
+// by the AI.
+func SaveGeneratedSongContent(songsDB *store.SQLiteSongsStore, song *store.Song, resp *SongContentResponse, userID string) error {
+	key, tempo, duration := song.Key, song.Tempo, song.Duration
+	if key == "" {
+		key = resp.Key
 	}
-
-	content, ok := message["content"].(string)
-	if !ok {
-		return nil, fmt.Errorf("invalid content format")
+	if tempo == nil {
+		tempo = resp.Tempo
+	}
+	if duration == nil {
+		duration = resp.Duration
 	}
 
-	return &SongContentResponse{Content: content}, nil
+	return songsDB.UpdateSongWithSections(song.ID, song.Title, song.Artist, key, song.Notes, resp.Content, tempo, duration, resp.Sections, userID, store.SongRevisionAI)
 }
 
-// generateSampleContent creates sample song content when AI is not available
-func (s *AIService) generateSampleContent(songTitle, artist, key string, tempo *int) *SongContentResponse {
-	tempoStr := "medium tempo"
-	if tempo != nil {
-		tempoStr = fmt.Sprintf("%d BPM", *tempo)
+// generateSampleSections creates sample song sections when AI is not available,
+// in the song's key when it has one
+func (s *AIService) generateSampleSections(songTitle, artist, key string) *AIGenerationResponse {
+	// The sample is written in C, or A minor for minor keys; move its keys to the song's key
+	sampleTonic, bridgeTonic := "C", "Am"
+	if _, minor, err := transpose.ParseKey(key); err == nil && minor {
+		sampleTonic, bridgeTonic = "Am", "C"
+	}
+	semitones, err := transpose.Interval(sampleTonic, key)
+	if err != nil {
+		semitones = 0
+	}
+	sampleKey := func(key string) string {
+		transposed, err := transpose.Key(key, semitones)
+		if err != nil {
+			return key
+		}
+		return transposed
 	}
 
-	sampleContent := fmt.Sprintf(`# %s - %s
-
-**Key:** %s | **Tempo:** %s
-
-## Song Structure
-- Intro
-- Verse 1
-- Chorus
-- Verse 2
-- Chorus
-- Bridge
-- Final Chorus
-- Outro
-
-## Intro
-**Chords:** %s - Am - F - G
-
-**Notes:** Gentle arpeggiated chords, establish the mood, everyone enters together. Start soft and build energy.
-
-## Verse 1
-**Chords:** %s - Am - F - G
-
-**Lyrics:**
-This is the first verse of our song
-With chords written above the lyrics
-Building the story and the mood
-Setting up for the chorus to come
-
-**Performance Notes:**
-- Clean strumming, medium volume
-- Building energy throughout the verse
-- Clear vocal delivery
-- Bass follows chord roots
-- Drums: steady 4/4 pattern
-
-## Chorus
-**Chords:** F - G - Am - %s
-
-**Lyrics:**
-This is the chorus, it's the hook
-That everyone will remember
-The part that gets stuck in your head
-The moment when we all sing together
-
-**Performance Notes:**
-- High energy, power chords
-- Full band, anthemic feel
-- Strong vocal harmonies
-- Guitar: palm-muted power chords
-- Bass: driving eighth notes
-- Drums: full kit, strong backbeat
-
-## Verse 2
-**Chords:** %s - Am - F - G
-
-**Lyrics:**
-Second verse with different lyrics
-But same chord progression as verse 1
-Building on the story we started
-Taking it to a deeper level
-
-**Performance Notes:**
-- More intensity than verse 1
-- Add guitar fills between lines
-- Stronger vocal delivery
-- Bass: more melodic lines
-- Drums: add hi-hat variations
-
-## Bridge
-**Chords:** Am - F - C - G
-
-**Lyrics:**
-Bridge section changes the mood
-Different chord progression here
-Taking the song in a new direction
-Before we return to the chorus
-
-**Performance Notes:**
-- Different key, emotional intensity
-- Fingerpicking guitar pattern
-- Dramatic pause before final chorus
-- Bass: walking bass line
-- Drums: half-time feel
-
-## Final Chorus
-**Chords:** F - G - Am - %s
-
-**Lyrics:**
-This is the chorus, it's the hook
-That everyone will remember
-The part that gets stuck in your head
-The moment when we all sing together
-
-**Performance Notes:**
-- Maximum energy and volume
-- Full band, everyone singing
-- Guitar: full power chords
-- Bass: driving and melodic
-- Drums: full kit, strong fills
-
-## Outro
-**Chords:** %s - Am - F - G
-
-**Lyrics:**
-Final lyrics for the outro
-Ending with a gentle fade
-Taking the energy down slowly
-Until we're just a whisper
-
-**Performance Notes:**
-- Gradual fade out
-- Sustained chords
-- Soft ending
-- Guitar: gentle arpeggios
-- Bass: long notes
-- Drums: soft brushes`, songTitle, artist, key, tempoStr, key, key, key, key, key, key)
-
-	return &SongContentResponse{Content: sampleContent}
-}
-
-// generateSampleSections creates sample song sections when AI is not available
-func (s *AIService) generateSampleSections(songTitle, artist string) *AIGenerationResponse {
 	return &AIGenerationResponse{
 		SongInfo: SongInfo{
 			Title:         songTitle,
 			Artist:        artist,
-			OriginalKey:   "C",
+			OriginalKey:   sampleKey(sampleTonic),
 			Tempo:         "120 BPM, driving rock",
 			TimeSignature: "4/4",
 			Duration:      "03:00",
@@ -323,32 +243,32 @@ func (s *AIService) generateSampleSections(songTitle, artist string) *AIGenerati
 		Sections: []SongSection{
 			{
 				Name: "intro",
-				Key:  "C",
+				Key:  sampleKey(sampleTonic),
 				Body: "**Lyrics:** [Instrumental intro]\n\n**Notes:** Gentle arpeggiated chords, establish the mood, everyone enters together",
 			},
 			{
 				Name: "verse_1",
-				Key:  "C",
+				Key:  sampleKey(sampleTonic),
 				Body: "**Lyrics:** This is the first verse of our song\nWith chords written above the lyrics\n\n**Notes:** Clean strumming, medium volume, building energy, clear vocal delivery",
 			},
 			{
 				Name: "chorus",
-				Key:  "C",
+				Key:  sampleKey(sampleTonic),
 				Body: "**Lyrics:** This is the chorus, it's the hook\nThat everyone will remember\n\n**Notes:** High energy, power chords, full band, anthemic feel",
 			},
 			{
 				Name: "verse_2",
-				Key:  "C",
+				Key:  sampleKey(sampleTonic),
 				Body: "**Lyrics:** Second verse with different lyrics\nBut same chord progression as verse 1\n\n**Notes:** More intensity than verse 1, add guitar fills, stronger vocal delivery",
 			},
 			{
 				Name: "bridge",
-				Key:  "Am",
+				Key:  sampleKey(bridgeTonic),
 				Body: "**Lyrics:** Bridge section changes the mood\nDifferent chord progression here\n\n**Notes:** Different key, emotional intensity, fingerpicking, dramatic pause",
 			},
 			{
 				Name: "outro",
-				Key:  "C",
+				Key:  sampleKey(sampleTonic),
 				Body: "**Lyrics:** Final lyrics for the outro\nEnding with a gentle fade\n\n**Notes:** Gradual fade out, sustained chords, soft ending",
 			},
 		},
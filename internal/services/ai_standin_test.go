@@ -0,0 +1,169 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+// newAIStandInServer starts a local HTTP server that speaks the OpenAI chat
+// completions API and the Anthropic Messages API, answering with the given
+// provider. It is closed when the test ends.
+func newAIStandInServer(t *testing.T, provider AIProvider) *httptest.Server {
+	return newAIServer(t, aiStandInHandler(provider))
+}
+
+// aiStandInHandler serves the OpenAI chat completions API at
+// /v1/chat/completions and the Anthropic Messages API at /v1/messages,
+// answering with the given provider
+func aiStandInHandler(provider AIProvider) http.HandlerFunc {
+	mux := http.NewServeMux()
+
+	mux.HandleFunc("POST /v1/chat/completions", func(w http.ResponseWriter, r *http.Request) {
+		var body openAIRequest
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+			http.Error(w, "Invalid request body", http.StatusBadRequest)
+			return
+		}
+		req := &CompletionRequest{MaxTokens: body.MaxTokens}
+		req.JSON = body.ResponseFormat != nil && body.ResponseFormat.Type == "json_object"
+		for _, message := range body.Messages {
+			switch message.Role {
+			case "system":
+				req.System = message.Content
+			case "user":
+				req.Prompt = message.Content
+			}
+		}
+
+		if body.Stream {
+			stream := NewEventStream(w)
+			resp, err := streamCompletion(r.Context(), provider, req, func(delta string) error {
+				return stream.Send("", openAIResponse{Choices: []openAIChoice{{Delta: openAIMessage{Content: delta}}}})
+			})
+			if err != nil {
+				return
+			}
+			stream.Send("", openAIResponse{Model: resp.Model, Usage: &openAIUsage{PromptTokens: resp.InputTokens, CompletionTokens: resp.OutputTokens}})
+			stream.SendData("", "[DONE]")
+			return
+		}
+
+		resp, err := provider.Complete(r.Context(), req)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+
+		var out openAIResponse
+		out.Model = resp.Model
+		out.Choices = []openAIChoice{{Message: openAIMessage{Role: "assistant", Content: resp.Content}}}
+		out.Usage = &openAIUsage{PromptTokens: resp.InputTokens, CompletionTokens: resp.OutputTokens}
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(out)
+	})
+
+	mux.HandleFunc("POST /v1/messages", func(w http.ResponseWriter, r *http.Request) {
+		var body anthropicRequest
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+			http.Error(w, "Invalid request body", http.StatusBadRequest)
+			return
+		}
+		req := &CompletionRequest{System: body.System, MaxTokens: body.MaxTokens}
+		prefill := ""
+		for _, message := range body.Messages {
+			switch message.Role {
+			case "user":
+				req.Prompt = message.Content
+			case "assistant":
+				prefill = message.Content
+			}
+		}
+		// A prefilled reply asks for JSON; the client already has the prefill, so
+		// it is left out of the answer
+		req.JSON = prefill == anthropicJSONPrefill
+		trimPrefill := func(content string) string {
+			trimmed := strings.TrimPrefix(content, prefill)
+			prefill = ""
+			return trimmed
+		}
+
+		if body.Stream {
+			stream := NewEventStream(w)
+			stream.Send("message_start", anthropicStreamEvent{Type: "message_start", Message: &anthropicResponse{Model: "fake"}})
+			resp, err := streamCompletion(r.Context(), provider, req, func(delta string) error {
+				return stream.Send("content_block_delta", anthropicStreamEvent{Type: "content_block_delta", Delta: &anthropicContentBlock{Type: "text_delta", Text: trimPrefill(delta)}})
+			})
+			if err != nil {
+				return
+			}
+			stream.Send("message_delta", anthropicStreamEvent{Type: "message_delta", Usage: &anthropicUsage{OutputTokens: resp.OutputTokens}})
+			stream.Send("message_stop", anthropicStreamEvent{Type: "message_stop"})
+			return
+		}
+
+		resp, err := provider.Complete(r.Context(), req)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+
+		var out anthropicResponse
+		out.Model = resp.Model
+		out.Content = []anthropicContentBlock{{Type: "text", Text: trimPrefill(resp.Content)}}
+		out.Usage.InputTokens = resp.InputTokens
+		out.Usage.OutputTokens = resp.OutputTokens
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(out)
+	})
+
+	return mux.ServeHTTP
+}
+
+// failingProvider is an AI provider whose every request fails
+type failingProvider struct {
+	err error
+}
+
+// Name identifies the failing provider
+func (p failingProvider) Name() string {
+	return "failing"
+}
+
+// Complete returns the provider's error
+func (p failingProvider) Complete(ctx context.Context, req *CompletionRequest) (*CompletionResponse, error) {
+	return nil, p.err
+}
+
+// newAIServer starts a local HTTP server that answers every request with the
+// handler. It is closed when the test ends.
+func newAIServer(t *testing.T, handler http.HandlerFunc) *httptest.Server {
+	server := httptest.NewServer(handler)
+	t.Cleanup(server.Close)
+	return server
+}
+
+// hangingHandler answers no request until the client gives up on it
+func hangingHandler(w http.ResponseWriter, r *http.Request) {
+	// The server notices the client hanging up once the body has been read
+	io.Copy(io.Discard, r.Body)
+	select {
+	case <-r.Context().Done():
+	case <-time.After(5 * time.Second):
+	}
+}
+
+// collectDeltas returns an onDelta callback that keeps every delta it receives
+func collectDeltas(deltas *[]string) func(delta string) error {
+	return func(delta string) error {
+		*deltas = append(*deltas, delta)
+		return nil
+	}
+}
@@ -0,0 +1,293 @@
+package services
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// maxStreamLine bounds a single line of a server-sent event stream
+const maxStreamLine = 1 << 20
+
+// streamPreviewInterval is how often the preview of a streamed reply is rebuilt
+// while no section has been completed
+const streamPreviewInterval = 250 * time.Millisecond
+
+// newStreamingClient creates an HTTP client for streamed replies. The timeout
+// bounds the wait for the reply to start rather than the whole reply, which can
+// take much longer to arrive.
+func newStreamingClient(timeout time.Duration) *http.Client {
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.ResponseHeaderTimeout = timeout
+	return &http.Client{Transport: transport}
+}
+
+// EventStream writes server-sent events to a response as they are produced
+type EventStream struct {
+	w       http.ResponseWriter
+	flusher http.Flusher
+}
+
+// NewEventStream sends the headers of a text/event-stream response and returns
+// a stream writing its events
+func NewEventStream(w http.ResponseWriter) *EventStream {
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.WriteHeader(http.StatusOK)
+	flusher, _ := w.(http.Flusher)
+	return &EventStream{w: w, flusher: flusher}
+}
+
+// Send writes an event with the value as JSON data
+func (s *EventStream) Send(event string, value any) error {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return err
+	}
+	return s.SendData(event, string(data))
+}
+
+// SendData writes an event with the given single-line data and flushes it
+func (s *EventStream) SendData(event, data string) error {
+	if event != "" {
+		if _, err := fmt.Fprintf(s.w, "event: %s\n", event); err != nil {
+			return err
+		}
+	}
+	if _, err := fmt.Fprintf(s.w, "data: %s\n\n", data); err != nil {
+		return err
+	}
+	if s.flusher != nil {
+		s.flusher.Flush()
+	}
+	return nil
+}
+
+// readServerSentEvents reads a text/event-stream body and calls onEvent with the
+// name and data of each event. Events without a name are reported as "message".
+func readServerSentEvents(body io.Reader, onEvent func(event, data string) error) error {
+	scanner := bufio.NewScanner(body)
+	scanner.Buffer(make([]byte, 0, 64*1024), maxStreamLine)
+
+	event := ""
+	var data []string
+	dispatch := func() error {
+		if len(data) == 0 {
+			event = ""
+			return nil
+		}
+		name := event
+		if name == "" {
+			name = "message"
+		}
+		err := onEvent(name, strings.Join(data, "\n"))
+		event, data = "", nil
+		return err
+	}
+
+	for scanner.Scan() {
+		line := scanner.Text()
+		if line == "" {
+			if err := dispatch(); err != nil {
+				return err
+			}
+			continue
+		}
+		field, value, _ := strings.Cut(line, ":")
+		value = strings.TrimPrefix(value, " ")
+		switch field {
+		case "event":
+			event = value
+		case "data":
+			data = append(data, value)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return fmt.Errorf("failed to read event stream: %w", err)
+	}
+	return dispatch()
+}
+
+// StreamSongContent generates song content like GenerateSongContent, calling
+// onPreview with the content rendered from the part of the reply received so far
+// as its sections arrive. An error from onPreview, or cancelling the context, aborts
+// the request to the provider.
+func (s *AIService) StreamSongContent(ctx context.Context, req *SongContentRequest, onPreview func(content string) error) (*SongContentResponse, error) {
+	// If no AI provider is configured, return sample data
+	if s.provider == nil {
+		resp := songContentResponse(s.generateSampleSections(req.SongTitle, req.Artist, req.Key), req.Key)
+		if err := onPreview(resp.Content); err != nil {
+			return nil, err
+		}
+		return resp, nil
+	}
+
+	prompt, err := songSectionsPrompt(req)
+	if err != nil {
+		return nil, err
+	}
+	completion := &CompletionRequest{
+		System: songSectionsSystemPrompt,
+		Prompt: prompt,
+		JSON:   true,
+	}
+
+	// A cached reply is shown at once
+	cacheKey := s.songContentCacheKey(completion, req)
+	if cached := s.cachedCompletion(cacheKey); cached != nil {
+		if sections, err := ParseAIGenerationResponse(cached.Content); err == nil {
+			resp := songContentResponse(sections, req.Key)
+			if err := onPreview(resp.Content); err != nil {
+				return nil, err
+			}
+			s.recordUsage(req, cached, true)
+			return resp, nil
+		}
+	}
+
+	if err := s.CheckAIQuota(req.BandID, req.UserID); err != nil {
+		return nil, err
+	}
+
+	var reply strings.Builder
+	lastPreview := ""
+	var lastParsed time.Time
+	resp, err := streamCompletion(ctx, s.provider, completion, func(delta string) error {
+		reply.WriteString(delta)
+
+		// Reading the preview parses the whole reply so far, so it is only done
+		// when a section may have been closed or after a pause since the last one
+		if !strings.Contains(delta, "}") && time.Since(lastParsed) < streamPreviewInterval {
+			return nil
+		}
+		lastParsed = time.Now()
+		partial := partialAIGenerationResponse(reply.String())
+		if partial == nil {
+			return nil
+		}
+		preview := partial.Markdown(req.Key)
+		if preview == lastPreview {
+			return nil
+		}
+		lastPreview = preview
+		return onPreview(preview)
+	})
+	if err == nil {
+		// The reply's tokens were used even when it is dropped below
+		s.recordUsage(req, resp, false)
+
+		// A reply that finished as the request was cancelled is dropped too
+		err = ctx.Err()
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate song content with %s: %w", s.provider.Name(), err)
+	}
+
+	sections, err := ParseAIGenerationResponse(resp.Content)
+	if err != nil {
+		return nil, fmt.Errorf("invalid song content from %s: %w", s.provider.Name(), err)
+	}
+	s.cacheCompletion(cacheKey, resp)
+
+	return songContentResponse(sections, req.Key), nil
+}
+
+// partialAIGenerationResponse reads the sections from the start of a JSON reply
+// that is still arriving, or returns nil when none can be read yet. Sections whose
+// name hasn't arrived are left out.
+func partialAIGenerationResponse(content string) *AIGenerationResponse {
+	start := strings.Index(content, "{")
+	if start < 0 {
+		return nil
+	}
+	object := content[start:]
+
+	// Close the JSON as it stands; when that isn't valid, such as after a key
+	// without its value, back up to the last comma and try again
+	cuts := partialJSONCuts(object)
+	for attempt := 0; attempt < 4; attempt++ {
+		var resp AIGenerationResponse
+		if json.Unmarshal([]byte(closePartialJSON(object)), &resp) == nil {
+			sections := resp.Sections[:0]
+			for _, section := range resp.Sections {
+				if strings.TrimSpace(section.Name) != "" {
+					sections = append(sections, section)
+				}
+			}
+			if len(sections) == 0 {
+				return nil
+			}
+			resp.Sections = sections
+			return &resp
+		}
+		if len(cuts) == 0 {
+			return nil
+		}
+		object = object[:cuts[len(cuts)-1]]
+		cuts = cuts[:len(cuts)-1]
+	}
+	return nil
+}
+
+// partialJSONCuts returns the offsets of the commas outside strings in a JSON prefix
+func partialJSONCuts(prefix string) []int {
+	var cuts []int
+	inString, escaped := false, false
+	for i := 0; i < len(prefix); i++ {
+		c := prefix[i]
+		switch {
+		case escaped:
+			escaped = false
+		case inString && c == '\\':
+			escaped = true
+		case c == '"':
+			inString = !inString
+		case !inString && c == ',':
+			cuts = append(cuts, i)
+		}
+	}
+	return cuts
+}
+
+// closePartialJSON terminates the open string, objects and arrays of a JSON prefix
+func closePartialJSON(prefix string) string {
+	var open []byte
+	inString, escaped := false, false
+	for i := 0; i < len(prefix); i++ {
+		c := prefix[i]
+		switch {
+		case escaped:
+			escaped = false
+		case inString && c == '\\':
+			escaped = true
+		case c == '"':
+			inString = !inString
+		case inString:
+		case c == '{':
+			open = append(open, '}')
+		case c == '[':
+			open = append(open, ']')
+		case (c == '}' || c == ']') && len(open) > 0:
+			open = open[:len(open)-1]
+		}
+	}
+
+	closed := prefix
+	if escaped {
+		closed = closed[:len(closed)-1]
+	}
+	if inString {
+		closed += `"`
+	}
+	closed = strings.TrimRight(closed, " \t\r\n")
+	closed = strings.TrimSuffix(closed, ",")
+	for i := len(open) - 1; i >= 0; i-- {
+		closed += string(open[i])
+	}
+	return closed
+}
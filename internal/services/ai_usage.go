@@ -0,0 +1,222 @@
+package services
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/nahue/setlist_manager/internal/store"
+)
+
+// DefaultAICacheTTL is how long replies are reused when AI_CACHE_TTL is not set
+const DefaultAICacheTTL = 30 * 24 * time.Hour
+
+// recentAIUsageLimit is how many requests the usage page lists
+const recentAIUsageLimit = 50
+
+// ErrAIQuotaExceeded is returned when a band or user has used up its monthly AI
+// tokens; the error message says which and when the quota resets
+var ErrAIQuotaExceeded = errors.New("monthly AI quota exceeded")
+
+// setUsageLimits sets how long replies are cached, the monthly token quotas and
+// the token prices from the config
+func (s *AIService) setUsageLimits(cfg *AIConfig) {
+	s.cacheTTL = cfg.CacheTTL
+	s.bandMonthlyTokens = cfg.BandMonthlyTokens
+	s.userMonthlyTokens = cfg.UserMonthlyTokens
+	s.inputCostPerMillion = cfg.InputCostPerMillion
+	s.outputCostPerMillion = cfg.OutputCostPerMillion
+}
+
+// monthStart returns the start of the month quotas are counted in
+func monthStart(now time.Time) time.Time {
+	return time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, now.Location())
+}
+
+// CheckAIQuota returns an error wrapping ErrAIQuotaExceeded when the band or the
+// user has used up its tokens for the month
+func (s *AIService) CheckAIQuota(bandID, userID string) error {
+	if s.usageDB == nil {
+		return nil
+	}
+	since := monthStart(time.Now())
+	resets := since.AddDate(0, 1, 0).Format("2006-01-02")
+
+	if s.bandMonthlyTokens > 0 && bandID != "" {
+		totals, err := s.usageDB.GetBandAIUsageTotals(bandID, since)
+		if err != nil {
+			return err
+		}
+		if totals.Tokens() >= s.bandMonthlyTokens {
+			return fmt.Errorf("%w: the band has used %d of its %d tokens this month; the quota resets on %s", ErrAIQuotaExceeded, totals.Tokens(), s.bandMonthlyTokens, resets)
+		}
+	}
+	if s.userMonthlyTokens > 0 && userID != "" {
+		totals, err := s.usageDB.GetUserAIUsageTotals(userID, since)
+		if err != nil {
+			return err
+		}
+		if totals.Tokens() >= s.userMonthlyTokens {
+			return fmt.Errorf("%w: you have used %d of your %d tokens this month; the quota resets on %s", ErrAIQuotaExceeded, totals.Tokens(), s.userMonthlyTokens, resets)
+		}
+	}
+	return nil
+}
+
+// songContentCacheKey identifies a request by the provider and model, the prompt
+// and the song fields it was built from, so any change to them misses the cache
+func (s *AIService) songContentCacheKey(completion *CompletionRequest, req *SongContentRequest) string {
+	prompt := sha256.Sum256([]byte(completion.System + "\x00" + completion.Prompt))
+	metadata, _ := json.Marshal(req)
+
+	key := sha256.New()
+	key.Write([]byte(s.provider.Name()))
+	key.Write([]byte{0})
+	key.Write([]byte(hex.EncodeToString(prompt[:])))
+	key.Write([]byte{0})
+	key.Write(metadata)
+	return hex.EncodeToString(key.Sum(nil))
+}
+
+// cachedCompletion returns the reply cached for a request, or nil when caching is
+// off or there is none. A cache that can't be read is skipped.
+func (s *AIService) cachedCompletion(cacheKey string) *CompletionResponse {
+	if s.usageDB == nil || s.cacheTTL <= 0 {
+		return nil
+	}
+	entry, err := s.usageDB.GetAICacheEntry(cacheKey, time.Now().Add(-s.cacheTTL))
+	if err != nil {
+		log.Printf("Error reading AI cache: %v", err)
+		return nil
+	}
+	if entry == nil {
+		return nil
+	}
+	return &CompletionResponse{
+		Content:      entry.Content,
+		Model:        entry.Model,
+		InputTokens:  entry.InputTokens,
+		OutputTokens: entry.OutputTokens,
+	}
+}
+
+// cacheCompletion keeps a valid reply for reuse and drops the expired ones
+func (s *AIService) cacheCompletion(cacheKey string, resp *CompletionResponse) {
+	if s.usageDB == nil || s.cacheTTL <= 0 {
+		return
+	}
+	err := s.usageDB.SaveAICacheEntry(cacheKey, &store.AICacheEntry{
+		Provider:     s.provider.Name(),
+		Model:        resp.Model,
+		Content:      resp.Content,
+		InputTokens:  resp.InputTokens,
+		OutputTokens: resp.OutputTokens,
+	})
+	if err != nil {
+		log.Printf("Error saving AI cache entry: %v", err)
+		return
+	}
+	if err := s.usageDB.CleanupExpiredAICache(time.Now().Add(-s.cacheTTL)); err != nil {
+		log.Printf("Error cleaning up AI cache: %v", err)
+	}
+}
+
+// recordUsage records the tokens a request used, for the band and user it was
+// made for. Usage that can't be recorded is logged rather than failing the request.
+func (s *AIService) recordUsage(req *SongContentRequest, resp *CompletionResponse, cached bool) {
+	if s.usageDB == nil || req.BandID == "" || req.UserID == "" {
+		return
+	}
+	model := resp.Model
+	if model == "" {
+		model = s.provider.Name()
+	}
+	role := req.Role
+	if role == "" {
+		role = PromptRoleMain
+	}
+	err := s.usageDB.RecordAIUsage(&store.AIUsage{
+		BandID:       req.BandID,
+		UserID:       req.UserID,
+		SongID:       req.SongID,
+		Role:         role,
+		Provider:     s.provider.Name(),
+		Model:        model,
+		InputTokens:  resp.InputTokens,
+		OutputTokens: resp.OutputTokens,
+		Cached:       cached,
+	})
+	if err != nil {
+		log.Printf("Error recording AI usage: %v", err)
+	}
+}
+
+// AIUsageReport summarizes a band's AI usage for the current month, with the
+// share of the user viewing it
+type AIUsageReport struct {
+	Since     time.Time
+	ResetsOn  time.Time
+	Band      *store.AIUsageTotals
+	User      *store.AIUsageTotals
+	ByUser    []*store.AIUserUsage
+	Recent    []*store.AIUsage
+	BandQuota int
+	UserQuota int
+
+	inputCostPerMillion  float64
+	outputCostPerMillion float64
+}
+
+// HasPrices reports whether token prices are configured, so costs can be shown
+func (r *AIUsageReport) HasPrices() bool {
+	return r.inputCostPerMillion > 0 || r.outputCostPerMillion > 0
+}
+
+// Cost estimates the price in USD of the tokens sent to the provider
+func (r *AIUsageReport) Cost(totals *store.AIUsageTotals) float64 {
+	return float64(totals.InputTokens)*r.inputCostPerMillion/1e6 + float64(totals.OutputTokens)*r.outputCostPerMillion/1e6
+}
+
+// RequestCost estimates the price in USD of one request, nothing when it was cached
+func (r *AIUsageReport) RequestCost(usage *store.AIUsage) float64 {
+	if usage.Cached {
+		return 0
+	}
+	return r.Cost(&store.AIUsageTotals{InputTokens: usage.InputTokens, OutputTokens: usage.OutputTokens})
+}
+
+// AIUsageReport loads a band's AI usage for the current month
+func (s *AIService) AIUsageReport(bandID, userID string) (*AIUsageReport, error) {
+	report := &AIUsageReport{
+		Since:                monthStart(time.Now()),
+		BandQuota:            s.bandMonthlyTokens,
+		UserQuota:            s.userMonthlyTokens,
+		Band:                 &store.AIUsageTotals{},
+		User:                 &store.AIUsageTotals{},
+		inputCostPerMillion:  s.inputCostPerMillion,
+		outputCostPerMillion: s.outputCostPerMillion,
+	}
+	report.ResetsOn = report.Since.AddDate(0, 1, 0)
+	if s.usageDB == nil {
+		return report, nil
+	}
+
+	var err error
+	if report.Band, err = s.usageDB.GetBandAIUsageTotals(bandID, report.Since); err != nil {
+		return nil, err
+	}
+	if report.User, err = s.usageDB.GetUserAIUsageTotals(userID, report.Since); err != nil {
+		return nil, err
+	}
+	if report.ByUser, err = s.usageDB.GetBandAIUsageByUser(bandID, report.Since); err != nil {
+		return nil, err
+	}
+	if report.Recent, err = s.usageDB.GetRecentAIUsage(bandID, recentAIUsageLimit); err != nil {
+		return nil, err
+	}
+	return report, nil
+}
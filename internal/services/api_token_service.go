@@ -0,0 +1,146 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/nahue/setlist_manager/internal/app/shared/types"
+	"github.com/nahue/setlist_manager/internal/store"
+)
+
+// Scopes of a personal access token: read allows GET requests to the JSON
+// API and write allows the requests that change something
+const (
+	APITokenScopeRead  = "read"
+	APITokenScopeWrite = "write"
+)
+
+// APITokenPrefix starts every personal access token, so they are easy to
+// recognize in scripts and configuration files
+const APITokenPrefix = "slm_"
+
+// APITokenExpirations are the lifetimes, in days, a token can be created with
+var APITokenExpirations = []int{7, 30, 90, 365}
+
+// Errors returned for personal access tokens that can't be created or used
+var (
+	ErrAPITokenNameRequired = errors.New("token name is required")
+	ErrInvalidAPITokenScope = errors.New("choose at least one valid scope")
+	ErrInvalidAPITokenTTL   = errors.New("invalid token expiration")
+	ErrAPITokenNotFound     = errors.New("API token not found")
+	ErrInvalidAPIToken      = errors.New("invalid, expired or revoked API token")
+)
+
+// APITokenService creates, checks and revokes the personal access tokens that
+// let scripts call the JSON API with an Authorization: Bearer header
+type APITokenService struct {
+	db *store.SQLiteAuthStore
+}
+
+// NewAPITokenService creates a new API token service
+func NewAPITokenService(db *store.SQLiteAuthStore) *APITokenService {
+	return &APITokenService{
+		db: db,
+	}
+}
+
+// Create creates a token for the user that expires after the given number of
+// days. The token itself is returned only here; just its hash is stored.
+func (s *APITokenService) Create(userID, name string, scopes []string, days int) (string, *store.APIToken, error) {
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return "", nil, ErrAPITokenNameRequired
+	}
+	if len(scopes) == 0 {
+		return "", nil, ErrInvalidAPITokenScope
+	}
+	for _, scope := range scopes {
+		if scope != APITokenScopeRead && scope != APITokenScopeWrite {
+			return "", nil, ErrInvalidAPITokenScope
+		}
+	}
+	if !slices.Contains(APITokenExpirations, days) {
+		return "", nil, ErrInvalidAPITokenTTL
+	}
+
+	// Generate the token and hash it for storage
+	token := APITokenPrefix + generateRandomToken()
+	tokenHash := hashToken(token)
+
+	expiresAt := time.Now().Add(time.Duration(days) * 24 * time.Hour)
+	apiToken, err := s.db.CreateAPIToken(userID, name, tokenHash, scopes, expiresAt)
+	if err != nil {
+		return "", nil, fmt.Errorf("failed to create API token: %w", err)
+	}
+
+	return token, apiToken, nil
+}
+
+// List returns the user's tokens that weren't revoked, expired ones included
+func (s *APITokenService) List(userID string) ([]*store.APIToken, error) {
+	return s.db.GetAPITokensByUser(userID)
+}
+
+// Revoke revokes one of the user's tokens, which stops working right away
+func (s *APITokenService) Revoke(userID, tokenID string) error {
+	revoked, err := s.db.RevokeAPIToken(tokenID, userID)
+	if err != nil {
+		return err
+	}
+	if !revoked {
+		return ErrAPITokenNotFound
+	}
+	return nil
+}
+
+// Authenticate returns the user a token belongs to and the token, and records
+// that it was used
+func (s *APITokenService) Authenticate(token string) (*types.User, *store.APIToken, error) {
+	if !strings.HasPrefix(token, APITokenPrefix) {
+		return nil, nil, ErrInvalidAPIToken
+	}
+
+	apiToken, err := s.db.GetAPITokenByHash(hashToken(token))
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to get API token: %w", err)
+	}
+	if apiToken == nil || apiToken.RevokedAt != nil || time.Now().After(apiToken.ExpiresAt) {
+		return nil, nil, ErrInvalidAPIToken
+	}
+
+	user, err := s.db.GetUserByID(apiToken.UserID)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to get user: %w", err)
+	}
+	if user == nil || !user.IsActive {
+		return nil, nil, ErrInvalidAPIToken
+	}
+
+	if err := s.db.UpdateAPITokenLastUsed(apiToken.ID); err != nil {
+		log.Printf("Warning: failed to update last use of API token %s: %v", apiToken.ID, err)
+		// Don't fail the request for this
+	}
+
+	return &types.User{
+		ID:        user.ID,
+		Email:     user.Email,
+		CreatedAt: user.CreatedAt,
+		LastLogin: user.LastLogin,
+		IsActive:  user.IsActive,
+	}, apiToken, nil
+}
+
+// APITokenAllows reports whether a token's scopes allow a request with the
+// given method
+func APITokenAllows(token *store.APIToken, method string) bool {
+	scope := APITokenScopeWrite
+	if method == http.MethodGet || method == http.MethodHead || method == http.MethodOptions {
+		scope = APITokenScopeRead
+	}
+	return slices.Contains(token.Scopes, scope)
+}
@@ -0,0 +1,443 @@
+package services
+
+import (
+	"archive/zip"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/nahue/setlist_manager/internal/app/shared/types"
+	"github.com/nahue/setlist_manager/internal/store"
+)
+
+// Identifiers written to every band archive manifest
+const (
+	BandArchiveFormat  = "setlist-manager-band"
+	BandArchiveVersion = 1
+)
+
+// Limits applied when reading an archive, so a crafted file cannot exhaust memory
+const (
+	maxArchiveManifestSize = 10 << 20
+	maxArchiveContentSize  = 1 << 20
+)
+
+// BandArchiveManifest is the manifest.json file at the root of a band archive
+type BandArchiveManifest struct {
+	Format     string            `json:"format"`
+	Version    int               `json:"version"`
+	ExportedAt time.Time         `json:"exported_at"`
+	Band       ArchivedBand      `json:"band"`
+	Members    []ArchivedMember  `json:"members"`
+	Songs      []ArchivedSong    `json:"songs"`
+	Setlists   []ArchivedSetlist `json:"setlists"`
+	Gigs       []ArchivedGig     `json:"gigs"`
+}
+
+// ArchivedBand holds the band's own details
+type ArchivedBand struct {
+	Name        string    `json:"name"`
+	Description string    `json:"description"`
+	CreatedAt   time.Time `json:"created_at"`
+}
+
+// ArchivedMember records a band member by email, since user IDs differ between servers
+type ArchivedMember struct {
+	Email    string    `json:"email"`
+	Role     string    `json:"role"`
+	JoinedAt time.Time `json:"joined_at"`
+}
+
+// ArchivedSong holds a song's details; its content is stored in a separate file of the archive
+type ArchivedSong struct {
+	ID              string `json:"id"`
+	Title           string `json:"title"`
+	Artist          string `json:"artist"`
+	Key             string `json:"key"`
+	Tempo           *int   `json:"tempo,omitempty"`
+	DurationSeconds *int   `json:"duration_seconds,omitempty"`
+	Notes           string `json:"notes"`
+	Position        int    `json:"position"`
+	ContentFile     string `json:"content_file,omitempty"`
+}
+
+// ArchivedSetlist holds a setlist and the IDs of its songs in order
+type ArchivedSetlist struct {
+	ID          string   `json:"id"`
+	Name        string   `json:"name"`
+	Description string   `json:"description"`
+	Songs       []string `json:"songs"`
+}
+
+// ArchivedGig holds a gig and its sets in order
+type ArchivedGig struct {
+	ID         string           `json:"id"`
+	Name       string           `json:"name"`
+	Venue      string           `json:"venue"`
+	Date       string           `json:"date"`
+	LoadInTime string           `json:"load_in_time"`
+	StartTime  string           `json:"start_time"`
+	CurfewTime string           `json:"curfew_time"`
+	Notes      string           `json:"notes"`
+	Sets       []ArchivedGigSet `json:"sets"`
+}
+
+// ArchivedGigSet is one set of an archived gig, referring to a setlist of the archive
+type ArchivedGigSet struct {
+	Name         string `json:"name"`
+	SetlistID    string `json:"setlist_id"`
+	BreakMinutes int    `json:"break_minutes"`
+	IsEncore     bool   `json:"is_encore"`
+}
+
+// BandArchiveService exports a band to a zip archive and restores bands from one
+type BandArchiveService struct {
+	bandsDB           *store.SQLiteBandsStore
+	songsDB           *store.SQLiteSongsStore
+	setlistsDB        *store.SQLiteSetlistsStore
+	gigsDB            *store.SQLiteGigsStore
+	invitationService *InvitationService
+}
+
+// NewBandArchiveService creates a new band archive service
+func NewBandArchiveService(bandsDB *store.SQLiteBandsStore, songsDB *store.SQLiteSongsStore, setlistsDB *store.SQLiteSetlistsStore, gigsDB *store.SQLiteGigsStore, invitationService *InvitationService) *BandArchiveService {
+	return &BandArchiveService{
+		bandsDB:           bandsDB,
+		songsDB:           songsDB,
+		setlistsDB:        setlistsDB,
+		gigsDB:            gigsDB,
+		invitationService: invitationService,
+	}
+}
+
+// Export writes a zip archive of the band with a manifest.json describing the band,
+// its members, songs, setlists and gigs, and one markdown file per song's content
+func (s *BandArchiveService) Export(band *store.Band, w io.Writer) error {
+	manifest := &BandArchiveManifest{
+		Format:     BandArchiveFormat,
+		Version:    BandArchiveVersion,
+		ExportedAt: time.Now().UTC(),
+		Band: ArchivedBand{
+			Name:        band.Name,
+			Description: band.Description,
+			CreatedAt:   band.CreatedAt,
+		},
+	}
+
+	members, err := s.bandsDB.GetBandMembers(band.ID)
+	if err != nil {
+		return err
+	}
+	for _, member := range members {
+		manifest.Members = append(manifest.Members, ArchivedMember{
+			Email:    member.User.Email,
+			Role:     member.Role,
+			JoinedAt: member.JoinedAt,
+		})
+	}
+
+	archive := zip.NewWriter(w)
+
+	songs, err := s.songsDB.GetSongsByBand(band.ID)
+	if err != nil {
+		return err
+	}
+	for i, song := range songs {
+		archived := ArchivedSong{
+			ID:              song.ID,
+			Title:           song.Title,
+			Artist:          song.Artist,
+			Key:             song.Key,
+			Tempo:           song.Tempo,
+			DurationSeconds: song.Duration,
+			Notes:           song.Notes,
+			Position:        i + 1,
+		}
+		if song.Content != "" {
+			archived.ContentFile = fmt.Sprintf("songs/%03d-%s.md", i+1, archiveSlug(song.Title))
+			if err := writeArchiveFile(archive, archived.ContentFile, []byte(song.Content), manifest.ExportedAt); err != nil {
+				return err
+			}
+		}
+		manifest.Songs = append(manifest.Songs, archived)
+	}
+
+	setlists, err := s.setlistsDB.GetSetlistsByBand(band.ID)
+	if err != nil {
+		return err
+	}
+	for _, setlist := range setlists {
+		entries, err := s.setlistsDB.GetSetlistSongs(setlist.ID)
+		if err != nil {
+			return err
+		}
+		archived := ArchivedSetlist{
+			ID:          setlist.ID,
+			Name:        setlist.Name,
+			Description: setlist.Description,
+			Songs:       []string{},
+		}
+		for _, entry := range entries {
+			archived.Songs = append(archived.Songs, entry.SongID)
+		}
+		manifest.Setlists = append(manifest.Setlists, archived)
+	}
+
+	gigs, err := s.gigsDB.GetGigsByBand(band.ID)
+	if err != nil {
+		return err
+	}
+	for _, gig := range gigs {
+		sets, err := s.gigsDB.GetGigSets(gig.ID)
+		if err != nil {
+			return err
+		}
+		archived := ArchivedGig{
+			ID:         gig.ID,
+			Name:       gig.Name,
+			Venue:      gig.Venue,
+			Date:       gig.Date,
+			LoadInTime: gig.LoadInTime,
+			StartTime:  gig.StartTime,
+			CurfewTime: gig.CurfewTime,
+			Notes:      gig.Notes,
+			Sets:       []ArchivedGigSet{},
+		}
+		for _, set := range sets {
+			archived.Sets = append(archived.Sets, ArchivedGigSet{
+				Name:         set.Name,
+				SetlistID:    set.SetlistID,
+				BreakMinutes: set.BreakMinutes,
+				IsEncore:     set.IsEncore,
+			})
+		}
+		manifest.Gigs = append(manifest.Gigs, archived)
+	}
+
+	data, err := json.MarshalIndent(manifest, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode manifest: %w", err)
+	}
+	if err := writeArchiveFile(archive, "manifest.json", data, manifest.ExportedAt); err != nil {
+		return err
+	}
+
+	if err := archive.Close(); err != nil {
+		return fmt.Errorf("failed to write archive: %w", err)
+	}
+	return nil
+}
+
+// Restore recreates the band in an archive as a new band owned by the given user.
+// An empty name keeps the archived band's name. When inviteMembers is set, the
+// archived members are invited to the new band with their role; nobody joins
+// it without accepting, since anyone can write an archive.
+func (s *BandArchiveService) Restore(ctx context.Context, r io.ReaderAt, size int64, owner *types.User, name string, inviteMembers bool) (*store.Band, error) {
+	manifest, contents, err := ReadBandArchive(r, size)
+	if err != nil {
+		return nil, err
+	}
+
+	restore := &store.BandRestore{
+		Name:         manifest.Band.Name,
+		Description:  manifest.Band.Description,
+		SetlistSongs: make(map[string][]string),
+		GigSets:      make(map[string][]*store.GigSet),
+	}
+	if name = strings.TrimSpace(name); name != "" {
+		restore.Name = name
+	}
+
+	for _, song := range manifest.Songs {
+		restore.Songs = append(restore.Songs, &store.Song{
+			ID:       song.ID,
+			Title:    song.Title,
+			Artist:   song.Artist,
+			Key:      song.Key,
+			Tempo:    song.Tempo,
+			Duration: song.DurationSeconds,
+			Notes:    song.Notes,
+			Content:  contents[song.ContentFile],
+		})
+	}
+
+	for _, setlist := range manifest.Setlists {
+		restore.Setlists = append(restore.Setlists, &store.Setlist{
+			ID:          setlist.ID,
+			Name:        setlist.Name,
+			Description: setlist.Description,
+		})
+		restore.SetlistSongs[setlist.ID] = setlist.Songs
+	}
+
+	for _, gig := range manifest.Gigs {
+		restore.Gigs = append(restore.Gigs, &store.Gig{
+			ID:         gig.ID,
+			Name:       gig.Name,
+			Venue:      gig.Venue,
+			Date:       gig.Date,
+			LoadInTime: gig.LoadInTime,
+			StartTime:  gig.StartTime,
+			CurfewTime: gig.CurfewTime,
+			Notes:      gig.Notes,
+		})
+		for _, set := range gig.Sets {
+			restore.GigSets[gig.ID] = append(restore.GigSets[gig.ID], &store.GigSet{
+				SetlistID:    set.SetlistID,
+				Name:         set.Name,
+				BreakMinutes: set.BreakMinutes,
+				IsEncore:     set.IsEncore,
+			})
+		}
+	}
+
+	band, err := s.bandsDB.RestoreBand(restore, owner.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	// The band is restored even when some invitations can't be sent
+	if inviteMembers {
+		for _, member := range manifest.Members {
+			if strings.EqualFold(member.Email, owner.Email) {
+				continue
+			}
+			_, err := s.invitationService.Invite(ctx, band, owner, member.Email, archivedMemberRole(member.Role))
+			if err != nil {
+				log.Printf("Error inviting %s to restored band %s: %v", member.Email, band.ID, err)
+			}
+		}
+	}
+
+	return band, nil
+}
+
+// archivedMemberRole is the role an archived member is invited with. Bands have
+// a single owner, so archived owners are invited as admins.
+func archivedMemberRole(role string) string {
+	switch role {
+	case store.RoleOwner:
+		return store.RoleAdmin
+	case store.RoleAdmin, store.RoleEditor, store.RoleViewer:
+		return role
+	}
+	// Archives from before roles were split have plain members, who could edit
+	return store.RoleEditor
+}
+
+// ReadBandArchive reads and validates a band archive, returning its manifest and
+// the song content files keyed by their path in the archive
+func ReadBandArchive(r io.ReaderAt, size int64) (*BandArchiveManifest, map[string]string, error) {
+	archive, err := zip.NewReader(r, size)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to open archive: %w", err)
+	}
+
+	files := make(map[string]*zip.File, len(archive.File))
+	for _, file := range archive.File {
+		files[file.Name] = file
+	}
+
+	manifestFile, ok := files["manifest.json"]
+	if !ok {
+		return nil, nil, fmt.Errorf("archive has no manifest.json")
+	}
+	data, err := readArchiveFile(manifestFile, maxArchiveManifestSize)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var manifest BandArchiveManifest
+	if err := json.Unmarshal(data, &manifest); err != nil {
+		return nil, nil, fmt.Errorf("failed to parse manifest: %w", err)
+	}
+	if manifest.Format != BandArchiveFormat {
+		return nil, nil, fmt.Errorf("not a band archive")
+	}
+	if manifest.Version > BandArchiveVersion {
+		return nil, nil, fmt.Errorf("unsupported archive version %d", manifest.Version)
+	}
+	if strings.TrimSpace(manifest.Band.Name) == "" {
+		return nil, nil, fmt.Errorf("archive has no band name")
+	}
+
+	contents := make(map[string]string)
+	for _, song := range manifest.Songs {
+		if strings.TrimSpace(song.Title) == "" {
+			return nil, nil, fmt.Errorf("archive has a song without a title")
+		}
+		if song.ContentFile == "" {
+			continue
+		}
+		file, ok := files[song.ContentFile]
+		if !ok {
+			return nil, nil, fmt.Errorf("archive is missing %s", song.ContentFile)
+		}
+		data, err := readArchiveFile(file, maxArchiveContentSize)
+		if err != nil {
+			return nil, nil, err
+		}
+		contents[song.ContentFile] = string(data)
+	}
+
+	return &manifest, contents, nil
+}
+
+// writeArchiveFile adds a file to the archive
+func writeArchiveFile(archive *zip.Writer, name string, data []byte, modified time.Time) error {
+	w, err := archive.CreateHeader(&zip.FileHeader{
+		Name:     name,
+		Method:   zip.Deflate,
+		Modified: modified,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to add %s to archive: %w", name, err)
+	}
+	if _, err := w.Write(data); err != nil {
+		return fmt.Errorf("failed to write %s to archive: %w", name, err)
+	}
+	return nil
+}
+
+// readArchiveFile reads a file from the archive, failing when it is larger than limit
+func readArchiveFile(file *zip.File, limit int64) ([]byte, error) {
+	rc, err := file.Open()
+	if err != nil {
+		return nil, fmt.Errorf("failed to open %s: %w", file.Name, err)
+	}
+	defer rc.Close()
+
+	data, err := io.ReadAll(io.LimitReader(rc, limit+1))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", file.Name, err)
+	}
+	if int64(len(data)) > limit {
+		return nil, fmt.Errorf("%s is too large", file.Name)
+	}
+	return data, nil
+}
+
+// archiveSlug turns a song title into a file name friendly slug
+func archiveSlug(title string) string {
+	var slug strings.Builder
+	dash := false
+	for _, r := range strings.ToLower(title) {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			slug.WriteRune(r)
+			dash = false
+		} else if !dash && slug.Len() > 0 {
+			slug.WriteRune('-')
+			dash = true
+		}
+	}
+	result := strings.TrimSuffix(slug.String(), "-")
+	if result == "" {
+		return "song"
+	}
+	return result
+}
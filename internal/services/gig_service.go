@@ -0,0 +1,219 @@
+package services
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/nahue/setlist_manager/internal/store"
+)
+
+// DefaultSongDuration is the estimated running time used for songs
+// that have no duration recorded
+const DefaultSongDuration = 4 * time.Minute
+
+// Layouts used to parse the date and times stored on a gig
+const (
+	GigDateLayout = "2006-01-02"
+	GigTimeLayout = "15:04"
+)
+
+// GigService computes the running order and timing of gigs
+type GigService struct {
+	gigsDB     *store.SQLiteGigsStore
+	setlistsDB *store.SQLiteSetlistsStore
+}
+
+// NewGigService creates a new gig service
+func NewGigService(gigsDB *store.SQLiteGigsStore, setlistsDB *store.SQLiteSetlistsStore) *GigService {
+	return &GigService{
+		gigsDB:     gigsDB,
+		setlistsDB: setlistsDB,
+	}
+}
+
+// GigSchedule is the projected timing of a whole gig
+type GigSchedule struct {
+	LoadIn    *time.Time
+	Start     time.Time
+	End       time.Time
+	Curfew    *time.Time
+	Overrun   time.Duration
+	Sets      []*ScheduledSet
+	Estimated bool
+}
+
+// ScheduledSet is the projected timing of one set and the break that follows it
+type ScheduledSet struct {
+	Set      *store.GigSet
+	Start    time.Time
+	End      time.Time
+	BreakEnd time.Time
+	Songs    []*ScheduledSong
+}
+
+// ScheduledSong is the projected timing of one song within a set
+type ScheduledSong struct {
+	Song      *store.Song
+	Start     time.Time
+	End       time.Time
+	Estimated bool
+}
+
+// OverCurfew reports whether the gig is projected to end after the curfew
+func (s *GigSchedule) OverCurfew() bool {
+	return s.Overrun > 0
+}
+
+// GetSchedule loads a gig's sets and songs and computes its schedule
+func (s *GigService) GetSchedule(gig *store.Gig) (*GigSchedule, error) {
+	sets, err := s.gigsDB.GetGigSets(gig.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	songsBySet := make(map[string][]*store.SetlistSong, len(sets))
+	for _, set := range sets {
+		if _, ok := songsBySet[set.SetlistID]; ok {
+			continue
+		}
+		entries, err := s.setlistsDB.GetSetlistSongs(set.SetlistID)
+		if err != nil {
+			return nil, err
+		}
+		songsBySet[set.SetlistID] = entries
+	}
+
+	return BuildGigSchedule(gig, sets, songsBySet)
+}
+
+// BuildGigSchedule projects start and end times for every set and song of a gig.
+// Sets are played in order, each followed by its break except the last one.
+// A curfew earlier than the start time is taken to fall on the following day.
+func BuildGigSchedule(gig *store.Gig, sets []*store.GigSet, songsBySet map[string][]*store.SetlistSong) (*GigSchedule, error) {
+	start, err := parseGigTime(gig.Date, gig.StartTime)
+	if err != nil {
+		return nil, fmt.Errorf("invalid start time: %w", err)
+	}
+
+	schedule := &GigSchedule{Start: start}
+
+	if gig.LoadInTime != "" {
+		loadIn, err := parseGigTime(gig.Date, gig.LoadInTime)
+		if err != nil {
+			return nil, fmt.Errorf("invalid load-in time: %w", err)
+		}
+		// Load-in after the start time means it happened the previous day
+		if loadIn.After(start) {
+			loadIn = loadIn.AddDate(0, 0, -1)
+		}
+		schedule.LoadIn = &loadIn
+	}
+
+	current := start
+	for i, set := range sets {
+		scheduled := &ScheduledSet{Set: set, Start: current}
+
+		for _, entry := range songsBySet[set.SetlistID] {
+			duration := SongDuration(entry.Song)
+			estimated := !hasDuration(entry.Song)
+			if estimated {
+				schedule.Estimated = true
+			}
+			scheduled.Songs = append(scheduled.Songs, &ScheduledSong{
+				Song:      entry.Song,
+				Start:     current,
+				End:       current.Add(duration),
+				Estimated: estimated,
+			})
+			current = current.Add(duration)
+		}
+
+		scheduled.End = current
+		if i < len(sets)-1 {
+			current = current.Add(time.Duration(set.BreakMinutes) * time.Minute)
+		}
+		scheduled.BreakEnd = current
+
+		schedule.Sets = append(schedule.Sets, scheduled)
+	}
+	schedule.End = current
+
+	if gig.CurfewTime != "" {
+		curfew, err := parseGigTime(gig.Date, gig.CurfewTime)
+		if err != nil {
+			return nil, fmt.Errorf("invalid curfew time: %w", err)
+		}
+		if curfew.Before(start) {
+			curfew = curfew.AddDate(0, 0, 1)
+		}
+		schedule.Curfew = &curfew
+		if schedule.End.After(curfew) {
+			schedule.Overrun = schedule.End.Sub(curfew)
+		}
+	}
+
+	return schedule, nil
+}
+
+// GigBook builds the gig book request for a scheduled gig, with each set
+// headed by its projected start time
+func GigBook(bandName string, gig *store.Gig, schedule *GigSchedule) *GigBookPDFRequest {
+	book := &GigBookPDFRequest{
+		BandName: bandName,
+		Title:    gig.Name,
+		Date:     gig.Date,
+		Venue:    gig.Venue,
+	}
+	if date, err := time.Parse(GigDateLayout, gig.Date); err == nil {
+		book.Date = date.Format("Monday, January 2, 2006")
+	}
+
+	for _, scheduled := range schedule.Sets {
+		set := &GigBookSet{
+			Name:     scheduled.Set.Name,
+			Start:    scheduled.Start.Format(GigTimeLayout),
+			IsEncore: scheduled.Set.IsEncore,
+		}
+		for _, song := range scheduled.Songs {
+			set.Songs = append(set.Songs, SongPDFRequest(song.Song))
+		}
+		book.Sets = append(book.Sets, set)
+	}
+
+	return book
+}
+
+// SongDuration returns how long a song is expected to run,
+// falling back to DefaultSongDuration when none is recorded
+func SongDuration(song *store.Song) time.Duration {
+	if !hasDuration(song) {
+		return DefaultSongDuration
+	}
+	return time.Duration(*song.Duration) * time.Second
+}
+
+// hasDuration reports whether a song has a recorded duration
+func hasDuration(song *store.Song) bool {
+	return song.Duration != nil && *song.Duration > 0
+}
+
+// ValidateGigTimes checks that a gig's date and times can be scheduled
+func ValidateGigTimes(date, loadInTime, startTime, curfewTime string) error {
+	if _, err := time.Parse(GigDateLayout, date); err != nil {
+		return fmt.Errorf("invalid date: %w", err)
+	}
+	for _, value := range []string{loadInTime, startTime, curfewTime} {
+		if value == "" {
+			continue
+		}
+		if _, err := time.Parse(GigTimeLayout, value); err != nil {
+			return fmt.Errorf("invalid time %q: %w", value, err)
+		}
+	}
+	return nil
+}
+
+// parseGigTime combines a gig date and an HH:MM time
+func parseGigTime(date, clock string) (time.Time, error) {
+	return time.ParseInLocation(GigDateLayout+" "+GigTimeLayout, date+" "+clock, time.Local)
+}
@@ -0,0 +1,195 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"net/mail"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/nahue/setlist_manager/internal/app/shared/types"
+	"github.com/nahue/setlist_manager/internal/store"
+)
+
+// Defaults for band invitations
+const (
+	InvitationExpiry          = 7 * 24 * time.Hour
+	invitationCleanupInterval = time.Hour
+)
+
+// Errors returned for invitations that can't be sent or answered
+var (
+	ErrInvalidInvitationEmail = errors.New("invalid email address")
+	ErrInvalidInvitationRole  = errors.New("invalid role")
+	ErrAlreadyBandMember      = errors.New("already a member of the band")
+	ErrInvitationPending      = errors.New("there is already a pending invitation for this email")
+	ErrInvitationNotPending   = errors.New("invitation is no longer valid")
+	ErrInvitationWrongUser    = errors.New("invitation was sent to a different email")
+)
+
+// InvitationService sends invitations to join a band by email and handles
+// their answers. Invitations expire after InvitationExpiry.
+type InvitationService struct {
+	bandsDB     *store.SQLiteBandsStore
+	mailService *MailService
+
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+}
+
+// NewInvitationService creates a new invitation service
+func NewInvitationService(bandsDB *store.SQLiteBandsStore, mailService *MailService) *InvitationService {
+	return &InvitationService{
+		bandsDB:     bandsDB,
+		mailService: mailService,
+	}
+}
+
+// InvitationPath is the page where an invitation sent with token is answered
+func InvitationPath(token string) string {
+	return "/invitation?token=" + url.QueryEscape(token)
+}
+
+// Invite invites an email to join a band with a role and emails them the
+// link to answer. The email doesn't need an account: one is created when
+// they sign in through the invitation.
+func (s *InvitationService) Invite(ctx context.Context, band *store.Band, inviter *types.User, email, role string) (*store.BandInvitation, error) {
+	address, err := mail.ParseAddress(strings.TrimSpace(email))
+	if err != nil {
+		return nil, ErrInvalidInvitationEmail
+	}
+	email = strings.ToLower(address.Address)
+	if role == store.RoleOwner || roleRanks[role] == 0 {
+		return nil, ErrInvalidInvitationRole
+	}
+
+	// Check the email isn't in the band or invited already
+	user, err := s.bandsDB.GetUserByEmail(email)
+	if err != nil {
+		return nil, err
+	}
+	if user != nil {
+		member, err := s.bandsDB.GetBandMember(band.ID, user.ID)
+		if err != nil {
+			return nil, err
+		}
+		if member != nil {
+			return nil, ErrAlreadyBandMember
+		}
+	}
+	pending, err := s.bandsDB.GetPendingBandInvitation(band.ID, email)
+	if err != nil {
+		return nil, err
+	}
+	if pending != nil {
+		return nil, ErrInvitationPending
+	}
+
+	token := generateRandomToken()
+	invitation, err := s.bandsDB.CreateBandInvitation(band.ID, email, inviter.ID, role, hashToken(token), time.Now().Add(InvitationExpiry))
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.mailService.SendBandInvitation(ctx, email, band.Name, inviter.Email, s.mailService.Link(InvitationPath(token))); err != nil {
+		return invitation, fmt.Errorf("invitation created but not sent: %w", err)
+	}
+	return invitation, nil
+}
+
+// Resend sends an unanswered invitation again with a new link, restarting its
+// expiry. The link sent before stops working.
+func (s *InvitationService) Resend(ctx context.Context, invitation *store.BandInvitation) error {
+	if invitation.Status != "pending" && invitation.Status != "expired" {
+		return ErrInvitationNotPending
+	}
+
+	token := generateRandomToken()
+	if err := s.bandsDB.RenewBandInvitation(invitation.ID, hashToken(token), time.Now().Add(InvitationExpiry)); err != nil {
+		return err
+	}
+
+	return s.mailService.SendBandInvitation(ctx, invitation.InvitedEmail, invitation.Band.Name, invitation.InvitedByUser.Email, s.mailService.Link(InvitationPath(token)))
+}
+
+// Revoke withdraws an unanswered invitation so its link stops working
+func (s *InvitationService) Revoke(invitation *store.BandInvitation) error {
+	if invitation.Status != "pending" && invitation.Status != "expired" {
+		return ErrInvitationNotPending
+	}
+	return s.bandsDB.RevokeBandInvitation(invitation.ID)
+}
+
+// GetInvitationByToken gets the invitation sent with token, or nil when there is none
+func (s *InvitationService) GetInvitationByToken(token string) (*store.BandInvitation, error) {
+	if token == "" {
+		return nil, nil
+	}
+	return s.bandsDB.GetBandInvitationByTokenHash(hashToken(token))
+}
+
+// Accept adds the user to the band of an invitation sent to their email, and
+// lets the person who sent it know
+func (s *InvitationService) Accept(ctx context.Context, invitation *store.BandInvitation, user *types.User) error {
+	if !strings.EqualFold(invitation.InvitedEmail, user.Email) {
+		return ErrInvitationWrongUser
+	}
+	if !invitation.Open() {
+		return ErrInvitationNotPending
+	}
+	if err := s.bandsDB.AcceptBandInvitation(invitation.ID, user.ID); err != nil {
+		return err
+	}
+
+	// The member is added even when the notification fails
+	link := s.mailService.Link("/band?id=" + invitation.BandID)
+	if err := s.mailService.SendInvitationAccepted(ctx, invitation.InvitedByUser.Email, invitation.Band.Name, user.Email, link); err != nil {
+		log.Printf("Error notifying that invitation %s was accepted: %v", invitation.ID, err)
+	}
+	return nil
+}
+
+// Decline turns down an invitation. Having the link is enough to decline it,
+// so it works without signing in.
+func (s *InvitationService) Decline(invitation *store.BandInvitation) error {
+	if !invitation.Open() {
+		return ErrInvitationNotPending
+	}
+	return s.bandsDB.DeclineBandInvitation(invitation.ID)
+}
+
+// Start marks expired invitations now and then every invitationCleanupInterval
+// until Stop is called
+func (s *InvitationService) Start() {
+	ctx, cancel := context.WithCancel(context.Background())
+	s.cancel = cancel
+	s.wg.Add(1)
+	go func() {
+		defer s.wg.Done()
+		for {
+			expired, err := s.bandsDB.CleanupExpiredInvitations()
+			if err != nil {
+				log.Printf("Error expiring invitations: %v", err)
+			} else if expired > 0 {
+				log.Printf("Expired %d band invitations", expired)
+			}
+			select {
+			case <-ctx.Done():
+				return
+			case <-time.After(invitationCleanupInterval):
+			}
+		}
+	}()
+}
+
+// Stop stops the scheduled expiry of invitations
+func (s *InvitationService) Stop() {
+	if s.cancel != nil {
+		s.cancel()
+	}
+	s.wg.Wait()
+}
@@ -0,0 +1,217 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/nahue/setlist_manager/internal/store"
+)
+
+// Defaults for the job queue
+const (
+	DefaultJobWorkers     = 2
+	DefaultJobMaxAttempts = 3
+	jobPollInterval       = 5 * time.Second
+	jobBaseBackoff        = 10 * time.Second
+	jobMaxBackoff         = 10 * time.Minute
+	jobTimeout            = 5 * time.Minute
+)
+
+// JobResult is the output of a job, such as a rendered PDF, kept for download
+type JobResult struct {
+	ContentType string
+	Filename    string
+	Data        []byte
+}
+
+// JobHandler runs one kind of job. It returns the job's result, or nil when the
+// job has nothing to download.
+type JobHandler func(ctx context.Context, job *store.Job) (*JobResult, error)
+
+// permanentJobError marks a failure that retrying won't fix
+type permanentJobError struct {
+	err error
+}
+
+func (e *permanentJobError) Error() string { return e.err.Error() }
+func (e *permanentJobError) Unwrap() error { return e.err }
+
+// PermanentJobError wraps an error so the job fails without being retried,
+// e.g. when the song it was queued for has been deleted
+func PermanentJobError(err error) error {
+	return &permanentJobError{err: err}
+}
+
+// JobQueue runs queued jobs from the database on a pool of workers. Jobs survive
+// server restarts: queued jobs stay in the database and jobs left running are
+// queued again on start. Failed jobs are retried with exponential backoff.
+type JobQueue struct {
+	jobsDB   *store.SQLiteJobsStore
+	workers  int
+	handlers map[string]JobHandler
+	timeouts map[string]time.Duration
+
+	// claimMu serializes claims so workers don't contend for the database lock
+	claimMu sync.Mutex
+	wake    chan struct{}
+	cancel  context.CancelFunc
+	wg      sync.WaitGroup
+}
+
+// NewJobQueue creates a job queue with the given number of workers
+func NewJobQueue(jobsDB *store.SQLiteJobsStore, workers int) *JobQueue {
+	if workers < 1 {
+		workers = DefaultJobWorkers
+	}
+	return &JobQueue{
+		jobsDB:   jobsDB,
+		workers:  workers,
+		handlers: make(map[string]JobHandler),
+		timeouts: make(map[string]time.Duration),
+		wake:     make(chan struct{}, 1),
+	}
+}
+
+// Register sets the handler that runs jobs of the given kind
+func (q *JobQueue) Register(kind string, handler JobHandler) {
+	q.RegisterWithTimeout(kind, jobTimeout, handler)
+}
+
+// RegisterWithTimeout sets the handler that runs jobs of the given kind, for
+// jobs that may take longer than the default timeout to run
+func (q *JobQueue) RegisterWithTimeout(kind string, timeout time.Duration, handler JobHandler) {
+	q.handlers[kind] = handler
+	q.timeouts[kind] = timeout
+}
+
+// Enqueue queues a job of the given kind for a band, with the payload stored as JSON
+func (q *JobQueue) Enqueue(bandID, kind, title, createdBy string, payload any) (*store.Job, error) {
+	if _, ok := q.handlers[kind]; !ok {
+		return nil, fmt.Errorf("unknown job kind %q", kind)
+	}
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal job payload: %w", err)
+	}
+
+	job, err := q.jobsDB.CreateJob(bandID, kind, title, string(data), createdBy, DefaultJobMaxAttempts)
+	if err != nil {
+		return nil, err
+	}
+
+	// Let an idle worker pick it up without waiting for the next poll
+	select {
+	case q.wake <- struct{}{}:
+	default:
+	}
+	return job, nil
+}
+
+// Start queues again the jobs a previous process left running and starts the workers
+func (q *JobQueue) Start() error {
+	requeued, err := q.jobsDB.RequeueRunningJobs()
+	if err != nil {
+		return err
+	}
+	if requeued > 0 {
+		log.Printf("Requeued %d interrupted jobs", requeued)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	q.cancel = cancel
+	for i := 0; i < q.workers; i++ {
+		q.wg.Add(1)
+		go q.work(ctx)
+	}
+	return nil
+}
+
+// Stop stops the workers and waits for the jobs they are running. Interrupted
+// jobs are queued again on the next start.
+func (q *JobQueue) Stop() {
+	if q.cancel != nil {
+		q.cancel()
+	}
+	q.wg.Wait()
+}
+
+// work runs jobs until the context is cancelled, polling when the queue is empty
+func (q *JobQueue) work(ctx context.Context) {
+	defer q.wg.Done()
+
+	for {
+		q.claimMu.Lock()
+		job, err := q.jobsDB.ClaimNextJob()
+		q.claimMu.Unlock()
+		if err != nil {
+			log.Printf("Error claiming job: %v", err)
+		}
+
+		if job != nil {
+			q.run(ctx, job)
+			continue
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-q.wake:
+		case <-time.After(jobPollInterval):
+		}
+	}
+}
+
+// run runs a claimed job and records its outcome
+func (q *JobQueue) run(ctx context.Context, job *store.Job) {
+	handler, ok := q.handlers[job.Kind]
+	if !ok {
+		q.finish(job, nil, PermanentJobError(fmt.Errorf("unknown job kind %q", job.Kind)))
+		return
+	}
+
+	jobCtx, cancel := context.WithTimeout(ctx, q.timeouts[job.Kind])
+	defer cancel()
+	result, err := handler(jobCtx, job)
+
+	// Leave a job interrupted by shutdown running; it is queued again on the next start
+	if ctx.Err() != nil {
+		return
+	}
+	q.finish(job, result, err)
+}
+
+// finish stores the result of a job, or schedules a retry when it failed
+func (q *JobQueue) finish(job *store.Job, result *JobResult, jobErr error) {
+	var err error
+	var permanent *permanentJobError
+	switch {
+	case jobErr == nil && result == nil:
+		err = q.jobsDB.CompleteJob(job.ID, "", "", nil)
+	case jobErr == nil:
+		err = q.jobsDB.CompleteJob(job.ID, result.ContentType, result.Filename, result.Data)
+	case errors.As(jobErr, &permanent) || job.Attempts >= job.MaxAttempts:
+		log.Printf("Job %s (%s) failed: %v", job.ID, job.Kind, jobErr)
+		err = q.jobsDB.FailJob(job.ID, jobErr.Error())
+	default:
+		log.Printf("Job %s (%s) failed on attempt %d, retrying: %v", job.ID, job.Kind, job.Attempts, jobErr)
+		err = q.jobsDB.RetryJob(job.ID, jobErr.Error(), time.Now().Add(jobBackoff(job.Attempts)))
+	}
+	if err != nil {
+		log.Printf("Error recording job %s outcome: %v", job.ID, err)
+	}
+}
+
+// jobBackoff is the wait before retrying a job that failed the given number of
+// times: doubling from jobBaseBackoff up to jobMaxBackoff
+func jobBackoff(attempts int) time.Duration {
+	backoff := jobBaseBackoff
+	for i := 1; i < attempts && backoff < jobMaxBackoff; i++ {
+		backoff *= 2
+	}
+	return min(backoff, jobMaxBackoff)
+}
@@ -0,0 +1,314 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/nahue/setlist_manager/internal/store"
+	"github.com/nahue/setlist_manager/internal/transpose"
+)
+
+// Job kinds run by the job queue
+const (
+	JobSongContent = "song_content"
+	JobBandContent = "band_content"
+	JobSongPDF     = "song_pdf"
+	JobSetlistPDF  = "setlist_pdf"
+	JobGigPDF      = "gig_pdf"
+)
+
+// SongContentJob is the payload of a JobSongContent job. Role selects a variant
+// such as the drum chart instead of the song's main content.
+type SongContentJob struct {
+	SongID string `json:"song_id"`
+	Role   string `json:"role,omitempty"`
+}
+
+// BandContentJob is the payload of a JobBandContent job. SongIDs selects the
+// songs to generate, or every song without content when empty. Overwrite allows
+// replacing content that was written or edited by hand. Role selects a variant
+// instead of the songs' main content.
+type BandContentJob struct {
+	BandID    string   `json:"band_id"`
+	Role      string   `json:"role,omitempty"`
+	SongIDs   []string `json:"song_ids,omitempty"`
+	Overwrite bool     `json:"overwrite,omitempty"`
+}
+
+// bandContentJobTimeout bounds a batch generation, which runs song by song
+// within the band's rate limit
+const bandContentJobTimeout = time.Hour
+
+// SongPDFJob is the payload of a JobSongPDF job. Key transposes the song when set,
+// from From or the song's own key.
+type SongPDFJob struct {
+	SongID  string `json:"song_id"`
+	Key     string `json:"key,omitempty"`
+	From    string `json:"from,omitempty"`
+	Layout  string `json:"layout,omitempty"`
+	Columns int    `json:"columns,omitempty"`
+}
+
+// SetlistPDFJob is the payload of a JobSetlistPDF job
+type SetlistPDFJob struct {
+	SetlistID string `json:"setlist_id"`
+	Layout    string `json:"layout,omitempty"`
+	Columns   int    `json:"columns,omitempty"`
+}
+
+// GigPDFJob is the payload of a JobGigPDF job
+type GigPDFJob struct {
+	GigID   string `json:"gig_id"`
+	Layout  string `json:"layout,omitempty"`
+	Columns int    `json:"columns,omitempty"`
+}
+
+// jobHandlers runs the app's job kinds
+type jobHandlers struct {
+	bandsDB    *store.SQLiteBandsStore
+	songsDB    *store.SQLiteSongsStore
+	setlistsDB *store.SQLiteSetlistsStore
+	gigsDB     *store.SQLiteGigsStore
+	gigService *GigService
+	aiService  *AIService
+	pdfService *PDFService
+}
+
+// RegisterJobHandlers registers the handlers for AI generation and PDF rendering jobs
+func RegisterJobHandlers(queue *JobQueue, bandsDB *store.SQLiteBandsStore, songsDB *store.SQLiteSongsStore, setlistsDB *store.SQLiteSetlistsStore, gigsDB *store.SQLiteGigsStore, gigService *GigService, aiService *AIService, pdfService *PDFService) {
+	h := &jobHandlers{
+		bandsDB:    bandsDB,
+		songsDB:    songsDB,
+		setlistsDB: setlistsDB,
+		gigsDB:     gigsDB,
+		gigService: gigService,
+		aiService:  aiService,
+		pdfService: pdfService,
+	}
+	queue.Register(JobSongContent, h.generateSongContent)
+	queue.RegisterWithTimeout(JobBandContent, bandContentJobTimeout, h.generateBandContent)
+	queue.Register(JobSongPDF, h.renderSongPDF)
+	queue.Register(JobSetlistPDF, h.renderSetlistPDF)
+	queue.Register(JobGigPDF, h.renderGigPDF)
+}
+
+// decodeJobPayload reads a job's payload; a payload that can't be read never will be
+func decodeJobPayload(job *store.Job, payload any) error {
+	if err := json.Unmarshal([]byte(job.Payload), payload); err != nil {
+		return PermanentJobError(fmt.Errorf("invalid job payload: %w", err))
+	}
+	return nil
+}
+
+// jobPromptRole returns the role a job generates content for, the main content
+// when the payload doesn't name one
+func jobPromptRole(role string) (string, error) {
+	if role == "" {
+		return PromptRoleMain, nil
+	}
+	if !ValidPromptRole(role) {
+		return "", PermanentJobError(fmt.Errorf("unknown role %q", role))
+	}
+	return role, nil
+}
+
+// jobAIError keeps a job that ran out of AI quota from being retried before the
+// quota resets
+func jobAIError(err error) error {
+	if errors.Is(err, ErrAIQuotaExceeded) {
+		return PermanentJobError(err)
+	}
+	return err
+}
+
+// getSong loads the song a job was queued for
+func (h *jobHandlers) getSong(songID string) (*store.Song, error) {
+	song, err := h.songsDB.GetSongByID(songID)
+	if err != nil {
+		return nil, err
+	}
+	if song == nil {
+		return nil, PermanentJobError(fmt.Errorf("song %s not found", songID))
+	}
+	return song, nil
+}
+
+// getBandName loads the name of the band a job was queued for
+func (h *jobHandlers) getBandName(bandID string) (string, error) {
+	band, err := h.bandsDB.GetBandByID(bandID)
+	if err != nil {
+		return "", err
+	}
+	if band == nil {
+		return "", PermanentJobError(fmt.Errorf("band %s not found", bandID))
+	}
+	return band.Name, nil
+}
+
+// generateSongContent generates a song's content with AI and saves it
+func (h *jobHandlers) generateSongContent(ctx context.Context, job *store.Job) (*JobResult, error) {
+	var payload SongContentJob
+	if err := decodeJobPayload(job, &payload); err != nil {
+		return nil, err
+	}
+	song, err := h.getSong(payload.SongID)
+	if err != nil {
+		return nil, err
+	}
+
+	role, err := jobPromptRole(payload.Role)
+	if err != nil {
+		return nil, err
+	}
+	req, err := h.aiService.SongContentRequest(song, role, job.CreatedBy)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := h.aiService.GenerateSongContent(ctx, req)
+	if err != nil {
+		return nil, jobAIError(err)
+	}
+
+	if role != PromptRoleMain {
+		return nil, SaveGeneratedSongVariant(h.songsDB, song, req, resp)
+	}
+	return nil, SaveGeneratedSongContent(h.songsDB, song, resp, job.CreatedBy)
+}
+
+// generateBandContent generates the content of a band's songs with AI, and keeps
+// the outcome of each song as the job's result
+func (h *jobHandlers) generateBandContent(ctx context.Context, job *store.Job) (*JobResult, error) {
+	var payload BandContentJob
+	if err := decodeJobPayload(job, &payload); err != nil {
+		return nil, err
+	}
+	bandName, err := h.getBandName(payload.BandID)
+	if err != nil {
+		return nil, err
+	}
+
+	role, err := jobPromptRole(payload.Role)
+	if err != nil {
+		return nil, err
+	}
+
+	result, err := h.aiService.GenerateBandContent(ctx, h.songsDB, payload.BandID, job.CreatedBy, role, payload.SongIDs, payload.Overwrite)
+	if err != nil {
+		return nil, jobAIError(err)
+	}
+
+	data, err := json.Marshal(result)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal batch result: %w", err)
+	}
+	filename := fmt.Sprintf("%s - contenido IA.json", bandName)
+	return &JobResult{ContentType: "application/json", Filename: filename, Data: data}, nil
+}
+
+// renderSongPDF renders a song's PDF, transposed when the job asks for a key
+func (h *jobHandlers) renderSongPDF(ctx context.Context, job *store.Job) (*JobResult, error) {
+	var payload SongPDFJob
+	if err := decodeJobPayload(job, &payload); err != nil {
+		return nil, err
+	}
+	song, err := h.getSong(payload.SongID)
+	if err != nil {
+		return nil, err
+	}
+
+	req := SongPDFRequest(song)
+	req.Layout, req.Columns = payload.Layout, payload.Columns
+	if payload.Key != "" {
+		from := payload.From
+		if from == "" {
+			from = song.Key
+		}
+		semitones, err := transpose.Interval(from, payload.Key)
+		if err != nil {
+			return nil, PermanentJobError(err)
+		}
+		req.Content = transpose.Content(song.Content, semitones, transpose.UsesFlats(payload.Key))
+		req.Key = payload.Key
+	}
+
+	data, err := h.pdfService.GenerateSongPDF(req)
+	if err != nil {
+		return nil, err
+	}
+
+	filename := fmt.Sprintf("%s - %s.pdf", song.Title, song.Artist)
+	if song.Artist == "" {
+		filename = fmt.Sprintf("%s.pdf", song.Title)
+	}
+	return &JobResult{ContentType: "application/pdf", Filename: filename, Data: data}, nil
+}
+
+// renderSetlistPDF renders a setlist's gig book
+func (h *jobHandlers) renderSetlistPDF(ctx context.Context, job *store.Job) (*JobResult, error) {
+	var payload SetlistPDFJob
+	if err := decodeJobPayload(job, &payload); err != nil {
+		return nil, err
+	}
+	setlist, err := h.setlistsDB.GetSetlistByID(payload.SetlistID)
+	if err != nil {
+		return nil, err
+	}
+	if setlist == nil {
+		return nil, PermanentJobError(fmt.Errorf("setlist %s not found", payload.SetlistID))
+	}
+	bandName, err := h.getBandName(setlist.BandID)
+	if err != nil {
+		return nil, err
+	}
+	entries, err := h.setlistsDB.GetSetlistSongs(setlist.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	book := SetlistBook(bandName, setlist, entries)
+	book.SetLayout(payload.Layout, payload.Columns)
+	data, err := h.pdfService.GenerateGigBookPDF(book)
+	if err != nil {
+		return nil, err
+	}
+
+	filename := fmt.Sprintf("%s - %s.pdf", bandName, setlist.Name)
+	return &JobResult{ContentType: "application/pdf", Filename: filename, Data: data}, nil
+}
+
+// renderGigPDF renders a gig's gig book
+func (h *jobHandlers) renderGigPDF(ctx context.Context, job *store.Job) (*JobResult, error) {
+	var payload GigPDFJob
+	if err := decodeJobPayload(job, &payload); err != nil {
+		return nil, err
+	}
+	gig, err := h.gigsDB.GetGigByID(payload.GigID)
+	if err != nil {
+		return nil, err
+	}
+	if gig == nil {
+		return nil, PermanentJobError(fmt.Errorf("gig %s not found", payload.GigID))
+	}
+	bandName, err := h.getBandName(gig.BandID)
+	if err != nil {
+		return nil, err
+	}
+	schedule, err := h.gigService.GetSchedule(gig)
+	if err != nil {
+		return nil, err
+	}
+
+	book := GigBook(bandName, gig, schedule)
+	book.SetLayout(payload.Layout, payload.Columns)
+	data, err := h.pdfService.GenerateGigBookPDF(book)
+	if err != nil {
+		return nil, err
+	}
+
+	filename := fmt.Sprintf("%s - %s - %s.pdf", bandName, gig.Name, gig.Date)
+	return &JobResult{ContentType: "application/pdf", Filename: filename, Data: data}, nil
+}
@@ -0,0 +1,81 @@
+package services
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"mime"
+	"mime/multipart"
+	"mime/quotedprintable"
+	"net/mail"
+	"net/textproto"
+	"strings"
+	"time"
+)
+
+// Bytes encodes the message as a MIME email with both bodies as alternatives
+func (m *EmailMessage) Bytes() ([]byte, error) {
+	from, err := mail.ParseAddress(m.From)
+	if err != nil {
+		return nil, fmt.Errorf("invalid sender %q: %w", m.From, err)
+	}
+	to, err := mail.ParseAddress(m.To)
+	if err != nil {
+		return nil, fmt.Errorf("invalid recipient %q: %w", m.To, err)
+	}
+
+	var body bytes.Buffer
+	parts := multipart.NewWriter(&body)
+	if err := writeMailPart(parts, "text/plain; charset=utf-8", m.Text); err != nil {
+		return nil, err
+	}
+	if m.HTML != "" {
+		if err := writeMailPart(parts, "text/html; charset=utf-8", m.HTML); err != nil {
+			return nil, err
+		}
+	}
+	if err := parts.Close(); err != nil {
+		return nil, err
+	}
+
+	var b bytes.Buffer
+	fmt.Fprintf(&b, "From: %s\r\n", from.String())
+	fmt.Fprintf(&b, "To: %s\r\n", to.String())
+	fmt.Fprintf(&b, "Subject: %s\r\n", mime.QEncoding.Encode("utf-8", m.Subject))
+	fmt.Fprintf(&b, "Date: %s\r\n", time.Now().Format(time.RFC1123Z))
+	fmt.Fprintf(&b, "Message-ID: <%s@%s>\r\n", generateMessageID(), mailDomain(from.Address))
+	b.WriteString("MIME-Version: 1.0\r\n")
+	fmt.Fprintf(&b, "Content-Type: multipart/alternative; boundary=%q\r\n", parts.Boundary())
+	b.WriteString("\r\n")
+	b.Write(body.Bytes())
+	return b.Bytes(), nil
+}
+
+// writeMailPart adds a quoted-printable encoded part to a multipart body
+func writeMailPart(parts *multipart.Writer, contentType, content string) error {
+	header := textproto.MIMEHeader{}
+	header.Set("Content-Type", contentType)
+	header.Set("Content-Transfer-Encoding", "quoted-printable")
+	part, err := parts.CreatePart(header)
+	if err != nil {
+		return err
+	}
+	encoder := quotedprintable.NewWriter(part)
+	if _, err := io.WriteString(encoder, content); err != nil {
+		return err
+	}
+	return encoder.Close()
+}
+
+// mailDomain returns the domain of an email address, for message IDs
+func mailDomain(address string) string {
+	if i := strings.LastIndex(address, "@"); i >= 0 {
+		return address[i+1:]
+	}
+	return "localhost"
+}
+
+// generateMessageID returns a unique local part for a Message-ID header
+func generateMessageID() string {
+	return fmt.Sprintf("%d.%s", time.Now().UnixNano(), generateRandomToken()[:16])
+}
@@ -0,0 +1,46 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// OutboxMailer doesn't deliver email: it writes each message to a .eml file in a
+// directory and logs it, for development
+type OutboxMailer struct {
+	dir string
+}
+
+// NewOutboxMailer creates a mailer that writes messages to dir
+func NewOutboxMailer(dir string) *OutboxMailer {
+	return &OutboxMailer{dir: dir}
+}
+
+// Name identifies the outbox directory, for logs
+func (m *OutboxMailer) Name() string {
+	return fmt.Sprintf("outbox (%s)", m.dir)
+}
+
+// Send writes the message to the outbox and logs its text, so links in it can be
+// followed from the server log
+func (m *OutboxMailer) Send(ctx context.Context, msg *EmailMessage) error {
+	data, err := msg.Bytes()
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(m.dir, 0755); err != nil {
+		return fmt.Errorf("failed to create outbox directory: %w", err)
+	}
+
+	path := filepath.Join(m.dir, time.Now().Format("20060102-150405.000000")+".eml")
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		return fmt.Errorf("failed to write email to outbox: %w", err)
+	}
+
+	log.Printf("Email to %s saved to %s: %s\n%s", msg.To, path, msg.Subject, msg.Text)
+	return nil
+}
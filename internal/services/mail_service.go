@@ -0,0 +1,301 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	htmltemplate "html/template"
+	"log"
+	"strings"
+	"text/template"
+	"time"
+)
+
+// Backoff between attempts to send a message after a temporary failure
+const (
+	mailBaseBackoff = time.Second
+	mailMaxBackoff  = 30 * time.Second
+)
+
+// MailService renders the app's emails and sends them, retrying temporary failures
+type MailService struct {
+	mailer      Mailer
+	from        string
+	baseURL     string
+	maxAttempts int
+	baseBackoff time.Duration
+}
+
+// NewMailService creates a mail service with the mailer configured in the
+// environment, falling back to the outbox when the configuration is invalid
+func NewMailService() *MailService {
+	baseURL, err := AppBaseURLFromEnv()
+	if err != nil {
+		log.Printf("Warning: %v; emailed links point to %s", err, DefaultAppBaseURL)
+		baseURL = DefaultAppBaseURL
+	}
+	log.Printf("Emailed links point to %s", baseURL)
+
+	cfg, err := MailConfigFromEnv()
+	if err != nil {
+		log.Printf("Warning: invalid mail configuration, writing emails to the outbox: %v", err)
+		return NewMailServiceWithMailer(NewOutboxMailer(DefaultMailOutboxDir), DefaultMailFrom, baseURL)
+	}
+	mailer, err := NewMailer(cfg)
+	if err != nil {
+		log.Printf("Warning: invalid mail configuration, writing emails to the outbox: %v", err)
+		return NewMailServiceWithMailer(NewOutboxMailer(cfg.OutboxDir), cfg.From, baseURL)
+	}
+	log.Printf("Mailer: %s", mailer.Name())
+	service := NewMailServiceWithMailer(mailer, cfg.From, baseURL)
+	service.maxAttempts = cfg.MaxAttempts
+	return service
+}
+
+// NewMailServiceWithMailer creates a mail service that sends email from the
+// given address with the given mailer, with links to the app at baseURL,
+// making up to DefaultMailMaxAttempts attempts per message
+func NewMailServiceWithMailer(mailer Mailer, from, baseURL string) *MailService {
+	return &MailService{
+		mailer:      mailer,
+		from:        from,
+		baseURL:     baseURL,
+		maxAttempts: DefaultMailMaxAttempts,
+		baseBackoff: mailBaseBackoff,
+	}
+}
+
+// Link returns the address of a path in the app, for links in emails. It
+// always uses the configured APP_BASE_URL, never the request's host.
+func (s *MailService) Link(path string) string {
+	return s.baseURL + path
+}
+
+// Send sends a message from the service's address. Temporary failures are
+// retried with exponential backoff until the attempts run out or ctx is done.
+func (s *MailService) Send(ctx context.Context, msg *EmailMessage) error {
+	if msg.From == "" {
+		msg.From = s.from
+	}
+
+	var err error
+	for attempt := 1; ; attempt++ {
+		err = s.mailer.Send(ctx, msg)
+		if err == nil || !TemporaryMailError(err) || attempt >= s.maxAttempts {
+			break
+		}
+		log.Printf("Sending email to %s failed on attempt %d, retrying: %v", msg.To, attempt, err)
+
+		select {
+		case <-ctx.Done():
+			return fmt.Errorf("failed to send email to %s: %w", msg.To, err)
+		case <-time.After(mailBackoff(s.baseBackoff, attempt)):
+		}
+	}
+	if err != nil {
+		return fmt.Errorf("failed to send email to %s: %w", msg.To, err)
+	}
+	return nil
+}
+
+// mailBackoff is the wait after the given failed attempt: doubling from base up
+// to mailMaxBackoff
+func mailBackoff(base time.Duration, attempt int) time.Duration {
+	backoff := base
+	for i := 1; i < attempt && backoff < mailMaxBackoff; i++ {
+		backoff *= 2
+	}
+	return min(backoff, mailMaxBackoff)
+}
+
+// SendMagicLink sends the link that signs a user in
+func (s *MailService) SendMagicLink(ctx context.Context, to, link string) error {
+	return s.sendTemplate(ctx, to, "magic_link", map[string]any{
+		"Link": link,
+	})
+}
+
+// SendBandInvitation invites someone to join a band through the given link
+func (s *MailService) SendBandInvitation(ctx context.Context, to, bandName, invitedBy, link string) error {
+	return s.sendTemplate(ctx, to, "band_invitation", map[string]any{
+		"Band":      bandName,
+		"InvitedBy": invitedBy,
+		"Link":      link,
+	})
+}
+
+// SendInvitationAccepted lets the person who sent an invitation know it was accepted
+func (s *MailService) SendInvitationAccepted(ctx context.Context, to, bandName, member, link string) error {
+	return s.sendTemplate(ctx, to, "invitation_accepted", map[string]any{
+		"Band":   bandName,
+		"Member": member,
+		"Link":   link,
+	})
+}
+
+// SendOwnershipTransfer asks a member to take over a band from its owner
+func (s *MailService) SendOwnershipTransfer(ctx context.Context, to, bandName, owner, link string) error {
+	return s.sendTemplate(ctx, to, "ownership_transfer", map[string]any{
+		"Band":  bandName,
+		"Owner": owner,
+		"Link":  link,
+	})
+}
+
+// SendOwnershipTransferred lets the previous owner of a band know the new one accepted it
+func (s *MailService) SendOwnershipTransferred(ctx context.Context, to, bandName, owner, link string) error {
+	return s.sendTemplate(ctx, to, "ownership_transferred", map[string]any{
+		"Band":  bandName,
+		"Owner": owner,
+		"Link":  link,
+	})
+}
+
+// sendTemplate renders one of the email templates with data and sends it
+func (s *MailService) sendTemplate(ctx context.Context, to, name string, data map[string]any) error {
+	msg, err := RenderEmail(name, data)
+	if err != nil {
+		return err
+	}
+	msg.To = to
+	return s.Send(ctx, msg)
+}
+
+// emailTemplate is the subject and bodies of an email, as Go templates. The
+// HTML body is rendered inside emailLayout.
+type emailTemplate struct {
+	subject string
+	text    string
+	html    string
+}
+
+// emailTemplates are the app's emails by name
+var emailTemplates = map[string]emailTemplate{
+	"magic_link": {
+		subject: `Tu enlace para entrar a Setlist Manager`,
+		text: `Hola,
+
+Usa este enlace para entrar a Setlist Manager:
+
+{{.Link}}
+
+El enlace vence en 15 minutos y sirve una sola vez. Si no lo pediste, ignora este correo.`,
+		html: `<p>Hola,</p>
+<p>Usa este enlace para entrar a Setlist Manager:</p>
+<p><a href="{{.Link}}" style="display:inline-block;padding:10px 18px;background:#4f46e5;color:#ffffff;text-decoration:none;border-radius:6px;">Entrar</a></p>
+<p style="color:#6b7280;font-size:13px;">El enlace vence en 15 minutos y sirve una sola vez. Si no lo pediste, ignora este correo.</p>`,
+	},
+	"band_invitation": {
+		subject: `{{.InvitedBy}} te invitó a {{.Band}}`,
+		text: `Hola,
+
+{{.InvitedBy}} te invitó a unirte a {{.Band}} en Setlist Manager, donde la banda comparte sus canciones, setlists y shows.
+
+Acepta o rechaza la invitación en:
+
+{{.Link}}
+
+La invitación vence en 7 días. Si no esperabas esta invitación, ignora este correo.`,
+		html: `<p>Hola,</p>
+<p><strong>{{.InvitedBy}}</strong> te invitó a unirte a <strong>{{.Band}}</strong> en Setlist Manager, donde la banda comparte sus canciones, setlists y shows.</p>
+<p><a href="{{.Link}}" style="display:inline-block;padding:10px 18px;background:#4f46e5;color:#ffffff;text-decoration:none;border-radius:6px;">Ver invitación</a></p>
+<p style="color:#6b7280;font-size:13px;">La invitación vence en 7 días. Si no esperabas esta invitación, ignora este correo.</p>`,
+	},
+	"invitation_accepted": {
+		subject: `{{.Member}} se unió a {{.Band}}`,
+		text: `Hola,
+
+{{.Member}} aceptó tu invitación y ahora es parte de {{.Band}} en Setlist Manager.
+
+Entra a la banda en:
+
+{{.Link}}`,
+		html: `<p>Hola,</p>
+<p><strong>{{.Member}}</strong> aceptó tu invitación y ahora es parte de <strong>{{.Band}}</strong> en Setlist Manager.</p>
+<p><a href="{{.Link}}" style="display:inline-block;padding:10px 18px;background:#4f46e5;color:#ffffff;text-decoration:none;border-radius:6px;">Ir a la banda</a></p>`,
+	},
+	"ownership_transfer": {
+		subject: `{{.Owner}} quiere transferirte {{.Band}}`,
+		text: `Hola,
+
+{{.Owner}} quiere que seas el propietario de {{.Band}} en Setlist Manager. Si aceptas, pasarás a ser el propietario y {{.Owner}} quedará como administrador.
+
+Acepta o rechaza la transferencia en:
+
+{{.Link}}`,
+		html: `<p>Hola,</p>
+<p><strong>{{.Owner}}</strong> quiere que seas el propietario de <strong>{{.Band}}</strong> en Setlist Manager. Si aceptas, pasarás a ser el propietario y {{.Owner}} quedará como administrador.</p>
+<p><a href="{{.Link}}" style="display:inline-block;padding:10px 18px;background:#4f46e5;color:#ffffff;text-decoration:none;border-radius:6px;">Ver transferencia</a></p>`,
+	},
+	"ownership_transferred": {
+		subject: `{{.Owner}} es el nuevo propietario de {{.Band}}`,
+		text: `Hola,
+
+{{.Owner}} aceptó la transferencia y ahora es el propietario de {{.Band}} en Setlist Manager. Sigues en la banda como administrador.
+
+Entra a la banda en:
+
+{{.Link}}`,
+		html: `<p>Hola,</p>
+<p><strong>{{.Owner}}</strong> aceptó la transferencia y ahora es el propietario de <strong>{{.Band}}</strong> en Setlist Manager. Sigues en la banda como administrador.</p>
+<p><a href="{{.Link}}" style="display:inline-block;padding:10px 18px;background:#4f46e5;color:#ffffff;text-decoration:none;border-radius:6px;">Ir a la banda</a></p>`,
+	},
+}
+
+// emailLayout wraps the HTML body of every email
+const emailLayout = `<!DOCTYPE html>
+<html>
+<body style="margin:0;padding:24px;background:#f3f4f6;font-family:Helvetica,Arial,sans-serif;color:#111827;">
+<div style="max-width:560px;margin:0 auto;background:#ffffff;border-radius:8px;padding:24px;">
+<h1 style="margin:0 0 16px;font-size:18px;color:#4f46e5;">Setlist Manager</h1>
+{{template "content" .}}
+</div>
+</body>
+</html>`
+
+// RenderEmail renders the email with the given name for data, without a recipient
+func RenderEmail(name string, data map[string]any) (*EmailMessage, error) {
+	tmpl, ok := emailTemplates[name]
+	if !ok {
+		return nil, fmt.Errorf("unknown email template %q", name)
+	}
+
+	subject, err := renderTextTemplate(tmpl.subject, data)
+	if err != nil {
+		return nil, fmt.Errorf("failed to render %s email subject: %w", name, err)
+	}
+	text, err := renderTextTemplate(tmpl.text, data)
+	if err != nil {
+		return nil, fmt.Errorf("failed to render %s email: %w", name, err)
+	}
+
+	html, err := htmltemplate.New("layout").Option("missingkey=error").Parse(emailLayout)
+	if err == nil {
+		_, err = html.New("content").Parse(tmpl.html)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("invalid %s email template: %w", name, err)
+	}
+	var b strings.Builder
+	if err := html.Execute(&b, data); err != nil {
+		return nil, fmt.Errorf("failed to render %s email: %w", name, err)
+	}
+
+	return &EmailMessage{
+		Subject: subject,
+		Text:    text,
+		HTML:    b.String(),
+	}, nil
+}
+
+// renderTextTemplate renders a text/template with data
+func renderTextTemplate(body string, data map[string]any) (string, error) {
+	tmpl, err := template.New("email").Option("missingkey=error").Parse(body)
+	if err != nil {
+		return "", err
+	}
+	var b strings.Builder
+	if err := tmpl.Execute(&b, data); err != nil {
+		return "", err
+	}
+	return b.String(), nil
+}
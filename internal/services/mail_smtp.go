@@ -0,0 +1,97 @@
+package services
+
+import (
+	"context"
+	"crypto/tls"
+	"fmt"
+	"net"
+	"net/mail"
+	"net/smtp"
+	"strconv"
+	"time"
+)
+
+// SMTPMailer sends email through an SMTP server, over TLS on port 465 and with
+// STARTTLS elsewhere when the server offers it
+type SMTPMailer struct {
+	cfg MailConfig
+}
+
+// NewSMTPMailer creates a mailer for the SMTP server in the config
+func NewSMTPMailer(cfg MailConfig) *SMTPMailer {
+	if cfg.Timeout == 0 {
+		cfg.Timeout = defaultMailTimeout
+	}
+	return &SMTPMailer{cfg: cfg}
+}
+
+// Name identifies the server, for logs
+func (m *SMTPMailer) Name() string {
+	return fmt.Sprintf("smtp (%s:%d)", m.cfg.SMTPHost, m.cfg.SMTPPort)
+}
+
+// Send delivers a message to the SMTP server
+func (m *SMTPMailer) Send(ctx context.Context, msg *EmailMessage) error {
+	data, err := msg.Bytes()
+	if err != nil {
+		return err
+	}
+	from, err := mail.ParseAddress(msg.From)
+	if err != nil {
+		return fmt.Errorf("invalid sender %q: %w", msg.From, err)
+	}
+	to, err := mail.ParseAddress(msg.To)
+	if err != nil {
+		return fmt.Errorf("invalid recipient %q: %w", msg.To, err)
+	}
+
+	addr := net.JoinHostPort(m.cfg.SMTPHost, strconv.Itoa(m.cfg.SMTPPort))
+	dialer := net.Dialer{Timeout: m.cfg.Timeout}
+	conn, err := dialer.DialContext(ctx, "tcp", addr)
+	if err != nil {
+		return fmt.Errorf("failed to connect to SMTP server: %w", err)
+	}
+	tlsConfig := &tls.Config{ServerName: m.cfg.SMTPHost}
+	implicitTLS := m.cfg.SMTPPort == 465
+	if implicitTLS {
+		conn = tls.Client(conn, tlsConfig)
+	}
+	conn.SetDeadline(time.Now().Add(m.cfg.Timeout))
+
+	client, err := smtp.NewClient(conn, m.cfg.SMTPHost)
+	if err != nil {
+		conn.Close()
+		return fmt.Errorf("failed to start SMTP session: %w", err)
+	}
+	defer client.Close()
+
+	if ok, _ := client.Extension("STARTTLS"); ok && !implicitTLS {
+		if err := client.StartTLS(tlsConfig); err != nil {
+			return fmt.Errorf("failed to start TLS: %w", err)
+		}
+	}
+	if m.cfg.SMTPUsername != "" {
+		auth := smtp.PlainAuth("", m.cfg.SMTPUsername, m.cfg.SMTPPassword, m.cfg.SMTPHost)
+		if err := client.Auth(auth); err != nil {
+			return fmt.Errorf("failed to authenticate with SMTP server: %w", err)
+		}
+	}
+
+	if err := client.Mail(from.Address); err != nil {
+		return fmt.Errorf("SMTP server rejected the sender: %w", err)
+	}
+	if err := client.Rcpt(to.Address); err != nil {
+		return fmt.Errorf("SMTP server rejected the recipient: %w", err)
+	}
+	w, err := client.Data()
+	if err != nil {
+		return fmt.Errorf("SMTP server rejected the message: %w", err)
+	}
+	if _, err := w.Write(data); err != nil {
+		return fmt.Errorf("failed to send message: %w", err)
+	}
+	if err := w.Close(); err != nil {
+		return fmt.Errorf("SMTP server rejected the message: %w", err)
+	}
+	return client.Quit()
+}
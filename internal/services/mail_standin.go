@@ -0,0 +1,218 @@
+package services
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"log"
+	"mime"
+	"net"
+	"net/mail"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// ReceivedEmail is a message delivered to the stand-in SMTP server
+type ReceivedEmail struct {
+	From string
+	To   []string
+	Data []byte
+}
+
+// Subject returns the decoded subject of the message
+func (e *ReceivedEmail) Subject() string {
+	msg, err := mail.ReadMessage(bytes.NewReader(e.Data))
+	if err != nil {
+		return ""
+	}
+	subject, err := new(mime.WordDecoder).DecodeHeader(msg.Header.Get("Subject"))
+	if err != nil {
+		return msg.Header.Get("Subject")
+	}
+	return subject
+}
+
+// SMTPStandInServer is a local SMTP server that accepts every message and keeps
+// it in memory, to exercise the SMTP mailer without a real server. It can be
+// told to refuse messages with a temporary error, to exercise retries.
+type SMTPStandInServer struct {
+	listener net.Listener
+
+	mu       sync.Mutex
+	messages []*ReceivedEmail
+	failures int
+	closed   bool
+	conns    sync.WaitGroup
+}
+
+// NewSMTPStandInServer starts a stand-in SMTP server on a free local port. It
+// logs each message it receives. The caller closes it.
+func NewSMTPStandInServer() (*SMTPStandInServer, error) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		return nil, fmt.Errorf("failed to start stand-in SMTP server: %w", err)
+	}
+	s := &SMTPStandInServer{listener: listener}
+	go s.serve()
+	return s, nil
+}
+
+// Host returns the host the server listens on
+func (s *SMTPStandInServer) Host() string {
+	host, _, _ := net.SplitHostPort(s.listener.Addr().String())
+	return host
+}
+
+// Port returns the port the server listens on
+func (s *SMTPStandInServer) Port() int {
+	_, port, _ := net.SplitHostPort(s.listener.Addr().String())
+	n, _ := strconv.Atoi(port)
+	return n
+}
+
+// Messages returns the messages received so far
+func (s *SMTPStandInServer) Messages() []*ReceivedEmail {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]*ReceivedEmail(nil), s.messages...)
+}
+
+// FailNext makes the server refuse the next n messages with a temporary error
+func (s *SMTPStandInServer) FailNext(n int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.failures = n
+}
+
+// Close stops the server and waits for open connections to finish
+func (s *SMTPStandInServer) Close() error {
+	s.mu.Lock()
+	s.closed = true
+	s.mu.Unlock()
+	err := s.listener.Close()
+	s.conns.Wait()
+	return err
+}
+
+// serve accepts connections until the server is closed
+func (s *SMTPStandInServer) serve() {
+	for {
+		conn, err := s.listener.Accept()
+		if err != nil {
+			s.mu.Lock()
+			closed := s.closed
+			s.mu.Unlock()
+			if !closed {
+				log.Printf("Stand-in SMTP server stopped: %v", err)
+			}
+			return
+		}
+		s.conns.Add(1)
+		go func() {
+			defer s.conns.Done()
+			s.handle(conn)
+		}()
+	}
+}
+
+// handle runs one SMTP session: enough of the protocol for net/smtp to
+// deliver messages, without TLS or authentication
+func (s *SMTPStandInServer) handle(conn net.Conn) {
+	defer conn.Close()
+	r := bufio.NewReader(conn)
+	reply := func(line string) {
+		fmt.Fprintf(conn, "%s\r\n", line)
+	}
+
+	reply("220 localhost stand-in SMTP server")
+	var msg *ReceivedEmail
+	for {
+		line, err := r.ReadString('\n')
+		if err != nil {
+			return
+		}
+		line = strings.TrimRight(line, "\r\n")
+		verb, arg, _ := strings.Cut(line, " ")
+
+		switch strings.ToUpper(verb) {
+		case "EHLO":
+			reply("250-localhost")
+			reply("250 8BITMIME")
+		case "HELO":
+			reply("250 localhost")
+		case "MAIL":
+			msg = &ReceivedEmail{From: smtpPath(arg)}
+			reply("250 OK")
+		case "RCPT":
+			if msg == nil {
+				reply("503 MAIL first")
+				continue
+			}
+			msg.To = append(msg.To, smtpPath(arg))
+			reply("250 OK")
+		case "DATA":
+			if msg == nil || len(msg.To) == 0 {
+				reply("503 RCPT first")
+				continue
+			}
+			reply("354 End data with <CR><LF>.<CR><LF>")
+			data, err := readSMTPData(r)
+			if err != nil {
+				return
+			}
+			msg.Data = data
+			reply(s.receive(msg))
+			msg = nil
+		case "RSET":
+			msg = nil
+			reply("250 OK")
+		case "NOOP":
+			reply("250 OK")
+		case "QUIT":
+			reply("221 Bye")
+			return
+		default:
+			reply("502 Command not implemented")
+		}
+	}
+}
+
+// receive stores a message, or refuses it when a failure was requested,
+// returning the reply for the client
+func (s *SMTPStandInServer) receive(msg *ReceivedEmail) string {
+	s.mu.Lock()
+	if s.failures > 0 {
+		s.failures--
+		s.mu.Unlock()
+		return "451 Temporary failure, try again later"
+	}
+	s.messages = append(s.messages, msg)
+	s.mu.Unlock()
+
+	log.Printf("Stand-in SMTP server received email to %s: %s", strings.Join(msg.To, ", "), msg.Subject())
+	return "250 OK"
+}
+
+// smtpPath extracts the address from a MAIL FROM:<...> or RCPT TO:<...> argument
+func smtpPath(arg string) string {
+	_, path, _ := strings.Cut(arg, ":")
+	path, _, _ = strings.Cut(strings.TrimSpace(path), " ")
+	return strings.Trim(path, "<>")
+}
+
+// readSMTPData reads a message body up to the line with a single dot, undoing
+// the dot stuffing of lines that start with one
+func readSMTPData(r *bufio.Reader) ([]byte, error) {
+	var data bytes.Buffer
+	for {
+		line, err := r.ReadString('\n')
+		if err != nil {
+			return nil, err
+		}
+		if line == ".\r\n" || line == ".\n" {
+			return data.Bytes(), nil
+		}
+		data.WriteString(strings.TrimPrefix(line, "."))
+	}
+}
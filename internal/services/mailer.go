@@ -0,0 +1,185 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/mail"
+	"net/textproto"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Mailer names accepted in MAIL_MAILER
+const (
+	MailerSMTP    = "smtp"
+	MailerOutbox  = "outbox"
+	MailerStandIn = "standin"
+)
+
+// Mailer delivers email messages
+type Mailer interface {
+	// Name identifies the mailer, for logs
+	Name() string
+	// Send delivers a message. Errors worth retrying are reported by
+	// TemporaryMailError.
+	Send(ctx context.Context, msg *EmailMessage) error
+}
+
+// EmailMessage is an email with a plain text and an HTML version of its body
+type EmailMessage struct {
+	From    string
+	To      string
+	Subject string
+	Text    string
+	HTML    string
+}
+
+// MailConfig configures how email is delivered
+type MailConfig struct {
+	Mailer       string
+	From         string
+	SMTPHost     string
+	SMTPPort     int
+	SMTPUsername string
+	SMTPPassword string
+	OutboxDir    string
+	Timeout      time.Duration
+	// MaxAttempts is how many times a message is sent before giving up, when
+	// sending fails with a temporary error
+	MaxAttempts int
+}
+
+// Defaults for email delivery
+const (
+	DefaultMailFrom        = "Setlist Manager <no-reply@localhost>"
+	DefaultMailOutboxDir   = "data/outbox"
+	DefaultMailMaxAttempts = 3
+	defaultSMTPPort        = 587
+	defaultMailTimeout     = 30 * time.Second
+	DefaultAppBaseURL      = "http://localhost:9090"
+)
+
+// AppBaseURLFromEnv reads APP_BASE_URL, the address the app is reached at,
+// e.g. "https://setlists.example.com". Every link in an email starts with it:
+// building links from the request's Host header would let anyone who forges
+// it send a user's login or invitation token to another site.
+func AppBaseURLFromEnv() (string, error) {
+	value := strings.TrimSpace(os.Getenv("APP_BASE_URL"))
+	if value == "" {
+		return DefaultAppBaseURL, nil
+	}
+	u, err := url.Parse(value)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" || u.RawQuery != "" || u.Fragment != "" {
+		return "", fmt.Errorf("invalid APP_BASE_URL %q, use an address like https://setlists.example.com", value)
+	}
+	return strings.TrimRight(value, "/"), nil
+}
+
+// MailConfigFromEnv reads the email configuration from the environment:
+//
+//	MAIL_MAILER        smtp, outbox or standin
+//	MAIL_FROM          sender address, e.g. "Setlist Manager <hola@example.com>"
+//	SMTP_HOST          SMTP server host
+//	SMTP_PORT          SMTP server port, 587 for STARTTLS or 465 for TLS (default 587)
+//	SMTP_USERNAME      SMTP username, when the server requires authentication
+//	SMTP_PASSWORD      SMTP password
+//	MAIL_OUTBOX_DIR    directory the outbox mailer writes .eml files to
+//	MAIL_TIMEOUT       timeout for talking to the SMTP server, e.g. 30s
+//	MAIL_MAX_ATTEMPTS  times a message is sent before giving up on temporary errors
+//
+// When MAIL_MAILER is not set, SMTP is used if SMTP_HOST is configured and the
+// outbox otherwise, so development never sends real email by accident.
+func MailConfigFromEnv() (*MailConfig, error) {
+	cfg := &MailConfig{
+		Mailer:       strings.ToLower(strings.TrimSpace(os.Getenv("MAIL_MAILER"))),
+		From:         strings.TrimSpace(os.Getenv("MAIL_FROM")),
+		SMTPHost:     strings.TrimSpace(os.Getenv("SMTP_HOST")),
+		SMTPPort:     defaultSMTPPort,
+		SMTPUsername: os.Getenv("SMTP_USERNAME"),
+		SMTPPassword: os.Getenv("SMTP_PASSWORD"),
+		OutboxDir:    strings.TrimSpace(os.Getenv("MAIL_OUTBOX_DIR")),
+		Timeout:      defaultMailTimeout,
+		MaxAttempts:  DefaultMailMaxAttempts,
+	}
+
+	if cfg.Mailer == "" {
+		cfg.Mailer = MailerOutbox
+		if cfg.SMTPHost != "" {
+			cfg.Mailer = MailerSMTP
+		}
+	}
+	if cfg.From == "" {
+		cfg.From = DefaultMailFrom
+	}
+	if _, err := mail.ParseAddress(cfg.From); err != nil {
+		return nil, fmt.Errorf("invalid MAIL_FROM %q: %w", cfg.From, err)
+	}
+	if cfg.OutboxDir == "" {
+		cfg.OutboxDir = DefaultMailOutboxDir
+	}
+
+	if value := os.Getenv("SMTP_PORT"); value != "" {
+		port, err := strconv.Atoi(value)
+		if err != nil || port < 1 || port > 65535 {
+			return nil, fmt.Errorf("invalid SMTP_PORT %q", value)
+		}
+		cfg.SMTPPort = port
+	}
+	if value := os.Getenv("MAIL_TIMEOUT"); value != "" {
+		timeout, err := time.ParseDuration(value)
+		if err != nil || timeout <= 0 {
+			return nil, fmt.Errorf("invalid MAIL_TIMEOUT %q, use a duration like 30s", value)
+		}
+		cfg.Timeout = timeout
+	}
+	if value := os.Getenv("MAIL_MAX_ATTEMPTS"); value != "" {
+		attempts, err := strconv.Atoi(value)
+		if err != nil || attempts < 1 {
+			return nil, fmt.Errorf("invalid MAIL_MAX_ATTEMPTS %q", value)
+		}
+		cfg.MaxAttempts = attempts
+	}
+
+	return cfg, nil
+}
+
+// NewMailer creates the mailer selected by the config
+func NewMailer(cfg *MailConfig) (Mailer, error) {
+	switch cfg.Mailer {
+	case MailerSMTP:
+		if cfg.SMTPHost == "" {
+			return nil, fmt.Errorf("the smtp mailer needs SMTP_HOST")
+		}
+		return NewSMTPMailer(*cfg), nil
+	case MailerOutbox:
+		return NewOutboxMailer(cfg.OutboxDir), nil
+	case MailerStandIn:
+		// Talk to an in-process SMTP server that logs what it receives, so the
+		// whole SMTP path runs without network access
+		server, err := NewSMTPStandInServer()
+		if err != nil {
+			return nil, err
+		}
+		standIn := *cfg
+		standIn.SMTPHost, standIn.SMTPPort = server.Host(), server.Port()
+		standIn.SMTPUsername, standIn.SMTPPassword = "", ""
+		return NewSMTPMailer(standIn), nil
+	}
+	return nil, fmt.Errorf("unknown mailer %q", cfg.Mailer)
+}
+
+// TemporaryMailError reports whether sending failed in a way that may succeed
+// later: the connection to the server failed, or it answered with a 4xx code
+func TemporaryMailError(err error) bool {
+	var protoErr *textproto.Error
+	if errors.As(err, &protoErr) {
+		return protoErr.Code >= 400 && protoErr.Code < 500
+	}
+	var netErr net.Error
+	return errors.As(err, &netErr)
+}
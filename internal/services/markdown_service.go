@@ -4,6 +4,7 @@ import (
 	"html/template"
 
 	"github.com/gomarkdown/markdown"
+	"github.com/gomarkdown/markdown/ast"
 	"github.com/gomarkdown/markdown/html"
 	"github.com/gomarkdown/markdown/parser"
 )
@@ -22,12 +23,8 @@ func (s *MarkdownService) ParseMarkdown(text string) template.HTML {
 		return template.HTML("")
 	}
 
-	// Create markdown parser with extensions
-	extensions := parser.CommonExtensions
-	parser := parser.NewWithExtensions(extensions)
-
 	// Parse markdown
-	doc := parser.Parse([]byte(text))
+	doc := parseMarkdown(text)
 
 	// Create HTML renderer
 	htmlFlags := html.CommonFlags | html.HrefTargetBlank
@@ -40,6 +37,13 @@ func (s *MarkdownService) ParseMarkdown(text string) template.HTML {
 	return template.HTML(htmlBytes)
 }
 
+// parseMarkdown parses markdown text into the AST shared by the HTML and PDF renderers
+func parseMarkdown(text string) ast.Node {
+	// Create markdown parser with extensions
+	extensions := parser.CommonExtensions
+	return parser.NewWithExtensions(extensions).Parse([]byte(text))
+}
+
 // ParseMarkdownSafe converts markdown text to HTML with safety measures
 func (s *MarkdownService) ParseMarkdownSafe(text string) template.HTML {
 	// For now, we'll use the same implementation
@@ -0,0 +1,118 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"log"
+
+	"github.com/nahue/setlist_manager/internal/app/shared/types"
+	"github.com/nahue/setlist_manager/internal/store"
+)
+
+// Errors returned for ownership transfers that can't be requested or answered
+var (
+	ErrNotBandOwner            = errors.New("only the owner can transfer the band")
+	ErrInvalidTransferTarget   = errors.New("the band can only be transferred to another member")
+	ErrTransferNotPending      = errors.New("ownership transfer is no longer pending")
+	ErrTransferWrongUser       = errors.New("ownership transfer was sent to a different member")
+	ErrSoleOwnerCannotLeave    = errors.New("the owner must transfer the band before leaving it")
+	ErrOwnershipTransferFailed = errors.New("ownership transfer could not be completed")
+)
+
+// OwnershipService hands bands from their owner to another member, who has to
+// accept before the roles change, and lets members leave bands
+type OwnershipService struct {
+	bandsDB     *store.SQLiteBandsStore
+	mailService *MailService
+}
+
+// NewOwnershipService creates a new ownership service
+func NewOwnershipService(bandsDB *store.SQLiteBandsStore, mailService *MailService) *OwnershipService {
+	return &OwnershipService{
+		bandsDB:     bandsDB,
+		mailService: mailService,
+	}
+}
+
+// BandSettingsPath is the page where a band's ownership transfer is answered
+func BandSettingsPath(bandID string) string {
+	return "/band/settings?id=" + bandID
+}
+
+// RequestTransfer asks a member to take over a band from its owner and emails
+// them the link to answer. A transfer still pending is replaced.
+func (s *OwnershipService) RequestTransfer(ctx context.Context, band *store.Band, owner *store.BandMember, from *types.User, toUserID string) (*store.BandOwnershipTransfer, error) {
+	if owner == nil || owner.Role != store.RoleOwner {
+		return nil, ErrNotBandOwner
+	}
+	if toUserID == owner.UserID {
+		return nil, ErrInvalidTransferTarget
+	}
+	recipient, err := s.bandsDB.GetBandMember(band.ID, toUserID)
+	if err != nil {
+		return nil, err
+	}
+	if recipient == nil {
+		return nil, ErrInvalidTransferTarget
+	}
+
+	if _, err := s.bandsDB.CreateOwnershipTransfer(band.ID, owner.UserID, toUserID); err != nil {
+		return nil, err
+	}
+	transfer, err := s.bandsDB.GetPendingOwnershipTransfer(band.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	// The transfer is shown in the band even when the email fails
+	if err := s.mailService.SendOwnershipTransfer(ctx, transfer.ToUser.Email, band.Name, from.Email, s.mailService.Link(BandSettingsPath(band.ID))); err != nil {
+		log.Printf("Error sending ownership transfer %s: %v", transfer.ID, err)
+	}
+	return transfer, nil
+}
+
+// Accept makes the user the owner of the band of a transfer sent to them, and
+// lets the previous owner, who becomes an admin, know
+func (s *OwnershipService) Accept(ctx context.Context, transfer *store.BandOwnershipTransfer, band *store.Band, user *types.User) error {
+	if transfer.ToUserID != user.ID {
+		return ErrTransferWrongUser
+	}
+	if transfer.Status != "pending" {
+		return ErrTransferNotPending
+	}
+	if err := s.bandsDB.AcceptOwnershipTransfer(transfer); err != nil {
+		log.Printf("Error accepting ownership transfer %s: %v", transfer.ID, err)
+		return ErrOwnershipTransferFailed
+	}
+
+	// The band changes hands even when the notification fails
+	if err := s.mailService.SendOwnershipTransferred(ctx, transfer.FromUser.Email, band.Name, user.Email, s.mailService.Link("/band?id="+band.ID)); err != nil {
+		log.Printf("Error notifying that ownership transfer %s was accepted: %v", transfer.ID, err)
+	}
+	return nil
+}
+
+// Decline turns down a transfer sent to the user
+func (s *OwnershipService) Decline(transfer *store.BandOwnershipTransfer, user *types.User) error {
+	if transfer.ToUserID != user.ID {
+		return ErrTransferWrongUser
+	}
+	return s.bandsDB.AnswerOwnershipTransfer(transfer.ID, "declined")
+}
+
+// Cancel withdraws a transfer the user requested
+func (s *OwnershipService) Cancel(transfer *store.BandOwnershipTransfer, user *types.User) error {
+	if transfer.FromUserID != user.ID {
+		return ErrNotBandOwner
+	}
+	return s.bandsDB.AnswerOwnershipTransfer(transfer.ID, "cancelled")
+}
+
+// Leave removes a member from a band. The owner can't leave, since the band
+// would be left without one: they transfer it first or delete it.
+func (s *OwnershipService) Leave(member *store.BandMember) error {
+	if member.Role == store.RoleOwner {
+		return ErrSoleOwnerCannotLeave
+	}
+	return s.bandsDB.RemoveBandMember(member.BandID, member.UserID)
+}
@@ -0,0 +1,299 @@
+package services
+
+import (
+	"strings"
+	"unicode/utf8"
+
+	"github.com/nahue/setlist_manager/internal/transpose"
+	"github.com/phpdave11/gofpdf"
+)
+
+// Song PDF layouts
+const (
+	// SongLayoutDocument renders song content as formatted markdown
+	SongLayoutDocument = "document"
+	// SongLayoutChart renders song content as a chord chart: lines are kept as
+	// written in monospace, in one or two columns, scaled to fit one or two pages
+	SongLayoutChart = "chart"
+)
+
+// Chart font sizes, in points. One page is preferred down to chartMinOnePageSize;
+// below that the chart may take two pages, and past chartMinSize long lines wrap.
+const (
+	chartMaxSize        = 12.0
+	chartMinOnePageSize = 8.0
+	chartMinSize        = 6.5
+	chartColumnGap      = 8.0
+)
+
+// Kinds of chart line
+const (
+	chartLyric = iota
+	chartChord
+	chartHeading
+	chartBlank
+)
+
+// chartLine is one line of a chord chart
+type chartLine struct {
+	kind int
+	text string
+}
+
+// chartUnit is a run of lines kept together in one column: a heading with the
+// lines after it, or a chord line with the lyric it sits over
+type chartUnit []chartLine
+
+// chartLines reads song content into chart lines, keeping leading whitespace
+// and dropping only the markdown that has no place in a chart
+func chartLines(content string) []chartLine {
+	var lines []chartLine
+	inFence := false
+	for _, raw := range strings.Split(strings.ReplaceAll(content, "\r\n", "\n"), "\n") {
+		line := strings.ReplaceAll(strings.TrimRight(raw, " \t"), "\t", "    ")
+		trimmed := strings.TrimSpace(line)
+
+		if strings.HasPrefix(trimmed, "```") || strings.HasPrefix(trimmed, "~~~") {
+			inFence = !inFence
+			continue
+		}
+
+		switch {
+		case inFence:
+			lines = append(lines, chartLine{kind: chartLyric, text: line})
+		case trimmed == "" || trimmed == "---" || trimmed == "***":
+			lines = append(lines, chartLine{kind: chartBlank})
+		case strings.HasPrefix(trimmed, "#"):
+			lines = append(lines, chartLine{kind: chartHeading, text: strings.TrimSpace(strings.TrimLeft(trimmed, "#"))})
+		case len(trimmed) > 4 && strings.HasPrefix(trimmed, "**") && strings.HasSuffix(trimmed, "**") && !strings.Contains(trimmed[2:len(trimmed)-2], "**"):
+			// A line that is all bold is a section label
+			lines = append(lines, chartLine{kind: chartHeading, text: trimmed[2 : len(trimmed)-2]})
+		case transpose.IsChordLine(line):
+			lines = append(lines, chartLine{kind: chartChord, text: line})
+		default:
+			lines = append(lines, chartLine{kind: chartLyric, text: line})
+		}
+	}
+
+	// Collapse runs of blank lines and trim them from both ends
+	var result []chartLine
+	for _, line := range lines {
+		if line.kind == chartBlank && (len(result) == 0 || result[len(result)-1].kind == chartBlank) {
+			continue
+		}
+		result = append(result, line)
+	}
+	for len(result) > 0 && result[len(result)-1].kind == chartBlank {
+		result = result[:len(result)-1]
+	}
+	return result
+}
+
+// chartUnits groups chart lines into the runs that shouldn't be split across columns
+func chartUnits(lines []chartLine) []chartUnit {
+	var units []chartUnit
+	for i := 0; i < len(lines); {
+		unit := chartUnit{lines[i]}
+		i++
+		// A heading stays with its first line, and a chord line with its lyric
+		if unit[0].kind == chartHeading && i < len(lines) && lines[i].kind != chartHeading {
+			unit = append(unit, lines[i])
+			i++
+		}
+		if unit[len(unit)-1].kind == chartChord && i < len(lines) && lines[i].kind == chartLyric {
+			unit = append(unit, lines[i])
+			i++
+		}
+		units = append(units, unit)
+	}
+	return units
+}
+
+// chartLayout holds the geometry of a chart at one font size
+type chartLayout struct {
+	size       float64
+	lineHeight float64
+	columns    int
+	firstTop   float64 // top of the columns on the first page, below the song header
+	top        float64 // top of the columns on later pages
+	bottom     float64 // lowest y a line may reach
+}
+
+// height returns how tall a chart line is
+func (l *chartLayout) height(line chartLine) float64 {
+	switch line.kind {
+	case chartBlank:
+		return l.lineHeight * 0.5
+	case chartHeading:
+		return l.lineHeight * 1.3
+	}
+	return l.lineHeight
+}
+
+// place walks the units through the columns and pages, calling draw with each
+// line's page, column and y position and finally with a blank line where the
+// chart ends, and returns the number of pages used
+func (l *chartLayout) place(units []chartUnit, draw func(line chartLine, page, column int, y float64)) int {
+	page, column := 0, 0
+	// Every column of the first page starts below the song header
+	columnTop := func() float64 {
+		if page == 0 {
+			return l.firstTop
+		}
+		return l.top
+	}
+	y := columnTop()
+	next := func() {
+		column++
+		if column == l.columns {
+			column = 0
+			page++
+		}
+		y = columnTop()
+	}
+	for _, unit := range units {
+		height := 0.0
+		for _, line := range unit {
+			height += l.height(line)
+		}
+		// Move whole units to the next column, unless they are taller than a column
+		if y+height > l.bottom && y > columnTop() && height <= l.bottom-l.top {
+			next()
+		}
+		for _, line := range unit {
+			// Blank lines at the top of a column are dropped
+			if line.kind == chartBlank && y == columnTop() {
+				continue
+			}
+			if y+l.height(line) > l.bottom {
+				next()
+			}
+			if draw != nil {
+				draw(line, page, column, y)
+			}
+			y += l.height(line)
+		}
+	}
+	if draw != nil {
+		draw(chartLine{kind: chartBlank}, page, column, y)
+	}
+	return page + 1
+}
+
+// wrapChartLines breaks lines wider than width into several lines
+func wrapChartLines(lines []chartLine, width, charWidth float64) []chartLine {
+	maxChars := int(width / charWidth)
+	if maxChars < 1 {
+		maxChars = 1
+	}
+	var result []chartLine
+	for _, line := range lines {
+		runes := []rune(line.text)
+		for len(runes) > maxChars {
+			result = append(result, chartLine{kind: line.kind, text: string(runes[:maxChars])})
+			runes = runes[maxChars:]
+		}
+		result = append(result, chartLine{kind: line.kind, text: string(runes)})
+	}
+	return result
+}
+
+// writeSongChart writes song content as a chord chart below the song header,
+// choosing the largest type that fits the chart on one page, or else on two
+func writeSongChart(pdf *gofpdf.Fpdf, content string, columns int) {
+	lines := chartLines(content)
+	if len(lines) == 0 {
+		return
+	}
+	if columns < 1 || columns > 2 {
+		columns = 1
+	}
+
+	autoBreak, breakMargin := pdf.GetAutoPageBreak()
+	pdf.SetAutoPageBreak(false, 0)
+	defer pdf.SetAutoPageBreak(autoBreak, breakMargin)
+
+	left, top, right, bottom := pdf.GetMargins()
+	if autoBreak {
+		bottom = breakMargin
+	}
+	pageWidth, pageHeight := pdf.GetPageSize()
+	colWidth := (pageWidth - left - right - float64(columns-1)*chartColumnGap) / float64(columns)
+
+	// Cells are padded on both sides
+	textWidth := colWidth - 2*pdf.GetCellMargin()
+
+	// Monospace characters are all as wide, so the widest line is the longest
+	longest := 0
+	for _, line := range lines {
+		if n := utf8.RuneCountInString(line.text); n > longest {
+			longest = n
+		}
+	}
+	pdf.SetFont("DejaVuMono", "B", 10)
+	charWidthAt10 := pdf.GetStringWidth("M")
+
+	layoutAt := func(size float64) *chartLayout {
+		return &chartLayout{
+			size:       size,
+			lineHeight: size * 0.3528 * 1.2,
+			columns:    columns,
+			firstTop:   pdf.GetY(),
+			top:        top,
+			bottom:     pageHeight - bottom,
+		}
+	}
+	fits := func(size float64, pages int) bool {
+		if float64(longest)*charWidthAt10*size/10 > textWidth {
+			return false
+		}
+		return layoutAt(size).place(chartUnits(lines), nil) <= pages
+	}
+
+	// Try one page, then two, from the largest size down
+	var layout *chartLayout
+	for _, attempt := range []struct {
+		pages   int
+		minSize float64
+	}{{1, chartMinOnePageSize}, {2, chartMinSize}} {
+		for size := chartMaxSize; size >= attempt.minSize; size -= 0.5 {
+			if fits(size, attempt.pages) {
+				layout = layoutAt(size)
+				break
+			}
+		}
+		if layout != nil {
+			break
+		}
+	}
+	// Too long or too wide even at the smallest size: wrap, and use as many pages as it takes
+	if layout == nil {
+		layout = layoutAt(chartMinSize)
+		lines = wrapChartLines(lines, textWidth, charWidthAt10*chartMinSize/10)
+	}
+
+	firstPage := pdf.PageNo()
+	layout.place(chartUnits(lines), func(line chartLine, page, column int, y float64) {
+		for pdf.PageNo() < firstPage+page {
+			pdf.AddPage()
+		}
+		x := left + float64(column)*(colWidth+chartColumnGap)
+		pdf.SetXY(x, y)
+		switch line.kind {
+		case chartBlank:
+			// Leaves the cursor below the last line for whatever follows the chart
+		case chartHeading:
+			pdf.SetFont("DejaVu", "B", layout.size*1.1)
+			pdf.SetTextColor(90, 90, 90)
+			pdf.CellFormat(colWidth, layout.height(line), line.text, "", 0, "L", false, 0, "")
+		case chartChord:
+			pdf.SetFont("DejaVuMono", "B", layout.size)
+			pdf.SetTextColor(30, 64, 175)
+			pdf.CellFormat(colWidth, layout.height(line), line.text, "", 0, "L", false, 0, "")
+		case chartLyric:
+			pdf.SetFont("DejaVuMono", "", layout.size)
+			pdf.CellFormat(colWidth, layout.height(line), line.text, "", 0, "L", false, 0, "")
+		}
+		pdf.SetTextColor(0, 0, 0)
+	})
+}
@@ -0,0 +1,489 @@
+package services
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/gomarkdown/markdown/ast"
+	"github.com/nahue/setlist_manager/internal/transpose"
+	"github.com/phpdave11/gofpdf"
+)
+
+// Markdown body type sizes, in points, and the line height in mm
+const (
+	markdownFontSize   = 11.0
+	markdownMonoSize   = 10.0
+	markdownLineHeight = 5.0
+)
+
+// markdownStyle is the inline formatting in effect while walking a paragraph
+type markdownStyle struct {
+	bold   bool
+	italic bool
+	strike bool
+	mono   bool
+	link   string
+}
+
+// markdownSpan is a run of text with a single style
+type markdownSpan struct {
+	text  string
+	style markdownStyle
+}
+
+// markdownLine is one source line of a paragraph
+type markdownLine struct {
+	spans []markdownSpan
+	// hard is set when the line ended with a markdown hard break
+	hard bool
+}
+
+// text returns the line without formatting
+func (l *markdownLine) text() string {
+	var b strings.Builder
+	for _, span := range l.spans {
+		b.WriteString(span.text)
+	}
+	return b.String()
+}
+
+// isChordLine reports whether the line is unformatted text holding only chords
+func (l *markdownLine) isChordLine() bool {
+	for _, span := range l.spans {
+		if span.style != (markdownStyle{}) {
+			return false
+		}
+	}
+	return transpose.IsChordLine(l.text())
+}
+
+// pdfMarkdown writes a markdown document to a PDF by walking its AST
+type pdfMarkdown struct {
+	pdf *gofpdf.Fpdf
+	// source holds the content lines, used to restore the leading spaces the
+	// parser strips from chord lines; next is the first line not yet matched
+	source []string
+	next   int
+	// tight is set inside tight lists, whose paragraphs get no spacing
+	tight bool
+	// quoted is set inside block quotes, which are written in gray
+	quoted bool
+}
+
+// blocks writes every block child of a node
+func (m *pdfMarkdown) blocks(node ast.Node) {
+	for _, child := range node.GetChildren() {
+		m.block(child)
+	}
+}
+
+// block writes a single block node
+func (m *pdfMarkdown) block(node ast.Node) {
+	switch n := node.(type) {
+	case *ast.Heading:
+		m.heading(n)
+	case *ast.Paragraph:
+		m.paragraph(n)
+	case *ast.List:
+		m.list(n)
+	case *ast.BlockQuote:
+		m.blockQuote(n)
+	case *ast.CodeBlock:
+		m.codeBlock(string(n.Literal))
+	case *ast.Table:
+		m.table(n)
+	case *ast.HorizontalRule:
+		m.rule()
+	case *ast.HTMLBlock:
+		// Raw HTML has no PDF equivalent
+	default:
+		m.blocks(node)
+	}
+}
+
+// heading writes a heading in bold, larger the higher its level
+func (m *pdfMarkdown) heading(n *ast.Heading) {
+	size := 12.0
+	switch n.Level {
+	case 1:
+		size = 16
+	case 2:
+		size = 14
+	}
+	lineHeight := size * 0.3528 * 1.3
+
+	m.pdf.Ln(2)
+	for _, line := range inlineLines(n) {
+		for _, span := range line.spans {
+			span.style.bold = true
+			m.writeSpan(span, size, lineHeight)
+		}
+		m.pdf.Ln(lineHeight)
+	}
+	m.pdf.Ln(1)
+}
+
+// paragraph writes a paragraph. Soft line breaks become spaces as they do on the
+// web page, except around chord lines: a chord line and the lyric below it keep
+// their own lines and are set in monospace so the chords stay over their syllables.
+func (m *pdfMarkdown) paragraph(n *ast.Paragraph) {
+	// flowing is set while the current PDF line can be continued by a soft break
+	flowing := false
+	underChords := false
+	for _, line := range inlineLines(n) {
+		switch {
+		case line.isChordLine():
+			if flowing {
+				m.pdf.Ln(markdownLineHeight)
+			}
+			m.writeSpan(markdownSpan{text: m.chordSource(line.text()), style: markdownStyle{bold: true, mono: true}}, markdownMonoSize, markdownLineHeight)
+			m.pdf.Ln(markdownLineHeight)
+			flowing = false
+			underChords = true
+			continue
+		case underChords:
+			for _, span := range line.spans {
+				span.style.mono = true
+				m.writeSpan(span, markdownMonoSize, markdownLineHeight)
+			}
+			m.pdf.Ln(markdownLineHeight)
+			underChords = false
+			continue
+		}
+
+		if flowing {
+			m.writeSpan(markdownSpan{text: " "}, markdownFontSize, markdownLineHeight)
+		}
+		for _, span := range line.spans {
+			m.writeSpan(span, markdownFontSize, markdownLineHeight)
+		}
+		flowing = true
+		if line.hard {
+			m.pdf.Ln(markdownLineHeight)
+			flowing = false
+		}
+	}
+	if flowing {
+		m.pdf.Ln(markdownLineHeight)
+	}
+	if !m.tight {
+		m.pdf.Ln(2)
+	}
+}
+
+// chordSource returns the content line a chord line came from, so that its
+// leading spaces survive; it falls back to the parsed text
+func (m *pdfMarkdown) chordSource(text string) string {
+	want := strings.TrimSpace(text)
+	for i := m.next; i < len(m.source); i++ {
+		if strings.TrimSpace(m.source[i]) == want {
+			m.next = i + 1
+			return strings.ReplaceAll(strings.TrimRight(m.source[i], " \t"), "\t", "    ")
+		}
+	}
+	return text
+}
+
+// list writes a bulleted or numbered list, indenting the item content under its marker
+func (m *pdfMarkdown) list(n *ast.List) {
+	left, _, _, _ := m.pdf.GetMargins()
+	indent := 6.0
+
+	tight := m.tight
+	m.tight = n.Tight
+	number := n.Start
+	if number == 0 {
+		number = 1
+	}
+	for _, child := range n.GetChildren() {
+		item, ok := child.(*ast.ListItem)
+		if !ok {
+			continue
+		}
+		marker := "•"
+		if n.ListFlags&ast.ListTypeOrdered != 0 {
+			marker = fmt.Sprintf("%d.", number)
+			number++
+		}
+
+		m.pdf.SetX(left)
+		m.setFont(markdownStyle{}, markdownFontSize)
+		m.pdf.CellFormat(indent, markdownLineHeight, marker, "", 0, "L", false, 0, "")
+		m.pdf.SetLeftMargin(left + indent)
+		m.blocks(item)
+		m.pdf.SetLeftMargin(left)
+	}
+	m.tight = tight
+	if !m.tight {
+		m.pdf.Ln(2)
+	}
+}
+
+// blockQuote writes a quote indented and in gray, with a rule down its left side
+func (m *pdfMarkdown) blockQuote(n *ast.BlockQuote) {
+	left, _, _, _ := m.pdf.GetMargins()
+	page, top := m.pdf.PageNo(), m.pdf.GetY()
+
+	quoted := m.quoted
+	m.quoted = true
+	m.pdf.SetLeftMargin(left + 6)
+	m.pdf.SetX(left + 6)
+	m.blocks(n)
+	m.pdf.SetLeftMargin(left)
+	m.pdf.SetX(left)
+	m.quoted = quoted
+
+	// The rule is only drawn when the quote didn't break across pages
+	if m.pdf.PageNo() == page {
+		m.pdf.SetDrawColor(190, 190, 190)
+		m.pdf.SetLineWidth(0.8)
+		m.pdf.Line(left+2, top, left+2, m.pdf.GetY()-2)
+		m.pdf.SetLineWidth(0.2)
+		m.pdf.SetDrawColor(0, 0, 0)
+	}
+}
+
+// codeBlock writes preformatted text in monospace on a light background, line by line
+func (m *pdfMarkdown) codeBlock(code string) {
+	m.setFont(markdownStyle{mono: true}, 9.5)
+	m.pdf.SetFillColor(242, 242, 242)
+	lines := strings.Split(strings.TrimRight(code, "\n"), "\n")
+	for _, line := range lines {
+		line = strings.ReplaceAll(line, "\t", "    ")
+		m.pdf.CellFormat(0, 4.5, line, "", 1, "L", true, 0, "")
+	}
+	m.pdf.Ln(2)
+}
+
+// table writes a table with a bold header row. Columns are as wide as their
+// content, scaled down to the page width, and long cells wrap.
+func (m *pdfMarkdown) table(n *ast.Table) {
+	type tableRow struct {
+		cells  []string
+		align  []string
+		header bool
+	}
+	var rows []tableRow
+	columns := 0
+	ast.WalkFunc(n, func(node ast.Node, entering bool) ast.WalkStatus {
+		row, ok := node.(*ast.TableRow)
+		if !ok || !entering {
+			return ast.GoToNext
+		}
+		var r tableRow
+		for _, child := range row.GetChildren() {
+			cell, ok := child.(*ast.TableCell)
+			if !ok {
+				continue
+			}
+			var text []string
+			for _, line := range inlineLines(cell) {
+				text = append(text, line.text())
+			}
+			r.cells = append(r.cells, strings.Join(text, " "))
+			r.align = append(r.align, tableCellAlign(cell.Align))
+			r.header = r.header || cell.IsHeader
+		}
+		if len(r.cells) > columns {
+			columns = len(r.cells)
+		}
+		rows = append(rows, r)
+		return ast.SkipChildren
+	})
+	if columns == 0 {
+		return
+	}
+
+	// Natural column widths, from the widest cell in each column
+	padding := 2 * m.pdf.GetCellMargin()
+	widths := make([]float64, columns)
+	for _, row := range rows {
+		m.setFont(markdownStyle{bold: row.header}, 10)
+		for i, cell := range row.cells {
+			if w := m.pdf.GetStringWidth(cell) + padding + 1; w > widths[i] {
+				widths[i] = w
+			}
+		}
+	}
+	left, _, right, _ := m.pdf.GetMargins()
+	pageWidth, pageHeight := m.pdf.GetPageSize()
+	available := pageWidth - left - right
+	total := 0.0
+	for _, w := range widths {
+		total += w
+	}
+	if total > available {
+		for i := range widths {
+			widths[i] *= available / total
+		}
+	}
+
+	lineHeight := 4.5
+	_, bottom := m.pdf.GetAutoPageBreak()
+	m.pdf.SetDrawColor(180, 180, 180)
+	m.pdf.SetFillColor(235, 235, 235)
+	for _, row := range rows {
+		m.setFont(markdownStyle{bold: row.header}, 10)
+		lines := 1
+		for i, cell := range row.cells {
+			if count := len(m.pdf.SplitText(cell, widths[i]-padding)); count > lines {
+				lines = count
+			}
+		}
+		height := float64(lines)*lineHeight + 1
+
+		// Rows are drawn cell by cell, so page breaks are handled here
+		if m.pdf.GetY()+height > pageHeight-bottom {
+			m.pdf.AddPage()
+		}
+		x, y := left, m.pdf.GetY()
+		for i := 0; i < columns; i++ {
+			style := "D"
+			if row.header {
+				style = "FD"
+			}
+			m.pdf.Rect(x, y, widths[i], height, style)
+			if i < len(row.cells) {
+				m.pdf.SetXY(x, y+0.5)
+				m.pdf.MultiCell(widths[i], lineHeight, row.cells[i], "", row.align[i], false)
+			}
+			x += widths[i]
+		}
+		m.pdf.SetXY(left, y+height)
+	}
+	m.pdf.SetDrawColor(0, 0, 0)
+	m.pdf.Ln(3)
+}
+
+// rule writes a horizontal rule across the page
+func (m *pdfMarkdown) rule() {
+	left, _, right, _ := m.pdf.GetMargins()
+	pageWidth, _ := m.pdf.GetPageSize()
+	m.pdf.Ln(2)
+	y := m.pdf.GetY()
+	m.pdf.SetDrawColor(180, 180, 180)
+	m.pdf.Line(left, y, pageWidth-right, y)
+	m.pdf.SetDrawColor(0, 0, 0)
+	m.pdf.Ln(4)
+}
+
+// writeSpan writes a run of text at the current position, wrapping at the margins
+func (m *pdfMarkdown) writeSpan(span markdownSpan, size, lineHeight float64) {
+	m.setFont(span.style, size)
+	switch {
+	case span.style.link != "":
+		m.pdf.SetTextColor(37, 99, 235)
+		m.pdf.WriteLinkString(lineHeight, span.text, span.style.link)
+	case m.quoted:
+		m.pdf.SetTextColor(90, 90, 90)
+		m.pdf.Write(lineHeight, span.text)
+	default:
+		m.pdf.Write(lineHeight, span.text)
+	}
+	m.pdf.SetTextColor(0, 0, 0)
+}
+
+// setFont selects the DejaVu face for a style
+func (m *pdfMarkdown) setFont(style markdownStyle, size float64) {
+	family := "DejaVu"
+	if style.mono {
+		family = "DejaVuMono"
+	}
+	fontStyle := ""
+	if style.bold {
+		fontStyle += "B"
+	}
+	if style.italic {
+		fontStyle += "I"
+	}
+	if style.strike {
+		fontStyle += "S"
+	}
+	if style.link != "" {
+		fontStyle += "U"
+	}
+	m.pdf.SetFont(family, fontStyle, size)
+}
+
+// inlineLines flattens the inline children of a block into styled lines,
+// splitting at the newlines kept in text and at hard breaks
+func inlineLines(node ast.Node) []markdownLine {
+	lines := []markdownLine{{}}
+	var walk func(node ast.Node, style markdownStyle)
+	add := func(text string, style markdownStyle) {
+		for i, part := range strings.Split(text, "\n") {
+			if i > 0 {
+				lines = append(lines, markdownLine{})
+			}
+			if part != "" {
+				last := &lines[len(lines)-1]
+				last.spans = append(last.spans, markdownSpan{text: part, style: style})
+			}
+		}
+	}
+	walk = func(node ast.Node, style markdownStyle) {
+		for _, child := range node.GetChildren() {
+			switch n := child.(type) {
+			case *ast.Text:
+				add(string(n.Literal), style)
+			case *ast.Hardbreak:
+				lines[len(lines)-1].hard = true
+				lines = append(lines, markdownLine{})
+			case *ast.Softbreak:
+				lines = append(lines, markdownLine{})
+			case *ast.Code:
+				codeStyle := style
+				codeStyle.mono = true
+				add(string(n.Literal), codeStyle)
+			case *ast.Emph:
+				emph := style
+				emph.italic = true
+				walk(n, emph)
+			case *ast.Strong:
+				strong := style
+				strong.bold = true
+				walk(n, strong)
+			case *ast.Del:
+				del := style
+				del.strike = true
+				walk(n, del)
+			case *ast.Link:
+				link := style
+				link.link = string(n.Destination)
+				walk(n, link)
+			case *ast.Image:
+				alt := style
+				alt.italic = true
+				walk(n, alt)
+			case *ast.NonBlockingSpace:
+				add(" ", style)
+			case *ast.HTMLSpan:
+				// Inline HTML has no PDF equivalent
+			default:
+				if leaf := child.AsLeaf(); leaf != nil {
+					add(string(leaf.Literal), style)
+				} else {
+					walk(child, style)
+				}
+			}
+		}
+	}
+	walk(node, markdownStyle{})
+
+	// Drop the empty line left after a trailing break
+	if len(lines) > 1 && len(lines[len(lines)-1].spans) == 0 {
+		lines = lines[:len(lines)-1]
+	}
+	return lines
+}
+
+// tableCellAlign maps a markdown column alignment to a gofpdf alignment
+func tableCellAlign(align ast.CellAlignFlags) string {
+	switch align {
+	case ast.TableAlignmentRight:
+		return "R"
+	case ast.TableAlignmentCenter:
+		return "C"
+	}
+	return "L"
+}
@@ -4,7 +4,9 @@ import (
 	"bytes"
 	"fmt"
 	"strings"
+	"time"
 
+	"github.com/nahue/setlist_manager/internal/store"
 	"github.com/phpdave11/gofpdf"
 )
 
@@ -23,10 +25,45 @@ type SongContentPDFRequest struct {
 	Key       string `json:"key"`
 	Tempo     *int   `json:"tempo"`
 	Content   string `json:"content"`
+	// Layout is SongLayoutDocument or SongLayoutChart; Columns applies to charts
+	Layout  string `json:"layout"`
+	Columns int    `json:"columns"`
+}
+
+// SongPDFRequest builds the PDF request for a stored song
+func SongPDFRequest(song *store.Song) *SongContentPDFRequest {
+	return &SongContentPDFRequest{
+		SongTitle: song.Title,
+		Artist:    song.Artist,
+		Key:       song.Key,
+		Tempo:     song.Tempo,
+		Content:   song.Content,
+	}
 }
 
 // GenerateSongPDF generates a PDF from song content
 func (s *PDFService) GenerateSongPDF(req *SongContentPDFRequest) ([]byte, error) {
+	pdf := newPDF(fmt.Sprintf("%s - %s", req.SongTitle, req.Artist))
+
+	pdf.AddPage()
+
+	// Set margins
+	pdf.SetMargins(20, 20, 20)
+	pdf.SetAutoPageBreak(true, 20)
+
+	writeSongHeader(pdf, req)
+	writeSongBody(pdf, req)
+
+	var buf bytes.Buffer
+	err := pdf.Output(&buf)
+	if err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// newPDF creates an A4 document with the DejaVu fonts registered for UTF-8 support
+func newPDF(title string) *gofpdf.Fpdf {
 	// Create a new PDF document with UTF-8 support
 	pdf := gofpdf.New("P", "mm", "A4", "")
 
@@ -35,18 +72,21 @@ func (s *PDFService) GenerateSongPDF(req *SongContentPDFRequest) ([]byte, error)
 	pdf.AddUTF8Font("DejaVu", "B", "fonts/DejaVuSans-Bold.ttf")
 	pdf.AddUTF8Font("DejaVu", "I", "fonts/DejaVuSans-Oblique.ttf")
 	pdf.AddUTF8Font("DejaVu", "BI", "fonts/DejaVuSans-BoldOblique.ttf")
+	pdf.AddUTF8Font("DejaVuMono", "", "fonts/DejaVuSansMono.ttf")
+	pdf.AddUTF8Font("DejaVuMono", "B", "fonts/DejaVuSansMono-Bold.ttf")
+	pdf.AddUTF8Font("DejaVuMono", "I", "fonts/DejaVuSansMono-Oblique.ttf")
+	pdf.AddUTF8Font("DejaVuMono", "BI", "fonts/DejaVuSansMono-BoldOblique.ttf")
 
 	// Set document metadata
 	pdf.SetAuthor("Setlist Manager", false)
 	pdf.SetCreator("Setlist Manager", false)
-	pdf.SetTitle(fmt.Sprintf("%s - %s", req.SongTitle, req.Artist), false)
-
-	pdf.AddPage()
+	pdf.SetTitle(title, true)
 
-	// Set margins
-	pdf.SetMargins(20, 20, 20)
-	pdf.SetAutoPageBreak(true, 20)
+	return pdf
+}
 
+// writeSongHeader writes the song title followed by its key and tempo
+func writeSongHeader(pdf *gofpdf.Fpdf, req *SongContentPDFRequest) {
 	// Title section with UTF-8 support
 	pdf.SetFont("DejaVu", "B", 18)
 	title := req.SongTitle
@@ -73,115 +113,431 @@ func (s *PDFService) GenerateSongPDF(req *SongContentPDFRequest) ([]byte, error)
 		pdf.Cell(0, 8, infoLine)
 		pdf.Ln(15)
 	}
+}
 
-	// Content section
-	pdf.SetFont("DejaVu", "", 11)
+// writeSongBody writes song content in the requested layout
+func writeSongBody(pdf *gofpdf.Fpdf, req *SongContentPDFRequest) {
+	if req.Layout == SongLayoutChart {
+		writeSongChart(pdf, req.Content, req.Columns)
+		return
+	}
+	writeSongContent(pdf, req.Content)
+}
 
-	// Split content into lines and process markdown formatting
-	lines := strings.Split(req.Content, "\n")
+// writeSongContent writes markdown song content from its parsed AST, so headings,
+// lists, tables, emphasis, quotes and code render as they do on the web page
+func writeSongContent(pdf *gofpdf.Fpdf, content string) {
+	m := &pdfMarkdown{pdf: pdf, source: strings.Split(content, "\n")}
+	m.blocks(parseMarkdown(content))
+	pdf.SetFont("DejaVu", "", markdownFontSize)
+}
+
+// GigBookPDFRequest represents the request for a printable gig book: a cover,
+// a setlist overview, a table of contents and every chart in set order
+type GigBookPDFRequest struct {
+	BandName string        `json:"band_name"`
+	Title    string        `json:"title"`
+	Date     string        `json:"date"`
+	Venue    string        `json:"venue"`
+	Sets     []*GigBookSet `json:"sets"`
+}
 
-	for _, line := range lines {
-		line = strings.TrimSpace(line)
-		if line == "" {
-			pdf.Ln(5)
-			continue
+// SetLayout sets the layout used for every chart in the book
+func (r *GigBookPDFRequest) SetLayout(layout string, columns int) {
+	for _, set := range r.Sets {
+		for _, song := range set.Songs {
+			song.Layout = layout
+			song.Columns = columns
 		}
+	}
+}
+
+// GigBookSet is one set of a gig book with its songs in playing order
+type GigBookSet struct {
+	Name     string                   `json:"name"`
+	Start    string                   `json:"start"`
+	IsEncore bool                     `json:"is_encore"`
+	Songs    []*SongContentPDFRequest `json:"songs"`
+}
 
-		// Handle headers
-		if strings.HasPrefix(line, "# ") {
-			pdf.SetFont("DejaVu", "B", 16)
-			pdf.Cell(0, 8, strings.TrimPrefix(line, "# "))
-			pdf.Ln(8)
-			pdf.SetFont("DejaVu", "", 11)
-			continue
+// GenerateGigBookPDF generates a gig book with a cover page, a one-page setlist
+// overview in large type, a table of contents linking to each chart, and every
+// chart on its own page with page numbers and an outline entry per song
+func (s *PDFService) GenerateGigBookPDF(req *GigBookPDFRequest) ([]byte, error) {
+	pdf := newPDF(fmt.Sprintf("%s - %s", req.BandName, req.Title))
+	pdf.SetMargins(20, 20, 20)
+	pdf.SetAutoPageBreak(true, 20)
+	pdf.AliasNbPages("")
+
+	// Number every page except the cover
+	pdf.SetFooterFunc(func() {
+		if pdf.PageNo() == 1 {
+			return
 		}
+		pdf.SetY(-15)
+		pdf.SetFont("DejaVu", "", 9)
+		pdf.SetTextColor(128, 128, 128)
+		pdf.CellFormat(0, 10, fmt.Sprintf("%s · %s — %d / {nb}", req.BandName, req.Title, pdf.PageNo()), "", 0, "C", false, 0, "")
+		pdf.SetTextColor(0, 0, 0)
+	})
+
+	writeGigBookCover(pdf, req)
+	writeGigBookOverview(pdf, req)
 
-		if strings.HasPrefix(line, "## ") {
-			pdf.SetFont("DejaVu", "B", 14)
-			pdf.Cell(0, 7, strings.TrimPrefix(line, "## "))
-			pdf.Ln(7)
-			pdf.SetFont("DejaVu", "", 11)
-			continue
+	// Links and page number placeholders for the table of contents,
+	// resolved once every chart has been placed
+	type tocEntry struct {
+		song  *SongContentPDFRequest
+		link  int
+		alias string
+	}
+	var entries []*tocEntry
+	for _, set := range req.Sets {
+		for _, song := range set.Songs {
+			entries = append(entries, &tocEntry{
+				song:  song,
+				link:  pdf.AddLink(),
+				alias: fmt.Sprintf("{toc%d}", len(entries)+1),
+			})
 		}
+	}
 
-		if strings.HasPrefix(line, "### ") {
+	// Table of contents
+	pdf.AddPage()
+	pdf.Bookmark("Contents", 0, -1)
+	pdf.SetFont("DejaVu", "B", 20)
+	pdf.CellFormat(0, 12, "Contents", "", 1, "L", false, 0, "")
+	pdf.Ln(4)
+	index := 0
+	for _, set := range req.Sets {
+		if len(req.Sets) > 1 {
 			pdf.SetFont("DejaVu", "B", 12)
-			pdf.Cell(0, 6, strings.TrimPrefix(line, "### "))
-			pdf.Ln(6)
-			pdf.SetFont("DejaVu", "", 11)
-			continue
+			pdf.CellFormat(0, 9, gigBookSetLabel(set), "", 1, "L", false, 0, "")
 		}
-
-		// Handle bold text
-		if strings.Contains(line, "**") {
-			parts := strings.Split(line, "**")
-			for i, part := range parts {
-				if i%2 == 1 { // Bold text
-					pdf.SetFont("DejaVu", "B", 11)
-					pdf.Write(5, part)
-					pdf.SetFont("DejaVu", "", 11)
-				} else { // Regular text
-					pdf.Write(5, part)
-				}
+		pdf.SetFont("DejaVu", "", 11)
+		for range set.Songs {
+			entry := entries[index]
+			index++
+			title := fmt.Sprintf("%d. %s", index, entry.song.SongTitle)
+			if entry.song.Artist != "" {
+				title += " - " + entry.song.Artist
 			}
-			pdf.Ln(5)
-			continue
+			pdf.CellFormat(150, 7, title, "", 0, "L", false, entry.link, "")
+			pdf.CellFormat(0, 7, entry.alias, "", 1, "R", false, entry.link, "")
 		}
+		pdf.Ln(3)
+	}
 
-		// Handle italic text
-		if strings.Contains(line, "*") && !strings.Contains(line, "**") {
-			parts := strings.Split(line, "*")
-			for i, part := range parts {
-				if i%2 == 1 && i < len(parts)-1 { // Italic text (but not the last part if it ends with *)
-					pdf.SetFont("DejaVu", "I", 11)
-					pdf.Write(5, part)
-					pdf.SetFont("DejaVu", "", 11)
-				} else { // Regular text
-					pdf.Write(5, part)
-				}
+	// Charts in set order, each starting on a new page
+	index = 0
+	for _, set := range req.Sets {
+		for i, song := range set.Songs {
+			entry := entries[index]
+			index++
+
+			pdf.AddPage()
+			if i == 0 {
+				pdf.Bookmark(gigBookSetLabel(set), 0, -1)
 			}
-			pdf.Ln(5)
-			continue
+			pdf.Bookmark(fmt.Sprintf("%d. %s", index, song.SongTitle), 1, -1)
+			pdf.SetLink(entry.link, 0, pdf.PageNo())
+			pdf.RegisterAlias(entry.alias, fmt.Sprintf("%d", pdf.PageNo()))
+
+			writeSongHeader(pdf, song)
+			if strings.TrimSpace(song.Content) == "" {
+				pdf.SetFont("DejaVu", "I", 11)
+				pdf.Write(5, "No chart available for this song.")
+				continue
+			}
+			writeSongBody(pdf, song)
+		}
+	}
+
+	var buf bytes.Buffer
+	err := pdf.Output(&buf)
+	if err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// writeGigBookCover writes the cover page with the band name, title, date and venue
+func writeGigBookCover(pdf *gofpdf.Fpdf, req *GigBookPDFRequest) {
+	pdf.AddPage()
+	// Bookmarks are only UTF-8 encoded once a UTF-8 font is selected
+	pdf.SetFont("DejaVu", "B", 32)
+	pdf.Bookmark(req.BandName, 0, -1)
+
+	pdf.SetY(90)
+	pdf.MultiCell(0, 14, req.BandName, "", "C", false)
+	pdf.Ln(6)
+	pdf.SetFont("DejaVu", "", 22)
+	pdf.MultiCell(0, 10, req.Title, "", "C", false)
+	pdf.Ln(10)
+	pdf.SetFont("DejaVu", "", 16)
+	if req.Date != "" {
+		pdf.CellFormat(0, 9, req.Date, "", 1, "C", false, 0, "")
+	}
+	if req.Venue != "" {
+		pdf.CellFormat(0, 9, req.Venue, "", 1, "C", false, 0, "")
+	}
+}
+
+// writeGigBookOverview writes the whole setlist on a single page, using the largest
+// type that lets every set heading and song fit
+func writeGigBookOverview(pdf *gofpdf.Fpdf, req *GigBookPDFRequest) {
+	pdf.AddPage()
+	pdf.Bookmark("Setlist", 0, -1)
+
+	pdf.SetFont("DejaVu", "B", 20)
+	pdf.CellFormat(0, 12, req.Title, "", 1, "C", false, 0, "")
+	pdf.Ln(4)
+
+	rows := 0
+	for _, set := range req.Sets {
+		rows += len(set.Songs)
+		if len(req.Sets) > 1 {
+			rows++
 		}
+	}
+	if rows == 0 {
+		return
+	}
+
+	// Each row takes 1.4 times the font size; 1pt is 0.3528mm
+	_, pageHeight := pdf.GetPageSize()
+	_, _, _, bottom := pdf.GetMargins()
+	available := pageHeight - bottom - 10 - pdf.GetY()
+	fontSize := available / float64(rows) / 1.4 / 0.3528
+	if fontSize > 28 {
+		fontSize = 28
+	}
+	lineHeight := fontSize * 0.3528 * 1.4
 
-		// Handle bullet points
-		if strings.HasPrefix(line, "- ") || strings.HasPrefix(line, "* ") {
-			pdf.Cell(5, 5, "•")
-			pdf.Write(5, " "+strings.TrimPrefix(strings.TrimPrefix(line, "- "), "* "))
-			pdf.Ln(5)
-			continue
+	// The overview must stay on one page even when the type gets small
+	pdf.SetAutoPageBreak(false, 0)
+	defer pdf.SetAutoPageBreak(true, 20)
+
+	number := 0
+	for _, set := range req.Sets {
+		if len(req.Sets) > 1 {
+			pdf.SetFont("DejaVu", "B", fontSize*0.8)
+			pdf.SetTextColor(100, 100, 100)
+			pdf.CellFormat(0, lineHeight, gigBookSetLabel(set), "", 1, "L", false, 0, "")
+			pdf.SetTextColor(0, 0, 0)
+		}
+		for _, song := range set.Songs {
+			number++
+			pdf.SetFont("DejaVu", "B", fontSize)
+			pdf.CellFormat(140, lineHeight, fmt.Sprintf("%d. %s", number, song.SongTitle), "", 0, "L", false, 0, "")
+			pdf.SetFont("DejaVu", "", fontSize*0.7)
+			pdf.CellFormat(0, lineHeight, song.Key, "", 1, "R", false, 0, "")
 		}
+	}
+}
 
-		// Handle numbered lists
-		if strings.Contains(line, ". ") && len(line) > 2 {
-			// Simple check for numbered lists
-			parts := strings.SplitN(line, ". ", 2)
-			if len(parts) == 2 {
-				pdf.Write(5, parts[0]+". "+parts[1])
-				pdf.Ln(5)
-				continue
+// gigBookSetLabel returns the heading used for a set, with its start time when known
+func gigBookSetLabel(set *GigBookSet) string {
+	label := set.Name
+	if set.IsEncore {
+		label += " (encore)"
+	}
+	if set.Start != "" {
+		label += " — " + set.Start
+	}
+	return label
+}
+
+// StageSheetRequest represents a big print setlist sheet: only the ordered song
+// titles, sized to fill a single page so it can be read from the floor
+type StageSheetRequest struct {
+	Title   string            `json:"title"`
+	Songs   []*StageSheetSong `json:"songs"`
+	Options StageSheetOptions `json:"options"`
+}
+
+// StageSheetSong is one song of a stage sheet
+type StageSheetSong struct {
+	Title string `json:"title"`
+	Key   string `json:"key"`
+	Tempo *int   `json:"tempo"`
+	Notes string `json:"notes"`
+	Segue bool   `json:"segue"`
+}
+
+// StageSheetOptions selects the page orientation and what is shown next to each title
+type StageSheetOptions struct {
+	Landscape  bool `json:"landscape"`
+	ShowKey    bool `json:"show_key"`
+	ShowTempo  bool `json:"show_tempo"`
+	ShowSegues bool `json:"show_segues"`
+	ShowNotes  bool `json:"show_notes"`
+}
+
+// DefaultStageSheetOptions returns the options used when none are chosen: portrait,
+// with keys and tempos
+func DefaultStageSheetOptions() StageSheetOptions {
+	return StageSheetOptions{ShowKey: true, ShowTempo: true}
+}
+
+// SetlistStageSheet builds the stage sheet request for a setlist's songs
+func SetlistStageSheet(setlist *store.Setlist, entries []*store.SetlistSong, options StageSheetOptions) *StageSheetRequest {
+	req := &StageSheetRequest{Title: setlist.Name, Options: options}
+	for _, entry := range entries {
+		req.Songs = append(req.Songs, &StageSheetSong{
+			Title: entry.Song.Title,
+			Key:   entry.Song.Key,
+			Tempo: entry.Song.Tempo,
+			Notes: entry.Song.Notes,
+			Segue: entry.Segue,
+		})
+	}
+	return req
+}
+
+// SetlistBook builds the gig book request for a setlist, printed as a single set
+func SetlistBook(bandName string, setlist *store.Setlist, entries []*store.SetlistSong) *GigBookPDFRequest {
+	set := &GigBookSet{Name: setlist.Name}
+	for _, entry := range entries {
+		set.Songs = append(set.Songs, SongPDFRequest(entry.Song))
+	}
+	return &GigBookPDFRequest{
+		BandName: bandName,
+		Title:    setlist.Name,
+		Date:     time.Now().Format("January 2, 2006"),
+		Sets:     []*GigBookSet{set},
+	}
+}
+
+// Label returns the song title as printed, with a segue arrow when the song runs into the next one
+func (r *StageSheetRequest) Label(index int) string {
+	song := r.Songs[index]
+	if r.Options.ShowSegues && song.Segue && index < len(r.Songs)-1 {
+		return song.Title + " →"
+	}
+	return song.Title
+}
+
+// Meta returns the key and tempo printed next to a song title, as selected by the options
+func (r *StageSheetRequest) Meta(index int) string {
+	song := r.Songs[index]
+	var parts []string
+	if r.Options.ShowKey && song.Key != "" {
+		parts = append(parts, song.Key)
+	}
+	if r.Options.ShowTempo && song.Tempo != nil {
+		parts = append(parts, fmt.Sprintf("%d", *song.Tempo))
+	}
+	return strings.Join(parts, "  ")
+}
+
+// NoteLine returns a song's notes flattened to one line, or "" when notes are not shown
+func (r *StageSheetRequest) NoteLine(index int) string {
+	if !r.Options.ShowNotes {
+		return ""
+	}
+	return strings.Join(strings.Fields(r.Songs[index].Notes), " ")
+}
+
+// Stage sheet proportions, relative to the title font size
+const (
+	stageSheetMetaScale  = 0.6
+	stageSheetNoteScale  = 0.45
+	stageSheetLineFactor = 1.2
+	stageSheetMaxFont    = 160.0
+)
+
+// GenerateStageSheetPDF generates a one-page setlist with the largest bold type
+// that fits every song, both across and down the page
+func (s *PDFService) GenerateStageSheetPDF(req *StageSheetRequest) ([]byte, error) {
+	pdf := newPDF(req.Title)
+	margin := 10.0
+	pdf.SetMargins(margin, margin, margin)
+	// The sheet must stay on one page however many songs it has
+	pdf.SetAutoPageBreak(false, 0)
+
+	orientation := "P"
+	if req.Options.Landscape {
+		orientation = "L"
+	}
+	pdf.AddPageFormat(orientation, pdf.GetPageSizeStr("A4"))
+	pdf.SetFont("DejaVu", "B", 12)
+	pdf.Bookmark(req.Title, 0, -1)
+
+	pageWidth, pageHeight := pdf.GetPageSize()
+	width := pageWidth - 2*margin
+	height := pageHeight - 2*margin
+
+	if len(req.Songs) == 0 {
+		pdf.SetFont("DejaVu", "I", 24)
+		pdf.SetY(pageHeight / 2)
+		pdf.CellFormat(0, 12, "No songs in this setlist", "", 1, "C", false, 0, "")
+	} else {
+		// Size the type by height first: each title line counts as one, and a
+		// note line as a fraction of one
+		lines := 0.0
+		for i := range req.Songs {
+			lines++
+			if req.NoteLine(i) != "" {
+				lines += stageSheetNoteScale
 			}
 		}
+		// 1pt is 0.3528mm
+		fontSize := height / lines / stageSheetLineFactor / 0.3528
+		if fontSize > stageSheetMaxFont {
+			fontSize = stageSheetMaxFont
+		}
 
-		// Handle code blocks (simple inline code)
-		if strings.Contains(line, "`") {
-			parts := strings.Split(line, "`")
-			for i, part := range parts {
-				if i%2 == 1 { // Code text
-					pdf.SetFont("DejaVu", "", 10) // Use DejaVu for code too
-					pdf.Write(5, part)
-					pdf.SetFont("DejaVu", "", 11)
-				} else { // Regular text
-					pdf.Write(5, part)
+		// Then shrink it until the widest row fits across the page
+		gap := 8.0
+		for i := range req.Songs {
+			pdf.SetFont("DejaVu", "B", fontSize)
+			rowWidth := pdf.GetStringWidth(req.Label(i))
+			if meta := req.Meta(i); meta != "" {
+				pdf.SetFont("DejaVu", "", fontSize*stageSheetMetaScale)
+				rowWidth += gap + pdf.GetStringWidth(meta)
+			}
+			if rowWidth > width {
+				fontSize *= width / rowWidth
+			}
+			if note := req.NoteLine(i); note != "" {
+				pdf.SetFont("DejaVu", "I", fontSize*stageSheetNoteScale)
+				if noteWidth := pdf.GetStringWidth(note); noteWidth > width {
+					fontSize *= width / noteWidth
 				}
 			}
-			pdf.Ln(5)
-			continue
 		}
 
-		// Regular text
-		pdf.Write(5, line)
-		pdf.Ln(5)
+		lineHeight := fontSize * 0.3528 * stageSheetLineFactor
+		noteHeight := lineHeight * stageSheetNoteScale
+
+		// Center the list vertically when the width limited the type
+		used := 0.0
+		for i := range req.Songs {
+			used += lineHeight
+			if req.NoteLine(i) != "" {
+				used += noteHeight
+			}
+		}
+		pdf.SetY(margin + (height-used)/2)
+
+		for i := range req.Songs {
+			y := pdf.GetY()
+			pdf.SetFont("DejaVu", "B", fontSize)
+			pdf.CellFormat(width, lineHeight, req.Label(i), "", 0, "L", false, 0, "")
+			if meta := req.Meta(i); meta != "" {
+				pdf.SetXY(margin, y)
+				pdf.SetFont("DejaVu", "", fontSize*stageSheetMetaScale)
+				pdf.CellFormat(width, lineHeight, meta, "", 0, "R", false, 0, "")
+			}
+			pdf.SetXY(margin, y+lineHeight)
+			if note := req.NoteLine(i); note != "" {
+				pdf.SetFont("DejaVu", "I", fontSize*stageSheetNoteScale)
+				pdf.SetTextColor(90, 90, 90)
+				pdf.CellFormat(width, noteHeight, note, "", 1, "L", false, 0, "")
+				pdf.SetTextColor(0, 0, 0)
+			}
+		}
 	}
 
 	var buf bytes.Buffer
@@ -0,0 +1,82 @@
+package services
+
+import "github.com/nahue/setlist_manager/internal/store"
+
+// Permission is something a band member may be allowed to do in the band
+type Permission string
+
+// Permissions checked by the band, song, setlist and gig handlers
+const (
+	// PermissionView lets a member read the band: songs and their charts,
+	// setlists, gigs, exports and AI usage
+	PermissionView Permission = "view"
+	// PermissionEdit lets a member create, change, reorder and delete songs,
+	// setlists and gigs, and restore songs from the trash
+	PermissionEdit Permission = "edit"
+	// PermissionGenerate lets a member generate song content with AI, which
+	// counts against the band's quota
+	PermissionGenerate Permission = "generate"
+	// PermissionManageMembers lets a member invite people, remove members and
+	// change their roles, for members with a lower role only
+	PermissionManageMembers Permission = "manage_members"
+	// PermissionManageBand lets a member change the band's prompt templates and
+	// download the band archive
+	PermissionManageBand Permission = "manage_band"
+	// PermissionDeleteBand lets a member delete and restore the band
+	PermissionDeleteBand Permission = "delete_band"
+)
+
+// rolePermissions are the permissions each role grants
+var rolePermissions = map[string][]Permission{
+	store.RoleOwner:  {PermissionView, PermissionEdit, PermissionGenerate, PermissionManageMembers, PermissionManageBand, PermissionDeleteBand},
+	store.RoleAdmin:  {PermissionView, PermissionEdit, PermissionGenerate, PermissionManageMembers, PermissionManageBand},
+	store.RoleEditor: {PermissionView, PermissionEdit, PermissionGenerate},
+	store.RoleViewer: {PermissionView},
+}
+
+// roleRanks orders the roles from least to most privileged
+var roleRanks = map[string]int{
+	store.RoleViewer: 1,
+	store.RoleEditor: 2,
+	store.RoleAdmin:  3,
+	store.RoleOwner:  4,
+}
+
+// Can reports whether a band member has a permission. A nil member, someone
+// who isn't in the band, has none.
+func Can(member *store.BandMember, permission Permission) bool {
+	return member != nil && RoleCan(member.Role, permission)
+}
+
+// RoleCan reports whether a role grants a permission
+func RoleCan(role string, permission Permission) bool {
+	for _, p := range rolePermissions[role] {
+		if p == permission {
+			return true
+		}
+	}
+	return false
+}
+
+// CanManageRole reports whether a member with a role may remove or change the
+// role of a member with targetRole: it takes a higher role than theirs
+func CanManageRole(role, targetRole string) bool {
+	return RoleCan(role, PermissionManageMembers) && roleRanks[role] > roleRanks[targetRole]
+}
+
+// AssignableRoles are the roles a member with a role may give others, most
+// privileged first. Ownership is only given by transferring it.
+func AssignableRoles(role string) []string {
+	var roles []string
+	for _, candidate := range []string{store.RoleAdmin, store.RoleEditor, store.RoleViewer} {
+		if CanManageRole(role, candidate) {
+			roles = append(roles, candidate)
+		}
+	}
+	return roles
+}
+
+// CanAssignRole reports whether a member with a role may give others targetRole
+func CanAssignRole(role, targetRole string) bool {
+	return targetRole != store.RoleOwner && roleRanks[targetRole] > 0 && CanManageRole(role, targetRole)
+}
@@ -0,0 +1,79 @@
+package services
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/nahue/setlist_manager/internal/store"
+)
+
+// ParseSongDuration parses a song length written as "m:ss", "h:mm:ss" or a plain
+// number of seconds and returns it in seconds
+func ParseSongDuration(value string) (int, error) {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return 0, fmt.Errorf("empty duration")
+	}
+
+	parts := strings.Split(value, ":")
+	if len(parts) > 3 {
+		return 0, fmt.Errorf("invalid duration %q", value)
+	}
+
+	seconds := 0
+	for i, part := range parts {
+		n, err := strconv.Atoi(part)
+		if err != nil || n < 0 {
+			return 0, fmt.Errorf("invalid duration %q", value)
+		}
+		// Minutes and seconds after the leading field must stay below 60
+		if i > 0 && (n >= 60 || len(part) != 2) {
+			return 0, fmt.Errorf("invalid duration %q", value)
+		}
+		seconds = seconds*60 + n
+	}
+
+	if seconds == 0 {
+		return 0, fmt.Errorf("invalid duration %q", value)
+	}
+
+	return seconds, nil
+}
+
+// ParseOptionalSongDuration parses a duration form value, returning nil when it is empty
+func ParseOptionalSongDuration(value string) (*int, error) {
+	if strings.TrimSpace(value) == "" {
+		return nil, nil
+	}
+	seconds, err := ParseSongDuration(value)
+	if err != nil {
+		return nil, err
+	}
+	return &seconds, nil
+}
+
+// FormatSongDuration formats seconds as "m:ss", or "h:mm:ss" from one hour up
+func FormatSongDuration(seconds int) string {
+	hours := seconds / 3600
+	minutes := (seconds % 3600) / 60
+	secs := seconds % 60
+	if hours > 0 {
+		return fmt.Sprintf("%d:%02d:%02d", hours, minutes, secs)
+	}
+	return fmt.Sprintf("%d:%02d", minutes, secs)
+}
+
+// SongsRunningTime adds up the recorded durations of the given songs and
+// reports how many songs have no duration
+func SongsRunningTime(songs []*store.Song) (int, int) {
+	total, missing := 0, 0
+	for _, song := range songs {
+		if song.Duration == nil {
+			missing++
+			continue
+		}
+		total += *song.Duration
+	}
+	return total, missing
+}
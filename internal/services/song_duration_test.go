@@ -0,0 +1,142 @@
+package services
+
+import (
+	"testing"
+
+	"github.com/nahue/setlist_manager/internal/store"
+)
+
+func TestParseSongDuration(t *testing.T) {
+	tests := []struct {
+		value   string
+		want    int
+		wantErr bool
+	}{
+		{value: "3:45", want: 225},
+		{value: "0:05", want: 5},
+		{value: "10:00", want: 600},
+		{value: "75:00", want: 4500},
+		{value: "1:02:03", want: 3723},
+		{value: "90", want: 90},
+		{value: " 4:00 ", want: 240},
+		{value: "", wantErr: true},
+		{value: "   ", wantErr: true},
+		{value: "0", wantErr: true},
+		{value: "0:00", wantErr: true},
+		{value: "3:5", wantErr: true},
+		{value: "3:005", wantErr: true},
+		{value: "3:60", wantErr: true},
+		{value: "1:60:00", wantErr: true},
+		{value: "-3:00", wantErr: true},
+		{value: "3:-1", wantErr: true},
+		{value: "3:", wantErr: true},
+		{value: ":30", wantErr: true},
+		{value: "3:45.5", wantErr: true},
+		{value: "3m45s", wantErr: true},
+		{value: "1:00:00:00", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.value, func(t *testing.T) {
+			got, err := ParseSongDuration(tt.value)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseSongDuration(%q) error = %v, want error %v", tt.value, err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("ParseSongDuration(%q) = %d, want %d", tt.value, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseOptionalSongDuration(t *testing.T) {
+	tests := []struct {
+		value   string
+		want    *int
+		wantErr bool
+	}{
+		{value: "", want: nil},
+		{value: "  ", want: nil},
+		{value: "2:30", want: intPtr(150)},
+		{value: "2:3", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.value, func(t *testing.T) {
+			got, err := ParseOptionalSongDuration(tt.value)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseOptionalSongDuration(%q) error = %v, want error %v", tt.value, err, tt.wantErr)
+			}
+			if (got == nil) != (tt.want == nil) || got != nil && *got != *tt.want {
+				t.Errorf("ParseOptionalSongDuration(%q) = %v, want %v", tt.value, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFormatSongDuration(t *testing.T) {
+	tests := []struct {
+		seconds int
+		want    string
+	}{
+		{seconds: 0, want: "0:00"},
+		{seconds: 5, want: "0:05"},
+		{seconds: 60, want: "1:00"},
+		{seconds: 225, want: "3:45"},
+		{seconds: 3599, want: "59:59"},
+		{seconds: 3600, want: "1:00:00"},
+		{seconds: 3723, want: "1:02:03"},
+		{seconds: 36000, want: "10:00:00"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.want, func(t *testing.T) {
+			got := FormatSongDuration(tt.seconds)
+			if got != tt.want {
+				t.Errorf("FormatSongDuration(%d) = %q, want %q", tt.seconds, got, tt.want)
+			}
+			// Every formatted duration but zero parses back to the same length
+			if tt.seconds > 0 {
+				if back, err := ParseSongDuration(got); err != nil || back != tt.seconds {
+					t.Errorf("ParseSongDuration(%q) = %d, %v, want %d", got, back, err, tt.seconds)
+				}
+			}
+		})
+	}
+}
+
+func TestSongsRunningTime(t *testing.T) {
+	tests := []struct {
+		name        string
+		songs       []*store.Song
+		wantTotal   int
+		wantMissing int
+	}{
+		{name: "no songs"},
+		{
+			name:      "all timed",
+			songs:     []*store.Song{{Duration: intPtr(180)}, {Duration: intPtr(245)}},
+			wantTotal: 425,
+		},
+		{
+			name:        "some missing",
+			songs:       []*store.Song{{Duration: intPtr(180)}, {}, {}},
+			wantTotal:   180,
+			wantMissing: 2,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			total, missing := SongsRunningTime(tt.songs)
+			if total != tt.wantTotal || missing != tt.wantMissing {
+				t.Errorf("SongsRunningTime() = %d, %d, want %d, %d", total, missing, tt.wantTotal, tt.wantMissing)
+			}
+		})
+	}
+}
+
+// intPtr returns a pointer to the value, for optional song fields
+func intPtr(n int) *int {
+	return &n
+}
@@ -0,0 +1,295 @@
+package services
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/nahue/setlist_manager/internal/store"
+	"github.com/nahue/setlist_manager/internal/transpose"
+)
+
+// MaxImportRows is the largest number of songs accepted in one import file
+const MaxImportRows = 500
+
+// importColumns maps the accepted CSV headers and JSON keys, in English and Spanish,
+// to the song field they fill
+var importColumns = map[string]string{
+	"title": "title", "titulo": "title", "título": "title", "name": "title", "nombre": "title",
+	"artist": "artist", "artista": "artist",
+	"key": "key", "tonalidad": "key", "tono": "key",
+	"tempo": "tempo", "bpm": "tempo",
+	"duration": "duration", "duracion": "duration", "duración": "duration",
+	"notes": "notes", "notas": "notes",
+	"content": "content", "contenido": "content",
+}
+
+// SongImportRow is one song read from an import file, with the problems found validating it
+type SongImportRow struct {
+	Line      int
+	Title     string
+	Artist    string
+	Key       string
+	Tempo     *int
+	Duration  *int
+	Notes     string
+	Content   string
+	Errors    []string
+	Duplicate bool
+}
+
+// Valid reports whether the row can be imported
+func (r *SongImportRow) Valid() bool {
+	return len(r.Errors) == 0
+}
+
+// SongImport is the parsed and validated content of an import file
+type SongImport struct {
+	Rows []*SongImportRow
+}
+
+// Importable returns the rows that will be inserted: valid rows, and duplicates only when requested
+func (i *SongImport) Importable(includeDuplicates bool) []*SongImportRow {
+	var rows []*SongImportRow
+	for _, row := range i.Rows {
+		if row.Valid() && (includeDuplicates || !row.Duplicate) {
+			rows = append(rows, row)
+		}
+	}
+	return rows
+}
+
+// InvalidCount returns the number of rows with errors
+func (i *SongImport) InvalidCount() int {
+	count := 0
+	for _, row := range i.Rows {
+		if !row.Valid() {
+			count++
+		}
+	}
+	return count
+}
+
+// DuplicateCount returns the number of valid rows that repeat an existing song or an earlier row
+func (i *SongImport) DuplicateCount() int {
+	count := 0
+	for _, row := range i.Rows {
+		if row.Valid() && row.Duplicate {
+			count++
+		}
+	}
+	return count
+}
+
+// ParseSongImport reads a CSV or JSON song list, validates every row and flags
+// rows that duplicate one of the existing songs or an earlier row of the file.
+// The format is taken from the file extension, or guessed from the content.
+func ParseSongImport(filename string, data []byte, existing []*store.Song) (*SongImport, error) {
+	data = bytes.TrimPrefix(data, []byte("\ufeff"))
+
+	var records []map[string]string
+	var lines []int
+	var err error
+	if isJSONImport(filename, data) {
+		records, err = parseJSONImport(data)
+		// JSON rows are numbered by their position in the list
+		for i := range records {
+			lines = append(lines, i+1)
+		}
+	} else {
+		records, lines, err = parseCSVImport(data)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if len(records) == 0 {
+		return nil, fmt.Errorf("the file has no songs")
+	}
+	if len(records) > MaxImportRows {
+		return nil, fmt.Errorf("the file has %d songs, the limit is %d", len(records), MaxImportRows)
+	}
+
+	seen := make(map[string]bool, len(existing)+len(records))
+	for _, song := range existing {
+		seen[songImportKey(song.Title, song.Artist)] = true
+	}
+
+	result := &SongImport{}
+	for i, record := range records {
+		row := validateImportRecord(record)
+		row.Line = lines[i]
+		if row.Title != "" {
+			key := songImportKey(row.Title, row.Artist)
+			row.Duplicate = seen[key]
+			seen[key] = true
+		}
+		result.Rows = append(result.Rows, row)
+	}
+
+	return result, nil
+}
+
+// isJSONImport reports whether an import file should be read as JSON
+func isJSONImport(filename string, data []byte) bool {
+	switch strings.ToLower(filepath.Ext(filename)) {
+	case ".json":
+		return true
+	case ".csv":
+		return false
+	}
+	trimmed := bytes.TrimSpace(data)
+	return len(trimmed) > 0 && (trimmed[0] == '[' || trimmed[0] == '{')
+}
+
+// parseCSVImport reads a CSV file whose first row names the columns. It returns
+// each record keyed by song field along with the line it started on.
+func parseCSVImport(data []byte) ([]map[string]string, []int, error) {
+	reader := csv.NewReader(bytes.NewReader(data))
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+
+	// Spreadsheets in Spanish locales usually export with semicolons
+	firstLine, _, _ := strings.Cut(string(data), "\n")
+	if strings.Count(firstLine, ";") > strings.Count(firstLine, ",") {
+		reader.Comma = ';'
+	}
+
+	header, err := reader.Read()
+	if err == io.EOF {
+		return nil, nil, nil
+	}
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to read CSV header: %w", err)
+	}
+
+	fields := make([]string, len(header))
+	hasTitle := false
+	for i, name := range header {
+		fields[i] = importColumns[strings.ToLower(strings.TrimSpace(name))]
+		if fields[i] == "title" {
+			hasTitle = true
+		}
+	}
+	if !hasTitle {
+		return nil, nil, fmt.Errorf("the CSV header must include a title column")
+	}
+
+	var records []map[string]string
+	var lines []int
+	for {
+		values, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to read CSV: %w", err)
+		}
+		line, _ := reader.FieldPos(0)
+
+		record := make(map[string]string)
+		empty := true
+		for i, value := range values {
+			if i >= len(fields) || fields[i] == "" {
+				continue
+			}
+			record[fields[i]] = value
+			if strings.TrimSpace(value) != "" {
+				empty = false
+			}
+		}
+		if empty {
+			continue
+		}
+		records = append(records, record)
+		lines = append(lines, line)
+	}
+
+	return records, lines, nil
+}
+
+// parseJSONImport reads a JSON list of songs, either as a bare array or as {"songs": [...]}
+func parseJSONImport(data []byte) ([]map[string]string, error) {
+	var items []map[string]any
+	if err := json.Unmarshal(data, &items); err != nil {
+		var wrapper struct {
+			Songs []map[string]any `json:"songs"`
+		}
+		if wrapperErr := json.Unmarshal(data, &wrapper); wrapperErr != nil {
+			return nil, fmt.Errorf("failed to parse JSON: %w", err)
+		}
+		items = wrapper.Songs
+	}
+
+	records := make([]map[string]string, 0, len(items))
+	for _, item := range items {
+		record := make(map[string]string)
+		for name, value := range item {
+			field := importColumns[strings.ToLower(name)]
+			if field == "" || value == nil {
+				continue
+			}
+			switch v := value.(type) {
+			case string:
+				record[field] = v
+			case float64:
+				record[field] = strconv.FormatFloat(v, 'f', -1, 64)
+			default:
+				record[field] = fmt.Sprint(v)
+			}
+		}
+		records = append(records, record)
+	}
+
+	return records, nil
+}
+
+// validateImportRecord builds an import row from a record and checks each of its fields
+func validateImportRecord(record map[string]string) *SongImportRow {
+	row := &SongImportRow{
+		Title:   strings.TrimSpace(record["title"]),
+		Artist:  strings.TrimSpace(record["artist"]),
+		Key:     strings.TrimSpace(record["key"]),
+		Notes:   strings.TrimSpace(record["notes"]),
+		Content: strings.TrimSpace(record["content"]),
+	}
+
+	if row.Title == "" {
+		row.Errors = append(row.Errors, "Falta el título")
+	}
+
+	if row.Key != "" {
+		if _, _, err := transpose.ParseKey(row.Key); err != nil {
+			row.Errors = append(row.Errors, fmt.Sprintf("Tonalidad inválida %q", row.Key))
+		}
+	}
+
+	if value := strings.TrimSpace(record["tempo"]); value != "" {
+		tempo, err := strconv.Atoi(value)
+		if err != nil || tempo < 1 || tempo > 300 {
+			row.Errors = append(row.Errors, fmt.Sprintf("Tempo inválido %q, debe estar entre 1 y 300", value))
+		} else {
+			row.Tempo = &tempo
+		}
+	}
+
+	if value := strings.TrimSpace(record["duration"]); value != "" {
+		duration, err := ParseSongDuration(value)
+		if err != nil {
+			row.Errors = append(row.Errors, fmt.Sprintf("Duración inválida %q, usa el formato m:ss", value))
+		} else {
+			row.Duration = &duration
+		}
+	}
+
+	return row
+}
+
+// songImportKey identifies a song for duplicate detection by its title and artist, ignoring case
+func songImportKey(title, artist string) string {
+	return strings.ToLower(strings.TrimSpace(title)) + "\x00" + strings.ToLower(strings.TrimSpace(artist))
+}
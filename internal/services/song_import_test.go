@@ -0,0 +1,202 @@
+package services
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/nahue/setlist_manager/internal/store"
+)
+
+// importRowSummary is the part of an import row the tests compare
+type importRowSummary struct {
+	Line      int
+	Title     string
+	Artist    string
+	Key       string
+	Tempo     int
+	Duration  int
+	Content   string
+	Errors    int
+	Duplicate bool
+}
+
+// summarizeImportRows reduces import rows to what the tests compare
+func summarizeImportRows(rows []*SongImportRow) []importRowSummary {
+	var summaries []importRowSummary
+	for _, row := range rows {
+		summary := importRowSummary{
+			Line:      row.Line,
+			Title:     row.Title,
+			Artist:    row.Artist,
+			Key:       row.Key,
+			Content:   row.Content,
+			Errors:    len(row.Errors),
+			Duplicate: row.Duplicate,
+		}
+		if row.Tempo != nil {
+			summary.Tempo = *row.Tempo
+		}
+		if row.Duration != nil {
+			summary.Duration = *row.Duration
+		}
+		summaries = append(summaries, summary)
+	}
+	return summaries
+}
+
+func TestParseSongImport(t *testing.T) {
+	tests := []struct {
+		name     string
+		filename string
+		data     string
+		existing []*store.Song
+		want     []importRowSummary
+	}{
+		{
+			name:     "csv",
+			filename: "songs.csv",
+			data:     "title,artist,key,tempo,duration\nWonderwall,Oasis,F#m,87,4:18\nYesterday,The Beatles,F,,2:05\n",
+			want: []importRowSummary{
+				{Line: 2, Title: "Wonderwall", Artist: "Oasis", Key: "F#m", Tempo: 87, Duration: 258},
+				{Line: 3, Title: "Yesterday", Artist: "The Beatles", Key: "F", Duration: 125},
+			},
+		},
+		{
+			name:     "csv with Spanish headers, semicolons and a byte order mark",
+			filename: "canciones.csv",
+			data:     "\ufeffTítulo;Artista;Tonalidad;BPM\nDe música ligera;Soda Stereo;Bm;128\n",
+			want: []importRowSummary{
+				{Line: 2, Title: "De música ligera", Artist: "Soda Stereo", Key: "Bm", Tempo: 128},
+			},
+		},
+		{
+			name:     "csv with unknown columns, blank rows and multi-line content",
+			filename: "songs.csv",
+			data:     "Title,Rating,Content\n\"Let It Be\",5,\"C G\nWhen I find myself\"\n,,\n\nHey Jude,4,\n",
+			want: []importRowSummary{
+				{Line: 2, Title: "Let It Be", Content: "C G\nWhen I find myself"},
+				{Line: 6, Title: "Hey Jude"},
+			},
+		},
+		{
+			name:     "csv row errors",
+			filename: "songs.csv",
+			data:     "title,key,tempo,duration\n,C,,\nBad key,H,,\nBad tempo,C,fast,\nToo fast,C,301,\nBad duration,C,,3:5\nAll wrong,X,0,abc\n",
+			want: []importRowSummary{
+				{Line: 2, Key: "C", Errors: 1},
+				{Line: 3, Title: "Bad key", Key: "H", Errors: 1},
+				{Line: 4, Title: "Bad tempo", Key: "C", Errors: 1},
+				{Line: 5, Title: "Too fast", Key: "C", Errors: 1},
+				{Line: 6, Title: "Bad duration", Key: "C", Errors: 1},
+				{Line: 7, Title: "All wrong", Key: "X", Errors: 3},
+			},
+		},
+		{
+			name:     "json array",
+			filename: "songs.json",
+			data:     `[{"title": "Wonderwall", "artist": "Oasis", "tempo": 87, "duration": "4:18"}, {"nombre": "Yesterday", "tono": "F", "bpm": "97"}]`,
+			want: []importRowSummary{
+				{Line: 1, Title: "Wonderwall", Artist: "Oasis", Tempo: 87, Duration: 258},
+				{Line: 2, Title: "Yesterday", Key: "F", Tempo: 97},
+			},
+		},
+		{
+			name:     "json wrapper detected without an extension",
+			filename: "export",
+			data:     `{"songs": [{"Title": "Hey Jude", "tempo": 72.5, "notes": null}, {"artist": "Nobody"}]}`,
+			want: []importRowSummary{
+				{Line: 1, Title: "Hey Jude", Errors: 1},
+				{Line: 2, Artist: "Nobody", Errors: 1},
+			},
+		},
+		{
+			name:     "duplicates of existing songs and earlier rows",
+			filename: "songs.csv",
+			data:     "title,artist\nwonderwall,OASIS\nYesterday,The Beatles\n yesterday , the beatles \nYesterday,Boyz II Men\n,Oasis\n",
+			existing: []*store.Song{{Title: "Wonderwall", Artist: "Oasis"}},
+			want: []importRowSummary{
+				{Line: 2, Title: "wonderwall", Artist: "OASIS", Duplicate: true},
+				{Line: 3, Title: "Yesterday", Artist: "The Beatles"},
+				{Line: 4, Title: "yesterday", Artist: "the beatles", Duplicate: true},
+				{Line: 5, Title: "Yesterday", Artist: "Boyz II Men"},
+				{Line: 6, Artist: "Oasis", Errors: 1},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseSongImport(tt.filename, []byte(tt.data), tt.existing)
+			if err != nil {
+				t.Fatalf("ParseSongImport() error = %v", err)
+			}
+			if rows := summarizeImportRows(got.Rows); !reflect.DeepEqual(rows, tt.want) {
+				t.Errorf("rows =\n%+v\nwant\n%+v", rows, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseSongImportErrors(t *testing.T) {
+	tooMany := "title\n" + strings.Repeat("Song\n", MaxImportRows+1)
+
+	tests := []struct {
+		name     string
+		filename string
+		data     string
+		wantErr  string
+	}{
+		{name: "empty csv", filename: "songs.csv", data: "", wantErr: "the file has no songs"},
+		{name: "header only", filename: "songs.csv", data: "title,artist\n", wantErr: "the file has no songs"},
+		{name: "no title column", filename: "songs.csv", data: "artist,key\nOasis,C\n", wantErr: "title column"},
+		{name: "broken csv", filename: "songs.csv", data: "title\n\"unterminated\n", wantErr: "failed to read CSV"},
+		{name: "empty json list", filename: "songs.json", data: "[]", wantErr: "the file has no songs"},
+		{name: "invalid json", filename: "songs.json", data: "[{", wantErr: "failed to parse JSON"},
+		{name: "too many rows", filename: "songs.csv", data: tooMany, wantErr: fmt.Sprintf("the limit is %d", MaxImportRows)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := ParseSongImport(tt.filename, []byte(tt.data), nil)
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("ParseSongImport() error = %v, want one containing %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestSongImportCounts(t *testing.T) {
+	data := "title,artist,tempo\nWonderwall,Oasis,\nYesterday,,\nYesterday,,\n,,90\nHey Jude,,fast\n"
+	existing := []*store.Song{{Title: "Wonderwall", Artist: "Oasis"}}
+
+	songImport, err := ParseSongImport("songs.csv", []byte(data), existing)
+	if err != nil {
+		t.Fatalf("ParseSongImport() error = %v", err)
+	}
+
+	if got := songImport.InvalidCount(); got != 2 {
+		t.Errorf("InvalidCount() = %d, want 2", got)
+	}
+	if got := songImport.DuplicateCount(); got != 2 {
+		t.Errorf("DuplicateCount() = %d, want 2", got)
+	}
+
+	tests := []struct {
+		includeDuplicates bool
+		wantLines         []int
+	}{
+		{includeDuplicates: false, wantLines: []int{3}},
+		{includeDuplicates: true, wantLines: []int{2, 3, 4}},
+	}
+	for _, tt := range tests {
+		var lines []int
+		for _, row := range songImport.Importable(tt.includeDuplicates) {
+			lines = append(lines, row.Line)
+		}
+		if !reflect.DeepEqual(lines, tt.wantLines) {
+			t.Errorf("Importable(%v) lines = %v, want %v", tt.includeDuplicates, lines, tt.wantLines)
+		}
+	}
+}
@@ -0,0 +1,63 @@
+package services
+
+import (
+	"strconv"
+
+	"github.com/nahue/setlist_manager/internal/store"
+	"github.com/nahue/setlist_manager/internal/textdiff"
+)
+
+// SongFieldChange is a song field that differs between two revisions
+type SongFieldChange struct {
+	Label string
+	Old   string
+	New   string
+}
+
+// SongRevisionDiff compares two revisions of a song: the metadata fields that
+// changed and a line diff of the content
+type SongRevisionDiff struct {
+	From    *store.SongRevision
+	To      *store.SongRevision
+	Fields  []SongFieldChange
+	Content []textdiff.Line
+}
+
+// Changed reports whether the revisions differ at all
+func (d *SongRevisionDiff) Changed() bool {
+	return len(d.Fields) > 0 || textdiff.Changed(d.Content)
+}
+
+// DiffSongRevisions compares an older revision of a song with a newer one
+func DiffSongRevisions(from, to *store.SongRevision) *SongRevisionDiff {
+	diff := &SongRevisionDiff{From: from, To: to}
+	field := func(label, oldValue, newValue string) {
+		if oldValue != newValue {
+			diff.Fields = append(diff.Fields, SongFieldChange{Label: label, Old: oldValue, New: newValue})
+		}
+	}
+	field("Título", from.Title, to.Title)
+	field("Artista", from.Artist, to.Artist)
+	field("Tonalidad", from.Key, to.Key)
+	field("Tempo", optionalIntLabel(from.Tempo), optionalIntLabel(to.Tempo))
+	field("Duración", optionalDurationLabel(from.Duration), optionalDurationLabel(to.Duration))
+	field("Notas", from.Notes, to.Notes)
+	diff.Content = textdiff.Lines(from.Content, to.Content)
+	return diff
+}
+
+// optionalIntLabel formats an optional number, empty when unset
+func optionalIntLabel(value *int) string {
+	if value == nil {
+		return ""
+	}
+	return strconv.Itoa(*value)
+}
+
+// optionalDurationLabel formats an optional duration as m:ss, empty when unset
+func optionalDurationLabel(seconds *int) string {
+	if seconds == nil {
+		return ""
+	}
+	return FormatSongDuration(*seconds)
+}
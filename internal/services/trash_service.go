@@ -0,0 +1,113 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/nahue/setlist_manager/internal/store"
+)
+
+// Defaults for the trash
+const (
+	DefaultTrashRetention = 30 * 24 * time.Hour
+	trashPurgeInterval    = time.Hour
+)
+
+// TrashService keeps deleted songs and bands recoverable for a retention
+// period, and permanently deletes them once it has passed
+type TrashService struct {
+	songsDB   *store.SQLiteSongsStore
+	bandsDB   *store.SQLiteBandsStore
+	retention time.Duration
+
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+}
+
+// NewTrashService creates a trash service that keeps deleted items for the
+// given retention, or DefaultTrashRetention when it isn't positive
+func NewTrashService(songsDB *store.SQLiteSongsStore, bandsDB *store.SQLiteBandsStore, retention time.Duration) *TrashService {
+	if retention <= 0 {
+		retention = DefaultTrashRetention
+	}
+	return &TrashService{
+		songsDB:   songsDB,
+		bandsDB:   bandsDB,
+		retention: retention,
+	}
+}
+
+// TrashRetentionFromEnv reads how long deleted items are kept from
+// TRASH_RETENTION, e.g. "720h". It returns 0 when the variable isn't set.
+func TrashRetentionFromEnv() (time.Duration, error) {
+	value := os.Getenv("TRASH_RETENTION")
+	if value == "" {
+		return 0, nil
+	}
+	retention, err := time.ParseDuration(value)
+	if err != nil || retention <= 0 {
+		return 0, fmt.Errorf("invalid TRASH_RETENTION %q, use a duration like 720h", value)
+	}
+	return retention, nil
+}
+
+// Retention is how long deleted items are kept before they are purged
+func (s *TrashService) Retention() time.Duration {
+	return s.retention
+}
+
+// PurgeDate is when an item deleted at the given time will be purged
+func (s *TrashService) PurgeDate(deletedAt time.Time) time.Time {
+	return deletedAt.Add(s.retention)
+}
+
+// Purge permanently deletes the songs and bands that have been in the trash
+// longer than the retention period
+func (s *TrashService) Purge() error {
+	before := time.Now().Add(-s.retention)
+
+	songs, err := s.songsDB.PurgeDeletedSongs(before)
+	if err != nil {
+		return err
+	}
+	bands, err := s.bandsDB.PurgeDeletedBands(before)
+	if err != nil {
+		return err
+	}
+	if songs > 0 || bands > 0 {
+		log.Printf("Purged %d songs and %d bands from the trash", songs, bands)
+	}
+	return nil
+}
+
+// Start purges the trash now and then every trashPurgeInterval until Stop is called
+func (s *TrashService) Start() {
+	ctx, cancel := context.WithCancel(context.Background())
+	s.cancel = cancel
+	s.wg.Add(1)
+	go func() {
+		defer s.wg.Done()
+		for {
+			if err := s.Purge(); err != nil {
+				log.Printf("Error purging trash: %v", err)
+			}
+			select {
+			case <-ctx.Done():
+				return
+			case <-time.After(trashPurgeInterval):
+			}
+		}
+	}()
+}
+
+// Stop stops the scheduled purges
+func (s *TrashService) Stop() {
+	if s.cancel != nil {
+		s.cancel()
+	}
+	s.wg.Wait()
+}
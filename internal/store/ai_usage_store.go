@@ -0,0 +1,257 @@
+package store
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+)
+
+// Database handles AI cache and usage database operations
+type SQLiteAIUsageStore struct {
+	db *sql.DB
+}
+
+// NewSQLiteAIUsageStore creates a new AI usage database instance
+func NewSQLiteAIUsageStore(db *sql.DB) *SQLiteAIUsageStore {
+	return &SQLiteAIUsageStore{db: db}
+}
+
+// AICacheEntry is a reply from the AI provider kept for reuse, with the tokens it
+// used when it was generated
+type AICacheEntry struct {
+	Provider     string    `json:"provider"`
+	Model        string    `json:"model"`
+	Content      string    `json:"content"`
+	InputTokens  int       `json:"input_tokens"`
+	OutputTokens int       `json:"output_tokens"`
+	CreatedAt    time.Time `json:"created_at"`
+}
+
+// AIUsage is one AI request made for a band. Cached requests were answered from
+// the cache; their tokens are the ones the reply used when it was generated.
+type AIUsage struct {
+	ID           string    `json:"id"`
+	BandID       string    `json:"band_id"`
+	UserID       string    `json:"user_id"`
+	SongID       string    `json:"song_id,omitempty"`
+	Role         string    `json:"role"`
+	Provider     string    `json:"provider"`
+	Model        string    `json:"model"`
+	InputTokens  int       `json:"input_tokens"`
+	OutputTokens int       `json:"output_tokens"`
+	Cached       bool      `json:"cached"`
+	CreatedAt    time.Time `json:"created_at"`
+	SongTitle    string    `json:"song_title,omitempty"`
+	User         *User     `json:"user,omitempty"`
+}
+
+// AIUsageTotals sums AI requests. InputTokens and OutputTokens count the requests
+// sent to the provider; SavedTokens counts the ones answered from the cache.
+type AIUsageTotals struct {
+	Requests       int `json:"requests"`
+	CachedRequests int `json:"cached_requests"`
+	InputTokens    int `json:"input_tokens"`
+	OutputTokens   int `json:"output_tokens"`
+	SavedTokens    int `json:"saved_tokens"`
+}
+
+// Tokens returns the tokens sent to and received from the provider
+func (t *AIUsageTotals) Tokens() int {
+	return t.InputTokens + t.OutputTokens
+}
+
+// AIUserUsage is a member's share of a band's AI usage
+type AIUserUsage struct {
+	User *User `json:"user"`
+	AIUsageTotals
+}
+
+// aiUsageTotalsColumns sums ai_usage rows into AIUsageTotals, in scan order
+const aiUsageTotalsColumns = `
+	COUNT(*),
+	COALESCE(SUM(CASE WHEN cached THEN 1 ELSE 0 END), 0),
+	COALESCE(SUM(CASE WHEN cached THEN 0 ELSE input_tokens END), 0),
+	COALESCE(SUM(CASE WHEN cached THEN 0 ELSE output_tokens END), 0),
+	COALESCE(SUM(CASE WHEN cached THEN input_tokens + output_tokens ELSE 0 END), 0)
+`
+
+// GetAICacheEntry gets the reply cached under a key since the given time, or nil
+// when there is none
+func (d *SQLiteAIUsageStore) GetAICacheEntry(cacheKey string, since time.Time) (*AICacheEntry, error) {
+	query := `
+		SELECT provider, model, content, input_tokens, output_tokens, created_at
+		FROM ai_cache
+		WHERE cache_key = ? AND created_at >= ?
+	`
+	var entry AICacheEntry
+	err := d.db.QueryRow(query, cacheKey, since).Scan(
+		&entry.Provider,
+		&entry.Model,
+		&entry.Content,
+		&entry.InputTokens,
+		&entry.OutputTokens,
+		&entry.CreatedAt,
+	)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to get AI cache entry: %w", err)
+	}
+	return &entry, nil
+}
+
+// SaveAICacheEntry caches a reply under a key, replacing the one cached before
+func (d *SQLiteAIUsageStore) SaveAICacheEntry(cacheKey string, entry *AICacheEntry) error {
+	query := `
+		INSERT INTO ai_cache (cache_key, provider, model, content, input_tokens, output_tokens, created_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?)
+		ON CONFLICT(cache_key) DO UPDATE SET
+			provider = excluded.provider,
+			model = excluded.model,
+			content = excluded.content,
+			input_tokens = excluded.input_tokens,
+			output_tokens = excluded.output_tokens,
+			created_at = excluded.created_at
+	`
+	_, err := d.db.Exec(query, cacheKey, entry.Provider, entry.Model, entry.Content, entry.InputTokens, entry.OutputTokens, time.Now())
+	if err != nil {
+		return fmt.Errorf("failed to save AI cache entry: %w", err)
+	}
+	return nil
+}
+
+// CleanupExpiredAICache removes replies cached before the given time
+func (d *SQLiteAIUsageStore) CleanupExpiredAICache(before time.Time) error {
+	_, err := d.db.Exec(`DELETE FROM ai_cache WHERE created_at < ?`, before)
+	if err != nil {
+		return fmt.Errorf("failed to cleanup AI cache: %w", err)
+	}
+	return nil
+}
+
+// RecordAIUsage records an AI request
+func (d *SQLiteAIUsageStore) RecordAIUsage(usage *AIUsage) error {
+	usage.ID = generateUUID()
+	usage.CreatedAt = time.Now()
+
+	var songID any
+	if usage.SongID != "" {
+		songID = usage.SongID
+	}
+
+	query := `
+		INSERT INTO ai_usage (id, band_id, user_id, song_id, role, provider, model, input_tokens, output_tokens, cached, created_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+	`
+	_, err := d.db.Exec(query, usage.ID, usage.BandID, usage.UserID, songID, usage.Role, usage.Provider, usage.Model, usage.InputTokens, usage.OutputTokens, usage.Cached, usage.CreatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to record AI usage: %w", err)
+	}
+	return nil
+}
+
+// GetBandAIUsageTotals sums a band's AI requests since the given time
+func (d *SQLiteAIUsageStore) GetBandAIUsageTotals(bandID string, since time.Time) (*AIUsageTotals, error) {
+	var totals AIUsageTotals
+	query := `SELECT ` + aiUsageTotalsColumns + ` FROM ai_usage WHERE band_id = ? AND created_at >= ?`
+	err := d.db.QueryRow(query, bandID, since).Scan(&totals.Requests, &totals.CachedRequests, &totals.InputTokens, &totals.OutputTokens, &totals.SavedTokens)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get band AI usage: %w", err)
+	}
+	return &totals, nil
+}
+
+// GetUserAIUsageTotals sums a user's AI requests across bands since the given time
+func (d *SQLiteAIUsageStore) GetUserAIUsageTotals(userID string, since time.Time) (*AIUsageTotals, error) {
+	var totals AIUsageTotals
+	query := `SELECT ` + aiUsageTotalsColumns + ` FROM ai_usage WHERE user_id = ? AND created_at >= ?`
+	err := d.db.QueryRow(query, userID, since).Scan(&totals.Requests, &totals.CachedRequests, &totals.InputTokens, &totals.OutputTokens, &totals.SavedTokens)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get user AI usage: %w", err)
+	}
+	return &totals, nil
+}
+
+// GetBandAIUsageByUser sums a band's AI requests since the given time for each
+// member who made any, heaviest users first
+func (d *SQLiteAIUsageStore) GetBandAIUsageByUser(bandID string, since time.Time) ([]*AIUserUsage, error) {
+	query := `
+		SELECT u.id, u.email, ` + aiUsageTotalsColumns + `
+		FROM ai_usage
+		INNER JOIN users u ON ai_usage.user_id = u.id
+		WHERE ai_usage.band_id = ? AND ai_usage.created_at >= ?
+		GROUP BY u.id, u.email
+		ORDER BY SUM(CASE WHEN cached THEN 0 ELSE input_tokens + output_tokens END) DESC
+	`
+	rows, err := d.db.Query(query, bandID, since)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get band AI usage by user: %w", err)
+	}
+	defer rows.Close()
+
+	var usages []*AIUserUsage
+	for rows.Next() {
+		var usage AIUserUsage
+		var user User
+		err := rows.Scan(&user.ID, &user.Email, &usage.Requests, &usage.CachedRequests, &usage.InputTokens, &usage.OutputTokens, &usage.SavedTokens)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan AI usage: %w", err)
+		}
+		usage.User = &user
+		usages = append(usages, &usage)
+	}
+
+	return usages, nil
+}
+
+// GetRecentAIUsage gets a band's latest AI requests, newest first
+func (d *SQLiteAIUsageStore) GetRecentAIUsage(bandID string, limit int) ([]*AIUsage, error) {
+	query := `
+		SELECT a.id, a.band_id, a.user_id, a.song_id, a.role, a.provider, a.model, a.input_tokens, a.output_tokens, a.cached, a.created_at,
+		       s.title, u.id, u.email
+		FROM ai_usage a
+		INNER JOIN users u ON a.user_id = u.id
+		LEFT JOIN songs s ON a.song_id = s.id
+		WHERE a.band_id = ?
+		ORDER BY a.created_at DESC
+		LIMIT ?
+	`
+	rows, err := d.db.Query(query, bandID, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get AI usage: %w", err)
+	}
+	defer rows.Close()
+
+	var usages []*AIUsage
+	for rows.Next() {
+		var usage AIUsage
+		var user User
+		var songID, songTitle sql.NullString
+		err := rows.Scan(
+			&usage.ID,
+			&usage.BandID,
+			&usage.UserID,
+			&songID,
+			&usage.Role,
+			&usage.Provider,
+			&usage.Model,
+			&usage.InputTokens,
+			&usage.OutputTokens,
+			&usage.Cached,
+			&usage.CreatedAt,
+			&songTitle,
+			&user.ID,
+			&user.Email,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan AI usage: %w", err)
+		}
+		usage.SongID = songID.String
+		usage.SongTitle = songTitle.String
+		usage.User = &user
+		usages = append(usages, &usage)
+	}
+
+	return usages, nil
+}
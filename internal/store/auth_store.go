@@ -3,6 +3,7 @@ package store
 import (
 	"database/sql"
 	"fmt"
+	"strings"
 	"time"
 )
 
@@ -44,6 +45,20 @@ type Session struct {
 	CreatedAt    time.Time `json:"created_at"`
 }
 
+// APIToken is a personal access token that lets a user's scripts call the
+// JSON API. Only the hash of the token is stored.
+type APIToken struct {
+	ID         string     `json:"id"`
+	UserID     string     `json:"user_id"`
+	Name       string     `json:"name"`
+	TokenHash  string     `json:"-"`
+	Scopes     []string   `json:"scopes"`
+	ExpiresAt  time.Time  `json:"expires_at"`
+	LastUsedAt *time.Time `json:"last_used_at,omitempty"`
+	RevokedAt  *time.Time `json:"revoked_at,omitempty"`
+	CreatedAt  time.Time  `json:"created_at"`
+}
+
 // CreateUser creates a new user
 func (d *SQLiteAuthStore) CreateUser(email string) (*User, error) {
 	userID := generateUUID()
@@ -261,3 +276,125 @@ func (d *SQLiteAuthStore) CleanupExpiredSessions() error {
 	}
 	return nil
 }
+
+// CreateAPIToken creates a personal access token for a user
+func (d *SQLiteAuthStore) CreateAPIToken(userID, name, tokenHash string, scopes []string, expiresAt time.Time) (*APIToken, error) {
+	tokenID := generateUUID()
+
+	query := `INSERT INTO api_tokens (id, user_id, name, token_hash, scopes, expires_at) VALUES (?, ?, ?, ?, ?, ?)`
+	_, err := d.db.Exec(query, tokenID, userID, name, tokenHash, strings.Join(scopes, ","), expiresAt)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create API token: %w", err)
+	}
+
+	return &APIToken{
+		ID:        tokenID,
+		UserID:    userID,
+		Name:      name,
+		TokenHash: tokenHash,
+		Scopes:    scopes,
+		ExpiresAt: expiresAt,
+		CreatedAt: time.Now(),
+	}, nil
+}
+
+// GetAPITokenByHash gets a personal access token by the hash of the token
+func (d *SQLiteAuthStore) GetAPITokenByHash(tokenHash string) (*APIToken, error) {
+	query := `SELECT id, user_id, name, token_hash, scopes, expires_at, last_used_at, revoked_at, created_at
+			  FROM api_tokens WHERE token_hash = ?`
+
+	token, err := scanAPIToken(d.db.QueryRow(query, tokenHash))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to get API token: %w", err)
+	}
+
+	return token, nil
+}
+
+// GetAPITokensByUser gets the personal access tokens of a user that weren't
+// revoked, newest first
+func (d *SQLiteAuthStore) GetAPITokensByUser(userID string) ([]*APIToken, error) {
+	query := `SELECT id, user_id, name, token_hash, scopes, expires_at, last_used_at, revoked_at, created_at
+			  FROM api_tokens
+			  WHERE user_id = ? AND revoked_at IS NULL
+			  ORDER BY created_at DESC`
+
+	rows, err := d.db.Query(query, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get API tokens: %w", err)
+	}
+	defer rows.Close()
+
+	var tokens []*APIToken
+	for rows.Next() {
+		token, err := scanAPIToken(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan API token: %w", err)
+		}
+		tokens = append(tokens, token)
+	}
+
+	return tokens, rows.Err()
+}
+
+// UpdateAPITokenLastUsed records that a personal access token was just used
+func (d *SQLiteAuthStore) UpdateAPITokenLastUsed(tokenID string) error {
+	query := `UPDATE api_tokens SET last_used_at = ? WHERE id = ?`
+	_, err := d.db.Exec(query, time.Now(), tokenID)
+	if err != nil {
+		return fmt.Errorf("failed to update API token last use: %w", err)
+	}
+	return nil
+}
+
+// RevokeAPIToken revokes one of a user's personal access tokens, returning
+// false when the user has no such token left to revoke
+func (d *SQLiteAuthStore) RevokeAPIToken(tokenID, userID string) (bool, error) {
+	query := `UPDATE api_tokens SET revoked_at = ? WHERE id = ? AND user_id = ? AND revoked_at IS NULL`
+	result, err := d.db.Exec(query, time.Now(), tokenID, userID)
+	if err != nil {
+		return false, fmt.Errorf("failed to revoke API token: %w", err)
+	}
+	n, err := result.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("failed to revoke API token: %w", err)
+	}
+	return n > 0, nil
+}
+
+// scanAPIToken scans an api_tokens row selected with every column
+func scanAPIToken(row interface{ Scan(...any) error }) (*APIToken, error) {
+	var token APIToken
+	var scopes string
+	var lastUsedAt, revokedAt sql.NullTime
+
+	err := row.Scan(
+		&token.ID,
+		&token.UserID,
+		&token.Name,
+		&token.TokenHash,
+		&scopes,
+		&token.ExpiresAt,
+		&lastUsedAt,
+		&revokedAt,
+		&token.CreatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	if scopes != "" {
+		token.Scopes = strings.Split(scopes, ",")
+	}
+	if lastUsedAt.Valid {
+		token.LastUsedAt = &lastUsedAt.Time
+	}
+	if revokedAt.Valid {
+		token.RevokedAt = &revokedAt.Time
+	}
+
+	return &token, nil
+}
@@ -27,8 +27,19 @@ type Band struct {
 	CreatedAt   time.Time `json:"created_at"`
 	UpdatedAt   time.Time `json:"updated_at"`
 	IsActive    bool      `json:"is_active"`
+	// DeletedAt and DeletedBy are set for bands in the trash
+	DeletedAt *time.Time `json:"deleted_at,omitempty"`
+	DeletedBy *User      `json:"deleted_by,omitempty"`
 }
 
+// Band member roles, from most to least privileged
+const (
+	RoleOwner  = "owner"
+	RoleAdmin  = "admin"
+	RoleEditor = "editor"
+	RoleViewer = "viewer"
+)
+
 // BandMember represents a band member
 type BandMember struct {
 	ID       string    `json:"id"`
@@ -49,13 +60,35 @@ type BandInvitation struct {
 	Role          string     `json:"role"`
 	Status        string     `json:"status"`
 	ExpiresAt     time.Time  `json:"expires_at"`
+	SentAt        time.Time  `json:"sent_at"`
 	CreatedAt     time.Time  `json:"created_at"`
 	AcceptedAt    *time.Time `json:"accepted_at,omitempty"`
 	DeclinedAt    *time.Time `json:"declined_at,omitempty"`
+	RevokedAt     *time.Time `json:"revoked_at,omitempty"`
 	Band          *Band      `json:"band,omitempty"`
 	InvitedByUser *User      `json:"invited_by_user,omitempty"`
 }
 
+// BandOwnershipTransfer is a request from a band's owner to hand the band to
+// another member
+type BandOwnershipTransfer struct {
+	ID         string     `json:"id"`
+	BandID     string     `json:"band_id"`
+	FromUserID string     `json:"from_user_id"`
+	ToUserID   string     `json:"to_user_id"`
+	Status     string     `json:"status"`
+	CreatedAt  time.Time  `json:"created_at"`
+	AnsweredAt *time.Time `json:"answered_at,omitempty"`
+	FromUser   *User      `json:"from_user,omitempty"`
+	ToUser     *User      `json:"to_user,omitempty"`
+}
+
+// Open reports whether an invitation can still be accepted or declined. An
+// invitation to a band in the trash can't.
+func (i *BandInvitation) Open() bool {
+	return i.Status == "pending" && time.Now().Before(i.ExpiresAt) && (i.Band == nil || i.Band.IsActive)
+}
+
 // CreateBand creates a new band
 func (d *SQLiteBandsStore) CreateBand(name, description, createdBy string) (*Band, error) {
 	bandID := generateUUID()
@@ -67,7 +100,7 @@ func (d *SQLiteBandsStore) CreateBand(name, description, createdBy string) (*Ban
 	}
 
 	// Add the creator as the owner
-	_, err = d.AddBandMember(bandID, createdBy, "owner")
+	_, err = d.AddBandMember(bandID, createdBy, RoleOwner)
 	if err != nil {
 		return nil, fmt.Errorf("failed to add creator as band owner: %w", err)
 	}
@@ -145,6 +178,143 @@ func (d *SQLiteBandsStore) GetBandsByUser(userID string) ([]*Band, error) {
 	return bands, nil
 }
 
+// GetDeletedBandsByUser gets the bands in the trash that a user owns, most
+// recently deleted first
+func (d *SQLiteBandsStore) GetDeletedBandsByUser(userID string) ([]*Band, error) {
+	query := `
+		SELECT b.id, b.name, b.description, b.created_by, b.created_at, b.updated_at, b.is_active, b.deleted_at,
+		       u.id, u.email
+		FROM bands b
+		INNER JOIN band_members bm ON b.id = bm.band_id
+		LEFT JOIN users u ON b.deleted_by = u.id
+		WHERE bm.user_id = ? AND bm.role = 'owner' AND bm.is_active = 1 AND b.is_active = 0
+		ORDER BY b.deleted_at DESC
+	`
+
+	rows, err := d.db.Query(query, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get deleted bands: %w", err)
+	}
+	defer rows.Close()
+
+	var bands []*Band
+	for rows.Next() {
+		var band Band
+		var description sql.NullString
+		var deletedAt sql.NullTime
+		var deletedByID, deletedByEmail sql.NullString
+		err := rows.Scan(
+			&band.ID,
+			&band.Name,
+			&description,
+			&band.CreatedBy,
+			&band.CreatedAt,
+			&band.UpdatedAt,
+			&band.IsActive,
+			&deletedAt,
+			&deletedByID,
+			&deletedByEmail,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan deleted band: %w", err)
+		}
+
+		band.Description = description.String
+		if deletedAt.Valid {
+			band.DeletedAt = &deletedAt.Time
+		} else {
+			band.DeletedAt = &band.UpdatedAt
+		}
+		if deletedByID.Valid {
+			band.DeletedBy = &User{ID: deletedByID.String, Email: deletedByEmail.String}
+		}
+		bands = append(bands, &band)
+	}
+
+	return bands, nil
+}
+
+// UpdateBand changes the name and description of a band
+func (d *SQLiteBandsStore) UpdateBand(bandID, name, description string) error {
+	query := `UPDATE bands SET name = ?, description = ?, updated_at = ? WHERE id = ? AND is_active = 1`
+	_, err := d.db.Exec(query, name, description, time.Now(), bandID)
+	if err != nil {
+		return fmt.Errorf("failed to update band: %w", err)
+	}
+	return nil
+}
+
+// DeleteBand moves a band to the trash together with its songs, which are
+// marked with the band's deletion time so they come back with it
+func (d *SQLiteBandsStore) DeleteBand(bandID, deletedBy string) error {
+	// Start a transaction
+	tx, err := d.db.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	now := time.Now()
+	_, err = tx.Exec(`UPDATE bands SET is_active = 0, deleted_at = ?, deleted_by = ?, updated_at = ? WHERE id = ? AND is_active = 1`,
+		now, deletedBy, now, bandID)
+	if err != nil {
+		return fmt.Errorf("failed to delete band: %w", err)
+	}
+	_, err = tx.Exec(`UPDATE songs SET is_active = 0, deleted_at = ?, deleted_by = ?, updated_at = ? WHERE band_id = ? AND is_active = 1`,
+		now, deletedBy, now, bandID)
+	if err != nil {
+		return fmt.Errorf("failed to delete band songs: %w", err)
+	}
+
+	// Commit transaction
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return nil
+}
+
+// UndeleteBand takes a band out of the trash, together with the songs that
+// were deleted with it. Songs deleted on their own stay in the band's trash.
+func (d *SQLiteBandsStore) UndeleteBand(bandID string) error {
+	// Start a transaction
+	tx, err := d.db.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	now := time.Now()
+	_, err = tx.Exec(`
+		UPDATE songs SET is_active = 1, deleted_at = NULL, deleted_by = NULL, updated_at = ?
+		WHERE band_id = ? AND is_active = 0 AND deleted_at = (SELECT deleted_at FROM bands WHERE id = ?)
+	`, now, bandID, bandID)
+	if err != nil {
+		return fmt.Errorf("failed to undelete band songs: %w", err)
+	}
+	_, err = tx.Exec(`UPDATE bands SET is_active = 1, deleted_at = NULL, deleted_by = NULL, updated_at = ? WHERE id = ?`, now, bandID)
+	if err != nil {
+		return fmt.Errorf("failed to undelete band: %w", err)
+	}
+
+	// Commit transaction
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return nil
+}
+
+// PurgeDeletedBands permanently deletes the bands that were moved to the trash
+// before the given time, with everything in them, returning how many were deleted
+func (d *SQLiteBandsStore) PurgeDeletedBands(before time.Time) (int64, error) {
+	result, err := d.db.Exec("DELETE FROM bands WHERE is_active = 0 AND deleted_at < ?", before)
+	if err != nil {
+		return 0, fmt.Errorf("failed to purge deleted bands: %w", err)
+	}
+	return result.RowsAffected()
+}
+
 // AddBandMember adds a member to a band
 func (d *SQLiteBandsStore) AddBandMember(bandID, userID, role string) (*BandMember, error) {
 	memberID := generateUUID()
@@ -216,16 +386,29 @@ func (d *SQLiteBandsStore) GetBandMembers(bandID string) ([]*BandMember, error)
 	return members, nil
 }
 
-// GetBandMember gets a specific band member
+// GetBandMember gets a specific band member. Bands in the trash have no
+// members here, so every permission check refuses them until they are restored.
 func (d *SQLiteBandsStore) GetBandMember(bandID, userID string) (*BandMember, error) {
+	return d.getBandMember(bandID, userID, true)
+}
+
+// GetDeletedBandMember gets a member of a band in the trash, to check who may
+// restore it
+func (d *SQLiteBandsStore) GetDeletedBandMember(bandID, userID string) (*BandMember, error) {
+	return d.getBandMember(bandID, userID, false)
+}
+
+// getBandMember gets a member of a band that is active or in the trash
+func (d *SQLiteBandsStore) getBandMember(bandID, userID string, bandActive bool) (*BandMember, error) {
 	query := `
-		SELECT id, band_id, user_id, role, joined_at, is_active
-		FROM band_members
-		WHERE band_id = ? AND user_id = ? AND is_active = 1
+		SELECT bm.id, bm.band_id, bm.user_id, bm.role, bm.joined_at, bm.is_active
+		FROM band_members bm
+		INNER JOIN bands b ON bm.band_id = b.id
+		WHERE bm.band_id = ? AND bm.user_id = ? AND bm.is_active = 1 AND b.is_active = ?
 	`
 
 	var member BandMember
-	err := d.db.QueryRow(query, bandID, userID).Scan(
+	err := d.db.QueryRow(query, bandID, userID, bandActive).Scan(
 		&member.ID,
 		&member.BandID,
 		&member.UserID,
@@ -244,13 +427,41 @@ func (d *SQLiteBandsStore) GetBandMember(bandID, userID string) (*BandMember, er
 	return &member, nil
 }
 
-// RemoveBandMember removes a member from a band
+// UpdateBandMemberRole changes the role of a member of a band
+func (d *SQLiteBandsStore) UpdateBandMemberRole(bandID, userID, role string) error {
+	query := `UPDATE band_members SET role = ? WHERE band_id = ? AND user_id = ? AND is_active = 1`
+	_, err := d.db.Exec(query, role, bandID, userID)
+	if err != nil {
+		return fmt.Errorf("failed to update band member role: %w", err)
+	}
+	return nil
+}
+
+// RemoveBandMember removes a member from a band, cancelling any pending
+// ownership transfer to them
 func (d *SQLiteBandsStore) RemoveBandMember(bandID, userID string) error {
-	query := `DELETE FROM band_members WHERE band_id = ? AND user_id = ?`
-	_, err := d.db.Exec(query, bandID, userID)
+	// Start a transaction
+	tx, err := d.db.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	_, err = tx.Exec(`DELETE FROM band_members WHERE band_id = ? AND user_id = ?`, bandID, userID)
 	if err != nil {
 		return fmt.Errorf("failed to remove band member: %w", err)
 	}
+	_, err = tx.Exec(`UPDATE band_ownership_transfers SET status = 'cancelled', answered_at = ? WHERE band_id = ? AND to_user_id = ? AND status = 'pending'`,
+		time.Now(), bandID, userID)
+	if err != nil {
+		return fmt.Errorf("failed to cancel ownership transfer: %w", err)
+	}
+
+	// Commit transaction
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
 	return nil
 }
 
@@ -283,12 +494,13 @@ func (d *SQLiteBandsStore) GetUserByEmail(email string) (*User, error) {
 	return &user, nil
 }
 
-// CreateBandInvitation creates a new band invitation
-func (d *SQLiteBandsStore) CreateBandInvitation(bandID, invitedEmail, invitedBy, role string, expiresAt time.Time) (*BandInvitation, error) {
+// CreateBandInvitation creates a new pending band invitation for the token with the given hash
+func (d *SQLiteBandsStore) CreateBandInvitation(bandID, invitedEmail, invitedBy, role, tokenHash string, expiresAt time.Time) (*BandInvitation, error) {
 	invitationID := generateUUID()
+	now := time.Now()
 
-	query := `INSERT INTO band_invitations (id, band_id, invited_email, invited_by, role, expires_at) VALUES (?, ?, ?, ?, ?, ?)`
-	_, err := d.db.Exec(query, invitationID, bandID, invitedEmail, invitedBy, role, expiresAt)
+	query := `INSERT INTO band_invitations (id, band_id, invited_email, invited_by, role, token_hash, expires_at, sent_at, created_at) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)`
+	_, err := d.db.Exec(query, invitationID, bandID, invitedEmail, invitedBy, role, tokenHash, expiresAt, now, now)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create band invitation: %w", err)
 	}
@@ -301,29 +513,30 @@ func (d *SQLiteBandsStore) CreateBandInvitation(bandID, invitedEmail, invitedBy,
 		Role:         role,
 		Status:       "pending",
 		ExpiresAt:    expiresAt,
-		CreatedAt:    time.Now(),
+		SentAt:       now,
+		CreatedAt:    now,
 	}, nil
 }
 
-// GetBandInvitationByID gets a band invitation by ID
-func (d *SQLiteBandsStore) GetBandInvitationByID(invitationID string) (*BandInvitation, error) {
-	query := `
-		SELECT bi.id, bi.band_id, bi.invited_email, bi.invited_by, bi.role, bi.status, 
-		       bi.expires_at, bi.created_at, bi.accepted_at, bi.declined_at,
-		       b.name, b.description,
-		       u.email
-		FROM band_invitations bi
-		INNER JOIN bands b ON bi.band_id = b.id
-		INNER JOIN users u ON bi.invited_by = u.id
-		WHERE bi.id = ?
-	`
-
+// bandInvitationQuery selects invitations with their band and the user who sent them
+const bandInvitationQuery = `
+	SELECT bi.id, bi.band_id, bi.invited_email, bi.invited_by, bi.role, bi.status,
+	       bi.expires_at, bi.sent_at, bi.created_at, bi.accepted_at, bi.declined_at, bi.revoked_at,
+	       b.id, b.name, b.description, b.is_active,
+	       u.id, u.email
+	FROM band_invitations bi
+	INNER JOIN bands b ON bi.band_id = b.id
+	INNER JOIN users u ON bi.invited_by = u.id`
+
+// scanBandInvitation scans a row selected with bandInvitationQuery
+func scanBandInvitation(row interface{ Scan(...any) error }) (*BandInvitation, error) {
 	var invitation BandInvitation
 	var band Band
 	var invitedByUser User
-	var acceptedAt, declinedAt sql.NullTime
+	var description sql.NullString
+	var acceptedAt, declinedAt, revokedAt sql.NullTime
 
-	err := d.db.QueryRow(query, invitationID).Scan(
+	err := row.Scan(
 		&invitation.ID,
 		&invitation.BandID,
 		&invitation.InvitedEmail,
@@ -331,19 +544,20 @@ func (d *SQLiteBandsStore) GetBandInvitationByID(invitationID string) (*BandInvi
 		&invitation.Role,
 		&invitation.Status,
 		&invitation.ExpiresAt,
+		&invitation.SentAt,
 		&invitation.CreatedAt,
 		&acceptedAt,
 		&declinedAt,
+		&revokedAt,
+		&band.ID,
 		&band.Name,
-		&band.Description,
+		&description,
+		&band.IsActive,
+		&invitedByUser.ID,
 		&invitedByUser.Email,
 	)
-
 	if err != nil {
-		if err == sql.ErrNoRows {
-			return nil, nil
-		}
-		return nil, fmt.Errorf("failed to get band invitation: %w", err)
+		return nil, err
 	}
 
 	if acceptedAt.Valid {
@@ -352,74 +566,99 @@ func (d *SQLiteBandsStore) GetBandInvitationByID(invitationID string) (*BandInvi
 	if declinedAt.Valid {
 		invitation.DeclinedAt = &declinedAt.Time
 	}
+	if revokedAt.Valid {
+		invitation.RevokedAt = &revokedAt.Time
+	}
 
+	band.Description = description.String
 	invitation.Band = &band
 	invitation.InvitedByUser = &invitedByUser
 
 	return &invitation, nil
 }
 
-// GetPendingInvitationsByEmail gets pending invitations for a user
-func (d *SQLiteBandsStore) GetPendingInvitationsByEmail(email string) ([]*BandInvitation, error) {
-	query := `
-		SELECT bi.id, bi.band_id, bi.invited_email, bi.invited_by, bi.role, bi.status, 
-		       bi.expires_at, bi.created_at, bi.accepted_at, bi.declined_at,
-		       b.name, b.description,
-		       u.email
-		FROM band_invitations bi
-		INNER JOIN bands b ON bi.band_id = b.id
-		INNER JOIN users u ON bi.invited_by = u.id
-		WHERE bi.invited_email = ? AND bi.status = 'pending' AND bi.expires_at > ?
-		ORDER BY bi.created_at DESC
-	`
-
-	rows, err := d.db.Query(query, email, time.Now())
+// getBandInvitations runs bandInvitationQuery with the given conditions
+func (d *SQLiteBandsStore) getBandInvitations(conditions string, args ...any) ([]*BandInvitation, error) {
+	rows, err := d.db.Query(bandInvitationQuery+" "+conditions, args...)
 	if err != nil {
-		return nil, fmt.Errorf("failed to get pending invitations: %w", err)
+		return nil, err
 	}
 	defer rows.Close()
 
 	var invitations []*BandInvitation
 	for rows.Next() {
-		var invitation BandInvitation
-		var band Band
-		var invitedByUser User
-		var acceptedAt, declinedAt sql.NullTime
-
-		err := rows.Scan(
-			&invitation.ID,
-			&invitation.BandID,
-			&invitation.InvitedEmail,
-			&invitation.InvitedBy,
-			&invitation.Role,
-			&invitation.Status,
-			&invitation.ExpiresAt,
-			&invitation.CreatedAt,
-			&acceptedAt,
-			&declinedAt,
-			&band.Name,
-			&band.Description,
-			&invitedByUser.Email,
-		)
+		invitation, err := scanBandInvitation(rows)
 		if err != nil {
 			return nil, fmt.Errorf("failed to scan invitation: %w", err)
 		}
+		invitations = append(invitations, invitation)
+	}
 
-		if acceptedAt.Valid {
-			invitation.AcceptedAt = &acceptedAt.Time
+	return invitations, rows.Err()
+}
+
+// GetBandInvitationByID gets a band invitation by ID
+func (d *SQLiteBandsStore) GetBandInvitationByID(invitationID string) (*BandInvitation, error) {
+	invitation, err := scanBandInvitation(d.db.QueryRow(bandInvitationQuery+` WHERE bi.id = ?`, invitationID))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
 		}
-		if declinedAt.Valid {
-			invitation.DeclinedAt = &declinedAt.Time
+		return nil, fmt.Errorf("failed to get band invitation: %w", err)
+	}
+	return invitation, nil
+}
+
+// GetBandInvitationByTokenHash gets the band invitation sent with the token with the given hash
+func (d *SQLiteBandsStore) GetBandInvitationByTokenHash(tokenHash string) (*BandInvitation, error) {
+	invitation, err := scanBandInvitation(d.db.QueryRow(bandInvitationQuery+` WHERE bi.token_hash = ?`, tokenHash))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
 		}
+		return nil, fmt.Errorf("failed to get band invitation: %w", err)
+	}
+	return invitation, nil
+}
 
-		invitation.Band = &band
-		invitation.InvitedByUser = &invitedByUser
-		invitations = append(invitations, &invitation)
+// GetPendingInvitationsByEmail gets pending invitations for a user
+func (d *SQLiteBandsStore) GetPendingInvitationsByEmail(email string) ([]*BandInvitation, error) {
+	invitations, err := d.getBandInvitations(`
+		WHERE bi.invited_email = lower(?) AND bi.status = 'pending' AND bi.expires_at > ? AND b.is_active = 1
+		ORDER BY bi.created_at DESC`, email, time.Now())
+	if err != nil {
+		return nil, fmt.Errorf("failed to get pending invitations: %w", err)
 	}
+	return invitations, nil
+}
 
+// GetBandInvitations gets the invitations of a band that are pending or
+// expired, so they can be resent or revoked
+func (d *SQLiteBandsStore) GetBandInvitations(bandID string) ([]*BandInvitation, error) {
+	invitations, err := d.getBandInvitations(`
+		WHERE bi.band_id = ? AND bi.status IN ('pending', 'expired')
+		ORDER BY bi.created_at DESC`, bandID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get band invitations: %w", err)
+	}
 	return invitations, nil
 }
 
+// GetPendingBandInvitation gets the invitation of a band that is still
+// pending for an email, or nil when there is none
+func (d *SQLiteBandsStore) GetPendingBandInvitation(bandID, email string) (*BandInvitation, error) {
+	invitations, err := d.getBandInvitations(`
+		WHERE bi.band_id = ? AND bi.invited_email = lower(?) AND bi.status = 'pending' AND bi.expires_at > ?
+		ORDER BY bi.created_at DESC LIMIT 1`, bandID, email, time.Now())
+	if err != nil {
+		return nil, fmt.Errorf("failed to get pending band invitation: %w", err)
+	}
+	if len(invitations) == 0 {
+		return nil, nil
+	}
+	return invitations[0], nil
+}
+
 // AcceptBandInvitation accepts a band invitation
 func (d *SQLiteBandsStore) AcceptBandInvitation(invitationID, userID string) error {
 	// Get the invitation
@@ -443,14 +682,19 @@ func (d *SQLiteBandsStore) AcceptBandInvitation(invitationID, userID string) err
 	}
 	defer tx.Rollback()
 
-	// Update invitation status
-	_, err = tx.Exec("UPDATE band_invitations SET status = 'accepted', accepted_at = ? WHERE id = ?", time.Now(), invitationID)
+	// Update invitation status, unless it was answered or revoked meanwhile or
+	// its band was deleted
+	result, err := tx.Exec(`UPDATE band_invitations SET status = 'accepted', accepted_at = ?
+		WHERE id = ? AND status = 'pending' AND band_id IN (SELECT id FROM bands WHERE is_active = 1)`, time.Now(), invitationID)
 	if err != nil {
 		return fmt.Errorf("failed to update invitation: %w", err)
 	}
+	if n, _ := result.RowsAffected(); n == 0 {
+		return fmt.Errorf("invitation is no longer valid")
+	}
 
-	// Add user to band
-	_, err = tx.Exec("INSERT INTO band_members (id, band_id, user_id, role) VALUES (?, ?, ?, ?)",
+	// Add user to band; someone who is already a member keeps their role
+	_, err = tx.Exec("INSERT INTO band_members (id, band_id, user_id, role) VALUES (?, ?, ?, ?) ON CONFLICT(band_id, user_id) DO NOTHING",
 		generateUUID(), invitation.BandID, userID, invitation.Role)
 	if err != nil {
 		return fmt.Errorf("failed to add band member: %w", err)
@@ -466,7 +710,7 @@ func (d *SQLiteBandsStore) AcceptBandInvitation(invitationID, userID string) err
 
 // DeclineBandInvitation declines a band invitation
 func (d *SQLiteBandsStore) DeclineBandInvitation(invitationID string) error {
-	query := `UPDATE band_invitations SET status = 'declined', declined_at = ? WHERE id = ?`
+	query := `UPDATE band_invitations SET status = 'declined', declined_at = ? WHERE id = ? AND status = 'pending'`
 	_, err := d.db.Exec(query, time.Now(), invitationID)
 	if err != nil {
 		return fmt.Errorf("failed to decline invitation: %w", err)
@@ -474,12 +718,169 @@ func (d *SQLiteBandsStore) DeclineBandInvitation(invitationID string) error {
 	return nil
 }
 
+// RevokeBandInvitation withdraws an invitation that was not answered yet
+func (d *SQLiteBandsStore) RevokeBandInvitation(invitationID string) error {
+	query := `UPDATE band_invitations SET status = 'revoked', revoked_at = ? WHERE id = ? AND status IN ('pending', 'expired')`
+	_, err := d.db.Exec(query, time.Now(), invitationID)
+	if err != nil {
+		return fmt.Errorf("failed to revoke invitation: %w", err)
+	}
+	return nil
+}
+
+// RenewBandInvitation makes an unanswered invitation pending again with a new
+// token and expiry, for sending it again. The old link stops working.
+func (d *SQLiteBandsStore) RenewBandInvitation(invitationID, tokenHash string, expiresAt time.Time) error {
+	query := `
+		UPDATE band_invitations SET status = 'pending', token_hash = ?, expires_at = ?, sent_at = ?
+		WHERE id = ? AND status IN ('pending', 'expired')
+	`
+	_, err := d.db.Exec(query, tokenHash, expiresAt, time.Now(), invitationID)
+	if err != nil {
+		return fmt.Errorf("failed to renew invitation: %w", err)
+	}
+	return nil
+}
+
 // CleanupExpiredInvitations marks expired invitations as expired
-func (d *SQLiteBandsStore) CleanupExpiredInvitations() error {
+func (d *SQLiteBandsStore) CleanupExpiredInvitations() (int64, error) {
 	query := `UPDATE band_invitations SET status = 'expired' WHERE status = 'pending' AND expires_at < ?`
-	_, err := d.db.Exec(query, time.Now())
+	result, err := d.db.Exec(query, time.Now())
+	if err != nil {
+		return 0, fmt.Errorf("failed to cleanup expired invitations: %w", err)
+	}
+	return result.RowsAffected()
+}
+
+// CreateOwnershipTransfer asks a member to take over a band from its owner,
+// replacing any transfer of the band that is still pending
+func (d *SQLiteBandsStore) CreateOwnershipTransfer(bandID, fromUserID, toUserID string) (*BandOwnershipTransfer, error) {
+	// Start a transaction
+	tx, err := d.db.Begin()
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	now := time.Now()
+	_, err = tx.Exec(`UPDATE band_ownership_transfers SET status = 'cancelled', answered_at = ? WHERE band_id = ? AND status = 'pending'`, now, bandID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to cancel previous ownership transfer: %w", err)
+	}
+
+	transferID := generateUUID()
+	_, err = tx.Exec(`INSERT INTO band_ownership_transfers (id, band_id, from_user_id, to_user_id, created_at) VALUES (?, ?, ?, ?, ?)`,
+		transferID, bandID, fromUserID, toUserID, now)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create ownership transfer: %w", err)
+	}
+
+	// Commit transaction
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return &BandOwnershipTransfer{
+		ID:         transferID,
+		BandID:     bandID,
+		FromUserID: fromUserID,
+		ToUserID:   toUserID,
+		Status:     "pending",
+		CreatedAt:  now,
+	}, nil
+}
+
+// GetPendingOwnershipTransfer gets the ownership transfer of a band waiting
+// for an answer, or nil when there is none
+func (d *SQLiteBandsStore) GetPendingOwnershipTransfer(bandID string) (*BandOwnershipTransfer, error) {
+	query := `
+		SELECT t.id, t.band_id, t.from_user_id, t.to_user_id, t.status, t.created_at,
+		       fu.email, tu.email
+		FROM band_ownership_transfers t
+		INNER JOIN users fu ON t.from_user_id = fu.id
+		INNER JOIN users tu ON t.to_user_id = tu.id
+		WHERE t.band_id = ? AND t.status = 'pending'
+		ORDER BY t.created_at DESC
+		LIMIT 1
+	`
+
+	var transfer BandOwnershipTransfer
+	var fromEmail, toEmail string
+	err := d.db.QueryRow(query, bandID).Scan(
+		&transfer.ID,
+		&transfer.BandID,
+		&transfer.FromUserID,
+		&transfer.ToUserID,
+		&transfer.Status,
+		&transfer.CreatedAt,
+		&fromEmail,
+		&toEmail,
+	)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to get ownership transfer: %w", err)
+	}
+
+	transfer.FromUser = &User{ID: transfer.FromUserID, Email: fromEmail}
+	transfer.ToUser = &User{ID: transfer.ToUserID, Email: toEmail}
+	return &transfer, nil
+}
+
+// AcceptOwnershipTransfer makes the recipient of a pending transfer the owner
+// of the band and the previous owner an admin. It fails when the transfer was
+// answered meanwhile or either of them no longer has the expected role.
+func (d *SQLiteBandsStore) AcceptOwnershipTransfer(transfer *BandOwnershipTransfer) error {
+	// Start a transaction
+	tx, err := d.db.Begin()
 	if err != nil {
-		return fmt.Errorf("failed to cleanup expired invitations: %w", err)
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	result, err := tx.Exec(`UPDATE band_ownership_transfers SET status = 'accepted', answered_at = ? WHERE id = ? AND status = 'pending'`,
+		time.Now(), transfer.ID)
+	if err != nil {
+		return fmt.Errorf("failed to update ownership transfer: %w", err)
+	}
+	if n, _ := result.RowsAffected(); n == 0 {
+		return fmt.Errorf("ownership transfer is no longer pending")
+	}
+
+	result, err = tx.Exec(`UPDATE band_members SET role = ? WHERE band_id = ? AND user_id = ? AND role = ? AND is_active = 1`,
+		RoleAdmin, transfer.BandID, transfer.FromUserID, RoleOwner)
+	if err != nil {
+		return fmt.Errorf("failed to update previous owner: %w", err)
+	}
+	if n, _ := result.RowsAffected(); n == 0 {
+		return fmt.Errorf("the transfer was requested by someone who is no longer the owner")
+	}
+
+	result, err = tx.Exec(`UPDATE band_members SET role = ? WHERE band_id = ? AND user_id = ? AND is_active = 1`,
+		RoleOwner, transfer.BandID, transfer.ToUserID)
+	if err != nil {
+		return fmt.Errorf("failed to update new owner: %w", err)
+	}
+	if n, _ := result.RowsAffected(); n == 0 {
+		return fmt.Errorf("the transfer recipient is no longer a member")
+	}
+
+	// Commit transaction
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return nil
+}
+
+// AnswerOwnershipTransfer closes a pending ownership transfer without
+// changing any role, with status "declined" or "cancelled"
+func (d *SQLiteBandsStore) AnswerOwnershipTransfer(transferID, status string) error {
+	query := `UPDATE band_ownership_transfers SET status = ?, answered_at = ? WHERE id = ? AND status = 'pending'`
+	_, err := d.db.Exec(query, status, time.Now(), transferID)
+	if err != nil {
+		return fmt.Errorf("failed to update ownership transfer: %w", err)
 	}
 	return nil
 }
@@ -558,3 +959,117 @@ func (d *SQLiteBandsStore) GetBandByIDShared(bandID string) (*types.Band, error)
 		IsActive:    band.IsActive,
 	}, nil
 }
+
+// BandRestore holds everything needed to recreate a band from an archive.
+// IDs on songs, setlists and gigs are the ones from the archive and are only
+// used to link records together; new IDs are generated on restore.
+type BandRestore struct {
+	Name         string
+	Description  string
+	Songs        []*Song
+	Setlists     []*Setlist
+	SetlistSongs map[string][]string
+	Gigs         []*Gig
+	GigSets      map[string][]*GigSet
+}
+
+// RestoreBand recreates a band owned by the given user in a single transaction,
+// together with its songs, setlists and gigs. The owner is its only member.
+func (d *SQLiteBandsStore) RestoreBand(restore *BandRestore, ownerID string) (*Band, error) {
+	// Start a transaction
+	tx, err := d.db.Begin()
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	bandID := generateUUID()
+	_, err = tx.Exec(`INSERT INTO bands (id, name, description, created_by) VALUES (?, ?, ?, ?)`,
+		bandID, restore.Name, restore.Description, ownerID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create band: %w", err)
+	}
+
+	_, err = tx.Exec(`INSERT INTO band_members (id, band_id, user_id, role) VALUES (?, ?, ?, ?)`,
+		generateUUID(), bandID, ownerID, RoleOwner)
+	if err != nil {
+		return nil, fmt.Errorf("failed to add creator as band owner: %w", err)
+	}
+
+	songIDs := make(map[string]string, len(restore.Songs))
+	songQuery := `INSERT INTO songs (id, band_id, title, artist, key, tempo, duration_seconds, notes, content, created_by, position) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`
+	for i, song := range restore.Songs {
+		songID := generateUUID()
+		_, err := tx.Exec(songQuery, songID, bandID, song.Title, song.Artist, song.Key, song.Tempo, song.Duration, song.Notes, song.Content, ownerID, i+1)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create song %q: %w", song.Title, err)
+		}
+		if err := addSongRevision(tx, songID, ownerID, SongRevisionImport); err != nil {
+			return nil, err
+		}
+		songIDs[song.ID] = songID
+	}
+
+	setlistIDs := make(map[string]string, len(restore.Setlists))
+	for _, setlist := range restore.Setlists {
+		setlistID := generateUUID()
+		_, err := tx.Exec(`INSERT INTO setlists (id, band_id, name, description, created_by) VALUES (?, ?, ?, ?, ?)`,
+			setlistID, bandID, setlist.Name, setlist.Description, ownerID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create setlist %q: %w", setlist.Name, err)
+		}
+		setlistIDs[setlist.ID] = setlistID
+
+		position := 0
+		for _, archivedSongID := range restore.SetlistSongs[setlist.ID] {
+			songID, ok := songIDs[archivedSongID]
+			if !ok {
+				continue
+			}
+			position++
+			_, err := tx.Exec(`INSERT OR IGNORE INTO setlist_songs (id, setlist_id, song_id, position) VALUES (?, ?, ?, ?)`,
+				generateUUID(), setlistID, songID, position)
+			if err != nil {
+				return nil, fmt.Errorf("failed to add song to setlist: %w", err)
+			}
+		}
+	}
+
+	for _, gig := range restore.Gigs {
+		gigID := generateUUID()
+		_, err := tx.Exec(`INSERT INTO gigs (id, band_id, name, venue, gig_date, load_in_time, start_time, curfew_time, notes, created_by) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+			gigID, bandID, gig.Name, gig.Venue, gig.Date, gig.LoadInTime, gig.StartTime, gig.CurfewTime, gig.Notes, ownerID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create gig %q: %w", gig.Name, err)
+		}
+
+		position := 0
+		for _, set := range restore.GigSets[gig.ID] {
+			setlistID, ok := setlistIDs[set.SetlistID]
+			if !ok {
+				continue
+			}
+			position++
+			_, err := tx.Exec(`INSERT INTO gig_sets (id, gig_id, setlist_id, name, position, break_minutes, is_encore) VALUES (?, ?, ?, ?, ?, ?, ?)`,
+				generateUUID(), gigID, setlistID, set.Name, position, set.BreakMinutes, set.IsEncore)
+			if err != nil {
+				return nil, fmt.Errorf("failed to add gig set: %w", err)
+			}
+		}
+	}
+
+	// Commit transaction
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return &Band{
+		ID:          bandID,
+		Name:        restore.Name,
+		Description: restore.Description,
+		CreatedBy:   ownerID,
+		CreatedAt:   time.Now(),
+		UpdatedAt:   time.Now(),
+		IsActive:    true,
+	}, nil
+}
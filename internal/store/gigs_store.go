@@ -0,0 +1,289 @@
+package store
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+)
+
+// Database handles gig-related database operations
+type SQLiteGigsStore struct {
+	db *sql.DB
+}
+
+// NewSQLiteGigsStore creates a new gigs database instance
+func NewSQLiteGigsStore(db *sql.DB) *SQLiteGigsStore {
+	return &SQLiteGigsStore{db: db}
+}
+
+// Gig represents a show played by a band.
+// Date is stored as YYYY-MM-DD and the times as HH:MM in the venue's local time.
+type Gig struct {
+	ID         string    `json:"id"`
+	BandID     string    `json:"band_id"`
+	Name       string    `json:"name"`
+	Venue      string    `json:"venue"`
+	Date       string    `json:"date"`
+	LoadInTime string    `json:"load_in_time"`
+	StartTime  string    `json:"start_time"`
+	CurfewTime string    `json:"curfew_time"`
+	Notes      string    `json:"notes"`
+	CreatedBy  string    `json:"created_by"`
+	CreatedAt  time.Time `json:"created_at"`
+	UpdatedAt  time.Time `json:"updated_at"`
+	IsActive   bool      `json:"is_active"`
+}
+
+// GigSet represents one set of a gig, played from a setlist
+type GigSet struct {
+	ID           string `json:"id"`
+	GigID        string `json:"gig_id"`
+	SetlistID    string `json:"setlist_id"`
+	SetlistName  string `json:"setlist_name"`
+	Name         string `json:"name"`
+	Position     int    `json:"position"`
+	BreakMinutes int    `json:"break_minutes"`
+	IsEncore     bool   `json:"is_encore"`
+}
+
+// CreateGig creates a new gig
+func (d *SQLiteGigsStore) CreateGig(bandID, name, venue, date, loadInTime, startTime, curfewTime, notes, createdBy string) (*Gig, error) {
+	gigID := generateUUID()
+
+	query := `INSERT INTO gigs (id, band_id, name, venue, gig_date, load_in_time, start_time, curfew_time, notes, created_by) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`
+	_, err := d.db.Exec(query, gigID, bandID, name, venue, date, loadInTime, startTime, curfewTime, notes, createdBy)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create gig: %w", err)
+	}
+
+	return &Gig{
+		ID:         gigID,
+		BandID:     bandID,
+		Name:       name,
+		Venue:      venue,
+		Date:       date,
+		LoadInTime: loadInTime,
+		StartTime:  startTime,
+		CurfewTime: curfewTime,
+		Notes:      notes,
+		CreatedBy:  createdBy,
+		CreatedAt:  time.Now(),
+		UpdatedAt:  time.Now(),
+		IsActive:   true,
+	}, nil
+}
+
+// GetGigsByBand gets all gigs for a band, soonest first
+func (d *SQLiteGigsStore) GetGigsByBand(bandID string) ([]*Gig, error) {
+	query := `
+		SELECT id, band_id, name, venue, gig_date, load_in_time, start_time, curfew_time, notes, created_by, created_at, updated_at, is_active
+		FROM gigs
+		WHERE band_id = ? AND is_active = 1
+		ORDER BY gig_date ASC, start_time ASC
+	`
+
+	rows, err := d.db.Query(query, bandID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get gigs: %w", err)
+	}
+	defer rows.Close()
+
+	var gigs []*Gig
+	for rows.Next() {
+		gig, err := scanGig(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan gig: %w", err)
+		}
+		gigs = append(gigs, gig)
+	}
+
+	return gigs, nil
+}
+
+// GetGigByID gets a gig by ID
+func (d *SQLiteGigsStore) GetGigByID(gigID string) (*Gig, error) {
+	query := `
+		SELECT id, band_id, name, venue, gig_date, load_in_time, start_time, curfew_time, notes, created_by, created_at, updated_at, is_active
+		FROM gigs
+		WHERE id = ? AND is_active = 1
+	`
+
+	gig, err := scanGig(d.db.QueryRow(query, gigID))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to get gig: %w", err)
+	}
+
+	return gig, nil
+}
+
+// UpdateGig updates a gig's details
+func (d *SQLiteGigsStore) UpdateGig(gigID, name, venue, date, loadInTime, startTime, curfewTime, notes string) error {
+	query := `UPDATE gigs SET name = ?, venue = ?, gig_date = ?, load_in_time = ?, start_time = ?, curfew_time = ?, notes = ?, updated_at = ? WHERE id = ?`
+	_, err := d.db.Exec(query, name, venue, date, loadInTime, startTime, curfewTime, notes, time.Now(), gigID)
+	if err != nil {
+		return fmt.Errorf("failed to update gig: %w", err)
+	}
+	return nil
+}
+
+// DeleteGig deletes a gig (soft delete)
+func (d *SQLiteGigsStore) DeleteGig(gigID string) error {
+	query := `UPDATE gigs SET is_active = 0, updated_at = ? WHERE id = ?`
+	_, err := d.db.Exec(query, time.Now(), gigID)
+	if err != nil {
+		return fmt.Errorf("failed to delete gig: %w", err)
+	}
+	return nil
+}
+
+// GetGigSets gets the sets of a gig in running order
+func (d *SQLiteGigsStore) GetGigSets(gigID string) ([]*GigSet, error) {
+	query := `
+		SELECT gs.id, gs.gig_id, gs.setlist_id, sl.name, gs.name, gs.position, gs.break_minutes, gs.is_encore
+		FROM gig_sets gs
+		INNER JOIN setlists sl ON gs.setlist_id = sl.id
+		WHERE gs.gig_id = ?
+		ORDER BY gs.position ASC
+	`
+
+	rows, err := d.db.Query(query, gigID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get gig sets: %w", err)
+	}
+	defer rows.Close()
+
+	var sets []*GigSet
+	for rows.Next() {
+		var set GigSet
+		err := rows.Scan(
+			&set.ID,
+			&set.GigID,
+			&set.SetlistID,
+			&set.SetlistName,
+			&set.Name,
+			&set.Position,
+			&set.BreakMinutes,
+			&set.IsEncore,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan gig set: %w", err)
+		}
+		sets = append(sets, &set)
+	}
+
+	return sets, nil
+}
+
+// AddGigSet appends a set to the end of a gig's running order
+func (d *SQLiteGigsStore) AddGigSet(gigID, setlistID, name string, breakMinutes int, isEncore bool) (*GigSet, error) {
+	setID := generateUUID()
+
+	// Get the next position for this gig
+	var maxPosition int
+	err := d.db.QueryRow("SELECT COALESCE(MAX(position), 0) FROM gig_sets WHERE gig_id = ?", gigID).Scan(&maxPosition)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get max position: %w", err)
+	}
+	nextPosition := maxPosition + 1
+
+	query := `INSERT INTO gig_sets (id, gig_id, setlist_id, name, position, break_minutes, is_encore) VALUES (?, ?, ?, ?, ?, ?, ?)`
+	_, err = d.db.Exec(query, setID, gigID, setlistID, name, nextPosition, breakMinutes, isEncore)
+	if err != nil {
+		return nil, fmt.Errorf("failed to add gig set: %w", err)
+	}
+
+	return &GigSet{
+		ID:           setID,
+		GigID:        gigID,
+		SetlistID:    setlistID,
+		Name:         name,
+		Position:     nextPosition,
+		BreakMinutes: breakMinutes,
+		IsEncore:     isEncore,
+	}, nil
+}
+
+// UpdateGigSet updates a set's name, following break and encore marker
+func (d *SQLiteGigsStore) UpdateGigSet(gigID, setID, name string, breakMinutes int, isEncore bool) error {
+	query := `UPDATE gig_sets SET name = ?, break_minutes = ?, is_encore = ? WHERE id = ? AND gig_id = ?`
+	_, err := d.db.Exec(query, name, breakMinutes, isEncore, setID, gigID)
+	if err != nil {
+		return fmt.Errorf("failed to update gig set: %w", err)
+	}
+	return nil
+}
+
+// RemoveGigSet removes a set from a gig
+func (d *SQLiteGigsStore) RemoveGigSet(gigID, setID string) error {
+	query := `DELETE FROM gig_sets WHERE id = ? AND gig_id = ?`
+	_, err := d.db.Exec(query, setID, gigID)
+	if err != nil {
+		return fmt.Errorf("failed to remove gig set: %w", err)
+	}
+	return nil
+}
+
+// ReorderGigSets updates the running order of a gig's sets
+func (d *SQLiteGigsStore) ReorderGigSets(gigID string, setOrder []string) error {
+	// Start a transaction
+	tx, err := d.db.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	// Update positions for each set
+	for i, setID := range setOrder {
+		_, err := tx.Exec("UPDATE gig_sets SET position = ? WHERE id = ? AND gig_id = ?", i+1, setID, gigID)
+		if err != nil {
+			return fmt.Errorf("failed to update gig set position: %w", err)
+		}
+	}
+
+	// Commit transaction
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return nil
+}
+
+// rowScanner is implemented by both *sql.Row and *sql.Rows
+type rowScanner interface {
+	Scan(dest ...any) error
+}
+
+// scanGig scans a gig row selected with the standard gig column list
+func scanGig(row rowScanner) (*Gig, error) {
+	var gig Gig
+	var venue, loadInTime, curfewTime, notes sql.NullString
+
+	err := row.Scan(
+		&gig.ID,
+		&gig.BandID,
+		&gig.Name,
+		&venue,
+		&gig.Date,
+		&loadInTime,
+		&gig.StartTime,
+		&curfewTime,
+		&notes,
+		&gig.CreatedBy,
+		&gig.CreatedAt,
+		&gig.UpdatedAt,
+		&gig.IsActive,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	gig.Venue = venue.String
+	gig.LoadInTime = loadInTime.String
+	gig.CurfewTime = curfewTime.String
+	gig.Notes = notes.String
+
+	return &gig, nil
+}
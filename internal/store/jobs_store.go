@@ -0,0 +1,271 @@
+package store
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+)
+
+// Job statuses
+const (
+	JobQueued    = "queued"
+	JobRunning   = "running"
+	JobSucceeded = "succeeded"
+	JobFailed    = "failed"
+	JobCancelled = "cancelled"
+)
+
+// Database handles background job operations
+type SQLiteJobsStore struct {
+	db *sql.DB
+}
+
+// NewSQLiteJobsStore creates a new jobs database instance
+func NewSQLiteJobsStore(db *sql.DB) *SQLiteJobsStore {
+	return &SQLiteJobsStore{db: db}
+}
+
+// Job is a unit of background work queued for a band, such as generating a
+// song's content or rendering a PDF. Payload holds the kind-specific arguments
+// as JSON. The result of a finished job is loaded separately with GetJobResult.
+type Job struct {
+	ID          string     `json:"id"`
+	BandID      string     `json:"band_id"`
+	Kind        string     `json:"kind"`
+	Title       string     `json:"title"`
+	Payload     string     `json:"payload"`
+	Status      string     `json:"status"`
+	Attempts    int        `json:"attempts"`
+	MaxAttempts int        `json:"max_attempts"`
+	RunAt       time.Time  `json:"run_at"`
+	LastError   string     `json:"last_error,omitempty"`
+	ResultType  string     `json:"result_type,omitempty"`
+	ResultName  string     `json:"result_name,omitempty"`
+	CreatedBy   string     `json:"created_by"`
+	CreatedAt   time.Time  `json:"created_at"`
+	UpdatedAt   time.Time  `json:"updated_at"`
+	StartedAt   *time.Time `json:"started_at,omitempty"`
+	FinishedAt  *time.Time `json:"finished_at,omitempty"`
+}
+
+// Finished reports whether the job will not run again
+func (j *Job) Finished() bool {
+	return j.Status == JobSucceeded || j.Status == JobFailed || j.Status == JobCancelled
+}
+
+// jobColumns are the columns read into a Job, in scan order
+const jobColumns = `id, band_id, kind, title, payload, status, attempts, max_attempts, run_at, last_error, result_type, result_name, created_by, created_at, updated_at, started_at, finished_at`
+
+// scanJob reads a row selected with jobColumns
+func scanJob(row interface{ Scan(...any) error }) (*Job, error) {
+	var job Job
+	var lastError, resultType, resultName sql.NullString
+	var startedAt, finishedAt sql.NullTime
+
+	err := row.Scan(
+		&job.ID,
+		&job.BandID,
+		&job.Kind,
+		&job.Title,
+		&job.Payload,
+		&job.Status,
+		&job.Attempts,
+		&job.MaxAttempts,
+		&job.RunAt,
+		&lastError,
+		&resultType,
+		&resultName,
+		&job.CreatedBy,
+		&job.CreatedAt,
+		&job.UpdatedAt,
+		&startedAt,
+		&finishedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	job.LastError = lastError.String
+	job.ResultType = resultType.String
+	job.ResultName = resultName.String
+	if startedAt.Valid {
+		job.StartedAt = &startedAt.Time
+	}
+	if finishedAt.Valid {
+		job.FinishedAt = &finishedAt.Time
+	}
+	return &job, nil
+}
+
+// CreateJob queues a job to run as soon as a worker is free
+func (d *SQLiteJobsStore) CreateJob(bandID, kind, title, payload, createdBy string, maxAttempts int) (*Job, error) {
+	jobID := generateUUID()
+	now := time.Now().UTC()
+
+	query := `INSERT INTO jobs (id, band_id, kind, title, payload, status, max_attempts, run_at, created_by, created_at, updated_at) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`
+	_, err := d.db.Exec(query, jobID, bandID, kind, title, payload, JobQueued, maxAttempts, now, createdBy, now, now)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create job: %w", err)
+	}
+
+	return &Job{
+		ID:          jobID,
+		BandID:      bandID,
+		Kind:        kind,
+		Title:       title,
+		Payload:     payload,
+		Status:      JobQueued,
+		MaxAttempts: maxAttempts,
+		RunAt:       now,
+		CreatedBy:   createdBy,
+		CreatedAt:   now,
+		UpdatedAt:   now,
+	}, nil
+}
+
+// ClaimNextJob marks the next due queued job as running and returns it, or
+// returns nil when no job is due. Each job is claimed by a single worker.
+func (d *SQLiteJobsStore) ClaimNextJob() (*Job, error) {
+	// Start a transaction
+	tx, err := d.db.Begin()
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	now := time.Now().UTC()
+	var jobID string
+	err = tx.QueryRow(`SELECT id FROM jobs WHERE status = ? AND run_at <= ? ORDER BY run_at ASC, created_at ASC LIMIT 1`, JobQueued, now).Scan(&jobID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to find next job: %w", err)
+	}
+
+	query := `UPDATE jobs SET status = ?, attempts = attempts + 1, started_at = ?, updated_at = ? WHERE id = ? AND status = ?`
+	result, err := tx.Exec(query, JobRunning, now, now, jobID, JobQueued)
+	if err != nil {
+		return nil, fmt.Errorf("failed to claim job: %w", err)
+	}
+	if rows, err := result.RowsAffected(); err != nil || rows == 0 {
+		// Another worker claimed it first
+		return nil, nil
+	}
+
+	job, err := scanJob(tx.QueryRow(`SELECT `+jobColumns+` FROM jobs WHERE id = ?`, jobID))
+	if err != nil {
+		return nil, fmt.Errorf("failed to get claimed job: %w", err)
+	}
+
+	// Commit transaction
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return job, nil
+}
+
+// CompleteJob marks a running job as succeeded and stores its result, if any
+func (d *SQLiteJobsStore) CompleteJob(jobID, resultType, resultName string, result []byte) error {
+	now := time.Now().UTC()
+	query := `UPDATE jobs SET status = ?, result = ?, result_type = ?, result_name = ?, last_error = NULL, finished_at = ?, updated_at = ? WHERE id = ? AND status = ?`
+	_, err := d.db.Exec(query, JobSucceeded, result, resultType, resultName, now, now, jobID, JobRunning)
+	if err != nil {
+		return fmt.Errorf("failed to complete job: %w", err)
+	}
+	return nil
+}
+
+// RetryJob puts a running job that failed back in the queue to run again at runAt
+func (d *SQLiteJobsStore) RetryJob(jobID, lastError string, runAt time.Time) error {
+	query := `UPDATE jobs SET status = ?, last_error = ?, run_at = ?, updated_at = ? WHERE id = ? AND status = ?`
+	_, err := d.db.Exec(query, JobQueued, lastError, runAt.UTC(), time.Now().UTC(), jobID, JobRunning)
+	if err != nil {
+		return fmt.Errorf("failed to retry job: %w", err)
+	}
+	return nil
+}
+
+// FailJob marks a running job as failed for good
+func (d *SQLiteJobsStore) FailJob(jobID, lastError string) error {
+	now := time.Now().UTC()
+	query := `UPDATE jobs SET status = ?, last_error = ?, finished_at = ?, updated_at = ? WHERE id = ? AND status = ?`
+	_, err := d.db.Exec(query, JobFailed, lastError, now, now, jobID, JobRunning)
+	if err != nil {
+		return fmt.Errorf("failed to fail job: %w", err)
+	}
+	return nil
+}
+
+// CancelJob cancels a job that hasn't started yet. It reports whether the job was cancelled.
+func (d *SQLiteJobsStore) CancelJob(jobID string) (bool, error) {
+	now := time.Now().UTC()
+	query := `UPDATE jobs SET status = ?, finished_at = ?, updated_at = ? WHERE id = ? AND status = ?`
+	result, err := d.db.Exec(query, JobCancelled, now, now, jobID, JobQueued)
+	if err != nil {
+		return false, fmt.Errorf("failed to cancel job: %w", err)
+	}
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("failed to cancel job: %w", err)
+	}
+	return rows > 0, nil
+}
+
+// RequeueRunningJobs puts jobs left running by a previous server process back in
+// the queue, and returns how many there were
+func (d *SQLiteJobsStore) RequeueRunningJobs() (int64, error) {
+	now := time.Now().UTC()
+	result, err := d.db.Exec(`UPDATE jobs SET status = ?, run_at = ?, updated_at = ? WHERE status = ?`, JobQueued, now, now, JobRunning)
+	if err != nil {
+		return 0, fmt.Errorf("failed to requeue running jobs: %w", err)
+	}
+	return result.RowsAffected()
+}
+
+// GetJobByID gets a job by ID, without its result
+func (d *SQLiteJobsStore) GetJobByID(jobID string) (*Job, error) {
+	job, err := scanJob(d.db.QueryRow(`SELECT `+jobColumns+` FROM jobs WHERE id = ?`, jobID))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to get job: %w", err)
+	}
+	return job, nil
+}
+
+// GetJobsByBand gets the most recent jobs of a band, newest first
+func (d *SQLiteJobsStore) GetJobsByBand(bandID string, limit int) ([]*Job, error) {
+	query := `SELECT ` + jobColumns + ` FROM jobs WHERE band_id = ? ORDER BY created_at DESC LIMIT ?`
+	rows, err := d.db.Query(query, bandID, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get jobs: %w", err)
+	}
+	defer rows.Close()
+
+	var jobs []*Job
+	for rows.Next() {
+		job, err := scanJob(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan job: %w", err)
+		}
+		jobs = append(jobs, job)
+	}
+
+	return jobs, nil
+}
+
+// GetJobResult gets the result stored by a succeeded job
+func (d *SQLiteJobsStore) GetJobResult(jobID string) ([]byte, error) {
+	var result []byte
+	err := d.db.QueryRow(`SELECT result FROM jobs WHERE id = ? AND status = ?`, jobID, JobSucceeded).Scan(&result)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to get job result: %w", err)
+	}
+	return result, nil
+}
@@ -0,0 +1,286 @@
+package store
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+)
+
+// Database handles prompt template database operations
+type SQLitePromptsStore struct {
+	db *sql.DB
+}
+
+// NewSQLitePromptsStore creates a new prompt templates database instance
+func NewSQLitePromptsStore(db *sql.DB) *SQLitePromptsStore {
+	return &SQLitePromptsStore{db: db}
+}
+
+// PromptTemplate is a band's prompt for generating one role's song content.
+// Version and Body are those of the template's latest version.
+type PromptTemplate struct {
+	ID        string    `json:"id"`
+	BandID    string    `json:"band_id"`
+	Name      string    `json:"name"`
+	Role      string    `json:"role"`
+	IsDefault bool      `json:"is_default"`
+	Version   int       `json:"version"`
+	Body      string    `json:"body"`
+	CreatedBy string    `json:"created_by"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// PromptTemplateVersion is one saved body of a prompt template
+type PromptTemplateVersion struct {
+	Version   int       `json:"version"`
+	Body      string    `json:"body"`
+	CreatedBy string    `json:"created_by"`
+	CreatedAt time.Time `json:"created_at"`
+	User      *User     `json:"user,omitempty"`
+}
+
+// promptTemplateQuery selects templates with the body of their latest version
+const promptTemplateQuery = `
+	SELECT t.id, t.band_id, t.name, t.role, t.is_default, v.version, v.body, t.created_by, t.created_at, t.updated_at
+	FROM prompt_templates t
+	INNER JOIN prompt_template_versions v ON v.template_id = t.id
+	WHERE v.version = (SELECT MAX(version) FROM prompt_template_versions WHERE template_id = t.id)
+`
+
+// scanPromptTemplate reads a row selected with promptTemplateQuery
+func scanPromptTemplate(row interface{ Scan(...any) error }) (*PromptTemplate, error) {
+	var template PromptTemplate
+	err := row.Scan(
+		&template.ID,
+		&template.BandID,
+		&template.Name,
+		&template.Role,
+		&template.IsDefault,
+		&template.Version,
+		&template.Body,
+		&template.CreatedBy,
+		&template.CreatedAt,
+		&template.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &template, nil
+}
+
+// CreatePromptTemplate creates a template with its first version
+func (d *SQLitePromptsStore) CreatePromptTemplate(bandID, name, role, body, createdBy string) (*PromptTemplate, error) {
+	// Start a transaction
+	tx, err := d.db.Begin()
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	templateID := generateUUID()
+	now := time.Now()
+
+	query := `INSERT INTO prompt_templates (id, band_id, name, role, created_by, created_at, updated_at) VALUES (?, ?, ?, ?, ?, ?, ?)`
+	if _, err := tx.Exec(query, templateID, bandID, name, role, createdBy, now, now); err != nil {
+		return nil, fmt.Errorf("failed to create prompt template: %w", err)
+	}
+
+	query = `INSERT INTO prompt_template_versions (id, template_id, version, body, created_by, created_at) VALUES (?, ?, ?, ?, ?, ?)`
+	if _, err := tx.Exec(query, templateID+"-1", templateID, 1, body, createdBy, now); err != nil {
+		return nil, fmt.Errorf("failed to create prompt template version: %w", err)
+	}
+
+	// Commit transaction
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return &PromptTemplate{
+		ID:        templateID,
+		BandID:    bandID,
+		Name:      name,
+		Role:      role,
+		Version:   1,
+		Body:      body,
+		CreatedBy: createdBy,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}, nil
+}
+
+// UpdatePromptTemplate renames a template and, when the body changed, saves it
+// as a new version
+func (d *SQLitePromptsStore) UpdatePromptTemplate(templateID, name, body, updatedBy string) error {
+	// Start a transaction
+	tx, err := d.db.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	var version int
+	var latest string
+	err = tx.QueryRow(`SELECT version, body FROM prompt_template_versions WHERE template_id = ? ORDER BY version DESC LIMIT 1`, templateID).Scan(&version, &latest)
+	if err != nil {
+		return fmt.Errorf("failed to get prompt template version: %w", err)
+	}
+
+	now := time.Now()
+	if _, err := tx.Exec(`UPDATE prompt_templates SET name = ?, updated_at = ? WHERE id = ?`, name, now, templateID); err != nil {
+		return fmt.Errorf("failed to update prompt template: %w", err)
+	}
+
+	if body != latest {
+		version++
+		query := `INSERT INTO prompt_template_versions (id, template_id, version, body, created_by, created_at) VALUES (?, ?, ?, ?, ?, ?)`
+		if _, err := tx.Exec(query, fmt.Sprintf("%s-%d", templateID, version), templateID, version, body, updatedBy, now); err != nil {
+			return fmt.Errorf("failed to create prompt template version: %w", err)
+		}
+	}
+
+	// Commit transaction
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return nil
+}
+
+// SetDefaultPromptTemplate makes a template the default for its band and role,
+// replacing the previous default
+func (d *SQLitePromptsStore) SetDefaultPromptTemplate(templateID string) error {
+	// Start a transaction
+	tx, err := d.db.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	var bandID, role string
+	if err := tx.QueryRow(`SELECT band_id, role FROM prompt_templates WHERE id = ?`, templateID).Scan(&bandID, &role); err != nil {
+		return fmt.Errorf("failed to get prompt template: %w", err)
+	}
+
+	if _, err := tx.Exec(`UPDATE prompt_templates SET is_default = 0 WHERE band_id = ? AND role = ?`, bandID, role); err != nil {
+		return fmt.Errorf("failed to clear default prompt template: %w", err)
+	}
+	if _, err := tx.Exec(`UPDATE prompt_templates SET is_default = 1 WHERE id = ?`, templateID); err != nil {
+		return fmt.Errorf("failed to set default prompt template: %w", err)
+	}
+
+	// Commit transaction
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return nil
+}
+
+// ClearDefaultPromptTemplate stops using a band's template by default for its
+// role, so the built-in prompt is used again
+func (d *SQLitePromptsStore) ClearDefaultPromptTemplate(templateID string) error {
+	_, err := d.db.Exec(`UPDATE prompt_templates SET is_default = 0 WHERE id = ?`, templateID)
+	if err != nil {
+		return fmt.Errorf("failed to clear default prompt template: %w", err)
+	}
+	return nil
+}
+
+// DeletePromptTemplate deletes a template and its versions
+func (d *SQLitePromptsStore) DeletePromptTemplate(templateID string) error {
+	// Start a transaction
+	tx, err := d.db.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.Exec(`DELETE FROM prompt_template_versions WHERE template_id = ?`, templateID); err != nil {
+		return fmt.Errorf("failed to delete prompt template versions: %w", err)
+	}
+	if _, err := tx.Exec(`DELETE FROM prompt_templates WHERE id = ?`, templateID); err != nil {
+		return fmt.Errorf("failed to delete prompt template: %w", err)
+	}
+
+	// Commit transaction
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return nil
+}
+
+// GetPromptTemplateByID gets a template with its latest version
+func (d *SQLitePromptsStore) GetPromptTemplateByID(templateID string) (*PromptTemplate, error) {
+	template, err := scanPromptTemplate(d.db.QueryRow(promptTemplateQuery+` AND t.id = ?`, templateID))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to get prompt template: %w", err)
+	}
+	return template, nil
+}
+
+// GetDefaultPromptTemplate gets a band's default template for a role, or nil
+// when the band has none
+func (d *SQLitePromptsStore) GetDefaultPromptTemplate(bandID, role string) (*PromptTemplate, error) {
+	template, err := scanPromptTemplate(d.db.QueryRow(promptTemplateQuery+` AND t.band_id = ? AND t.role = ? AND t.is_default = 1`, bandID, role))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to get default prompt template: %w", err)
+	}
+	return template, nil
+}
+
+// GetPromptTemplatesByBand gets a band's templates by role and name
+func (d *SQLitePromptsStore) GetPromptTemplatesByBand(bandID string) ([]*PromptTemplate, error) {
+	rows, err := d.db.Query(promptTemplateQuery+` AND t.band_id = ? ORDER BY t.role ASC, t.name ASC`, bandID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get prompt templates: %w", err)
+	}
+	defer rows.Close()
+
+	var templates []*PromptTemplate
+	for rows.Next() {
+		template, err := scanPromptTemplate(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan prompt template: %w", err)
+		}
+		templates = append(templates, template)
+	}
+
+	return templates, nil
+}
+
+// GetPromptTemplateVersions gets the versions of a template, newest first
+func (d *SQLitePromptsStore) GetPromptTemplateVersions(templateID string) ([]*PromptTemplateVersion, error) {
+	query := `
+		SELECT v.version, v.body, v.created_by, v.created_at, u.id, u.email
+		FROM prompt_template_versions v
+		INNER JOIN users u ON v.created_by = u.id
+		WHERE v.template_id = ?
+		ORDER BY v.version DESC
+	`
+	rows, err := d.db.Query(query, templateID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get prompt template versions: %w", err)
+	}
+	defer rows.Close()
+
+	var versions []*PromptTemplateVersion
+	for rows.Next() {
+		var version PromptTemplateVersion
+		var user User
+		if err := rows.Scan(&version.Version, &version.Body, &version.CreatedBy, &version.CreatedAt, &user.ID, &user.Email); err != nil {
+			return nil, fmt.Errorf("failed to scan prompt template version: %w", err)
+		}
+		version.User = &user
+		versions = append(versions, &version)
+	}
+
+	return versions, nil
+}
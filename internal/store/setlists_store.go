@@ -0,0 +1,333 @@
+package store
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+)
+
+// Database handles setlist-related database operations
+type SQLiteSetlistsStore struct {
+	db *sql.DB
+}
+
+// NewSQLiteSetlistsStore creates a new setlists database instance
+func NewSQLiteSetlistsStore(db *sql.DB) *SQLiteSetlistsStore {
+	return &SQLiteSetlistsStore{db: db}
+}
+
+// Setlist represents a named, ordered selection of songs from a band's library
+type Setlist struct {
+	ID          string    `json:"id"`
+	BandID      string    `json:"band_id"`
+	Name        string    `json:"name"`
+	Description string    `json:"description"`
+	CreatedBy   string    `json:"created_by"`
+	CreatedAt   time.Time `json:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at"`
+	IsActive    bool      `json:"is_active"`
+	SongCount   int       `json:"song_count"`
+}
+
+// SetlistSong represents a song entry within a setlist
+type SetlistSong struct {
+	ID        string `json:"id"`
+	SetlistID string `json:"setlist_id"`
+	SongID    string `json:"song_id"`
+	Position  int    `json:"position"`
+	Segue     bool   `json:"segue"`
+	Song      *Song  `json:"song,omitempty"`
+}
+
+// CreateSetlist creates a new setlist
+func (d *SQLiteSetlistsStore) CreateSetlist(bandID, name, description, createdBy string) (*Setlist, error) {
+	setlistID := generateUUID()
+
+	query := `INSERT INTO setlists (id, band_id, name, description, created_by) VALUES (?, ?, ?, ?, ?)`
+	_, err := d.db.Exec(query, setlistID, bandID, name, description, createdBy)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create setlist: %w", err)
+	}
+
+	return &Setlist{
+		ID:          setlistID,
+		BandID:      bandID,
+		Name:        name,
+		Description: description,
+		CreatedBy:   createdBy,
+		CreatedAt:   time.Now(),
+		UpdatedAt:   time.Now(),
+		IsActive:    true,
+	}, nil
+}
+
+// GetSetlistsByBand gets all setlists for a band
+func (d *SQLiteSetlistsStore) GetSetlistsByBand(bandID string) ([]*Setlist, error) {
+	query := `
+		SELECT sl.id, sl.band_id, sl.name, sl.description, sl.created_by, sl.created_at, sl.updated_at, sl.is_active,
+		       (SELECT COUNT(*) FROM setlist_songs ss INNER JOIN songs s ON ss.song_id = s.id
+		        WHERE ss.setlist_id = sl.id AND s.is_active = 1)
+		FROM setlists sl
+		WHERE sl.band_id = ? AND sl.is_active = 1
+		ORDER BY sl.updated_at DESC
+	`
+
+	rows, err := d.db.Query(query, bandID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get setlists: %w", err)
+	}
+	defer rows.Close()
+
+	var setlists []*Setlist
+	for rows.Next() {
+		var setlist Setlist
+		var description sql.NullString
+
+		err := rows.Scan(
+			&setlist.ID,
+			&setlist.BandID,
+			&setlist.Name,
+			&description,
+			&setlist.CreatedBy,
+			&setlist.CreatedAt,
+			&setlist.UpdatedAt,
+			&setlist.IsActive,
+			&setlist.SongCount,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan setlist: %w", err)
+		}
+
+		if description.Valid {
+			setlist.Description = description.String
+		}
+
+		setlists = append(setlists, &setlist)
+	}
+
+	return setlists, nil
+}
+
+// GetSetlistByID gets a setlist by ID
+func (d *SQLiteSetlistsStore) GetSetlistByID(setlistID string) (*Setlist, error) {
+	query := `
+		SELECT id, band_id, name, description, created_by, created_at, updated_at, is_active
+		FROM setlists
+		WHERE id = ? AND is_active = 1
+	`
+
+	var setlist Setlist
+	var description sql.NullString
+
+	err := d.db.QueryRow(query, setlistID).Scan(
+		&setlist.ID,
+		&setlist.BandID,
+		&setlist.Name,
+		&description,
+		&setlist.CreatedBy,
+		&setlist.CreatedAt,
+		&setlist.UpdatedAt,
+		&setlist.IsActive,
+	)
+
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to get setlist: %w", err)
+	}
+
+	if description.Valid {
+		setlist.Description = description.String
+	}
+
+	return &setlist, nil
+}
+
+// UpdateSetlist updates a setlist's name and description
+func (d *SQLiteSetlistsStore) UpdateSetlist(setlistID, name, description string) error {
+	query := `UPDATE setlists SET name = ?, description = ?, updated_at = ? WHERE id = ?`
+	_, err := d.db.Exec(query, name, description, time.Now(), setlistID)
+	if err != nil {
+		return fmt.Errorf("failed to update setlist: %w", err)
+	}
+	return nil
+}
+
+// DeleteSetlist deletes a setlist (soft delete)
+func (d *SQLiteSetlistsStore) DeleteSetlist(setlistID string) error {
+	query := `UPDATE setlists SET is_active = 0, updated_at = ? WHERE id = ?`
+	_, err := d.db.Exec(query, time.Now(), setlistID)
+	if err != nil {
+		return fmt.Errorf("failed to delete setlist: %w", err)
+	}
+	return nil
+}
+
+// GetSetlistSongs gets the songs of a setlist in setlist order
+func (d *SQLiteSetlistsStore) GetSetlistSongs(setlistID string) ([]*SetlistSong, error) {
+	query := `
+		SELECT ss.id, ss.setlist_id, ss.song_id, ss.position, ss.segue,
+		       s.id, s.band_id, s.title, s.artist, s.key, s.tempo, s.duration_seconds, s.notes, s.content, s.position, s.created_by, s.created_at, s.updated_at, s.is_active
+		FROM setlist_songs ss
+		INNER JOIN songs s ON ss.song_id = s.id
+		WHERE ss.setlist_id = ? AND s.is_active = 1
+		ORDER BY ss.position ASC
+	`
+
+	rows, err := d.db.Query(query, setlistID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get setlist songs: %w", err)
+	}
+	defer rows.Close()
+
+	var entries []*SetlistSong
+	for rows.Next() {
+		var entry SetlistSong
+		var song Song
+		var tempo, duration sql.NullInt32
+		var content sql.NullString
+
+		err := rows.Scan(
+			&entry.ID,
+			&entry.SetlistID,
+			&entry.SongID,
+			&entry.Position,
+			&entry.Segue,
+			&song.ID,
+			&song.BandID,
+			&song.Title,
+			&song.Artist,
+			&song.Key,
+			&tempo,
+			&duration,
+			&song.Notes,
+			&content,
+			&song.Position,
+			&song.CreatedBy,
+			&song.CreatedAt,
+			&song.UpdatedAt,
+			&song.IsActive,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan setlist song: %w", err)
+		}
+
+		if tempo.Valid {
+			tempoInt := int(tempo.Int32)
+			song.Tempo = &tempoInt
+		}
+		if duration.Valid {
+			durationInt := int(duration.Int32)
+			song.Duration = &durationInt
+		}
+		if content.Valid {
+			song.Content = content.String
+		}
+
+		entry.Song = &song
+		entries = append(entries, &entry)
+	}
+
+	return entries, nil
+}
+
+// AddSongToSetlist appends a library song to the end of a setlist
+func (d *SQLiteSetlistsStore) AddSongToSetlist(setlistID, songID string) (*SetlistSong, error) {
+	entryID := generateUUID()
+
+	// Get the next position for this setlist
+	var maxPosition int
+	err := d.db.QueryRow("SELECT COALESCE(MAX(position), 0) FROM setlist_songs WHERE setlist_id = ?", setlistID).Scan(&maxPosition)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get max position: %w", err)
+	}
+	nextPosition := maxPosition + 1
+
+	query := `INSERT INTO setlist_songs (id, setlist_id, song_id, position) VALUES (?, ?, ?, ?)`
+	_, err = d.db.Exec(query, entryID, setlistID, songID, nextPosition)
+	if err != nil {
+		return nil, fmt.Errorf("failed to add song to setlist: %w", err)
+	}
+
+	if err := d.touchSetlist(setlistID); err != nil {
+		return nil, err
+	}
+
+	return &SetlistSong{
+		ID:        entryID,
+		SetlistID: setlistID,
+		SongID:    songID,
+		Position:  nextPosition,
+	}, nil
+}
+
+// IsSongInSetlist checks whether a song is already part of a setlist
+func (d *SQLiteSetlistsStore) IsSongInSetlist(setlistID, songID string) (bool, error) {
+	var count int
+	err := d.db.QueryRow("SELECT COUNT(*) FROM setlist_songs WHERE setlist_id = ? AND song_id = ?", setlistID, songID).Scan(&count)
+	if err != nil {
+		return false, fmt.Errorf("failed to check setlist song: %w", err)
+	}
+	return count > 0, nil
+}
+
+// RemoveSongFromSetlist removes a song from a setlist without touching the song itself
+func (d *SQLiteSetlistsStore) RemoveSongFromSetlist(setlistID, songID string) error {
+	query := `DELETE FROM setlist_songs WHERE setlist_id = ? AND song_id = ?`
+	_, err := d.db.Exec(query, setlistID, songID)
+	if err != nil {
+		return fmt.Errorf("failed to remove song from setlist: %w", err)
+	}
+	return d.touchSetlist(setlistID)
+}
+
+// SetSetlistSongSegue marks whether a setlist song runs straight into the next one
+func (d *SQLiteSetlistsStore) SetSetlistSongSegue(setlistID, songID string, segue bool) error {
+	query := `UPDATE setlist_songs SET segue = ? WHERE setlist_id = ? AND song_id = ?`
+	_, err := d.db.Exec(query, segue, setlistID, songID)
+	if err != nil {
+		return fmt.Errorf("failed to update setlist song segue: %w", err)
+	}
+	return d.touchSetlist(setlistID)
+}
+
+// ReorderSetlistSongs updates the positions of songs in a setlist
+func (d *SQLiteSetlistsStore) ReorderSetlistSongs(setlistID string, songOrder []string) error {
+	// Start a transaction
+	tx, err := d.db.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	// Update positions for each song
+	for i, songID := range songOrder {
+		_, err := tx.Exec("UPDATE setlist_songs SET position = ? WHERE setlist_id = ? AND song_id = ?",
+			i+1, setlistID, songID)
+		if err != nil {
+			return fmt.Errorf("failed to update setlist song position: %w", err)
+		}
+	}
+
+	_, err = tx.Exec("UPDATE setlists SET updated_at = ? WHERE id = ?", time.Now(), setlistID)
+	if err != nil {
+		return fmt.Errorf("failed to update setlist: %w", err)
+	}
+
+	// Commit transaction
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return nil
+}
+
+// touchSetlist bumps the setlist's updated_at timestamp
+func (d *SQLiteSetlistsStore) touchSetlist(setlistID string) error {
+	_, err := d.db.Exec("UPDATE setlists SET updated_at = ? WHERE id = ?", time.Now(), setlistID)
+	if err != nil {
+		return fmt.Errorf("failed to update setlist: %w", err)
+	}
+	return nil
+}
@@ -24,6 +24,7 @@ type Song struct {
 	Artist    string    `json:"artist"`
 	Key       string    `json:"key"`
 	Tempo     *int      `json:"tempo,omitempty"`
+	Duration  *int      `json:"duration_seconds,omitempty"`
 	Notes     string    `json:"notes"`
 	Content   string    `json:"content"`
 	Position  int       `json:"position"`
@@ -32,25 +33,44 @@ type Song struct {
 	UpdatedAt time.Time `json:"updated_at"`
 	IsActive  bool      `json:"is_active"`
 	User      *User     `json:"user,omitempty"`
+	// DeletedAt and DeletedBy are set for songs in the trash
+	DeletedAt *time.Time `json:"deleted_at,omitempty"`
+	DeletedBy *User      `json:"deleted_by,omitempty"`
 }
 
-// CreateSong creates a new song
-func (d *SQLiteSongsStore) CreateSong(bandID, title, artist, key, notes, content, createdBy string, tempo *int) (*Song, error) {
+// CreateSong creates a new song. Source says how it was written, and is kept
+// with its first revision.
+func (d *SQLiteSongsStore) CreateSong(bandID, title, artist, key, notes, content, createdBy, source string, tempo, duration *int) (*Song, error) {
 	songID := generateUUID()
 
+	// Start a transaction
+	tx, err := d.db.Begin()
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
 	// Get the next position for this band
 	var maxPosition int
-	err := d.db.QueryRow("SELECT COALESCE(MAX(position), 0) FROM songs WHERE band_id = ? AND is_active = 1", bandID).Scan(&maxPosition)
+	err = tx.QueryRow("SELECT COALESCE(MAX(position), 0) FROM songs WHERE band_id = ? AND is_active = 1", bandID).Scan(&maxPosition)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get max position: %w", err)
 	}
 	nextPosition := maxPosition + 1
 
-	query := `INSERT INTO songs (id, band_id, title, artist, key, tempo, notes, content, created_by, position) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`
-	_, err = d.db.Exec(query, songID, bandID, title, artist, key, tempo, notes, content, createdBy, nextPosition)
+	query := `INSERT INTO songs (id, band_id, title, artist, key, tempo, duration_seconds, notes, content, created_by, position) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`
+	_, err = tx.Exec(query, songID, bandID, title, artist, key, tempo, duration, notes, content, createdBy, nextPosition)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create song: %w", err)
 	}
+	if err := addSongRevision(tx, songID, createdBy, source); err != nil {
+		return nil, err
+	}
+
+	// Commit transaction
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit transaction: %w", err)
+	}
 
 	return &Song{
 		ID:        songID,
@@ -59,6 +79,7 @@ func (d *SQLiteSongsStore) CreateSong(bandID, title, artist, key, notes, content
 		Artist:    artist,
 		Key:       key,
 		Tempo:     tempo,
+		Duration:  duration,
 		Notes:     notes,
 		Content:   content,
 		Position:  nextPosition,
@@ -69,10 +90,68 @@ func (d *SQLiteSongsStore) CreateSong(bandID, title, artist, key, notes, content
 	}, nil
 }
 
+// CreateSongs creates several songs for a band in a single transaction,
+// appending them after the band's existing songs in the given order. Their first
+// revisions are recorded as imported.
+func (d *SQLiteSongsStore) CreateSongs(bandID, createdBy string, songs []*Song) ([]*Song, error) {
+	// Start a transaction
+	tx, err := d.db.Begin()
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	// Get the next position for this band
+	var maxPosition int
+	err = tx.QueryRow("SELECT COALESCE(MAX(position), 0) FROM songs WHERE band_id = ? AND is_active = 1", bandID).Scan(&maxPosition)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get max position: %w", err)
+	}
+
+	query := `INSERT INTO songs (id, band_id, title, artist, key, tempo, duration_seconds, notes, content, created_by, position) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`
+	created := make([]*Song, 0, len(songs))
+	for i, song := range songs {
+		songID := generateUUID()
+		position := maxPosition + i + 1
+
+		_, err := tx.Exec(query, songID, bandID, song.Title, song.Artist, song.Key, song.Tempo, song.Duration, song.Notes, song.Content, createdBy, position)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create song %q: %w", song.Title, err)
+		}
+		if err := addSongRevision(tx, songID, createdBy, SongRevisionImport); err != nil {
+			return nil, err
+		}
+
+		created = append(created, &Song{
+			ID:        songID,
+			BandID:    bandID,
+			Title:     song.Title,
+			Artist:    song.Artist,
+			Key:       song.Key,
+			Tempo:     song.Tempo,
+			Duration:  song.Duration,
+			Notes:     song.Notes,
+			Content:   song.Content,
+			Position:  position,
+			CreatedBy: createdBy,
+			CreatedAt: time.Now(),
+			UpdatedAt: time.Now(),
+			IsActive:  true,
+		})
+	}
+
+	// Commit transaction
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return created, nil
+}
+
 // GetSongsByBand gets all songs for a band
 func (d *SQLiteSongsStore) GetSongsByBand(bandID string) ([]*Song, error) {
 	query := `
-		SELECT s.id, s.band_id, s.title, s.artist, s.key, s.tempo, s.notes, s.content, s.position, s.created_by, s.created_at, s.updated_at, s.is_active,
+		SELECT s.id, s.band_id, s.title, s.artist, s.key, s.tempo, s.duration_seconds, s.notes, s.content, s.position, s.created_by, s.created_at, s.updated_at, s.is_active,
 		       u.id, u.email, u.created_at, u.last_login, u.is_active
 		FROM songs s
 		INNER JOIN users u ON s.created_by = u.id
@@ -91,7 +170,7 @@ func (d *SQLiteSongsStore) GetSongsByBand(bandID string) ([]*Song, error) {
 		var song Song
 		var user User
 		var lastLogin sql.NullTime
-		var tempo sql.NullInt32
+		var tempo, duration sql.NullInt32
 		var content sql.NullString
 
 		err := rows.Scan(
@@ -101,6 +180,7 @@ func (d *SQLiteSongsStore) GetSongsByBand(bandID string) ([]*Song, error) {
 			&song.Artist,
 			&song.Key,
 			&tempo,
+			&duration,
 			&song.Notes,
 			&content,
 			&song.Position,
@@ -125,6 +205,10 @@ func (d *SQLiteSongsStore) GetSongsByBand(bandID string) ([]*Song, error) {
 			tempoInt := int(tempo.Int32)
 			song.Tempo = &tempoInt
 		}
+		if duration.Valid {
+			durationInt := int(duration.Int32)
+			song.Duration = &durationInt
+		}
 		if content.Valid {
 			song.Content = content.String
 		}
@@ -139,13 +223,13 @@ func (d *SQLiteSongsStore) GetSongsByBand(bandID string) ([]*Song, error) {
 // GetSongByID gets a song by ID
 func (d *SQLiteSongsStore) GetSongByID(songID string) (*Song, error) {
 	query := `
-		SELECT s.id, s.band_id, s.title, s.artist, s.key, s.tempo, s.notes, s.content, s.position, s.created_by, s.created_at, s.updated_at, s.is_active
+		SELECT s.id, s.band_id, s.title, s.artist, s.key, s.tempo, s.duration_seconds, s.notes, s.content, s.position, s.created_by, s.created_at, s.updated_at, s.is_active
 		FROM songs s
 		WHERE s.id = ? AND s.is_active = 1
 	`
 
 	var song Song
-	var tempo sql.NullInt32
+	var tempo, duration sql.NullInt32
 	var content sql.NullString
 
 	err := d.db.QueryRow(query, songID).Scan(
@@ -155,6 +239,7 @@ func (d *SQLiteSongsStore) GetSongByID(songID string) (*Song, error) {
 		&song.Artist,
 		&song.Key,
 		&tempo,
+		&duration,
 		&song.Notes,
 		&content,
 		&song.Position,
@@ -175,6 +260,10 @@ func (d *SQLiteSongsStore) GetSongByID(songID string) (*Song, error) {
 		tempoInt := int(tempo.Int32)
 		song.Tempo = &tempoInt
 	}
+	if duration.Valid {
+		durationInt := int(duration.Int32)
+		song.Duration = &durationInt
+	}
 	if content.Valid {
 		song.Content = content.String
 	}
@@ -182,26 +271,203 @@ func (d *SQLiteSongsStore) GetSongByID(songID string) (*Song, error) {
 	return &song, nil
 }
 
-// UpdateSong updates a song
-func (d *SQLiteSongsStore) UpdateSong(songID, title, artist, key, notes, content string, tempo *int) error {
-	query := `UPDATE songs SET title = ?, artist = ?, key = ?, tempo = ?, notes = ?, content = ?, updated_at = ? WHERE id = ?`
-	_, err := d.db.Exec(query, title, artist, key, tempo, notes, content, time.Now(), songID)
+// UpdateSong updates a song and records the new state as a revision by the
+// given user. Source says how the change was made, e.g. SongRevisionAI.
+func (d *SQLiteSongsStore) UpdateSong(songID, title, artist, key, notes, content string, tempo, duration *int, updatedBy, source string) error {
+	// Start a transaction
+	tx, err := d.db.Begin()
 	if err != nil {
-		return fmt.Errorf("failed to update song: %w", err)
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	if err := updateSong(tx, songID, title, artist, key, notes, content, tempo, duration, updatedBy, source); err != nil {
+		return err
 	}
+
+	// Commit transaction
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
 	return nil
 }
 
-// DeleteSong deletes a song (soft delete)
-func (d *SQLiteSongsStore) DeleteSong(songID string) error {
-	query := `UPDATE songs SET is_active = 0, updated_at = ? WHERE id = ?`
-	_, err := d.db.Exec(query, time.Now(), songID)
+// UpdateSongWithSections updates a song like UpdateSong and replaces its sections
+// in the same transaction, so the content and its sections always match
+func (d *SQLiteSongsStore) UpdateSongWithSections(songID, title, artist, key, notes, content string, tempo, duration *int, sections []*SongSection, updatedBy, source string) error {
+	// Start a transaction
+	tx, err := d.db.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	if err := updateSong(tx, songID, title, artist, key, notes, content, tempo, duration, updatedBy, source); err != nil {
+		return err
+	}
+	if err := replaceSongSections(tx, songID, sections); err != nil {
+		return err
+	}
+
+	// Commit transaction
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return nil
+}
+
+// updateSong updates a song within a transaction and records the revision
+func updateSong(tx *sql.Tx, songID, title, artist, key, notes, content string, tempo, duration *int, updatedBy, source string) error {
+	query := `UPDATE songs SET title = ?, artist = ?, key = ?, tempo = ?, duration_seconds = ?, notes = ?, content = ?, updated_at = ? WHERE id = ?`
+	_, err := tx.Exec(query, title, artist, key, tempo, duration, notes, content, time.Now(), songID)
+	if err != nil {
+		return fmt.Errorf("failed to update song: %w", err)
+	}
+	return addSongRevision(tx, songID, updatedBy, source)
+}
+
+// DeleteSong moves a song to its band's trash (soft delete)
+func (d *SQLiteSongsStore) DeleteSong(songID, deletedBy string) error {
+	query := `UPDATE songs SET is_active = 0, deleted_at = ?, deleted_by = ?, updated_at = ? WHERE id = ?`
+	_, err := d.db.Exec(query, time.Now(), deletedBy, time.Now(), songID)
 	if err != nil {
 		return fmt.Errorf("failed to delete song: %w", err)
 	}
 	return nil
 }
 
+// deletedSongQuery selects songs in the trash with the user who deleted them
+const deletedSongQuery = `
+	SELECT s.id, s.band_id, s.title, s.artist, s.key, s.position, s.created_by, s.created_at, s.updated_at, s.deleted_at,
+	       u.id, u.email
+	FROM songs s
+	LEFT JOIN users u ON s.deleted_by = u.id
+	WHERE s.is_active = 0`
+
+// scanDeletedSong scans a row selected with deletedSongQuery
+func scanDeletedSong(row interface{ Scan(...any) error }) (*Song, error) {
+	var song Song
+	var artist, key sql.NullString
+	var deletedAt sql.NullTime
+	var userID, userEmail sql.NullString
+
+	err := row.Scan(
+		&song.ID,
+		&song.BandID,
+		&song.Title,
+		&artist,
+		&key,
+		&song.Position,
+		&song.CreatedBy,
+		&song.CreatedAt,
+		&song.UpdatedAt,
+		&deletedAt,
+		&userID,
+		&userEmail,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	song.Artist = artist.String
+	song.Key = key.String
+	if deletedAt.Valid {
+		song.DeletedAt = &deletedAt.Time
+	} else {
+		song.DeletedAt = &song.UpdatedAt
+	}
+	if userID.Valid {
+		song.DeletedBy = &User{ID: userID.String, Email: userEmail.String}
+	}
+	return &song, nil
+}
+
+// GetDeletedSongs gets the songs in a band's trash, most recently deleted first
+func (d *SQLiteSongsStore) GetDeletedSongs(bandID string) ([]*Song, error) {
+	rows, err := d.db.Query(deletedSongQuery+` AND s.band_id = ? ORDER BY s.deleted_at DESC`, bandID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get deleted songs: %w", err)
+	}
+	defer rows.Close()
+
+	var songs []*Song
+	for rows.Next() {
+		song, err := scanDeletedSong(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan deleted song: %w", err)
+		}
+		songs = append(songs, song)
+	}
+	return songs, nil
+}
+
+// GetDeletedSong gets a song in the trash by ID, or nil when there is none
+func (d *SQLiteSongsStore) GetDeletedSong(songID string) (*Song, error) {
+	song, err := scanDeletedSong(d.db.QueryRow(deletedSongQuery+` AND s.id = ?`, songID))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to get deleted song: %w", err)
+	}
+	return song, nil
+}
+
+// UndeleteSong takes a song out of the trash, back at the position it had. When
+// another song has taken that position since, it and the songs after it move
+// down one place.
+func (d *SQLiteSongsStore) UndeleteSong(songID string) error {
+	// Start a transaction
+	tx, err := d.db.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	var bandID string
+	var position int
+	err = tx.QueryRow("SELECT band_id, position FROM songs WHERE id = ? AND is_active = 0", songID).Scan(&bandID, &position)
+	if err != nil {
+		return fmt.Errorf("failed to get deleted song: %w", err)
+	}
+
+	var taken int
+	err = tx.QueryRow("SELECT COUNT(*) FROM songs WHERE band_id = ? AND is_active = 1 AND position = ?", bandID, position).Scan(&taken)
+	if err != nil {
+		return fmt.Errorf("failed to check song position: %w", err)
+	}
+	if taken > 0 {
+		_, err = tx.Exec("UPDATE songs SET position = position + 1 WHERE band_id = ? AND is_active = 1 AND position >= ?", bandID, position)
+		if err != nil {
+			return fmt.Errorf("failed to update song positions: %w", err)
+		}
+	}
+
+	query := `UPDATE songs SET is_active = 1, deleted_at = NULL, deleted_by = NULL, updated_at = ? WHERE id = ?`
+	if _, err := tx.Exec(query, time.Now(), songID); err != nil {
+		return fmt.Errorf("failed to undelete song: %w", err)
+	}
+
+	// Commit transaction
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return nil
+}
+
+// PurgeDeletedSongs permanently deletes the songs that were moved to the trash
+// before the given time, returning how many were deleted
+func (d *SQLiteSongsStore) PurgeDeletedSongs(before time.Time) (int64, error) {
+	result, err := d.db.Exec("DELETE FROM songs WHERE is_active = 0 AND deleted_at < ?", before)
+	if err != nil {
+		return 0, fmt.Errorf("failed to purge deleted songs: %w", err)
+	}
+	return result.RowsAffected()
+}
+
 // ReorderSongs updates the positions of songs in a band
 func (d *SQLiteSongsStore) ReorderSongs(bandID string, songOrder []string) error {
 	// Start a transaction
@@ -227,3 +493,329 @@ func (d *SQLiteSongsStore) ReorderSongs(bandID string, songOrder []string) error
 
 	return nil
 }
+
+// SongSection is one section of a song's generated cheatsheet, in playing order
+type SongSection struct {
+	Name string `json:"name"`
+	Key  string `json:"key"`
+	Body string `json:"body"`
+}
+
+// GetSongSections gets the sections of a song in playing order
+func (d *SQLiteSongsStore) GetSongSections(songID string) ([]*SongSection, error) {
+	rows, err := d.db.Query("SELECT name, key, body FROM song_sections WHERE song_id = ? ORDER BY position ASC", songID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get song sections: %w", err)
+	}
+	defer rows.Close()
+
+	var sections []*SongSection
+	for rows.Next() {
+		var section SongSection
+		var key sql.NullString
+		if err := rows.Scan(&section.Name, &key, &section.Body); err != nil {
+			return nil, fmt.Errorf("failed to scan song section: %w", err)
+		}
+		section.Key = key.String
+		sections = append(sections, &section)
+	}
+
+	return sections, nil
+}
+
+// ReplaceSongSections replaces the sections of a song with the given ones.
+// Passing no sections clears them.
+func (d *SQLiteSongsStore) ReplaceSongSections(songID string, sections []*SongSection) error {
+	// Start a transaction
+	tx, err := d.db.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	if err := replaceSongSections(tx, songID, sections); err != nil {
+		return err
+	}
+
+	// Commit transaction
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return nil
+}
+
+// replaceSongSections replaces the sections of a song within a transaction
+func replaceSongSections(tx *sql.Tx, songID string, sections []*SongSection) error {
+	if _, err := tx.Exec("DELETE FROM song_sections WHERE song_id = ?", songID); err != nil {
+		return fmt.Errorf("failed to clear song sections: %w", err)
+	}
+
+	query := `INSERT INTO song_sections (id, song_id, name, key, body, position) VALUES (?, ?, ?, ?, ?, ?)`
+	for i, section := range sections {
+		sectionID := fmt.Sprintf("%s-%d", songID, i+1)
+		if _, err := tx.Exec(query, sectionID, songID, section.Name, section.Key, section.Body, i+1); err != nil {
+			return fmt.Errorf("failed to create song section %q: %w", section.Name, err)
+		}
+	}
+	return nil
+}
+
+// SongVariant is instrument-specific content generated for a song, such as a
+// drum chart, kept next to the song's main content
+type SongVariant struct {
+	Role            string    `json:"role"`
+	Content         string    `json:"content"`
+	TemplateID      string    `json:"template_id,omitempty"`
+	TemplateVersion int       `json:"template_version,omitempty"`
+	UpdatedAt       time.Time `json:"updated_at"`
+}
+
+// GetSongVariants gets the variants of a song by role
+func (d *SQLiteSongsStore) GetSongVariants(songID string) ([]*SongVariant, error) {
+	rows, err := d.db.Query("SELECT role, content, template_id, template_version, updated_at FROM song_variants WHERE song_id = ? ORDER BY role ASC", songID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get song variants: %w", err)
+	}
+	defer rows.Close()
+
+	var variants []*SongVariant
+	for rows.Next() {
+		var variant SongVariant
+		var templateID sql.NullString
+		var templateVersion sql.NullInt32
+		if err := rows.Scan(&variant.Role, &variant.Content, &templateID, &templateVersion, &variant.UpdatedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan song variant: %w", err)
+		}
+		variant.TemplateID = templateID.String
+		variant.TemplateVersion = int(templateVersion.Int32)
+		variants = append(variants, &variant)
+	}
+
+	return variants, nil
+}
+
+// GetSongVariant gets a song's variant for a role, or nil when it has none
+func (d *SQLiteSongsStore) GetSongVariant(songID, role string) (*SongVariant, error) {
+	var variant SongVariant
+	var templateID sql.NullString
+	var templateVersion sql.NullInt32
+	err := d.db.QueryRow("SELECT role, content, template_id, template_version, updated_at FROM song_variants WHERE song_id = ? AND role = ?", songID, role).Scan(
+		&variant.Role, &variant.Content, &templateID, &templateVersion, &variant.UpdatedAt,
+	)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to get song variant: %w", err)
+	}
+	variant.TemplateID = templateID.String
+	variant.TemplateVersion = int(templateVersion.Int32)
+	return &variant, nil
+}
+
+// SaveSongVariant stores a song's variant for a role, replacing the previous
+// one. The template is the band template it was generated with, if any.
+func (d *SQLiteSongsStore) SaveSongVariant(songID, role, content, templateID string, templateVersion int) error {
+	var template sql.NullString
+	var version sql.NullInt32
+	if templateID != "" {
+		template = sql.NullString{String: templateID, Valid: true}
+		version = sql.NullInt32{Int32: int32(templateVersion), Valid: true}
+	}
+
+	now := time.Now()
+	query := `
+		INSERT INTO song_variants (id, song_id, role, content, template_id, template_version, created_at, updated_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?)
+		ON CONFLICT(song_id, role) DO UPDATE SET
+			content = excluded.content,
+			template_id = excluded.template_id,
+			template_version = excluded.template_version,
+			updated_at = excluded.updated_at
+	`
+	_, err := d.db.Exec(query, songID+"-"+role, songID, role, content, template, version, now, now)
+	if err != nil {
+		return fmt.Errorf("failed to save song variant: %w", err)
+	}
+	return nil
+}
+
+// DeleteSongVariant deletes a song's variant for a role
+func (d *SQLiteSongsStore) DeleteSongVariant(songID, role string) error {
+	_, err := d.db.Exec("DELETE FROM song_variants WHERE song_id = ? AND role = ?", songID, role)
+	if err != nil {
+		return fmt.Errorf("failed to delete song variant: %w", err)
+	}
+	return nil
+}
+
+// Sources of song revisions
+const (
+	SongRevisionManual  = "manual"
+	SongRevisionAI      = "ai"
+	SongRevisionImport  = "import"
+	SongRevisionRestore = "restore"
+)
+
+// SongRevision is a saved state of a song's metadata and content
+type SongRevision struct {
+	Revision  int       `json:"revision"`
+	Title     string    `json:"title"`
+	Artist    string    `json:"artist"`
+	Key       string    `json:"key"`
+	Tempo     *int      `json:"tempo,omitempty"`
+	Duration  *int      `json:"duration_seconds,omitempty"`
+	Notes     string    `json:"notes"`
+	Content   string    `json:"content"`
+	Source    string    `json:"source"`
+	CreatedBy string    `json:"created_by"`
+	CreatedAt time.Time `json:"created_at"`
+	User      *User     `json:"user,omitempty"`
+}
+
+// addSongRevision records a song's current state as its next revision, unless
+// nothing changed since its latest revision
+func addSongRevision(tx *sql.Tx, songID, createdBy, source string) error {
+	var unchanged bool
+	err := tx.QueryRow(`
+		SELECT COUNT(*) > 0
+		FROM songs s
+		INNER JOIN song_revisions r ON r.song_id = s.id
+		WHERE s.id = ?
+			AND r.revision = (SELECT MAX(revision) FROM song_revisions WHERE song_id = s.id)
+			AND r.title IS s.title AND r.artist IS s.artist AND r.key IS s.key
+			AND r.tempo IS s.tempo AND r.duration_seconds IS s.duration_seconds
+			AND r.notes IS s.notes AND r.content IS s.content
+	`, songID).Scan(&unchanged)
+	if err != nil {
+		return fmt.Errorf("failed to compare song revision: %w", err)
+	}
+	if unchanged {
+		return nil
+	}
+
+	var revision int
+	if err := tx.QueryRow("SELECT COALESCE(MAX(revision), 0) + 1 FROM song_revisions WHERE song_id = ?", songID).Scan(&revision); err != nil {
+		return fmt.Errorf("failed to get next song revision: %w", err)
+	}
+
+	query := `
+		INSERT INTO song_revisions (id, song_id, revision, title, artist, key, tempo, duration_seconds, notes, content, source, created_by, created_at)
+		SELECT ?, id, ?, title, artist, key, tempo, duration_seconds, notes, content, ?, ?, ?
+		FROM songs WHERE id = ?
+	`
+	_, err = tx.Exec(query, fmt.Sprintf("%s-%d", songID, revision), revision, source, createdBy, time.Now(), songID)
+	if err != nil {
+		return fmt.Errorf("failed to create song revision: %w", err)
+	}
+	return nil
+}
+
+// songRevisionQuery selects revisions with the user who saved them
+const songRevisionQuery = `
+	SELECT r.revision, r.title, r.artist, r.key, r.tempo, r.duration_seconds, r.notes, r.content, r.source, r.created_by, r.created_at, u.id, u.email
+	FROM song_revisions r
+	LEFT JOIN users u ON r.created_by = u.id
+`
+
+// scanSongRevision reads a row selected with songRevisionQuery
+func scanSongRevision(row interface{ Scan(...any) error }) (*SongRevision, error) {
+	var revision SongRevision
+	var artist, key, notes, content, userID, userEmail sql.NullString
+	var tempo, duration sql.NullInt32
+	err := row.Scan(
+		&revision.Revision,
+		&revision.Title,
+		&artist,
+		&key,
+		&tempo,
+		&duration,
+		&notes,
+		&content,
+		&revision.Source,
+		&revision.CreatedBy,
+		&revision.CreatedAt,
+		&userID,
+		&userEmail,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	revision.Artist = artist.String
+	revision.Key = key.String
+	revision.Notes = notes.String
+	revision.Content = content.String
+	if tempo.Valid {
+		tempoInt := int(tempo.Int32)
+		revision.Tempo = &tempoInt
+	}
+	if duration.Valid {
+		durationInt := int(duration.Int32)
+		revision.Duration = &durationInt
+	}
+	if userID.Valid {
+		revision.User = &User{ID: userID.String, Email: userEmail.String}
+	}
+	return &revision, nil
+}
+
+// GetSongRevisions gets the revisions of a song, newest first
+func (d *SQLiteSongsStore) GetSongRevisions(songID string) ([]*SongRevision, error) {
+	rows, err := d.db.Query(songRevisionQuery+` WHERE r.song_id = ? ORDER BY r.revision DESC`, songID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get song revisions: %w", err)
+	}
+	defer rows.Close()
+
+	var revisions []*SongRevision
+	for rows.Next() {
+		revision, err := scanSongRevision(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan song revision: %w", err)
+		}
+		revisions = append(revisions, revision)
+	}
+
+	return revisions, nil
+}
+
+// GetSongRevision gets a revision of a song, or nil when it doesn't exist
+func (d *SQLiteSongsStore) GetSongRevision(songID string, revision int) (*SongRevision, error) {
+	songRevision, err := scanSongRevision(d.db.QueryRow(songRevisionQuery+` WHERE r.song_id = ? AND r.revision = ?`, songID, revision))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to get song revision: %w", err)
+	}
+	return songRevision, nil
+}
+
+// GetSongContentSource returns the source of the revision that gave a song its
+// current content, e.g. SongRevisionAI, or "" when no revision has it. Later
+// revisions that only changed the song's metadata don't count.
+func (d *SQLiteSongsStore) GetSongContentSource(songID string) (string, error) {
+	query := `
+		SELECT r.source
+		FROM songs s
+		INNER JOIN song_revisions r ON r.song_id = s.id AND r.content IS s.content
+		WHERE s.id = ?
+			AND r.revision > (
+				SELECT COALESCE(MAX(revision), 0) FROM song_revisions
+				WHERE song_id = s.id AND content IS NOT s.content
+			)
+		ORDER BY r.revision ASC
+		LIMIT 1
+	`
+	var source string
+	err := d.db.QueryRow(query, songID).Scan(&source)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return "", nil
+		}
+		return "", fmt.Errorf("failed to get song content source: %w", err)
+	}
+	return source, nil
+}
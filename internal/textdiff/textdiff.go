@@ -0,0 +1,83 @@
+package textdiff
+
+import "strings"
+
+// Kinds of diff lines
+const (
+	Equal  = "equal"
+	Insert = "insert"
+	Delete = "delete"
+)
+
+// Line is one line of a diff: a line both texts share, or one only in the new
+// or the old text
+type Line struct {
+	Kind string
+	Text string
+	// OldNumber and NewNumber are the line's 1-based numbers in each text, 0
+	// when the line isn't in that text
+	OldNumber int
+	NewNumber int
+}
+
+// Lines compares two texts line by line and returns the lines of the new text
+// interleaved with the lines removed from the old one, removed lines first. It
+// uses the longest common subsequence so unchanged lines line up.
+func Lines(oldText, newText string) []Line {
+	oldLines, newLines := splitLines(oldText), splitLines(newText)
+
+	// common[i][j] is the length of the longest common subsequence of
+	// oldLines[i:] and newLines[j:]
+	common := make([][]int, len(oldLines)+1)
+	for i := range common {
+		common[i] = make([]int, len(newLines)+1)
+	}
+	for i := len(oldLines) - 1; i >= 0; i-- {
+		for j := len(newLines) - 1; j >= 0; j-- {
+			if oldLines[i] == newLines[j] {
+				common[i][j] = common[i+1][j+1] + 1
+			} else {
+				common[i][j] = max(common[i+1][j], common[i][j+1])
+			}
+		}
+	}
+
+	var lines []Line
+	i, j := 0, 0
+	for i < len(oldLines) || j < len(newLines) {
+		switch {
+		case i < len(oldLines) && j < len(newLines) && oldLines[i] == newLines[j]:
+			lines = append(lines, Line{Kind: Equal, Text: oldLines[i], OldNumber: i + 1, NewNumber: j + 1})
+			i++
+			j++
+		case i < len(oldLines) && (j == len(newLines) || common[i+1][j] >= common[i][j+1]):
+			lines = append(lines, Line{Kind: Delete, Text: oldLines[i], OldNumber: i + 1})
+			i++
+		default:
+			lines = append(lines, Line{Kind: Insert, Text: newLines[j], NewNumber: j + 1})
+			j++
+		}
+	}
+	return lines
+}
+
+// Changed reports whether a diff has any inserted or deleted lines
+func Changed(lines []Line) bool {
+	for _, line := range lines {
+		if line.Kind != Equal {
+			return true
+		}
+	}
+	return false
+}
+
+// splitLines splits a text into lines, ignoring the final line break and
+// treating an empty text as having no lines
+func splitLines(text string) []string {
+	text = strings.ReplaceAll(text, "\r\n", "\n")
+	text = strings.TrimSuffix(text, "\n")
+	if text == "" {
+		return nil
+	}
+	return strings.Split(text, "\n")
+}
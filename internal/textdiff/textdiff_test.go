@@ -0,0 +1,149 @@
+package textdiff
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestLines(t *testing.T) {
+	tests := []struct {
+		name    string
+		oldText string
+		newText string
+		want    []Line
+	}{
+		{
+			name: "both empty",
+		},
+		{
+			name:    "identical",
+			oldText: "C G\nHello",
+			newText: "C G\nHello",
+			want: []Line{
+				{Kind: Equal, Text: "C G", OldNumber: 1, NewNumber: 1},
+				{Kind: Equal, Text: "Hello", OldNumber: 2, NewNumber: 2},
+			},
+		},
+		{
+			name:    "from empty",
+			newText: "one\ntwo\n",
+			want: []Line{
+				{Kind: Insert, Text: "one", NewNumber: 1},
+				{Kind: Insert, Text: "two", NewNumber: 2},
+			},
+		},
+		{
+			name:    "to empty",
+			oldText: "one\ntwo",
+			want: []Line{
+				{Kind: Delete, Text: "one", OldNumber: 1},
+				{Kind: Delete, Text: "two", OldNumber: 2},
+			},
+		},
+		{
+			name:    "changed line, removed line first",
+			oldText: "Verse\nC G\nLyric",
+			newText: "Verse\nD A\nLyric",
+			want: []Line{
+				{Kind: Equal, Text: "Verse", OldNumber: 1, NewNumber: 1},
+				{Kind: Delete, Text: "C G", OldNumber: 2},
+				{Kind: Insert, Text: "D A", NewNumber: 2},
+				{Kind: Equal, Text: "Lyric", OldNumber: 3, NewNumber: 3},
+			},
+		},
+		{
+			name:    "inserted line keeps the rest aligned",
+			oldText: "a\nb\nc",
+			newText: "a\nx\nb\nc",
+			want: []Line{
+				{Kind: Equal, Text: "a", OldNumber: 1, NewNumber: 1},
+				{Kind: Insert, Text: "x", NewNumber: 2},
+				{Kind: Equal, Text: "b", OldNumber: 2, NewNumber: 3},
+				{Kind: Equal, Text: "c", OldNumber: 3, NewNumber: 4},
+			},
+		},
+		{
+			name:    "deleted line",
+			oldText: "a\nb\nc",
+			newText: "a\nc",
+			want: []Line{
+				{Kind: Equal, Text: "a", OldNumber: 1, NewNumber: 1},
+				{Kind: Delete, Text: "b", OldNumber: 2},
+				{Kind: Equal, Text: "c", OldNumber: 3, NewNumber: 2},
+			},
+		},
+		{
+			name:    "moved line",
+			oldText: "a\nb\nc",
+			newText: "b\nc\na",
+			want: []Line{
+				{Kind: Delete, Text: "a", OldNumber: 1},
+				{Kind: Equal, Text: "b", OldNumber: 2, NewNumber: 1},
+				{Kind: Equal, Text: "c", OldNumber: 3, NewNumber: 2},
+				{Kind: Insert, Text: "a", NewNumber: 3},
+			},
+		},
+		{
+			name:    "repeated lines",
+			oldText: "la\nla\nla",
+			newText: "la\nla",
+			want: []Line{
+				{Kind: Equal, Text: "la", OldNumber: 1, NewNumber: 1},
+				{Kind: Equal, Text: "la", OldNumber: 2, NewNumber: 2},
+				{Kind: Delete, Text: "la", OldNumber: 3},
+			},
+		},
+		{
+			name:    "line endings and final break are ignored",
+			oldText: "a\r\nb\r\n",
+			newText: "a\nb",
+			want: []Line{
+				{Kind: Equal, Text: "a", OldNumber: 1, NewNumber: 1},
+				{Kind: Equal, Text: "b", OldNumber: 2, NewNumber: 2},
+			},
+		},
+		{
+			name:    "blank lines count",
+			oldText: "a\nb",
+			newText: "a\n\nb",
+			want: []Line{
+				{Kind: Equal, Text: "a", OldNumber: 1, NewNumber: 1},
+				{Kind: Insert, Text: "", NewNumber: 2},
+				{Kind: Equal, Text: "b", OldNumber: 2, NewNumber: 3},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := Lines(tt.oldText, tt.newText)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Lines(%q, %q) =\n%+v\nwant\n%+v", tt.oldText, tt.newText, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestChanged(t *testing.T) {
+	tests := []struct {
+		name    string
+		oldText string
+		newText string
+		want    bool
+	}{
+		{name: "both empty", want: false},
+		{name: "identical", oldText: "a\nb", newText: "a\nb", want: false},
+		{name: "only the final break differs", oldText: "a\n", newText: "a", want: false},
+		{name: "inserted", oldText: "a", newText: "a\nb", want: true},
+		{name: "deleted", oldText: "a\nb", newText: "b", want: true},
+		{name: "whitespace", oldText: "a", newText: "a ", want: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Changed(Lines(tt.oldText, tt.newText)); got != tt.want {
+				t.Errorf("Changed(Lines(%q, %q)) = %v, want %v", tt.oldText, tt.newText, got, tt.want)
+			}
+		})
+	}
+}
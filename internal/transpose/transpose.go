@@ -0,0 +1,292 @@
+package transpose
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// Note names used when spelling transposed chords
+var (
+	sharpNotes = []string{"C", "C#", "D", "D#", "E", "F", "F#", "G", "G#", "A", "A#", "B"}
+	flatNotes  = []string{"C", "Db", "D", "Eb", "E", "F", "Gb", "G", "Ab", "A", "Bb", "B"}
+)
+
+// Conventional spelling of each key, indexed by the semitone of its tonic
+var (
+	majorKeys = []string{"C", "Db", "D", "Eb", "E", "F", "F#", "G", "Ab", "A", "Bb", "B"}
+	minorKeys = []string{"Cm", "C#m", "Dm", "Ebm", "Em", "Fm", "F#m", "Gm", "G#m", "Am", "Bbm", "Bm"}
+)
+
+// chordPattern matches a single chord symbol: root, quality/extensions and optional slash bass
+var chordPattern = regexp.MustCompile(`^([A-G](?:#|b|♯|♭)?)((?:maj|min|mi|m|M|dim|aug|sus|add|alt|no|[0-9]|#|b|♯|♭|\+|-|°|ø|Δ|\(|\)|/9|/11)*)(?:/([A-G](?:#|b|♯|♭)?))?$`)
+
+// keyPattern matches a key name such as "A", "F#m", "Bb minor" or "C major"
+var keyPattern = regexp.MustCompile(`^([A-G](?:#|b|♯|♭)?)\s*(m|min|minor|menor|major|maj|mayor)?$`)
+
+// labelPattern matches markdown labels whose value is a chord progression or a key,
+// e.g. "**Chords:** C - Am - F - G" or "**Key:** C"
+var labelPattern = regexp.MustCompile(`(?i)(\*\*(?:chords|acordes|key|tonalidad|tono)\s*:?\s*\*\*\s*:?\s*|\b(?:chords|acordes)\s*:\s*)([^|\n]+)`)
+
+// bracketPattern matches inline ChordPro-style chords such as "[Am7]"
+var bracketPattern = regexp.MustCompile(`\[([^\]\s]+)\]`)
+
+// tokenPattern splits a line into whitespace-separated tokens
+var tokenPattern = regexp.MustCompile(`\S+`)
+
+// separators are tokens that may appear on a chord line without being chords
+var separators = map[string]bool{
+	"|": true, "||": true, "|:": true, ":|": true, "-": true, "–": true, "/": true,
+	"%": true, "N.C.": true, "NC": true, "x2": true, "x3": true, "x4": true, "...": true,
+}
+
+// Chord is a parsed chord symbol
+type Chord struct {
+	Root   string
+	Suffix string
+	Bass   string
+}
+
+// ParseChord parses a chord symbol such as "C", "F#m7", "Bbmaj7/D" or "Gsus4"
+func ParseChord(symbol string) (Chord, bool) {
+	match := chordPattern.FindStringSubmatch(symbol)
+	if match == nil {
+		return Chord{}, false
+	}
+	return Chord{Root: match[1], Suffix: match[2], Bass: match[3]}, true
+}
+
+// String returns the chord symbol
+func (c Chord) String() string {
+	if c.Bass != "" {
+		return c.Root + c.Suffix + "/" + c.Bass
+	}
+	return c.Root + c.Suffix
+}
+
+// Transpose moves the chord by the given number of semitones,
+// spelling the new notes with flats or sharps
+func (c Chord) Transpose(semitones int, useFlats bool) Chord {
+	transposed := Chord{
+		Root:   transposeNote(c.Root, semitones, useFlats),
+		Suffix: c.Suffix,
+	}
+	if c.Bass != "" {
+		transposed.Bass = transposeNote(c.Bass, semitones, useFlats)
+	}
+	return transposed
+}
+
+// ParseKey parses a key name and returns the semitone of its tonic and whether it is minor
+func ParseKey(key string) (int, bool, error) {
+	match := keyPattern.FindStringSubmatch(strings.TrimSpace(key))
+	if match == nil {
+		return 0, false, fmt.Errorf("invalid key %q", key)
+	}
+	minor := false
+	switch strings.ToLower(match[2]) {
+	case "m", "min", "minor", "menor":
+		minor = true
+	}
+	return noteIndex(match[1]), minor, nil
+}
+
+// Interval returns the number of semitones (0-11) from one key to another
+func Interval(fromKey, toKey string) (int, error) {
+	from, _, err := ParseKey(fromKey)
+	if err != nil {
+		return 0, err
+	}
+	to, _, err := ParseKey(toKey)
+	if err != nil {
+		return 0, err
+	}
+	return mod12(to - from), nil
+}
+
+// Key transposes a key name by the given number of semitones using its conventional spelling
+func Key(key string, semitones int) (string, error) {
+	tonic, minor, err := ParseKey(key)
+	if err != nil {
+		return "", err
+	}
+	index := mod12(tonic + semitones)
+	if minor {
+		return minorKeys[index], nil
+	}
+	return majorKeys[index], nil
+}
+
+// KeyOptions lists the twelve keys in the same mode as the given key,
+// or all major and minor keys when the key is empty or unknown
+func KeyOptions(key string) []string {
+	_, minor, err := ParseKey(key)
+	if err != nil {
+		options := append([]string{}, majorKeys...)
+		return append(options, minorKeys...)
+	}
+	if minor {
+		return append([]string{}, minorKeys...)
+	}
+	return append([]string{}, majorKeys...)
+}
+
+// UsesFlats reports whether chords in the given key are conventionally spelled with flats
+func UsesFlats(key string) bool {
+	tonic, minor, err := ParseKey(key)
+	if err != nil {
+		return false
+	}
+	name := majorKeys[tonic]
+	if minor {
+		name = minorKeys[tonic]
+	}
+	if strings.Contains(name, "b") {
+		return true
+	}
+	// F major and D, G, C and F minor have flats in their key signature
+	if minor {
+		return tonic == 2 || tonic == 7 || tonic == 0 || tonic == 5
+	}
+	return tonic == 5
+}
+
+// ToKey transposes song content from one key to another
+func ToKey(content, fromKey, toKey string) (string, error) {
+	semitones, err := Interval(fromKey, toKey)
+	if err != nil {
+		return "", err
+	}
+	return Content(content, semitones, UsesFlats(toKey)), nil
+}
+
+// Content transposes every chord it recognises in song content: lines made only of
+// chords, chord and key labels such as "**Chords:** C - G", and inline "[C]" chords.
+// Lyrics and other text are left untouched.
+func Content(content string, semitones int, useFlats bool) string {
+	if mod12(semitones) == 0 {
+		return content
+	}
+
+	lines := strings.Split(content, "\n")
+	for i, line := range lines {
+		if IsChordLine(line) {
+			lines[i] = transposeChordLine(line, semitones, useFlats)
+			continue
+		}
+		line = transposeLabels(line, semitones, useFlats)
+		lines[i] = transposeBrackets(line, semitones, useFlats)
+	}
+	return strings.Join(lines, "\n")
+}
+
+// IsChordLine reports whether a line contains chords and nothing else
+func IsChordLine(line string) bool {
+	tokens := tokenPattern.FindAllString(line, -1)
+	chords := 0
+	for _, token := range tokens {
+		if separators[token] {
+			continue
+		}
+		if _, ok := ParseChord(trimDecoration(token)); !ok {
+			return false
+		}
+		chords++
+	}
+	return chords > 0
+}
+
+// transposeChordLine transposes a chord-only line, keeping each chord at its
+// original column so that it stays above the same lyric
+func transposeChordLine(line string, semitones int, useFlats bool) string {
+	var out strings.Builder
+	for _, loc := range tokenPattern.FindAllStringIndex(line, -1) {
+		token := transposeToken(line[loc[0]:loc[1]], semitones, useFlats)
+		if out.Len() < loc[0] {
+			out.WriteString(strings.Repeat(" ", loc[0]-out.Len()))
+		} else if out.Len() > 0 {
+			out.WriteString(" ")
+		}
+		out.WriteString(token)
+	}
+	return out.String()
+}
+
+// transposeLabels transposes the values of chord and key labels within a line
+func transposeLabels(line string, semitones int, useFlats bool) string {
+	return labelPattern.ReplaceAllStringFunc(line, func(match string) string {
+		parts := labelPattern.FindStringSubmatch(match)
+		value := parts[2]
+		if !IsChordLine(value) {
+			return match
+		}
+		trimmed := strings.TrimRight(value, " ")
+		return parts[1] + transposeChordLine(trimmed, semitones, useFlats) + value[len(trimmed):]
+	})
+}
+
+// transposeBrackets transposes inline chords written as "[C]", leaving markdown links alone
+func transposeBrackets(line string, semitones int, useFlats bool) string {
+	var out strings.Builder
+	last := 0
+	for _, loc := range bracketPattern.FindAllStringSubmatchIndex(line, -1) {
+		// Skip markdown links such as [text](url)
+		if loc[1] < len(line) && line[loc[1]] == '(' {
+			continue
+		}
+		chord, ok := ParseChord(line[loc[2]:loc[3]])
+		if !ok {
+			continue
+		}
+		out.WriteString(line[last:loc[0]])
+		out.WriteString("[" + chord.Transpose(semitones, useFlats).String() + "]")
+		last = loc[1]
+	}
+	out.WriteString(line[last:])
+	return out.String()
+}
+
+// transposeToken transposes a chord token, keeping surrounding decoration such as "(" or "**"
+func transposeToken(token string, semitones int, useFlats bool) string {
+	if separators[token] {
+		return token
+	}
+	core := trimDecoration(token)
+	chord, ok := ParseChord(core)
+	if !ok {
+		return token
+	}
+	start := strings.Index(token, core)
+	return token[:start] + chord.Transpose(semitones, useFlats).String() + token[start+len(core):]
+}
+
+// trimDecoration strips punctuation and markdown emphasis around a chord token
+func trimDecoration(token string) string {
+	return strings.Trim(token, "()*_,.;:")
+}
+
+// transposeNote moves a note name by the given number of semitones
+func transposeNote(note string, semitones int, useFlats bool) string {
+	index := mod12(noteIndex(note) + semitones)
+	if useFlats {
+		return flatNotes[index]
+	}
+	return sharpNotes[index]
+}
+
+// noteIndex returns the semitone (0-11) of a note name, with C at 0
+func noteIndex(note string) int {
+	base := map[byte]int{'C': 0, 'D': 2, 'E': 4, 'F': 5, 'G': 7, 'A': 9, 'B': 11}[note[0]]
+	switch note[1:] {
+	case "#", "♯":
+		base++
+	case "b", "♭":
+		base--
+	}
+	return mod12(base)
+}
+
+// mod12 wraps a semitone count into the 0-11 range
+func mod12(n int) int {
+	return ((n % 12) + 12) % 12
+}
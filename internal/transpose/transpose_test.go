@@ -0,0 +1,306 @@
+package transpose
+
+import "testing"
+
+func TestParseChord(t *testing.T) {
+	tests := []struct {
+		symbol string
+		want   Chord
+		ok     bool
+	}{
+		{symbol: "C", want: Chord{Root: "C"}, ok: true},
+		{symbol: "F#m7", want: Chord{Root: "F#", Suffix: "m7"}, ok: true},
+		{symbol: "Bbmaj7/D", want: Chord{Root: "Bb", Suffix: "maj7", Bass: "D"}, ok: true},
+		{symbol: "C/G", want: Chord{Root: "C", Bass: "G"}, ok: true},
+		{symbol: "Gsus4", want: Chord{Root: "G", Suffix: "sus4"}, ok: true},
+		{symbol: "Am7b5", want: Chord{Root: "A", Suffix: "m7b5"}, ok: true},
+		{symbol: "E♭", want: Chord{Root: "E♭"}, ok: true},
+		{symbol: "C6/9", want: Chord{Root: "C", Suffix: "6/9"}, ok: true},
+		{symbol: "H7", ok: false},
+		{symbol: "Hello", ok: false},
+		{symbol: "c", ok: false},
+		{symbol: "", ok: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.symbol, func(t *testing.T) {
+			got, ok := ParseChord(tt.symbol)
+			if ok != tt.ok {
+				t.Fatalf("ParseChord(%q) ok = %v, want %v", tt.symbol, ok, tt.ok)
+			}
+			if got != tt.want {
+				t.Errorf("ParseChord(%q) = %+v, want %+v", tt.symbol, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestChordTranspose(t *testing.T) {
+	tests := []struct {
+		chord     string
+		semitones int
+		useFlats  bool
+		want      string
+	}{
+		{chord: "C", semitones: 2, want: "D"},
+		{chord: "C/E", semitones: 2, want: "D/F#"},
+		{chord: "C/E", semitones: 1, useFlats: true, want: "Db/F"},
+		{chord: "C/E", semitones: 1, want: "C#/F"},
+		{chord: "Bbmaj7/D", semitones: 2, want: "Cmaj7/E"},
+		{chord: "F#m", semitones: -1, useFlats: true, want: "Fm"},
+		{chord: "A#", semitones: 0, useFlats: true, want: "Bb"},
+		{chord: "Db", semitones: 0, want: "C#"},
+		{chord: "B7", semitones: 1, want: "C7"},
+		{chord: "C", semitones: -1, want: "B"},
+		{chord: "G♭m", semitones: 12, useFlats: true, want: "Gbm"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.chord, func(t *testing.T) {
+			chord, ok := ParseChord(tt.chord)
+			if !ok {
+				t.Fatalf("ParseChord(%q) failed", tt.chord)
+			}
+			if got := chord.Transpose(tt.semitones, tt.useFlats).String(); got != tt.want {
+				t.Errorf("%s transposed by %d (flats %v) = %q, want %q", tt.chord, tt.semitones, tt.useFlats, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestKey(t *testing.T) {
+	tests := []struct {
+		key       string
+		semitones int
+		want      string
+		wantErr   bool
+	}{
+		{key: "C", semitones: 2, want: "D"},
+		{key: "A#", semitones: 0, want: "Bb"},
+		{key: "Gb", semitones: 0, want: "F#"},
+		{key: "Bb minor", semitones: 2, want: "Cm"},
+		{key: "C#m", semitones: 0, want: "C#m"},
+		{key: "Dbm", semitones: 0, want: "C#m"},
+		{key: "A menor", semitones: 3, want: "Cm"},
+		{key: "E major", semitones: -4, want: "C"},
+		{key: " G ", semitones: 5, want: "C"},
+		{key: "H", wantErr: true},
+		{key: "", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.key, func(t *testing.T) {
+			got, err := Key(tt.key, tt.semitones)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Key(%q, %d) error = %v, want error %v", tt.key, tt.semitones, err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("Key(%q, %d) = %q, want %q", tt.key, tt.semitones, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestInterval(t *testing.T) {
+	tests := []struct {
+		from, to string
+		want     int
+		wantErr  bool
+	}{
+		{from: "C", to: "G", want: 7},
+		{from: "G", to: "C", want: 5},
+		{from: "Am", to: "C", want: 3},
+		{from: "F#", to: "Gb", want: 0},
+		{from: "Bb", to: "A", want: 11},
+		{from: "C", to: "X", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.from+"->"+tt.to, func(t *testing.T) {
+			got, err := Interval(tt.from, tt.to)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Interval(%q, %q) error = %v, want error %v", tt.from, tt.to, err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("Interval(%q, %q) = %d, want %d", tt.from, tt.to, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestUsesFlats(t *testing.T) {
+	tests := []struct {
+		key  string
+		want bool
+	}{
+		{key: "C", want: false},
+		{key: "G", want: false},
+		{key: "F", want: true},
+		{key: "Bb", want: true},
+		{key: "A#", want: true},
+		{key: "F#", want: false},
+		{key: "Dm", want: true},
+		{key: "Gm", want: true},
+		{key: "Em", want: false},
+		{key: "F#m", want: false},
+		{key: "Ebm", want: true},
+		{key: "", want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.key, func(t *testing.T) {
+			if got := UsesFlats(tt.key); got != tt.want {
+				t.Errorf("UsesFlats(%q) = %v, want %v", tt.key, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestIsChordLine(t *testing.T) {
+	tests := []struct {
+		name string
+		line string
+		want bool
+	}{
+		{name: "chords", line: "C       G       Am      F", want: true},
+		{name: "slash chords", line: "C/E  Dm7/C  G/B", want: true},
+		{name: "bars", line: "| C | G | Am | F |", want: true},
+		{name: "repeats", line: "|: Am F C G :| x2", want: true},
+		{name: "decorated", line: "(C) **G**", want: true},
+		{name: "no chord", line: "N.C.", want: false},
+		{name: "lyrics", line: "Hello darkness my old friend", want: false},
+		{name: "lyrics starting with a chord name", line: "A day in the life", want: false},
+		{name: "chords and words", line: "C G Am then F", want: false},
+		{name: "empty", line: "", want: false},
+		{name: "blank", line: "    ", want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := IsChordLine(tt.line); got != tt.want {
+				t.Errorf("IsChordLine(%q) = %v, want %v", tt.line, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestContent(t *testing.T) {
+	tests := []struct {
+		name      string
+		content   string
+		semitones int
+		useFlats  bool
+		want      string
+	}{
+		{
+			name:      "chord line above lyrics keeps its columns",
+			content:   "C       G\nHello   world",
+			semitones: 2,
+			want:      "D       A\nHello   world",
+		},
+		{
+			name:      "longer chord pushes the next one along",
+			content:   "C G",
+			semitones: 1,
+			want:      "C# G#",
+		},
+		{
+			name:      "slash chords",
+			content:   "C/E  F  G/B",
+			semitones: 2,
+			want:      "D/F# G  A/C#",
+		},
+		{
+			name:      "flats",
+			content:   "C  F  G",
+			semitones: 3,
+			useFlats:  true,
+			want:      "Eb Ab Bb",
+		},
+		{
+			name:      "sharps",
+			content:   "Bb Eb F",
+			semitones: 1,
+			want:      "B  E  F#",
+		},
+		{
+			name:      "bracket chords",
+			content:   "[C]Hello [G/B]darkness my [Am]old friend",
+			semitones: 2,
+			want:      "[D]Hello [A/C#]darkness my [Bm]old friend",
+		},
+		{
+			name:      "markdown links are not chords",
+			content:   "See [C](https://example.com) and [Am]",
+			semitones: 2,
+			want:      "See [C](https://example.com) and [Bm]",
+		},
+		{
+			name:      "bracketed words are not chords",
+			content:   "[Chorus] sing [x2]",
+			semitones: 2,
+			want:      "[Chorus] sing [x2]",
+		},
+		{
+			name:      "chord and key labels",
+			content:   "**Chords:** C - Am - F - G\n**Key:** C\nChords: Em D",
+			semitones: 2,
+			want:      "**Chords:** D - Bm - G - A\n**Key:** D\nChords: F#m E",
+		},
+		{
+			name:      "labels that aren't chords",
+			content:   "**Key:** depends on the singer",
+			semitones: 2,
+			want:      "**Key:** depends on the singer",
+		},
+		{
+			name:      "lyrics are left alone",
+			content:   "A day in the life\nC G Am then F",
+			semitones: 2,
+			want:      "A day in the life\nC G Am then F",
+		},
+		{
+			name:      "a full octave changes nothing",
+			content:   "C#  G\n[A#]",
+			semitones: 12,
+			useFlats:  true,
+			want:      "C#  G\n[A#]",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Content(tt.content, tt.semitones, tt.useFlats); got != tt.want {
+				t.Errorf("Content(%q, %d, %v) =\n%q\nwant\n%q", tt.content, tt.semitones, tt.useFlats, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestToKey(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		from    string
+		to      string
+		want    string
+		wantErr bool
+	}{
+		{name: "to a flat key", content: "C G Am F", from: "C", to: "Bb", want: "Bb F Gm Eb"},
+		{name: "to a sharp key", content: "C G Am F", from: "C", to: "E", want: "E B C#m A"},
+		{name: "minor key with flats", content: "Am Dm E7", from: "Am", to: "Dm", want: "Dm Gm A7"},
+		{name: "invalid key", content: "C", from: "C", to: "Q", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ToKey(tt.content, tt.from, tt.to)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ToKey() error = %v, want error %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("ToKey(%q, %q, %q) = %q, want %q", tt.content, tt.from, tt.to, got, tt.want)
+			}
+		})
+	}
+}
@@ -26,9 +26,14 @@ func main() {
 	authStore := store.NewSQLiteAuthStore(db.GetDB())
 	bandsStore := store.NewSQLiteBandsStore(db.GetDB())
 	songsStore := store.NewSQLiteSongsStore(db.GetDB())
+	setlistsStore := store.NewSQLiteSetlistsStore(db.GetDB())
+	gigsStore := store.NewSQLiteGigsStore(db.GetDB())
+	jobsStore := store.NewSQLiteJobsStore(db.GetDB())
+	promptsStore := store.NewSQLitePromptsStore(db.GetDB())
+	aiUsageStore := store.NewSQLiteAIUsageStore(db.GetDB())
 
 	// Create application with all dependencies - always use authentication
-	application := app.NewApplication(db, authStore, bandsStore, songsStore)
+	application := app.NewApplication(db, authStore, bandsStore, songsStore, setlistsStore, gigsStore, jobsStore, promptsStore, aiUsageStore)
 
 	// Start server
 	log.Fatal(application.Start("9090"))
@@ -0,0 +1,36 @@
+-- +goose Up
+CREATE TABLE setlists (
+    id TEXT PRIMARY KEY,
+    band_id TEXT NOT NULL,
+    name TEXT NOT NULL,
+    description TEXT,
+    created_by TEXT NOT NULL,
+    created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+    updated_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+    is_active INTEGER DEFAULT 1,
+    FOREIGN KEY (band_id) REFERENCES bands(id) ON DELETE CASCADE,
+    FOREIGN KEY (created_by) REFERENCES users(id) ON DELETE CASCADE
+);
+
+CREATE TABLE setlist_songs (
+    id TEXT PRIMARY KEY,
+    setlist_id TEXT NOT NULL,
+    song_id TEXT NOT NULL,
+    position INTEGER DEFAULT 0,
+    created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+    FOREIGN KEY (setlist_id) REFERENCES setlists(id) ON DELETE CASCADE,
+    FOREIGN KEY (song_id) REFERENCES songs(id) ON DELETE CASCADE,
+    UNIQUE(setlist_id, song_id)
+);
+
+-- Indexes for better performance
+CREATE INDEX idx_setlists_band_id ON setlists(band_id, is_active);
+CREATE INDEX idx_setlist_songs_position ON setlist_songs(setlist_id, position);
+CREATE INDEX idx_setlist_songs_song_id ON setlist_songs(song_id);
+
+-- +goose Down
+DROP INDEX IF EXISTS idx_setlist_songs_song_id;
+DROP INDEX IF EXISTS idx_setlist_songs_position;
+DROP INDEX IF EXISTS idx_setlists_band_id;
+DROP TABLE IF EXISTS setlist_songs;
+DROP TABLE IF EXISTS setlists;
@@ -0,0 +1,43 @@
+-- +goose Up
+CREATE TABLE gigs (
+    id TEXT PRIMARY KEY,
+    band_id TEXT NOT NULL,
+    name TEXT NOT NULL,
+    venue TEXT,
+    gig_date TEXT NOT NULL,
+    load_in_time TEXT,
+    start_time TEXT NOT NULL,
+    curfew_time TEXT,
+    notes TEXT,
+    created_by TEXT NOT NULL,
+    created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+    updated_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+    is_active INTEGER DEFAULT 1,
+    FOREIGN KEY (band_id) REFERENCES bands(id) ON DELETE CASCADE,
+    FOREIGN KEY (created_by) REFERENCES users(id) ON DELETE CASCADE
+);
+
+CREATE TABLE gig_sets (
+    id TEXT PRIMARY KEY,
+    gig_id TEXT NOT NULL,
+    setlist_id TEXT NOT NULL,
+    name TEXT NOT NULL,
+    position INTEGER DEFAULT 0,
+    break_minutes INTEGER NOT NULL DEFAULT 0,
+    is_encore INTEGER NOT NULL DEFAULT 0,
+    created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+    FOREIGN KEY (gig_id) REFERENCES gigs(id) ON DELETE CASCADE,
+    FOREIGN KEY (setlist_id) REFERENCES setlists(id) ON DELETE CASCADE
+);
+
+-- Indexes for better performance
+CREATE INDEX idx_gigs_band_id ON gigs(band_id, is_active);
+CREATE INDEX idx_gigs_gig_date ON gigs(gig_date);
+CREATE INDEX idx_gig_sets_position ON gig_sets(gig_id, position);
+
+-- +goose Down
+DROP INDEX IF EXISTS idx_gig_sets_position;
+DROP INDEX IF EXISTS idx_gigs_gig_date;
+DROP INDEX IF EXISTS idx_gigs_band_id;
+DROP TABLE IF EXISTS gig_sets;
+DROP TABLE IF EXISTS gigs;
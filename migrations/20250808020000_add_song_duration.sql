@@ -0,0 +1,5 @@
+-- +goose Up
+ALTER TABLE songs ADD COLUMN duration_seconds INTEGER;
+
+-- +goose Down
+ALTER TABLE songs DROP COLUMN duration_seconds;
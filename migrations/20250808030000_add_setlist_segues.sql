@@ -0,0 +1,5 @@
+-- +goose Up
+ALTER TABLE setlist_songs ADD COLUMN segue BOOLEAN DEFAULT 0;
+
+-- +goose Down
+ALTER TABLE setlist_songs DROP COLUMN segue;
@@ -0,0 +1,17 @@
+-- +goose Up
+CREATE TABLE song_sections (
+    id TEXT PRIMARY KEY,
+    song_id TEXT NOT NULL,
+    name TEXT NOT NULL,
+    key TEXT,
+    body TEXT NOT NULL,
+    position INTEGER DEFAULT 0,
+    created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+    FOREIGN KEY (song_id) REFERENCES songs(id) ON DELETE CASCADE
+);
+
+CREATE INDEX idx_song_sections_position ON song_sections(song_id, position);
+
+-- +goose Down
+DROP INDEX IF EXISTS idx_song_sections_position;
+DROP TABLE IF EXISTS song_sections;
@@ -0,0 +1,32 @@
+-- +goose Up
+CREATE TABLE jobs (
+    id TEXT PRIMARY KEY,
+    band_id TEXT NOT NULL,
+    kind TEXT NOT NULL,
+    title TEXT NOT NULL,
+    payload TEXT NOT NULL,
+    status TEXT NOT NULL DEFAULT 'queued',
+    attempts INTEGER NOT NULL DEFAULT 0,
+    max_attempts INTEGER NOT NULL DEFAULT 3,
+    run_at DATETIME NOT NULL,
+    last_error TEXT,
+    result BLOB,
+    result_type TEXT,
+    result_name TEXT,
+    created_by TEXT NOT NULL,
+    created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+    updated_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+    started_at DATETIME,
+    finished_at DATETIME,
+    FOREIGN KEY (band_id) REFERENCES bands(id) ON DELETE CASCADE,
+    FOREIGN KEY (created_by) REFERENCES users(id) ON DELETE CASCADE
+);
+
+-- Workers pick the next queued job that is due
+CREATE INDEX idx_jobs_queue ON jobs(status, run_at);
+CREATE INDEX idx_jobs_band_id ON jobs(band_id, created_at);
+
+-- +goose Down
+DROP INDEX IF EXISTS idx_jobs_band_id;
+DROP INDEX IF EXISTS idx_jobs_queue;
+DROP TABLE IF EXISTS jobs;
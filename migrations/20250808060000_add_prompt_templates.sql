@@ -0,0 +1,49 @@
+-- +goose Up
+CREATE TABLE prompt_templates (
+    id TEXT PRIMARY KEY,
+    band_id TEXT NOT NULL,
+    name TEXT NOT NULL,
+    role TEXT NOT NULL,
+    is_default BOOLEAN DEFAULT 0,
+    created_by TEXT NOT NULL,
+    created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+    updated_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+    FOREIGN KEY (band_id) REFERENCES bands(id) ON DELETE CASCADE,
+    FOREIGN KEY (created_by) REFERENCES users(id) ON DELETE CASCADE
+);
+
+-- Every edit of a template's body is kept as a new version
+CREATE TABLE prompt_template_versions (
+    id TEXT PRIMARY KEY,
+    template_id TEXT NOT NULL,
+    version INTEGER NOT NULL,
+    body TEXT NOT NULL,
+    created_by TEXT NOT NULL,
+    created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+    FOREIGN KEY (template_id) REFERENCES prompt_templates(id) ON DELETE CASCADE,
+    FOREIGN KEY (created_by) REFERENCES users(id) ON DELETE CASCADE,
+    UNIQUE(template_id, version)
+);
+
+-- Instrument-specific content generated for a song, next to its main content
+CREATE TABLE song_variants (
+    id TEXT PRIMARY KEY,
+    song_id TEXT NOT NULL,
+    role TEXT NOT NULL,
+    content TEXT NOT NULL,
+    template_id TEXT,
+    template_version INTEGER,
+    created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+    updated_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+    FOREIGN KEY (song_id) REFERENCES songs(id) ON DELETE CASCADE,
+    FOREIGN KEY (template_id) REFERENCES prompt_templates(id) ON DELETE SET NULL,
+    UNIQUE(song_id, role)
+);
+
+CREATE INDEX idx_prompt_templates_band_id ON prompt_templates(band_id, role);
+
+-- +goose Down
+DROP INDEX IF EXISTS idx_prompt_templates_band_id;
+DROP TABLE IF EXISTS song_variants;
+DROP TABLE IF EXISTS prompt_template_versions;
+DROP TABLE IF EXISTS prompt_templates;
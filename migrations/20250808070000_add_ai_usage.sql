@@ -0,0 +1,38 @@
+-- +goose Up
+-- Replies from the AI provider, reused when the same prompt is sent again
+CREATE TABLE ai_cache (
+    cache_key TEXT PRIMARY KEY,
+    provider TEXT NOT NULL,
+    model TEXT NOT NULL,
+    content TEXT NOT NULL,
+    input_tokens INTEGER NOT NULL DEFAULT 0,
+    output_tokens INTEGER NOT NULL DEFAULT 0,
+    created_at DATETIME DEFAULT CURRENT_TIMESTAMP
+);
+
+-- Every AI request made for a band, with the tokens it used
+CREATE TABLE ai_usage (
+    id TEXT PRIMARY KEY,
+    band_id TEXT NOT NULL,
+    user_id TEXT NOT NULL,
+    song_id TEXT,
+    role TEXT NOT NULL DEFAULT 'main',
+    provider TEXT NOT NULL,
+    model TEXT NOT NULL,
+    input_tokens INTEGER NOT NULL DEFAULT 0,
+    output_tokens INTEGER NOT NULL DEFAULT 0,
+    cached BOOLEAN DEFAULT 0,
+    created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+    FOREIGN KEY (band_id) REFERENCES bands(id) ON DELETE CASCADE,
+    FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE,
+    FOREIGN KEY (song_id) REFERENCES songs(id) ON DELETE SET NULL
+);
+
+CREATE INDEX idx_ai_usage_band_id ON ai_usage(band_id, created_at);
+CREATE INDEX idx_ai_usage_user_id ON ai_usage(user_id, created_at);
+
+-- +goose Down
+DROP INDEX IF EXISTS idx_ai_usage_user_id;
+DROP INDEX IF EXISTS idx_ai_usage_band_id;
+DROP TABLE IF EXISTS ai_usage;
+DROP TABLE IF EXISTS ai_cache;
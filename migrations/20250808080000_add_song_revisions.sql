@@ -0,0 +1,28 @@
+-- +goose Up
+-- Every saved state of a song's metadata and content, with who saved it and how
+CREATE TABLE song_revisions (
+    id TEXT PRIMARY KEY,
+    song_id TEXT NOT NULL,
+    revision INTEGER NOT NULL,
+    title TEXT NOT NULL,
+    artist TEXT,
+    key TEXT,
+    tempo INTEGER,
+    duration_seconds INTEGER,
+    notes TEXT,
+    content TEXT,
+    source TEXT NOT NULL DEFAULT 'manual',
+    created_by TEXT NOT NULL,
+    created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+    FOREIGN KEY (song_id) REFERENCES songs(id) ON DELETE CASCADE,
+    FOREIGN KEY (created_by) REFERENCES users(id) ON DELETE CASCADE,
+    UNIQUE(song_id, revision)
+);
+
+-- Existing songs start their history with their current state
+INSERT INTO song_revisions (id, song_id, revision, title, artist, key, tempo, duration_seconds, notes, content, source, created_by, created_at)
+SELECT id || '-1', id, 1, title, artist, key, tempo, duration_seconds, notes, content, 'manual', created_by, updated_at
+FROM songs;
+
+-- +goose Down
+DROP TABLE IF EXISTS song_revisions;
@@ -0,0 +1,22 @@
+-- +goose Up
+-- When and by whom songs and bands were moved to the trash, so they can be
+-- listed, restored and purged after the retention period
+ALTER TABLE songs ADD COLUMN deleted_at DATETIME;
+ALTER TABLE songs ADD COLUMN deleted_by TEXT;
+ALTER TABLE bands ADD COLUMN deleted_at TIMESTAMP;
+ALTER TABLE bands ADD COLUMN deleted_by TEXT;
+
+-- Items deleted before the trash existed count from their last update
+UPDATE songs SET deleted_at = updated_at WHERE is_active = 0;
+UPDATE bands SET deleted_at = updated_at WHERE is_active = 0;
+
+CREATE INDEX idx_songs_deleted_at ON songs(deleted_at);
+CREATE INDEX idx_bands_deleted_at ON bands(deleted_at);
+
+-- +goose Down
+DROP INDEX IF EXISTS idx_bands_deleted_at;
+DROP INDEX IF EXISTS idx_songs_deleted_at;
+ALTER TABLE bands DROP COLUMN deleted_by;
+ALTER TABLE bands DROP COLUMN deleted_at;
+ALTER TABLE songs DROP COLUMN deleted_by;
+ALTER TABLE songs DROP COLUMN deleted_at;
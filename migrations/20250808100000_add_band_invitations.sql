@@ -0,0 +1,56 @@
+-- +goose Up
+-- Invitations to join a band, addressed to an email that may not have an
+-- account yet. The link in the invitation email carries a token; only its
+-- hash is stored.
+CREATE TABLE band_invitations (
+    id TEXT PRIMARY KEY,
+    band_id TEXT NOT NULL,
+    invited_email TEXT NOT NULL,
+    invited_by TEXT NOT NULL,
+    role TEXT NOT NULL DEFAULT 'member',
+    token_hash TEXT UNIQUE NOT NULL,
+    status TEXT NOT NULL DEFAULT 'pending',
+    expires_at TIMESTAMP NOT NULL,
+    sent_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    accepted_at TIMESTAMP,
+    declined_at TIMESTAMP,
+    revoked_at TIMESTAMP,
+    FOREIGN KEY (band_id) REFERENCES bands(id) ON DELETE CASCADE,
+    FOREIGN KEY (invited_by) REFERENCES users(id) ON DELETE CASCADE
+);
+
+CREATE INDEX idx_band_invitations_band_id ON band_invitations(band_id, status);
+CREATE INDEX idx_band_invitations_email ON band_invitations(invited_email, status);
+CREATE INDEX idx_band_invitations_expires_at ON band_invitations(status, expires_at);
+
+-- The invitations table was never used
+DROP INDEX IF EXISTS idx_invitations_status;
+DROP INDEX IF EXISTS idx_invitations_token;
+DROP INDEX IF EXISTS idx_invitations_email;
+DROP INDEX IF EXISTS idx_invitations_band_id;
+DROP TABLE IF EXISTS invitations;
+
+-- +goose Down
+CREATE TABLE invitations (
+    id TEXT PRIMARY KEY,
+    band_id TEXT NOT NULL,
+    email TEXT NOT NULL,
+    token TEXT UNIQUE NOT NULL,
+    status TEXT NOT NULL DEFAULT 'pending',
+    expires_at TIMESTAMP NOT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    accepted_at TIMESTAMP,
+    declined_at TIMESTAMP,
+    FOREIGN KEY (band_id) REFERENCES bands(id) ON DELETE CASCADE
+);
+
+CREATE INDEX idx_invitations_band_id ON invitations(band_id);
+CREATE INDEX idx_invitations_email ON invitations(email);
+CREATE INDEX idx_invitations_token ON invitations(token);
+CREATE INDEX idx_invitations_status ON invitations(status);
+
+DROP INDEX IF EXISTS idx_band_invitations_expires_at;
+DROP INDEX IF EXISTS idx_band_invitations_email;
+DROP INDEX IF EXISTS idx_band_invitations_band_id;
+DROP TABLE IF EXISTS band_invitations;
@@ -0,0 +1,9 @@
+-- +goose Up
+-- Plain members become editors, who can change songs but not the band or its
+-- members; viewers can only read
+UPDATE band_members SET role = 'editor' WHERE role = 'member';
+UPDATE band_invitations SET role = 'editor' WHERE role = 'member';
+
+-- +goose Down
+UPDATE band_invitations SET role = 'member' WHERE role IN ('editor', 'viewer');
+UPDATE band_members SET role = 'member' WHERE role IN ('editor', 'viewer');
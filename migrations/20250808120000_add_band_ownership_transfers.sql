@@ -0,0 +1,21 @@
+-- +goose Up
+-- Requests from a band's owner to hand the band to another member, who has to
+-- accept before the roles change
+CREATE TABLE band_ownership_transfers (
+    id TEXT PRIMARY KEY,
+    band_id TEXT NOT NULL,
+    from_user_id TEXT NOT NULL,
+    to_user_id TEXT NOT NULL,
+    status TEXT NOT NULL DEFAULT 'pending',
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    answered_at TIMESTAMP,
+    FOREIGN KEY (band_id) REFERENCES bands(id) ON DELETE CASCADE,
+    FOREIGN KEY (from_user_id) REFERENCES users(id) ON DELETE CASCADE,
+    FOREIGN KEY (to_user_id) REFERENCES users(id) ON DELETE CASCADE
+);
+
+CREATE INDEX idx_band_ownership_transfers_band_id ON band_ownership_transfers(band_id, status);
+
+-- +goose Down
+DROP INDEX IF EXISTS idx_band_ownership_transfers_band_id;
+DROP TABLE IF EXISTS band_ownership_transfers;
@@ -0,0 +1,23 @@
+-- +goose Up
+-- Personal access tokens that let scripts call the JSON API on behalf of a
+-- user. Only a hash of each token is stored, like sessions.
+CREATE TABLE api_tokens (
+    id TEXT PRIMARY KEY,
+    user_id TEXT NOT NULL,
+    name TEXT NOT NULL,
+    token_hash TEXT UNIQUE NOT NULL,
+    scopes TEXT NOT NULL,
+    expires_at TIMESTAMP NOT NULL,
+    last_used_at TIMESTAMP,
+    revoked_at TIMESTAMP,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE
+);
+
+CREATE INDEX idx_api_tokens_token_hash ON api_tokens(token_hash);
+CREATE INDEX idx_api_tokens_user_id ON api_tokens(user_id);
+
+-- +goose Down
+DROP INDEX IF EXISTS idx_api_tokens_user_id;
+DROP INDEX IF EXISTS idx_api_tokens_token_hash;
+DROP TABLE IF EXISTS api_tokens;
@@ -0,0 +1,221 @@
+// Code generated by templ - DO NOT EDIT.
+
+// templ: version: v0.3.943
+package templates
+
+//lint:file-ignore SA4006 This context is only used if a nested component is present.
+
+import "github.com/a-h/templ"
+import templruntime "github.com/a-h/templ/runtime"
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/nahue/setlist_manager/internal/app/shared/types"
+	"github.com/nahue/setlist_manager/internal/store"
+)
+
+// jobsListData builds the Alpine state that polls a band's jobs while any is unfinished
+func jobsListData(bandID string, jobs []*store.Job) string {
+	if jobs == nil {
+		jobs = []*store.Job{}
+	}
+	jobsJSON, _ := json.Marshal(jobs)
+	bandIDJSON, _ := json.Marshal(bandID)
+
+	return fmt.Sprintf(`{
+		bandID: %s,
+		jobs: %s,
+		details: {},
+		timer: null,
+		init() {
+			this.schedule();
+		},
+		destroy() {
+			clearTimeout(this.timer);
+		},
+		get pending() {
+			return this.jobs.some(job => job.status === 'queued' || job.status === 'running');
+		},
+		schedule() {
+			clearTimeout(this.timer);
+			if (this.pending) {
+				this.timer = setTimeout(() => this.refresh(), 3000);
+			}
+		},
+		refresh() {
+			fetch('/api/bands/jobs?band_id=' + encodeURIComponent(this.bandID))
+				.then(response => response.json())
+				.then(data => {
+					this.jobs = data.jobs || [];
+					this.schedule();
+				})
+				.catch(error => {
+					console.error('Error loading jobs:', error);
+					this.schedule();
+				});
+		},
+		cancel(job) {
+			fetch('/api/jobs/' + job.id + '/cancel', { method: 'POST' })
+				.then(response => {
+					if (!response.ok) {
+						throw new Error('cancel failed');
+					}
+					this.refresh();
+				})
+				.catch(error => {
+					console.error('Error cancelling job:', error);
+					alert('No se pudo cancelar el trabajo');
+				});
+		},
+		toggleDetails(job) {
+			if (this.details[job.id]) {
+				delete this.details[job.id];
+				return;
+			}
+			fetch('/api/jobs/' + job.id + '/download')
+				.then(response => response.json())
+				.then(result => {
+					this.details[job.id] = result;
+				})
+				.catch(error => {
+					console.error('Error loading job result:', error);
+				});
+		},
+		songStatusLabel(song) {
+			return {
+				generated: 'Generada',
+				edited: 'Omitida: editada a mano',
+				changed: 'Omitida: se editó durante la generación',
+				not_found: 'Omitida: no encontrada',
+				failed: 'Falló',
+			}[song.status] || song.status;
+		},
+		statusLabel(job) {
+			return {
+				queued: job.attempts > 0 ? 'Reintentando' : 'En cola',
+				running: 'En curso',
+				succeeded: 'Completado',
+				failed: 'Falló',
+				cancelled: 'Cancelado',
+			}[job.status] || job.status;
+		},
+		statusClass(job) {
+			return {
+				queued: 'bg-gray-100 text-gray-700 dark:bg-gray-700 dark:text-gray-200',
+				running: 'bg-blue-100 text-blue-800 dark:bg-blue-900 dark:text-blue-200',
+				succeeded: 'bg-green-100 text-green-800 dark:bg-green-900 dark:text-green-200',
+				failed: 'bg-red-100 text-red-800 dark:bg-red-900 dark:text-red-200',
+				cancelled: 'bg-yellow-100 text-yellow-800 dark:bg-yellow-900 dark:text-yellow-200',
+			}[job.status] || '';
+		},
+		formatDate(value) {
+			return value ? new Date(value).toLocaleString() : '';
+		},
+	}`, bandIDJSON, jobsJSON)
+}
+
+func JobsPage(band *types.Band, user *types.User, jobs []*store.Job) templ.Component {
+	return templruntime.GeneratedTemplate(func(templ_7745c5c3_Input templruntime.GeneratedComponentInput) (templ_7745c5c3_Err error) {
+		templ_7745c5c3_W, ctx := templ_7745c5c3_Input.Writer, templ_7745c5c3_Input.Context
+		if templ_7745c5c3_CtxErr := ctx.Err(); templ_7745c5c3_CtxErr != nil {
+			return templ_7745c5c3_CtxErr
+		}
+		templ_7745c5c3_Buffer, templ_7745c5c3_IsBuffer := templruntime.GetBuffer(templ_7745c5c3_W)
+		if !templ_7745c5c3_IsBuffer {
+			defer func() {
+				templ_7745c5c3_BufErr := templruntime.ReleaseBuffer(templ_7745c5c3_Buffer)
+				if templ_7745c5c3_Err == nil {
+					templ_7745c5c3_Err = templ_7745c5c3_BufErr
+				}
+			}()
+		}
+		ctx = templ.InitializeContext(ctx)
+		templ_7745c5c3_Var1 := templ.GetChildren(ctx)
+		if templ_7745c5c3_Var1 == nil {
+			templ_7745c5c3_Var1 = templ.NopComponent
+		}
+		ctx = templ.ClearChildren(ctx)
+		templ_7745c5c3_Err = BaseLayout(PageData{
+			Title:       band.Name + " - Trabajos",
+			Description: "Trabajos en segundo plano de la banda",
+			Content:     JobsContent(band, jobs),
+			User:        user,
+		}).Render(ctx, templ_7745c5c3_Buffer)
+		if templ_7745c5c3_Err != nil {
+			return templ_7745c5c3_Err
+		}
+		return nil
+	})
+}
+
+func JobsContent(band *types.Band, jobs []*store.Job) templ.Component {
+	return templruntime.GeneratedTemplate(func(templ_7745c5c3_Input templruntime.GeneratedComponentInput) (templ_7745c5c3_Err error) {
+		templ_7745c5c3_W, ctx := templ_7745c5c3_Input.Writer, templ_7745c5c3_Input.Context
+		if templ_7745c5c3_CtxErr := ctx.Err(); templ_7745c5c3_CtxErr != nil {
+			return templ_7745c5c3_CtxErr
+		}
+		templ_7745c5c3_Buffer, templ_7745c5c3_IsBuffer := templruntime.GetBuffer(templ_7745c5c3_W)
+		if !templ_7745c5c3_IsBuffer {
+			defer func() {
+				templ_7745c5c3_BufErr := templruntime.ReleaseBuffer(templ_7745c5c3_Buffer)
+				if templ_7745c5c3_Err == nil {
+					templ_7745c5c3_Err = templ_7745c5c3_BufErr
+				}
+			}()
+		}
+		ctx = templ.InitializeContext(ctx)
+		templ_7745c5c3_Var2 := templ.GetChildren(ctx)
+		if templ_7745c5c3_Var2 == nil {
+			templ_7745c5c3_Var2 = templ.NopComponent
+		}
+		ctx = templ.ClearChildren(ctx)
+		templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 1, "<div class=\"max-w-5xl mx-auto\" x-data=\"")
+		if templ_7745c5c3_Err != nil {
+			return templ_7745c5c3_Err
+		}
+		var templ_7745c5c3_Var3 string
+		templ_7745c5c3_Var3, templ_7745c5c3_Err = templ.JoinStringErrs(jobsListData(band.ID, jobs))
+		if templ_7745c5c3_Err != nil {
+			return templ.Error{Err: templ_7745c5c3_Err, FileName: `templates/jobs.templ`, Line: 121, Col: 68}
+		}
+		_, templ_7745c5c3_Err = templ_7745c5c3_Buffer.WriteString(templ.EscapeString(templ_7745c5c3_Var3))
+		if templ_7745c5c3_Err != nil {
+			return templ_7745c5c3_Err
+		}
+		templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 2, "\"><!-- Header --><div class=\"mb-8\"><div class=\"flex items-center space-x-3\"><a href=\"")
+		if templ_7745c5c3_Err != nil {
+			return templ_7745c5c3_Err
+		}
+		var templ_7745c5c3_Var4 templ.SafeURL
+		templ_7745c5c3_Var4, templ_7745c5c3_Err = templ.JoinURLErrs("/band?id=" + band.ID)
+		if templ_7745c5c3_Err != nil {
+			return templ.Error{Err: templ_7745c5c3_Err, FileName: `templates/jobs.templ`, Line: 125, Col: 35}
+		}
+		_, templ_7745c5c3_Err = templ_7745c5c3_Buffer.WriteString(templ.EscapeString(templ_7745c5c3_Var4))
+		if templ_7745c5c3_Err != nil {
+			return templ_7745c5c3_Err
+		}
+		templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 3, "\" class=\"text-indigo-600 hover:text-indigo-500 dark:text-indigo-400 dark:hover:text-indigo-300\"><svg class=\"h-5 w-5\" fill=\"none\" viewBox=\"0 0 24 24\" stroke=\"currentColor\"><path stroke-linecap=\"round\" stroke-linejoin=\"round\" stroke-width=\"2\" d=\"M10 19l-7-7m0 0l7-7m-7 7h18\"></path></svg></a><h1 class=\"text-3xl font-bold text-gray-900 dark:text-white\">Trabajos</h1></div><p class=\"mt-2 text-gray-600 dark:text-gray-400\">Generación con IA y PDFs de ")
+		if templ_7745c5c3_Err != nil {
+			return templ_7745c5c3_Err
+		}
+		var templ_7745c5c3_Var5 string
+		templ_7745c5c3_Var5, templ_7745c5c3_Err = templ.JoinStringErrs(band.Name)
+		if templ_7745c5c3_Err != nil {
+			return templ.Error{Err: templ_7745c5c3_Err, FileName: `templates/jobs.templ`, Line: 133, Col: 44}
+		}
+		_, templ_7745c5c3_Err = templ_7745c5c3_Buffer.WriteString(templ.EscapeString(templ_7745c5c3_Var5))
+		if templ_7745c5c3_Err != nil {
+			return templ_7745c5c3_Err
+		}
+		templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 4, " que corren en segundo plano. Puedes salir de esta página y volver más tarde.</p></div><div class=\"bg-white dark:bg-gray-800 shadow rounded-lg dark:shadow-none\"><div class=\"px-6 py-4 border-b border-gray-200 dark:border-gray-700 flex items-center justify-between\"><h2 class=\"text-lg font-medium text-gray-900 dark:text-white\">Trabajos recientes</h2><span x-show=\"pending\" class=\"text-xs text-gray-500 dark:text-gray-400\">Actualizando…</span></div><div class=\"p-6\"><template x-if=\"jobs.length === 0\"><div class=\"text-center py-8\"><p class=\"text-sm text-gray-500 dark:text-gray-400\">Aún no hay trabajos</p><p class=\"text-xs text-gray-400 dark:text-gray-500\">Usa las opciones \"en segundo plano\" de canciones, setlists y gigs para encolar uno</p></div></template><ul class=\"divide-y divide-gray-200 dark:divide-gray-700\"><template x-for=\"job in jobs\" :key=\"job.id\"><li class=\"py-4 flex items-start justify-between gap-4\"><div class=\"min-w-0\"><div class=\"flex items-center gap-2\"><span class=\"inline-flex items-center px-2 py-0.5 rounded text-xs font-medium\" :class=\"statusClass(job)\" x-text=\"statusLabel(job)\"></span><p class=\"text-sm font-medium text-gray-900 dark:text-white truncate\" x-text=\"job.title\"></p></div><p class=\"mt-1 text-xs text-gray-500 dark:text-gray-400\">Creado <span x-text=\"formatDate(job.created_at)\"></span><template x-if=\"job.finished_at\"><span>· Terminado <span x-text=\"formatDate(job.finished_at)\"></span></span></template><template x-if=\"job.attempts > 1 || (job.status === 'queued' && job.attempts > 0)\"><span>· Intento <span x-text=\"job.attempts\"></span> de <span x-text=\"job.max_attempts\"></span></span></template></p><template x-if=\"job.last_error && job.status !== 'succeeded'\"><p class=\"mt-1 text-xs text-red-600 dark:text-red-400\" x-text=\"job.last_error\"></p></template><template x-if=\"details[job.id]\"><div class=\"mt-2 text-xs text-gray-600 dark:text-gray-400\"><p><span x-text=\"details[job.id].generated\"></span> generadas · <span x-text=\"details[job.id].skipped\"></span> omitidas · <span x-text=\"details[job.id].failed\"></span> con error</p><ul class=\"mt-1 space-y-0.5\"><template x-for=\"song in details[job.id].songs\" :key=\"song.song_id\"><li><a :href=\"'/song?id=' + song.song_id\" class=\"text-indigo-600 dark:text-indigo-400 hover:underline\" x-text=\"song.title || song.song_id\"></a>: <span :class=\"song.status === 'failed' ? 'text-red-600 dark:text-red-400' : ''\" x-text=\"songStatusLabel(song)\"></span><template x-if=\"song.error\"><span class=\"text-red-600 dark:text-red-400\" x-text=\"'(' + song.error + ')'\"></span></template></li></template></ul></div></template></div><div class=\"flex-shrink-0 flex items-center gap-2\"><template x-if=\"job.status === 'succeeded' && job.kind === 'band_content'\"><button type=\"button\" @click=\"toggleDetails(job)\" class=\"inline-flex items-center px-3 py-1.5 border border-gray-300 dark:border-gray-600 text-xs font-medium rounded-md text-gray-700 dark:text-gray-200 bg-white dark:bg-gray-800 hover:bg-gray-50 dark:hover:bg-gray-700\"><span x-text=\"details[job.id] ? 'Ocultar detalle' : 'Ver detalle'\"></span></button></template><template x-if=\"job.status === 'succeeded' && job.result_name && job.kind !== 'band_content'\"><a :href=\"'/api/jobs/' + job.id + '/download'\" class=\"inline-flex items-center px-3 py-1.5 border border-transparent text-xs font-medium rounded-md text-white bg-indigo-600 hover:bg-indigo-700\">Descargar</a></template><template x-if=\"job.status === 'queued'\"><button type=\"button\" @click=\"cancel(job)\" class=\"inline-flex items-center px-3 py-1.5 border border-gray-300 dark:border-gray-600 text-xs font-medium rounded-md text-gray-700 dark:text-gray-200 bg-white dark:bg-gray-800 hover:bg-gray-50 dark:hover:bg-gray-700\">Cancelar</button></template></div></li></template></ul></div></div></div>")
+		if templ_7745c5c3_Err != nil {
+			return templ_7745c5c3_Err
+		}
+		return nil
+	})
+}
+
+var _ = templruntime.GeneratedTemplate